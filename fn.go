@@ -3,6 +3,10 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/crossplane/function-sdk-go/logging"
@@ -10,10 +14,16 @@ import (
 	"github.com/crossplane/function-sdk-go/request"
 	"github.com/crossplane/function-sdk-go/resource"
 	"github.com/crossplane/function-sdk-go/response"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8sdiscovery "k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
 
 	"github.com/crossplane/function-kubecore-schema-registry/input/v1beta1"
 	"github.com/crossplane/function-kubecore-schema-registry/pkg/discovery"
+	"github.com/crossplane/function-kubecore-schema-registry/pkg/discovery/resolver"
 	"github.com/crossplane/function-kubecore-schema-registry/pkg/errors"
 	"github.com/crossplane/function-kubecore-schema-registry/pkg/initialization"
 	"github.com/crossplane/function-kubecore-schema-registry/pkg/labels"
@@ -34,6 +44,88 @@ type Function struct {
 	responseBuilder responsebuilder.Builder
 	config          *types.RegistryConfig
 	labelProcessor  *labels.Processor
+	clusterProvider *clusterClientProvider
+}
+
+// clusterClientProvider caches the REST config and RESTMapper across
+// invocations so each RunFunction call does not rebuild cluster discovery.
+// It is safe for concurrent use.
+type clusterClientProvider struct {
+	mu sync.Mutex
+
+	// refreshInterval controls how often the mapper's discovery cache is reset
+	refreshInterval time.Duration
+
+	newConfig func() (*rest.Config, error)
+	newMapper func(config *rest.Config) (meta.RESTMapper, error)
+
+	config      *rest.Config
+	mapper      meta.RESTMapper
+	refreshedAt time.Time
+}
+
+// newClusterClientProvider creates a provider backed by the in-cluster config
+func newClusterClientProvider(refreshInterval time.Duration) *clusterClientProvider {
+	return &clusterClientProvider{
+		refreshInterval: refreshInterval,
+		newConfig:       rest.InClusterConfig,
+		newMapper:       newDiscoveryRESTMapper,
+	}
+}
+
+// newDiscoveryRESTMapper creates a RESTMapper backed by cached API discovery
+func newDiscoveryRESTMapper(config *rest.Config) (meta.RESTMapper, error) {
+	discoveryClient, err := k8sdiscovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(discoveryClient)), nil
+}
+
+// Get returns the cached REST config and RESTMapper, creating them on first
+// use and resetting the mapper's discovery cache once the refresh interval passes
+func (p *clusterClientProvider) Get() (*rest.Config, meta.RESTMapper, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.config == nil {
+		config, err := p.newConfig()
+		if err != nil {
+			return nil, nil, errors.KubernetesClientError(fmt.Sprintf("failed to get in-cluster config: %v", err))
+		}
+
+		mapper, err := p.newMapper(config)
+		if err != nil {
+			return nil, nil, errors.KubernetesClientError(fmt.Sprintf("failed to create REST mapper: %v", err))
+		}
+
+		p.config = config
+		p.mapper = mapper
+		p.refreshedAt = time.Now()
+		return p.config, p.mapper, nil
+	}
+
+	if time.Since(p.refreshedAt) >= p.refreshInterval {
+		p.resetMapper()
+	}
+
+	return p.config, p.mapper, nil
+}
+
+// Invalidate resets the mapper's discovery cache, e.g. after a discovery error
+func (p *clusterClientProvider) Invalidate() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.resetMapper()
+}
+
+// resetMapper drops cached discovery data; callers must hold p.mu
+func (p *clusterClientProvider) resetMapper() {
+	if resettable, ok := p.mapper.(meta.ResettableRESTMapper); ok {
+		resettable.Reset()
+	}
+	p.refreshedAt = time.Now()
 }
 
 // NewFunction creates a new function instance
@@ -53,12 +145,12 @@ func NewFunction(log logging.Logger) *Function {
 	reg = registry.NewEmbeddedRegistry()
 
 	// Log registry initialization
-	if types, err := reg.ListResourceTypes(); err == nil {
-		log.Info("Registry initialized",
-			"mode", "embedded",
-			"total_types", len(types),
-			"configured_patterns", config.APIGroupPatterns)
-	}
+	health := reg.HealthCheck()
+	log.Info("Registry initialized",
+		"mode", "embedded",
+		"total_types", health.TotalTypes,
+		"healthy", health.Healthy,
+		"configured_patterns", config.APIGroupPatterns)
 
 	return &Function{
 		log:             log,
@@ -67,6 +159,7 @@ func NewFunction(log logging.Logger) *Function {
 		responseBuilder: responsebuilder.NewDefaultBuilder(),
 		config:          config,
 		labelProcessor:  labels.NewProcessor(log, "crossplane-system"), // TODO: Get actual function namespace
+		clusterProvider: newClusterClientProvider(config.CacheTTL),
 	}
 }
 
@@ -113,6 +206,9 @@ func (f *Function) RunFunction(ctx context.Context, req *fnv1.RunFunctionRequest
 		return rsp, nil
 	}
 
+	// desiredXR is the composite written to the desired state, if any
+	var desiredXR *resource.Composite
+
 	// Process XR label injection if enabled
 	if in.XRLabels != nil && in.XRLabels.Enabled {
 		f.log.Info("Starting XR label processing")
@@ -123,7 +219,7 @@ func (f *Function) RunFunction(ctx context.Context, req *fnv1.RunFunctionRequest
 		f.log.Info("XR label processing completed successfully")
 		
 		// Create a clean desired XR without problematic metadata fields
-		desiredXR := &resource.Composite{
+		desiredXR = &resource.Composite{
 			Resource: xr.Resource.DeepCopy(),
 		}
 		
@@ -171,6 +267,15 @@ func (f *Function) RunFunction(ctx context.Context, req *fnv1.RunFunctionRequest
 		return rsp, nil
 	}
 
+	// Requests sharing an 'into' name would overwrite each other's results
+	var intoAliases discovery.IntoAliases
+	if in.MergeDuplicateInto != nil && *in.MergeDuplicateInto {
+		fetchRequests, intoAliases = discovery.AliasDuplicateInto(fetchRequests)
+	} else if err := parser.ValidateUniqueInto(fetchRequests); err != nil {
+		response.Fatal(rsp, errors.Wrap(err, "invalid fetch requests"))
+		return rsp, nil
+	}
+
 	// Parse timeout and max concurrent settings
 	timeout := 5 * time.Second // default
 	maxConcurrent := 10        // default
@@ -198,21 +303,44 @@ func (f *Function) RunFunction(ctx context.Context, req *fnv1.RunFunctionRequest
 		"phase2Enabled", phase2Enabled,
 		"phase3Enabled", phase3Enabled)
 
+	// Report the plan without creating a discovery engine or calling the API
+	if in.DryRun != nil && *in.DryRun {
+		plan, err := f.dryRunPlan(fetchRequests, phase3Enabled, in.TraversalConfig)
+		if err != nil {
+			response.Fatal(rsp, errors.Wrap(err, "invalid fetch requests"))
+			return rsp, nil
+		}
+		for _, line := range plan {
+			response.Normal(rsp, line)
+		}
+		f.log.Info("Dry run completed", "requestCount", len(fetchRequests))
+		return rsp, nil
+	}
+
 	// Create discovery engine with Phase 2/3 capabilities if enabled
-	discoveryEngine, err := f.createDiscoveryEngine(timeout, maxConcurrent, phase2Enabled, phase3Enabled, in.TraversalConfig)
+	discoveryEngine, err := f.createDiscoveryEngine(timeout, maxConcurrent, phase2Enabled, phase3Enabled, in.TraversalConfig, in.ReferencePatterns)
 	if err != nil {
 		response.Fatal(rsp, errors.Wrap(err, "failed to create discovery engine"))
 		return rsp, nil
 	}
 
+	// Release caches and background goroutines held by the engine
+	if closer, ok := discoveryEngine.(io.Closer); ok {
+		defer closer.Close()
+	}
+
 	// Fetch resources
 	f.log.Info("Starting resource fetch operations")
 	fetchResult, err := discoveryEngine.FetchResources(fetchRequests)
 	if err != nil {
+		// Cached discovery may be stale, refresh it for the next invocation
+		f.clusterProvider.Invalidate()
 		response.Fatal(rsp, errors.Wrap(err, "resource fetch failed"))
 		return rsp, nil
 	}
 
+	discovery.MergeDuplicateInto(fetchResult, fetchRequests, intoAliases)
+
 	// Log summary
 	f.log.Info("Resource fetch completed",
 		"totalRequested", fetchResult.Summary.TotalRequested,
@@ -221,12 +349,44 @@ func (f *Function) RunFunction(ctx context.Context, req *fnv1.RunFunctionRequest
 		"skipped", fetchResult.Summary.Skipped,
 		"duration", fetchResult.Summary.TotalDuration)
 
+	// Required resources that could not be fetched stop the pipeline
+	failOnMissingRequired := in.FailOnMissingRequired == nil || *in.FailOnMissingRequired
+	if failOnMissingRequired && len(fetchResult.Summary.Errors) > 0 {
+		response.Fatal(rsp, requiredFetchError(fetchResult.Summary.Errors))
+		return rsp, nil
+	}
+
 	// Build and set response context
 	if err := f.responseBuilder.SetContext(rsp, fetchResult); err != nil {
 		response.Fatal(rsp, errors.Wrap(err, "failed to build response context"))
 		return rsp, nil
 	}
 
+	// Let Crossplane fetch the discovered resources itself
+	if in.EmitRequirements != nil && *in.EmitRequirements {
+		responsebuilder.SetExtraResourceRequirements(rsp, fetchResult)
+	}
+
+	// Record traversal summary counts on the desired XR
+	if in.TraversalConfig != nil && in.TraversalConfig.AnnotateSummary && fetchResult.TraversalSummary != nil {
+		if desiredXR == nil {
+			desiredXR, err = request.GetDesiredCompositeResource(req)
+			if err != nil {
+				response.Fatal(rsp, errors.Wrap(err, "cannot get desired composite resource"))
+				return rsp, nil
+			}
+		}
+		annotateTraversalSummary(desiredXR, fetchResult.TraversalSummary)
+		response.SetDesiredCompositeResource(rsp, desiredXR)
+	}
+
+	// Let the composition author know why expected resources may be missing
+	if len(fetchResult.DiscoveryErrors) > 0 {
+		f.log.Info("Discovery completed with recoverable errors", "count", len(fetchResult.DiscoveryErrors))
+		response.Warning(rsp, fmt.Errorf("%d discovery errors occurred, see context key %s",
+			len(fetchResult.DiscoveryErrors), responsebuilder.DiscoveryErrorsContextKey))
+	}
+
 	// Set appropriate response conditions
 	if fetchResult.Summary.Failed > 0 {
 		response.ConditionFalse(rsp, "ResourcesFetched", "SomeResourcesFailed").
@@ -254,12 +414,154 @@ func (f *Function) RunFunction(ctx context.Context, req *fnv1.RunFunctionRequest
 	return rsp, nil
 }
 
+// annotateTraversalSummary adds the traversal summary annotations to the
+// desired XR, keeping any annotations it already has
+func annotateTraversalSummary(desiredXR *resource.Composite, summary *discovery.TraversalSummary) {
+	annotations := desiredXR.Resource.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	for key, value := range summary.Annotations() {
+		annotations[key] = value
+	}
+	desiredXR.Resource.SetAnnotations(annotations)
+}
+
+// requiredFetchError describes the non-optional requests that could not be
+// fetched, identifying each resource by apiVersion, kind, namespace and name
+func requiredFetchError(fetchErrors []*discovery.FetchError) error {
+	failures := make([]string, 0, len(fetchErrors))
+	for _, fetchErr := range fetchErrors {
+		req := fetchErr.ResourceRequest
+
+		name := req.Name
+		if req.Namespace != nil && *req.Namespace != "" {
+			name = *req.Namespace + "/" + req.Name
+		}
+
+		failures = append(failures, fmt.Sprintf("%s/%s %s (into %q): %s: %s",
+			req.APIVersion, req.Kind, name, req.Into, fetchErr.Error.Code, fetchErr.Error.Message))
+	}
+	sort.Strings(failures)
+
+	return fmt.Errorf("%d required resources could not be fetched: %s",
+		len(failures), strings.Join(failures, "; "))
+}
+
+// dryRunPlan describes the fetches and traversal an invocation would perform,
+// one line per request, resolving resources from the registry or by guessing
+// the plural so that no API calls are made
+func (f *Function) dryRunPlan(requests []v1beta1.ResourceRequest, phase3Enabled bool, traversalConfig *v1beta1.TraversalConfig) ([]string, error) {
+	if err := discovery.NewKindAllowlist(f.config.AllowedKinds).Check(requests); err != nil {
+		return nil, err
+	}
+
+	plan := make([]string, 0, len(requests)+2)
+	for i, req := range requests {
+		gv, err := schema.ParseGroupVersion(req.APIVersion)
+		if err != nil {
+			return nil, errors.ValidationError(
+				fmt.Sprintf("fetchResources[%d].apiVersion '%s' is invalid: %v", i, req.APIVersion, err))
+		}
+		if req.Kind == "" {
+			return nil, errors.ValidationError(fmt.Sprintf("fetchResources[%d].kind is required", i))
+		}
+
+		resourceName, _ := meta.UnsafeGuessKindToResource(gv.WithKind(req.Kind))
+		if rt, err := f.registry.GetResourceType(req.APIVersion, req.Kind); err == nil && rt.Plural != "" {
+			resourceName = gv.WithResource(rt.Plural)
+		}
+		gvr := gv.String() + "/" + resourceName.Resource
+
+		matchType := req.MatchType
+		if matchType == "" {
+			matchType = v1beta1.MatchTypeDirect
+		}
+
+		switch matchType {
+		case v1beta1.MatchTypeDirect:
+			if req.Name == "" {
+				return nil, errors.ValidationError(fmt.Sprintf("fetchResources[%d].name is required", i))
+			}
+			name := req.Name
+			if req.Namespace != nil && *req.Namespace != "" {
+				name = *req.Namespace + "/" + req.Name
+			}
+			plan = append(plan, fmt.Sprintf("Dry run: fetchResources[%d] into %q would get %s %s",
+				i, req.Into, gvr, name))
+		default:
+			if req.Selector == nil {
+				return nil, errors.ValidationError(
+					fmt.Sprintf("fetchResources[%d].selector is required for matchType %s", i, matchType))
+			}
+			plan = append(plan, fmt.Sprintf("Dry run: fetchResources[%d] into %q would list %s matching %s",
+				i, req.Into, gvr, describeSelector(req.Selector)))
+		}
+	}
+
+	if phase3Enabled && traversalConfig != nil && traversalConfig.Enabled {
+		maxDepth := 3
+		if traversalConfig.MaxDepth != nil {
+			maxDepth = *traversalConfig.MaxDepth
+		}
+		direction := traversalConfig.Direction
+		if direction == "" {
+			direction = v1beta1.TraversalDirectionForward
+		}
+		plan = append(plan, fmt.Sprintf("Dry run: traversal would follow references %s up to depth %d",
+			direction, maxDepth))
+	}
+
+	plan = append(plan, fmt.Sprintf("Dry run: planned %d fetches, no API calls were made", len(requests)))
+	return plan, nil
+}
+
+// describeSelector renders a selector's labels, expressions and namespaces in
+// a stable order for dry run results
+func describeSelector(selector *v1beta1.Selector) string {
+	var terms []string
+	if selector.Labels != nil {
+		keys := make([]string, 0, len(selector.Labels.MatchLabels))
+		for key := range selector.Labels.MatchLabels {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			terms = append(terms, key+"="+selector.Labels.MatchLabels[key])
+		}
+		for _, requirement := range selector.Labels.MatchExpressions {
+			terms = append(terms, fmt.Sprintf("%s %s (%s)",
+				requirement.Key, requirement.Operator, strings.Join(requirement.Values, ",")))
+		}
+	}
+	for _, expression := range selector.Expressions {
+		value := strings.Join(expression.Values, ",")
+		if expression.Value != nil {
+			value = *expression.Value
+		}
+		terms = append(terms, fmt.Sprintf("%s %s %s", expression.Field, expression.Operator, value))
+	}
+
+	description := "{" + strings.Join(terms, ", ") + "}"
+	if len(selector.Namespaces) > 0 {
+		description += " in namespaces " + strings.Join(selector.Namespaces, ",")
+	}
+	return description
+}
+
 // createDiscoveryEngine creates a Kubernetes discovery engine
-func (f *Function) createDiscoveryEngine(timeout time.Duration, maxConcurrent int, phase2Enabled bool, phase3Enabled bool, traversalConfig *v1beta1.TraversalConfig) (discovery.Engine, error) {
-	// Get in-cluster configuration
-	config, err := rest.InClusterConfig()
+func (f *Function) createDiscoveryEngine(timeout time.Duration, maxConcurrent int, phase2Enabled bool, phase3Enabled bool, traversalConfig *v1beta1.TraversalConfig, referencePatterns *v1beta1.ReferencePatternsConfig) (discovery.Engine, error) {
+	// Get the cached in-cluster configuration and REST mapper
+	config, mapper, err := f.clusterProvider.Get()
 	if err != nil {
-		return nil, errors.KubernetesClientError(fmt.Sprintf("failed to get in-cluster config: %v", err))
+		return nil, err
+	}
+
+	allowedKinds := discovery.NewKindAllowlist(f.config.AllowedKinds)
+	fetchRetry := resolver.RetryPolicy{
+		MaxRetries: f.config.FetchMaxRetries,
+		Backoff:    f.config.FetchRetryBackoff,
+		Jitter:     f.config.FetchRetryJitter,
 	}
 
 	// Use enhanced discovery engine if Phase 2 or 3 is enabled
@@ -270,12 +572,16 @@ func (f *Function) createDiscoveryEngine(timeout time.Duration, maxConcurrent in
 			TimeoutPerRequest:     timeout,
 			MaxConcurrentRequests: maxConcurrent,
 			Phase2Enabled:         true, // Phase 3 builds on Phase 2
+			FetchRetry:            fetchRetry,
 		}
 
 		engine, err := discovery.NewEnhancedDiscoveryEngine(config, f.registry, discoveryContext, traversalConfig, f.log)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to create Phase 3 discovery engine")
 		}
+		engine.SetReferencePatterns(referencePatterns)
+		engine.SetRESTMapper(mapper)
+		engine.SetKindAllowlist(allowedKinds)
 
 		return engine, nil
 	} else if phase2Enabled {
@@ -285,12 +591,15 @@ func (f *Function) createDiscoveryEngine(timeout time.Duration, maxConcurrent in
 			TimeoutPerRequest:     timeout,
 			MaxConcurrentRequests: maxConcurrent,
 			Phase2Enabled:         true,
+			FetchRetry:            fetchRetry,
 		}
 
 		engine, err := discovery.NewEnhancedEngine(config, f.registry, discoveryContext)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to create enhanced discovery engine")
 		}
+		engine.SetRESTMapper(mapper)
+		engine.SetKindAllowlist(allowedKinds)
 
 		return engine, nil
 	} else {
@@ -299,6 +608,9 @@ func (f *Function) createDiscoveryEngine(timeout time.Duration, maxConcurrent in
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to create Kubernetes discovery engine")
 		}
+		engine.SetRESTMapper(mapper)
+		engine.SetKindAllowlist(allowedKinds)
+		engine.SetRetryPolicy(fetchRetry)
 
 		return engine, nil
 	}
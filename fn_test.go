@@ -4,9 +4,18 @@ package main
 
 import (
 	"context"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/rest"
 
 	"github.com/crossplane/function-sdk-go/logging"
 	fnv1 "github.com/crossplane/function-sdk-go/proto/v1"
@@ -610,6 +619,7 @@ func TestXRLabelInjection(t *testing.T) {
 		reason           string
 		args             args
 		expectedLabels   map[string]string
+		absentLabels     []string
 		shouldError      bool
 		checkDesiredXR   bool
 	}{
@@ -787,6 +797,58 @@ func TestXRLabelInjection(t *testing.T) {
 			},
 			checkDesiredXR: true,
 		},
+		"RemoveLabels": {
+			reason: "Should remove stale labels from the XR after adds and merges",
+			args: args{
+				ctx: context.Background(),
+				req: &fnv1.RunFunctionRequest{
+					Meta: &fnv1.RequestMeta{Tag: "test"},
+					Observed: &fnv1.State{
+						Composite: &fnv1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion": "test.kubecore.io/v1alpha1",
+								"kind": "TestXR",
+								"metadata": {
+									"name": "test-xr",
+									"namespace": "production",
+									"labels": {
+										"kubecore.io/scope": "namespace-staging",
+										"kubecore.io/legacy": "true",
+										"team": "platform"
+									}
+								},
+								"spec": {}
+							}`),
+						},
+					},
+					Input: resource.MustStructJSON(`{
+						"apiVersion": "registry.fn.crossplane.io/v1beta1",
+						"kind": "Input",
+						"xrLabels": {
+							"enabled": true,
+							"labels": {
+								"environment": "production"
+							},
+							"removeLabels": ["kubecore.io/legacy"],
+							"conditionalRemovals": [
+								{
+									"key": "kubecore.io/scope",
+									"condition": "label-mismatch",
+									"value": "namespace-production"
+								}
+							]
+						},
+						"fetchResources": []
+					}`),
+				},
+			},
+			expectedLabels: map[string]string{
+				"environment": "production",
+				"team":        "platform",
+			},
+			absentLabels:   []string{"kubecore.io/legacy", "kubecore.io/scope"},
+			checkDesiredXR: true,
+		},
 		"LabelInjectionDisabled": {
 			reason: "Should not apply labels when disabled",
 			args: args{
@@ -893,6 +955,13 @@ func TestXRLabelInjection(t *testing.T) {
 						t.Errorf("%s\nLabel %s: expected %s, got %s", tc.reason, expectedKey, expectedValue, actualValue)
 					}
 				}
+
+				// Check removed labels are absent
+				for _, absentKey := range tc.absentLabels {
+					if _, exists := desiredLabels[absentKey]; exists {
+						t.Errorf("%s\nExpected label %s to be absent from desired XR", tc.reason, absentKey)
+					}
+				}
 			}
 		})
 	}
@@ -949,3 +1018,635 @@ func TestEmbeddedRegistry(t *testing.T) {
 		})
 	}
 }
+
+func TestClusterClientProviderReuse(t *testing.T) {
+	f := NewFunction(logging.NewNopLogger())
+
+	configCalls, mapperCalls := 0, 0
+	provider := newClusterClientProvider(time.Hour)
+	provider.newConfig = func() (*rest.Config, error) {
+		configCalls++
+		// Nothing listens here, so fetches fail fast without a cluster
+		return &rest.Config{Host: "http://127.0.0.1:1"}, nil
+	}
+	provider.newMapper = func(_ *rest.Config) (meta.RESTMapper, error) {
+		mapperCalls++
+		return meta.NewDefaultRESTMapper(nil), nil
+	}
+	f.clusterProvider = provider
+
+	req := &fnv1.RunFunctionRequest{
+		Meta: &fnv1.RequestMeta{Tag: "test"},
+		Observed: &fnv1.State{
+			Composite: &fnv1.Resource{
+				Resource: resource.MustStructJSON(`{
+					"apiVersion": "test.kubecore.io/v1alpha1",
+					"kind": "TestXR",
+					"metadata": {
+						"name": "test-xr"
+					}
+				}`),
+			},
+		},
+		Input: resource.MustStructJSON(`{
+			"apiVersion": "registry.fn.crossplane.io/v1beta1",
+			"kind": "Input",
+			"fetchTimeout": "1s",
+			"fetchResources": [
+				{
+					"into": "config",
+					"apiVersion": "v1",
+					"kind": "ConfigMap",
+					"name": "test-config",
+					"namespace": "default",
+					"optional": true
+				}
+			]
+		}`),
+	}
+
+	var mappers []meta.RESTMapper
+	for i := 0; i < 2; i++ {
+		if _, err := f.RunFunction(context.Background(), req); err != nil {
+			t.Fatalf("RunFunction call %d: unexpected error: %v", i, err)
+		}
+		mappers = append(mappers, provider.mapper)
+	}
+
+	if configCalls != 1 || mapperCalls != 1 {
+		t.Errorf("expected config and mapper to be created once, got %d and %d", configCalls, mapperCalls)
+	}
+	if mappers[0] == nil || mappers[0] != mappers[1] {
+		t.Errorf("expected both invocations to reuse the same cached mapper")
+	}
+}
+
+func TestRequiredResourceMissing(t *testing.T) {
+	// The API server knows no resources, so every fetch is not found and every
+	// list is empty
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if path.Base(r.URL.Path) == "configmaps" {
+			_, _ = w.Write([]byte(`{"kind":"ConfigMapList","apiVersion":"v1","metadata":{},"items":[]}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404}`))
+	}))
+	defer server.Close()
+
+	byName := `"name": "app-config"`
+	bySelector := `"matchType": "label", "selector": {"labels": {"matchLabels": {"app": "config"}}}`
+
+	cases := map[string]struct {
+		reason    string
+		phase2    string
+		match     string
+		optional  string
+		wantFatal bool
+		wantError string
+	}{
+		"RequiredConfigMapAbsent": {
+			reason:    "A missing non-optional resource should return a fatal result",
+			phase2:    "false",
+			match:     byName,
+			optional:  "false",
+			wantFatal: true,
+			wantError: "v1/ConfigMap default/app-config",
+		},
+		"OptionalConfigMapAbsent": {
+			reason:    "A missing optional resource should not return a fatal result",
+			phase2:    "false",
+			match:     byName,
+			optional:  "true",
+			wantFatal: false,
+		},
+		"Phase2RequiredConfigMapAbsent": {
+			reason:    "A missing non-optional resource should return a fatal result with phase2Features",
+			phase2:    "true",
+			match:     byName,
+			optional:  "false",
+			wantFatal: true,
+			wantError: "v1/ConfigMap default/app-config",
+		},
+		"Phase2OptionalConfigMapAbsent": {
+			reason:    "A missing optional resource should not return a fatal result with phase2Features",
+			phase2:    "true",
+			match:     byName,
+			optional:  "true",
+			wantFatal: false,
+		},
+		"Phase2RequiredSelectorMatchesNothing": {
+			reason:    "A non-optional selector matching nothing should return a fatal result",
+			phase2:    "true",
+			match:     bySelector,
+			optional:  "false",
+			wantFatal: true,
+			wantError: "RESOURCE_NOT_FOUND",
+		},
+		"Phase2OptionalSelectorMatchesNothing": {
+			reason:    "An optional selector matching nothing should not return a fatal result",
+			phase2:    "true",
+			match:     bySelector,
+			optional:  "true",
+			wantFatal: false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			f := NewFunction(logging.NewNopLogger())
+			provider := newClusterClientProvider(time.Hour)
+			provider.newConfig = func() (*rest.Config, error) {
+				return &rest.Config{Host: server.URL}, nil
+			}
+			provider.newMapper = func(_ *rest.Config) (meta.RESTMapper, error) {
+				return meta.NewDefaultRESTMapper(nil), nil
+			}
+			f.clusterProvider = provider
+
+			req := &fnv1.RunFunctionRequest{
+				Meta: &fnv1.RequestMeta{Tag: "test"},
+				Observed: &fnv1.State{
+					Composite: &fnv1.Resource{
+						Resource: resource.MustStructJSON(`{
+							"apiVersion": "test.kubecore.io/v1alpha1",
+							"kind": "TestXR",
+							"metadata": {
+								"name": "test-xr"
+							}
+						}`),
+					},
+				},
+				Input: resource.MustStructJSON(`{
+					"apiVersion": "registry.fn.crossplane.io/v1beta1",
+					"kind": "Input",
+					"phase2Features": ` + tc.phase2 + `,
+					"fetchResources": [
+						{
+							"into": "config",
+							"apiVersion": "v1",
+							"kind": "ConfigMap",
+							` + tc.match + `,
+							"namespace": "default",
+							"optional": ` + tc.optional + `
+						}
+					]
+				}`),
+			}
+
+			rsp, err := f.RunFunction(context.Background(), req)
+			if err != nil {
+				t.Fatalf("%s\nUnexpected error: %v", tc.reason, err)
+			}
+
+			var fatal *fnv1.Result
+			for _, result := range rsp.GetResults() {
+				if result.GetSeverity() == fnv1.Severity_SEVERITY_FATAL {
+					fatal = result
+				}
+			}
+
+			if tc.wantFatal != (fatal != nil) {
+				t.Fatalf("%s\nExpected fatal result: %t, got results: %v", tc.reason, tc.wantFatal, rsp.GetResults())
+			}
+			if fatal != nil && !strings.Contains(fatal.GetMessage(), tc.wantError) {
+				t.Errorf("%s\nExpected fatal message to identify the resource, got: %s", tc.reason, fatal.GetMessage())
+			}
+		})
+	}
+}
+
+func TestDuplicateIntoNames(t *testing.T) {
+	// The API server serves the app-config and db-config ConfigMaps by name
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		name := path.Base(r.URL.Path)
+		if r.Method != http.MethodGet || (name != "app-config" && name != "db-config") {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"` + name + `","namespace":"default"}}`))
+	}))
+	defer server.Close()
+
+	cases := map[string]struct {
+		reason    string
+		merge     string
+		wantFatal bool
+		wantNames []string
+	}{
+		"RejectDuplicates": {
+			reason:    "Duplicate into names should return a fatal result by default",
+			merge:     "false",
+			wantFatal: true,
+		},
+		"MergeDuplicates": {
+			reason:    "Duplicate into names should collect both results when merging is enabled",
+			merge:     "true",
+			wantNames: []string{"app-config", "db-config"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			f := NewFunction(logging.NewNopLogger())
+			provider := newClusterClientProvider(time.Hour)
+			provider.newConfig = func() (*rest.Config, error) {
+				return &rest.Config{Host: server.URL}, nil
+			}
+			provider.newMapper = func(_ *rest.Config) (meta.RESTMapper, error) {
+				return meta.NewDefaultRESTMapper(nil), nil
+			}
+			f.clusterProvider = provider
+
+			req := &fnv1.RunFunctionRequest{
+				Meta: &fnv1.RequestMeta{Tag: "test"},
+				Observed: &fnv1.State{
+					Composite: &fnv1.Resource{
+						Resource: resource.MustStructJSON(`{
+							"apiVersion": "test.kubecore.io/v1alpha1",
+							"kind": "TestXR",
+							"metadata": {
+								"name": "test-xr"
+							}
+						}`),
+					},
+				},
+				Input: resource.MustStructJSON(`{
+					"apiVersion": "registry.fn.crossplane.io/v1beta1",
+					"kind": "Input",
+					"mergeDuplicateInto": ` + tc.merge + `,
+					"fetchResources": [
+						{
+							"into": "config",
+							"apiVersion": "v1",
+							"kind": "ConfigMap",
+							"name": "app-config",
+							"namespace": "default"
+						},
+						{
+							"into": "config",
+							"apiVersion": "v1",
+							"kind": "ConfigMap",
+							"name": "db-config",
+							"namespace": "default"
+						}
+					]
+				}`),
+			}
+
+			rsp, err := f.RunFunction(context.Background(), req)
+			if err != nil {
+				t.Fatalf("%s\nUnexpected error: %v", tc.reason, err)
+			}
+
+			var fatal *fnv1.Result
+			for _, result := range rsp.GetResults() {
+				if result.GetSeverity() == fnv1.Severity_SEVERITY_FATAL {
+					fatal = result
+				}
+			}
+
+			if tc.wantFatal != (fatal != nil) {
+				t.Fatalf("%s\nExpected fatal result: %t, got results: %v", tc.reason, tc.wantFatal, rsp.GetResults())
+			}
+			if fatal != nil {
+				if !strings.Contains(fatal.GetMessage(), "duplicates fetchResources[0]") {
+					t.Errorf("%s\nExpected fatal message to identify the duplicate, got: %s", tc.reason, fatal.GetMessage())
+				}
+				return
+			}
+
+			fetched := rsp.GetContext().GetFields()["kubecore-schema-registry.fn.kubecore.platform.io/fetched-resources"]
+			list := fetched.GetStructValue().GetFields()["multiResources"].GetStructValue().GetFields()["config"].GetListValue().GetValues()
+
+			var got []string
+			for _, item := range list {
+				metadata := item.GetStructValue().GetFields()["metadata"].GetStructValue()
+				got = append(got, metadata.GetFields()["name"].GetStringValue())
+			}
+			if strings.Join(got, ",") != strings.Join(tc.wantNames, ",") {
+				t.Errorf("%s\nExpected merged resources %v, got %v", tc.reason, tc.wantNames, got)
+			}
+		})
+	}
+}
+
+func TestTraversalSummaryAnnotations(t *testing.T) {
+	// The API server serves the app-config ConfigMap, which references nothing
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != http.MethodGet || path.Base(r.URL.Path) != "app-config" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"app-config","namespace":"default"}}`))
+	}))
+	defer server.Close()
+
+	cases := map[string]struct {
+		reason          string
+		annotateSummary string
+		want            map[string]string
+	}{
+		"AnnotateSummary": {
+			reason:          "The traversal summary counts should be added to the desired XR's existing annotations",
+			annotateSummary: "true",
+			want: map[string]string{
+				"example.org/owner":                "platform",
+				"kubecore.io/discovered-resources": "1",
+				"kubecore.io/max-depth-reached":    "0",
+				"kubecore.io/cycles-detected":      "0",
+			},
+		},
+		"SummaryNotRequested": {
+			reason:          "The desired XR's annotations should be unchanged unless annotateSummary is enabled",
+			annotateSummary: "false",
+			want: map[string]string{
+				"example.org/owner": "platform",
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			f := NewFunction(logging.NewNopLogger())
+			provider := newClusterClientProvider(time.Hour)
+			provider.newConfig = func() (*rest.Config, error) {
+				return &rest.Config{Host: server.URL}, nil
+			}
+			provider.newMapper = func(_ *rest.Config) (meta.RESTMapper, error) {
+				return meta.NewDefaultRESTMapper(nil), nil
+			}
+			f.clusterProvider = provider
+
+			req := &fnv1.RunFunctionRequest{
+				Meta: &fnv1.RequestMeta{Tag: "test"},
+				Observed: &fnv1.State{
+					Composite: &fnv1.Resource{
+						Resource: resource.MustStructJSON(`{
+							"apiVersion": "test.kubecore.io/v1alpha1",
+							"kind": "TestXR",
+							"metadata": {
+								"name": "test-xr"
+							}
+						}`),
+					},
+				},
+				Desired: &fnv1.State{
+					Composite: &fnv1.Resource{
+						Resource: resource.MustStructJSON(`{
+							"apiVersion": "test.kubecore.io/v1alpha1",
+							"kind": "TestXR",
+							"metadata": {
+								"name": "test-xr",
+								"annotations": {
+									"example.org/owner": "platform"
+								}
+							}
+						}`),
+					},
+				},
+				Input: resource.MustStructJSON(`{
+					"apiVersion": "registry.fn.crossplane.io/v1beta1",
+					"kind": "Input",
+					"phase3Features": true,
+					"traversalConfig": {
+						"enabled": true,
+						"maxDepth": 0,
+						"annotateSummary": ` + tc.annotateSummary + `
+					},
+					"fetchResources": [
+						{
+							"into": "config",
+							"apiVersion": "v1",
+							"kind": "ConfigMap",
+							"name": "app-config",
+							"namespace": "default"
+						}
+					]
+				}`),
+			}
+
+			rsp, err := f.RunFunction(context.Background(), req)
+			if err != nil {
+				t.Fatalf("%s\nUnexpected error: %v", tc.reason, err)
+			}
+			for _, result := range rsp.GetResults() {
+				if result.GetSeverity() == fnv1.Severity_SEVERITY_FATAL {
+					t.Fatalf("%s\nUnexpected fatal result: %s", tc.reason, result.GetMessage())
+				}
+			}
+
+			xr, err := request.GetDesiredCompositeResource(&fnv1.RunFunctionRequest{Desired: rsp.GetDesired()})
+			if err != nil {
+				t.Fatalf("%s\nCannot get desired XR: %v", tc.reason, err)
+			}
+			if got := xr.Resource.GetAnnotations(); !maps.Equal(tc.want, got) {
+				t.Errorf("%s\nExpected desired XR annotations %v, got %v", tc.reason, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	var apiCalls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		apiCalls.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	f := NewFunction(logging.NewNopLogger())
+	configCalls := 0
+	provider := newClusterClientProvider(time.Hour)
+	provider.newConfig = func() (*rest.Config, error) {
+		configCalls++
+		return &rest.Config{Host: server.URL}, nil
+	}
+	provider.newMapper = func(_ *rest.Config) (meta.RESTMapper, error) {
+		return meta.NewDefaultRESTMapper(nil), nil
+	}
+	f.clusterProvider = provider
+
+	req := &fnv1.RunFunctionRequest{
+		Meta: &fnv1.RequestMeta{Tag: "test"},
+		Observed: &fnv1.State{
+			Composite: &fnv1.Resource{
+				Resource: resource.MustStructJSON(`{
+					"apiVersion": "test.kubecore.io/v1alpha1",
+					"kind": "TestXR",
+					"metadata": {
+						"name": "test-xr"
+					}
+				}`),
+			},
+		},
+		Input: resource.MustStructJSON(`{
+			"apiVersion": "registry.fn.crossplane.io/v1beta1",
+			"kind": "Input",
+			"dryRun": true,
+			"phase3Features": true,
+			"traversalConfig": {
+				"enabled": true,
+				"maxDepth": 2
+			},
+			"fetchResources": [
+				{
+					"into": "config",
+					"apiVersion": "v1",
+					"kind": "ConfigMap",
+					"name": "app-config",
+					"namespace": "default"
+				},
+				{
+					"into": "apps",
+					"apiVersion": "apps/v1",
+					"kind": "Deployment",
+					"matchType": "label",
+					"selector": {
+						"labels": {
+							"matchLabels": {"tier": "web", "app": "shop"}
+						},
+						"namespaces": ["default"]
+					}
+				}
+			]
+		}`),
+	}
+
+	rsp, err := f.RunFunction(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if configCalls != 0 || apiCalls.Load() != 0 {
+		t.Errorf("Expected no client calls in dry run, got %d config and %d API calls", configCalls, apiCalls.Load())
+	}
+
+	var messages []string
+	for _, result := range rsp.GetResults() {
+		if result.GetSeverity() != fnv1.Severity_SEVERITY_NORMAL {
+			t.Errorf("Expected only normal results, got %s: %s", result.GetSeverity(), result.GetMessage())
+		}
+		messages = append(messages, result.GetMessage())
+	}
+	plan := strings.Join(messages, "\n")
+
+	for _, want := range []string{
+		`fetchResources[0] into "config" would get v1/configmaps default/app-config`,
+		`fetchResources[1] into "apps" would list apps/v1/deployments matching {app=shop, tier=web} in namespaces default`,
+		"traversal would follow references forward up to depth 2",
+		"planned 2 fetches",
+	} {
+		if !strings.Contains(plan, want) {
+			t.Errorf("Expected dry run plan to contain %q, got:\n%s", want, plan)
+		}
+	}
+	if rsp.GetContext() != nil && len(rsp.GetContext().GetFields()) > 0 {
+		t.Errorf("Expected dry run to leave the context empty, got %v", rsp.GetContext())
+	}
+}
+
+func TestKindAllowlist(t *testing.T) {
+	// The API server serves any object by name and counts the requests it receives
+	var apiCalls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiCalls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		kind := "ConfigMap"
+		if strings.Contains(r.URL.Path, "/secrets/") {
+			kind = "Secret"
+		}
+		_, _ = w.Write([]byte(`{"apiVersion":"v1","kind":"` + kind + `","metadata":{"name":"` + path.Base(r.URL.Path) + `","namespace":"default"}}`))
+	}))
+	defer server.Close()
+
+	cases := map[string]struct {
+		reason       string
+		kind         string
+		wantFatal    bool
+		wantAPICalls bool
+	}{
+		"AllowedKind": {
+			reason:       "A kind on the allowlist should be fetched",
+			kind:         "ConfigMap",
+			wantAPICalls: true,
+		},
+		"DisallowedKind": {
+			reason:    "A kind missing from the allowlist should be rejected before any fetch",
+			kind:      "Secret",
+			wantFatal: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			apiCalls.Store(0)
+
+			f := NewFunction(logging.NewNopLogger())
+			f.config.AllowedKinds = []string{"ConfigMap", "Deployment.apps"}
+			provider := newClusterClientProvider(time.Hour)
+			provider.newConfig = func() (*rest.Config, error) {
+				return &rest.Config{Host: server.URL}, nil
+			}
+			provider.newMapper = func(_ *rest.Config) (meta.RESTMapper, error) {
+				return meta.NewDefaultRESTMapper(nil), nil
+			}
+			f.clusterProvider = provider
+
+			req := &fnv1.RunFunctionRequest{
+				Meta: &fnv1.RequestMeta{Tag: "test"},
+				Observed: &fnv1.State{
+					Composite: &fnv1.Resource{
+						Resource: resource.MustStructJSON(`{
+							"apiVersion": "test.kubecore.io/v1alpha1",
+							"kind": "TestXR",
+							"metadata": {
+								"name": "test-xr"
+							}
+						}`),
+					},
+				},
+				Input: resource.MustStructJSON(`{
+					"apiVersion": "registry.fn.crossplane.io/v1beta1",
+					"kind": "Input",
+					"fetchResources": [
+						{
+							"into": "target",
+							"apiVersion": "v1",
+							"kind": "` + tc.kind + `",
+							"name": "app",
+							"namespace": "default"
+						}
+					]
+				}`),
+			}
+
+			rsp, err := f.RunFunction(context.Background(), req)
+			if err != nil {
+				t.Fatalf("%s\nUnexpected error: %v", tc.reason, err)
+			}
+
+			var fatal *fnv1.Result
+			for _, result := range rsp.GetResults() {
+				if result.GetSeverity() == fnv1.Severity_SEVERITY_FATAL {
+					fatal = result
+				}
+			}
+
+			if tc.wantFatal != (fatal != nil) {
+				t.Fatalf("%s\nExpected fatal result: %t, got results: %v", tc.reason, tc.wantFatal, rsp.GetResults())
+			}
+			if fatal != nil && !strings.Contains(fatal.GetMessage(), "kind "+tc.kind+" (v1) is not in the allowed kinds") {
+				t.Errorf("%s\nExpected fatal message to name the kind, got: %s", tc.reason, fatal.GetMessage())
+			}
+			if tc.wantAPICalls != (apiCalls.Load() > 0) {
+				t.Errorf("%s\nExpected API calls: %t, got %d", tc.reason, tc.wantAPICalls, apiCalls.Load())
+			}
+		})
+	}
+}
@@ -27,6 +27,27 @@ type Input struct {
 	// +kubebuilder:validation:Pattern="^[0-9]+(s|m|h)$"
 	FetchTimeout *string `json:"fetchTimeout,omitempty"`
 
+	// FailOnMissingRequired returns a fatal result when a non-optional fetch request
+	// cannot be fetched, so compositions do not proceed without their dependencies
+	// +kubebuilder:default=true
+	FailOnMissingRequired *bool `json:"failOnMissingRequired,omitempty"`
+
+	// MergeDuplicateInto collects requests that share an 'into' name under that
+	// name in multiResources instead of rejecting the input
+	// +kubebuilder:default=false
+	MergeDuplicateInto *bool `json:"mergeDuplicateInto,omitempty"`
+
+	// DryRun validates the input and reports the planned fetches and traversal
+	// as results without calling the Kubernetes API
+	// +kubebuilder:default=false
+	DryRun *bool `json:"dryRun,omitempty"`
+
+	// EmitRequirements also lists the fetched and discovered resources as
+	// extra resource requirements in the response, so Crossplane fetches and
+	// refreshes them for the next invocation
+	// +kubebuilder:default=false
+	EmitRequirements *bool `json:"emitRequirements,omitempty"`
+
 	// MaxConcurrentFetches limits the number of concurrent fetch operations
 	// +kubebuilder:default=10
 	// +kubebuilder:validation:Minimum=1
@@ -44,6 +65,10 @@ type Input struct {
 	// TraversalConfig contains configuration for Phase 3 transitive discovery
 	TraversalConfig *TraversalConfig `json:"traversalConfig,omitempty"`
 
+	// ReferencePatterns adds reference detection patterns for this invocation,
+	// on top of or instead of the built-in ones
+	ReferencePatterns *ReferencePatternsConfig `json:"referencePatterns,omitempty"`
+
 	// XRLabels enables XR label injection capabilities
 	XRLabels *XRLabelConfig `json:"xrLabels,omitempty"`
 }
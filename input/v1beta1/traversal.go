@@ -8,11 +8,12 @@ type TraversalConfig struct {
 	// +kubebuilder:default=false
 	Enabled bool `json:"enabled,omitempty"`
 
-	// MaxDepth limits the depth of transitive discovery
+	// MaxDepth limits the depth of transitive discovery. A value of 0 returns
+	// only the root resources without following any references.
 	// +kubebuilder:default=3
-	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Minimum=0
 	// +kubebuilder:validation:Maximum=10
-	MaxDepth int `json:"maxDepth,omitempty"`
+	MaxDepth *int `json:"maxDepth,omitempty"`
 
 	// MaxResources limits the total number of resources to discover
 	// +kubebuilder:default=100
@@ -20,6 +21,20 @@ type TraversalConfig struct {
 	// +kubebuilder:validation:Maximum=1000
 	MaxResources int `json:"maxResources,omitempty"`
 
+	// MaxEdges limits the total number of edges in the resource graph.
+	// Traversal stops expanding once the limit is reached. Zero means
+	// unlimited.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxEdges int `json:"maxEdges,omitempty"`
+
+	// MaxRoots limits how many Phase 1/2 results are seeded into traversal as
+	// roots. Roots are ordered by 'into' name and then by their position in
+	// the fetch result, and only the first maxRoots are kept. 0 means no limit.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxRoots int `json:"maxRoots,omitempty"`
+
 	// Timeout limits the total time for traversal
 	// +kubebuilder:default="10s"
 	// +kubebuilder:validation:Pattern="^[0-9]+(s|m|h)$"
@@ -30,6 +45,33 @@ type TraversalConfig struct {
 	// +kubebuilder:default="forward"
 	Direction TraversalDirection `json:"direction,omitempty"`
 
+	// SchedulingMode chooses the order in which forward traversal expands
+	// discovered resources. BreadthFirst expands a whole depth at a time;
+	// BestFirst expands the resource reached through the most confident
+	// references first, which finds the most relevant chains sooner when
+	// maxResources cuts traversal short.
+	// +kubebuilder:validation:Enum=BreadthFirst;BestFirst
+	// +kubebuilder:default="BreadthFirst"
+	SchedulingMode SchedulingMode `json:"schedulingMode,omitempty"`
+
+	// TargetKinds makes discovery goal-directed: only resources of these
+	// kinds, e.g. ["Secret"], are returned, while references are still
+	// followed through resources of other kinds to reach them.
+	// +optional
+	TargetKinds []string `json:"targetKinds,omitempty"`
+
+	// IncludeCompositionMetadata adds the CompositeResourceDefinition and the
+	// selected Composition of every discovered composite resource to the
+	// graph, linked from the composite
+	// +optional
+	IncludeCompositionMetadata bool `json:"includeCompositionMetadata,omitempty"`
+
+	// CrossNamespace controls whether references are followed into other
+	// namespaces. When set it overrides scopeFilter.crossNamespaceEnabled, and
+	// when false references naming another namespace are skipped with a warning.
+	// +optional
+	CrossNamespace *bool `json:"crossNamespace,omitempty"`
+
 	// ScopeFilter determines which resources to include in traversal
 	ScopeFilter *ScopeFilterConfig `json:"scopeFilter,omitempty"`
 
@@ -47,8 +89,33 @@ type TraversalConfig struct {
 
 	// Performance controls performance optimization
 	Performance *PerformanceConfig `json:"performance,omitempty"`
+
+	// ResultMerge controls how discovered resources are surfaced in the
+	// fetch result. Resources already fetched by fetchResources are never
+	// added twice.
+	// +kubebuilder:validation:Enum=separate;into
+	// +kubebuilder:default="separate"
+	ResultMerge ResultMergeStrategy `json:"resultMerge,omitempty"`
+
+	// AnnotateSummary writes traversal summary counts to the desired XR as
+	// kubecore.io/discovered-resources, kubecore.io/max-depth-reached and
+	// kubecore.io/cycles-detected annotations.
+	// +optional
+	AnnotateSummary bool `json:"annotateSummary,omitempty"`
 }
 
+// ResultMergeStrategy defines how transitive discoveries are merged into the fetch result
+type ResultMergeStrategy string
+
+const (
+	// ResultMergeSeparate stores each discovered resource under its own
+	// "phase3_<resource id>" key
+	ResultMergeSeparate ResultMergeStrategy = "separate"
+	// ResultMergeInto appends each discovered resource to the 'into' of the
+	// fetch request whose resource it was discovered from
+	ResultMergeInto ResultMergeStrategy = "into"
+)
+
 // TraversalDirection defines the direction of graph traversal
 type TraversalDirection string
 
@@ -61,6 +128,16 @@ const (
 	TraversalDirectionBidirectional TraversalDirection = "bidirectional"
 )
 
+// SchedulingMode defines the order in which discovered resources are expanded
+type SchedulingMode string
+
+const (
+	// SchedulingModeBreadthFirst expands every resource at one depth before the next
+	SchedulingModeBreadthFirst SchedulingMode = "BreadthFirst"
+	// SchedulingModeBestFirst expands the most confidently referenced resource first
+	SchedulingModeBestFirst SchedulingMode = "BestFirst"
+)
+
 // ScopeFilterConfig controls which resources are included in traversal
 type ScopeFilterConfig struct {
 	// PlatformOnly limits traversal to platform resources only
@@ -89,6 +166,11 @@ type ScopeFilterConfig struct {
 
 	// ExcludeNamespaces specifies which namespaces to exclude
 	ExcludeNamespaces []string `json:"excludeNamespaces,omitempty"`
+
+	// SkipTerminatingResources drops discovered resources that are being
+	// deleted, i.e. have metadata.deletionTimestamp set
+	// +kubebuilder:default=false
+	SkipTerminatingResources bool `json:"skipTerminatingResources,omitempty"`
 }
 
 // BatchConfig controls batch processing optimization
@@ -172,14 +254,119 @@ type ReferenceResolutionConfig struct {
 	// +kubebuilder:default=true
 	SkipMissingReferences bool `json:"skipMissingReferences,omitempty"`
 
+	// FailOnForbidden aborts traversal when a referenced resource cannot be
+	// read because access is denied. By default such references are reported
+	// as access_denied warnings and traversal continues.
+	// +kubebuilder:default=false
+	FailOnForbidden bool `json:"failOnForbidden,omitempty"`
+
 	// MinConfidenceThreshold is the minimum confidence required for following references
 	// +kubebuilder:default=0.5
 	// +kubebuilder:validation:Minimum=0.0
 	// +kubebuilder:validation:Maximum=1.0
 	MinConfidenceThreshold float64 `json:"minConfidenceThreshold,omitempty"`
 
+	// LowConfidenceWarningThreshold reports a low_confidence validation
+	// warning for each resource whose references average a confidence below
+	// it, naming the resource kind. Such kinds are usually only matched by
+	// heuristics and should be added to the registry. Zero disables it.
+	// +kubebuilder:validation:Minimum=0.0
+	// +kubebuilder:validation:Maximum=1.0
+	// +optional
+	LowConfidenceWarningThreshold float64 `json:"lowConfidenceWarningThreshold,omitempty"`
+
 	// AdditionalPatterns contains additional patterns for detecting reference fields
 	AdditionalPatterns []ReferencePattern `json:"additionalPatterns,omitempty"`
+
+	// NamespaceRewrite maps a reference's target namespace to the namespace it is
+	// resolved in, e.g. {"template": "tenant-a"}. Applied after references
+	// without a namespace default to the source resource's namespace.
+	NamespaceRewrite map[string]string `json:"namespaceRewrite,omitempty"`
+
+	// GroupAliases maps renamed API groups to their new name, e.g.
+	// {"platform.kubecore.io": "core.kubecore.io"}. Lookups fall back to the
+	// original group when the aliased resource is not found.
+	GroupAliases map[string]string `json:"groupAliases,omitempty"`
+
+	// AnnotationReferences detects references encoded in annotations, e.g.
+	// a "kubecore.io/cluster" prefix pointing at KubeCluster resources.
+	// Annotations are not scanned unless at least one pattern is configured.
+	AnnotationReferences []AnnotationReferencePattern `json:"annotationReferences,omitempty"`
+
+	// CELReferences extract references from resources of a kind with a CEL
+	// expression, for references that field name patterns cannot describe,
+	// e.g. a target whose name and kind are stored in separate fields.
+	CELReferences []CELReference `json:"celReferences,omitempty"`
+
+	// ClusterSourceNamespace is the namespace that references from
+	// cluster-scoped resources, such as cluster XRs, resolve in when they do
+	// not name a namespace themselves
+	// +optional
+	ClusterSourceNamespace string `json:"clusterSourceNamespace,omitempty"`
+
+	// UseClaimNamespace resolves references from a cluster-scoped composite in
+	// its claim's namespace, read from the crossplane.io/claim-namespace label
+	// or annotation, before falling back to clusterSourceNamespace
+	// +optional
+	UseClaimNamespace bool `json:"useClaimNamespace,omitempty"`
+}
+
+// AnnotationReferencePattern maps annotations with a key prefix to a target type.
+// The annotation value is the name of the target resource.
+type AnnotationReferencePattern struct {
+	// KeyPrefix is the annotation key prefix that marks a reference
+	// +kubebuilder:validation:Required
+	KeyPrefix string `json:"keyPrefix"`
+
+	// TargetKind is the kind of the referenced resource
+	// +kubebuilder:validation:Required
+	TargetKind string `json:"targetKind"`
+
+	// TargetGroup is the API group of the referenced resource
+	TargetGroup string `json:"targetGroup,omitempty"`
+
+	// TargetVersion is the API version of the referenced resource
+	TargetVersion string `json:"targetVersion,omitempty"`
+
+	// Confidence is the confidence level of references from this pattern
+	// +kubebuilder:default=0.9
+	// +kubebuilder:validation:Minimum=0.0
+	// +kubebuilder:validation:Maximum=1.0
+	Confidence float64 `json:"confidence,omitempty"`
+}
+
+// CELReference extracts references from resources of one kind with a CEL
+// expression. The expression sees the resource as "object" and returns a map,
+// or a list of maps, with the target's "name" and "kind" and optionally its
+// "apiVersion" and "namespace", e.g.
+// {"name": object.spec.foo, "kind": object.spec.bar}.
+type CELReference struct {
+	// Kind is the kind of the resources the expression is evaluated against
+	// +kubebuilder:validation:Required
+	Kind string `json:"kind"`
+
+	// APIVersion restricts the expression to resources of this API version
+	APIVersion string `json:"apiVersion,omitempty"`
+
+	// Expression is the CEL expression producing the referenced targets
+	// +kubebuilder:validation:Required
+	Expression string `json:"expression"`
+
+	// Confidence is the confidence level of references from this expression
+	// +kubebuilder:default=0.9
+	// +kubebuilder:validation:Minimum=0.0
+	// +kubebuilder:validation:Maximum=1.0
+	Confidence float64 `json:"confidence,omitempty"`
+}
+
+// ReferencePatternsConfig supplies reference detection patterns from input
+type ReferencePatternsConfig struct {
+	// Patterns are matched against field names after the built-in patterns
+	Patterns []ReferencePattern `json:"patterns,omitempty"`
+
+	// ReplaceDefaults drops the built-in patterns so only Patterns are used
+	// +kubebuilder:default=false
+	ReplaceDefaults bool `json:"replaceDefaults,omitempty"`
 }
 
 // ReferencePattern defines a pattern for detecting reference fields
@@ -194,11 +381,21 @@ type ReferencePattern struct {
 	// TargetGroup is the expected target API group
 	TargetGroup string `json:"targetGroup,omitempty"`
 
+	// RefType is the kind of relationship the matched field expresses
+	// +kubebuilder:validation:Enum=configMap;secret;service;pvc;custom
+	// +kubebuilder:default="custom"
+	RefType string `json:"refType,omitempty"`
+
 	// Confidence is the confidence level of this pattern
 	// +kubebuilder:default=0.8
 	// +kubebuilder:validation:Minimum=0.0
 	// +kubebuilder:validation:Maximum=1.0
 	Confidence float64 `json:"confidence,omitempty"`
+
+	// NameTemplate derives the target name from the source resource when the
+	// field value is not itself the name, e.g. "{metadata.name}-config".
+	// "{value}" expands to the matched field's own value.
+	NameTemplate string `json:"nameTemplate,omitempty"`
 }
 
 // CycleHandlingConfig controls how cycles are handled
@@ -221,6 +418,10 @@ type CycleHandlingConfig struct {
 	// ReportCycles includes cycle information in results
 	// +kubebuilder:default=true
 	ReportCycles bool `json:"reportCycles,omitempty"`
+
+	// MinNodesForDetection skips cycle detection on graphs with fewer nodes
+	// +kubebuilder:validation:Minimum=0
+	MinNodesForDetection int `json:"minNodesForDetection,omitempty"`
 }
 
 // CycleAction defines actions to take when cycles are detected
@@ -243,6 +444,12 @@ type PerformanceConfig struct {
 	// +kubebuilder:validation:Maximum=50
 	MaxConcurrentRequests int `json:"maxConcurrentRequests,omitempty"`
 
+	// MaxConcurrentExtraction limits concurrent reference extraction separately
+	// from API requests; defaults to MaxConcurrentRequests
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=50
+	MaxConcurrentExtraction int `json:"maxConcurrentExtraction,omitempty"`
+
 	// RequestTimeout is the timeout for individual API requests
 	// +kubebuilder:default="2s"
 	// +kubebuilder:validation:Pattern="^[0-9]+(s|m|h)$"
@@ -258,6 +465,21 @@ type PerformanceConfig struct {
 
 	// MemoryLimits sets memory usage limits
 	MemoryLimits *MemoryLimits `json:"memoryLimits,omitempty"`
+
+	// CRDListsPerSecond rate limits the CRD Lists made to discover target
+	// kinds missing from the registry; 0 leaves them unlimited
+	// +kubebuilder:validation:Minimum=0
+	CRDListsPerSecond int `json:"crdListsPerSecond,omitempty"`
+
+	// CRDListBurst is how many CRD Lists may be made at once within
+	// CRDListsPerSecond; defaults to 1
+	// +kubebuilder:validation:Minimum=1
+	CRDListBurst int `json:"crdListBurst,omitempty"`
+
+	// MaxConcurrentCRDLists bounds the CRD Lists in flight at once; 0 leaves
+	// them unbounded
+	// +kubebuilder:validation:Minimum=0
+	MaxConcurrentCRDLists int `json:"maxConcurrentCRDLists,omitempty"`
 }
 
 // MemoryLimits defines memory usage constraints
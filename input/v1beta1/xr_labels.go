@@ -24,8 +24,47 @@ type XRLabelConfig struct {
 
 	// EnforceLabels ensures specified labels cannot be overridden
 	EnforceLabels []string `json:"enforceLabels,omitempty"`
+
+	// RemoveLabels lists label keys to remove from the XR after adds and merges
+	RemoveLabels []string `json:"removeLabels,omitempty"`
+
+	// ConditionalRemovals defines labels that are removed only when a condition matches
+	ConditionalRemovals []LabelRemoval `json:"conditionalRemovals,omitempty"`
 }
 
+// LabelRemoval defines a label removal gated on a condition
+type LabelRemoval struct {
+	// Key is the label key to remove
+	// +kubebuilder:validation:Required
+	Key string `json:"key"`
+
+	// Condition determines when the label is removed
+	// +kubebuilder:validation:Enum=always;xr-field-missing;xr-field-equals;label-mismatch
+	// +kubebuilder:default="always"
+	Condition RemovalCondition `json:"condition,omitempty"`
+
+	// SourcePath specifies the XR field evaluated by xr-field conditions
+	SourcePath string `json:"sourcePath,omitempty"`
+
+	// Value is compared against the XR field (xr-field-equals) or the
+	// current label value (label-mismatch)
+	Value string `json:"value,omitempty"`
+}
+
+// RemovalCondition defines when a conditional label removal applies
+type RemovalCondition string
+
+const (
+	// RemovalConditionAlways removes the label unconditionally
+	RemovalConditionAlways RemovalCondition = "always"
+	// RemovalConditionXRFieldMissing removes the label when the XR field is absent
+	RemovalConditionXRFieldMissing RemovalCondition = "xr-field-missing"
+	// RemovalConditionXRFieldEquals removes the label when the XR field equals Value
+	RemovalConditionXRFieldEquals RemovalCondition = "xr-field-equals"
+	// RemovalConditionLabelMismatch removes the label when its current value differs from Value
+	RemovalConditionLabelMismatch RemovalCondition = "label-mismatch"
+)
+
 // DynamicLabel defines a label with dynamic value computation
 type DynamicLabel struct {
 	// Key is the label key to set
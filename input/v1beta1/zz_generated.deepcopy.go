@@ -8,6 +8,21 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AnnotationReferencePattern) DeepCopyInto(out *AnnotationReferencePattern) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AnnotationReferencePattern.
+func (in *AnnotationReferencePattern) DeepCopy() *AnnotationReferencePattern {
+	if in == nil {
+		return nil
+	}
+	out := new(AnnotationReferencePattern)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BatchConfig) DeepCopyInto(out *BatchConfig) {
 	*out = *in
@@ -28,6 +43,21 @@ func (in *BatchConfig) DeepCopy() *BatchConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CELReference) DeepCopyInto(out *CELReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CELReference.
+func (in *CELReference) DeepCopy() *CELReference {
+	if in == nil {
+		return nil
+	}
+	out := new(CELReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CacheConfig) DeepCopyInto(out *CacheConfig) {
 	*out = *in
@@ -125,6 +155,26 @@ func (in *Input) DeepCopyInto(out *Input) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.FailOnMissingRequired != nil {
+		in, out := &in.FailOnMissingRequired, &out.FailOnMissingRequired
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MergeDuplicateInto != nil {
+		in, out := &in.MergeDuplicateInto, &out.MergeDuplicateInto
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DryRun != nil {
+		in, out := &in.DryRun, &out.DryRun
+		*out = new(bool)
+		**out = **in
+	}
+	if in.EmitRequirements != nil {
+		in, out := &in.EmitRequirements, &out.EmitRequirements
+		*out = new(bool)
+		**out = **in
+	}
 	if in.MaxConcurrentFetches != nil {
 		in, out := &in.MaxConcurrentFetches, &out.MaxConcurrentFetches
 		*out = new(int)
@@ -145,6 +195,11 @@ func (in *Input) DeepCopyInto(out *Input) {
 		*out = new(TraversalConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ReferencePatterns != nil {
+		in, out := &in.ReferencePatterns, &out.ReferencePatterns
+		*out = new(ReferencePatternsConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.XRLabels != nil {
 		in, out := &in.XRLabels, &out.XRLabels
 		*out = new(XRLabelConfig)
@@ -170,6 +225,21 @@ func (in *Input) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LabelRemoval) DeepCopyInto(out *LabelRemoval) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LabelRemoval.
+func (in *LabelRemoval) DeepCopy() *LabelRemoval {
+	if in == nil {
+		return nil
+	}
+	out := new(LabelRemoval)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LabelSelector) DeepCopyInto(out *LabelSelector) {
 	*out = *in
@@ -349,6 +419,26 @@ func (in *ReferencePattern) DeepCopy() *ReferencePattern {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReferencePatternsConfig) DeepCopyInto(out *ReferencePatternsConfig) {
+	*out = *in
+	if in.Patterns != nil {
+		in, out := &in.Patterns, &out.Patterns
+		*out = make([]ReferencePattern, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReferencePatternsConfig.
+func (in *ReferencePatternsConfig) DeepCopy() *ReferencePatternsConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ReferencePatternsConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ReferenceResolutionConfig) DeepCopyInto(out *ReferenceResolutionConfig) {
 	*out = *in
@@ -357,6 +447,30 @@ func (in *ReferenceResolutionConfig) DeepCopyInto(out *ReferenceResolutionConfig
 		*out = make([]ReferencePattern, len(*in))
 		copy(*out, *in)
 	}
+	if in.NamespaceRewrite != nil {
+		in, out := &in.NamespaceRewrite, &out.NamespaceRewrite
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.GroupAliases != nil {
+		in, out := &in.GroupAliases, &out.GroupAliases
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.AnnotationReferences != nil {
+		in, out := &in.AnnotationReferences, &out.AnnotationReferences
+		*out = make([]AnnotationReferencePattern, len(*in))
+		copy(*out, *in)
+	}
+	if in.CELReferences != nil {
+		in, out := &in.CELReferences, &out.CELReferences
+		*out = make([]CELReference, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReferenceResolutionConfig.
@@ -514,11 +628,26 @@ func (in *TransformOptions) DeepCopy() *TransformOptions {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TraversalConfig) DeepCopyInto(out *TraversalConfig) {
 	*out = *in
+	if in.MaxDepth != nil {
+		in, out := &in.MaxDepth, &out.MaxDepth
+		*out = new(int)
+		**out = **in
+	}
 	if in.Timeout != nil {
 		in, out := &in.Timeout, &out.Timeout
 		*out = new(string)
 		**out = **in
 	}
+	if in.TargetKinds != nil {
+		in, out := &in.TargetKinds, &out.TargetKinds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CrossNamespace != nil {
+		in, out := &in.CrossNamespace, &out.CrossNamespace
+		*out = new(bool)
+		**out = **in
+	}
 	if in.ScopeFilter != nil {
 		in, out := &in.ScopeFilter, &out.ScopeFilter
 		*out = new(ScopeFilterConfig)
@@ -588,6 +717,16 @@ func (in *XRLabelConfig) DeepCopyInto(out *XRLabelConfig) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.RemoveLabels != nil {
+		in, out := &in.RemoveLabels, &out.RemoveLabels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ConditionalRemovals != nil {
+		in, out := &in.ConditionalRemovals, &out.ConditionalRemovals
+		*out = make([]LabelRemoval, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new XRLabelConfig.
@@ -25,7 +25,14 @@ func (c *CLI) Run() error {
 		return err
 	}
 
-	return function.Serve(NewFunction(log),
+	fn := NewFunction(log)
+
+	// Fail fast if the embedded registry is inconsistent
+	if err := fn.registry.Validate(); err != nil {
+		return err
+	}
+
+	return function.Serve(fn,
 		function.Listen(c.Network, c.Address),
 		function.MTLSCertificates(c.TLSCertsDir),
 		function.Insecure(c.Insecure),
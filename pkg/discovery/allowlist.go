@@ -0,0 +1,84 @@
+package discovery
+
+import (
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/crossplane/function-kubecore-schema-registry/input/v1beta1"
+	functionerrors "github.com/crossplane/function-kubecore-schema-registry/pkg/errors"
+)
+
+// KindAllowlist restricts the kinds fetch requests may name. Entries are a
+// bare Kind, allowed in any group, or Kind.group as printed by GroupKind, e.g.
+// "ConfigMap" or "Deployment.apps".
+type KindAllowlist struct {
+	entries map[string]struct{}
+}
+
+// NewKindAllowlist creates an allowlist from its entries, or returns nil if
+// there are none so that every kind is allowed
+func NewKindAllowlist(entries []string) *KindAllowlist {
+	allowlist := &KindAllowlist{entries: make(map[string]struct{}, len(entries))}
+	for _, entry := range entries {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			allowlist.entries[entry] = struct{}{}
+		}
+	}
+
+	if len(allowlist.entries) == 0 {
+		return nil
+	}
+	return allowlist
+}
+
+// Kinds returns the allowlist entries in sorted order, or nil for a nil
+// allowlist
+func (a *KindAllowlist) Kinds() []string {
+	if a == nil {
+		return nil
+	}
+	kinds := make([]string, 0, len(a.entries))
+	for entry := range a.entries {
+		kinds = append(kinds, entry)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// Allows reports whether the kind of the given apiVersion may be fetched
+func (a *KindAllowlist) Allows(apiVersion, kind string) bool {
+	if a == nil {
+		return true
+	}
+	if _, ok := a.entries[kind]; ok {
+		return true
+	}
+
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return false
+	}
+	_, ok := a.entries[gv.WithKind(kind).GroupKind().String()]
+	return ok
+}
+
+// Check returns an error naming the first request whose kind is not allowed
+func (a *KindAllowlist) Check(requests []v1beta1.ResourceRequest) error {
+	for _, req := range requests {
+		if a.Allows(req.APIVersion, req.Kind) {
+			continue
+		}
+
+		return functionerrors.KindNotAllowedError(functionerrors.ResourceRef{
+			Into:       req.Into,
+			Name:       req.Name,
+			Namespace:  stringPtrValue(req.Namespace),
+			APIVersion: req.APIVersion,
+			Kind:       req.Kind,
+		})
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package discovery
+
+import (
+	"fmt"
+
+	"github.com/crossplane/function-kubecore-schema-registry/input/v1beta1"
+)
+
+// IntoAliases maps the internal 'into' names given to duplicate requests back
+// to the 'into' name the composition author used
+type IntoAliases map[string]string
+
+// AliasDuplicateInto gives every request after the first that shares an 'into'
+// name a unique internal name, so engines store each result separately instead
+// of overwriting one another. The aliases let MergeDuplicateInto fold the
+// results back together once fetching is done.
+func AliasDuplicateInto(requests []v1beta1.ResourceRequest) ([]v1beta1.ResourceRequest, IntoAliases) {
+	aliases := make(IntoAliases)
+	seen := make(map[string]int, len(requests))
+
+	aliased := make([]v1beta1.ResourceRequest, len(requests))
+	for i, req := range requests {
+		aliased[i] = req
+
+		count := seen[req.Into]
+		seen[req.Into] = count + 1
+		if count == 0 {
+			continue
+		}
+
+		// '#' is not valid in an 'into' name, so aliases never collide with one
+		alias := fmt.Sprintf("%s#%d", req.Into, count)
+		aliases[alias] = req.Into
+		aliased[i].Into = alias
+	}
+
+	return aliased, aliases
+}
+
+// MergeDuplicateInto collects the results of requests that shared an 'into'
+// name under that name in MultiResources, in request order. Resources keeps
+// the first request's result and fetch errors report the original name.
+// Constraint results of aliased requests are dropped.
+func MergeDuplicateInto(result *FetchResult, requests []v1beta1.ResourceRequest, aliases IntoAliases) {
+	if result == nil || len(aliases) == 0 {
+		return
+	}
+
+	// Gather every result per original name before removing the aliases
+	merged := make(map[string][]*FetchedResource)
+	for _, req := range requests {
+		into := req.Into
+		if original, ok := aliases[into]; ok {
+			into = original
+		}
+		merged[into] = append(merged[into], result.GetAll(req.Into)...)
+	}
+
+	for alias, original := range aliases {
+		delete(result.Resources, alias)
+		delete(result.MultiResources, alias)
+		if result.Phase2Results != nil {
+			delete(result.Phase2Results.ConstraintResults, alias)
+		}
+
+		if result.MultiResources == nil {
+			result.MultiResources = make(map[string][]*FetchedResource)
+		}
+		result.MultiResources[original] = merged[original]
+	}
+
+	for _, resources := range merged {
+		for _, resource := range resources {
+			if original, ok := aliases[resource.Request.Into]; ok {
+				resource.Request.Into = original
+			}
+		}
+	}
+
+	for _, fetchErr := range result.Summary.Errors {
+		if original, ok := aliases[fetchErr.ResourceRequest.Into]; ok {
+			fetchErr.ResourceRequest.Into = original
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"golang.org/x/sync/errgroup"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -29,6 +30,9 @@ type EnhancedEngine struct {
 
 	// Performance tracking
 	queryOptimizer *QueryOptimizer
+
+	// allowedKinds restricts the kinds that may be fetched
+	allowedKinds *KindAllowlist
 }
 
 // NewEnhancedEngine creates a new enhanced discovery engine with Phase 2 capabilities
@@ -55,7 +59,12 @@ func NewEnhancedEngine(config *rest.Config, registry registry.Registry, context
 	}
 
 	// Register resolvers
-	engine.resolvers[v1beta1.MatchTypeDirect] = resolver.NewDirectResolver(dynamicClient, typedClient, registry)
+	// Resolvers share the engine's jitter source
+	fetchRetry := context.FetchRetry.WithJitterSource()
+
+	directResolver := resolver.NewDirectResolver(dynamicClient, typedClient, registry)
+	directResolver.SetRetryPolicy(fetchRetry)
+	engine.resolvers[v1beta1.MatchTypeDirect] = directResolver
 
 	// Only register Phase 2 resolvers if enabled
 	if context.Phase2Enabled {
@@ -64,6 +73,7 @@ func NewEnhancedEngine(config *rest.Config, registry registry.Registry, context
 			TimeoutPerRequest:     context.TimeoutPerRequest,
 			MaxConcurrentRequests: context.MaxConcurrentRequests,
 			Phase2Enabled:         context.Phase2Enabled,
+			FetchRetry:            fetchRetry,
 		}
 		engine.resolvers[v1beta1.MatchTypeLabel] = resolver.NewLabelResolver(dynamicClient, typedClient, registry, resolverContext)
 		engine.resolvers[v1beta1.MatchTypeExpression] = resolver.NewExpressionResolver(dynamicClient, typedClient, registry, resolverContext)
@@ -72,8 +82,27 @@ func NewEnhancedEngine(config *rest.Config, registry registry.Registry, context
 	return engine, nil
 }
 
+// SetRESTMapper sets a RESTMapper the resolvers consult for kinds missing from
+// the registry
+func (e *EnhancedEngine) SetRESTMapper(mapper meta.RESTMapper) {
+	for _, r := range e.resolvers {
+		if mapped, ok := r.(interface{ SetRESTMapper(meta.RESTMapper) }); ok {
+			mapped.SetRESTMapper(mapper)
+		}
+	}
+}
+
+// SetKindAllowlist restricts the kinds that may be fetched; nil allows every kind
+func (e *EnhancedEngine) SetKindAllowlist(allowlist *KindAllowlist) {
+	e.allowedKinds = allowlist
+}
+
 // FetchResources fetches resources based on the provided requests
 func (e *EnhancedEngine) FetchResources(requests []v1beta1.ResourceRequest) (*FetchResult, error) {
+	if err := e.allowedKinds.Check(requests); err != nil {
+		return nil, err
+	}
+
 	startTime := time.Now()
 
 	result := &FetchResult{
@@ -167,18 +196,29 @@ func (e *EnhancedEngine) FetchResources(requests []v1beta1.ResourceRequest) (*Fe
 
 			// Process successful results
 			if len(resources) == 0 {
-				// No resources found
-				if req.Optional {
-					result.Summary.Skipped++
-				} else {
-					result.Summary.NotFound++
-					result.Summary.Failed++
-				}
+				// A selector that matched nothing is not found like a missing name
+				result.recordFetchStatus(req, &FetchedResource{
+					Request:   req,
+					FetchedAt: time.Now(),
+					Metadata: ResourceMetadata{
+						FetchStatus: FetchStatusNotFound,
+						Error: functionerrors.ResourceNotFoundError(functionerrors.ResourceRef{
+							Into:       req.Into,
+							Name:       req.Name,
+							Namespace:  stringPtrValue(req.Namespace),
+							APIVersion: req.APIVersion,
+							Kind:       req.Kind,
+						}),
+					},
+				})
 			} else if len(resources) == 1 {
-				// Single resource result (Phase 1 or Phase 2 with single match)
+				// Single resource result (Phase 1 or Phase 2 with single match),
+				// which a direct lookup reports as not found by its fetch status
 				result.Resources[req.Into] = resources[0]
-				result.Summary.Successful++
-				totalResourcesScanned++
+				result.recordFetchStatus(req, resources[0])
+				if resources[0].Metadata.FetchStatus == FetchStatusSuccess {
+					totalResourcesScanned++
+				}
 			} else {
 				// Multiple resources result (Phase 2 only)
 				result.MultiResources[req.Into] = resources
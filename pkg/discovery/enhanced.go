@@ -3,8 +3,10 @@ package discovery
 import (
 	"context"
 	"fmt"
+	"sort"
 	"time"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/rest"
 
@@ -32,10 +34,20 @@ type EnhancedDiscoveryEngine struct {
 
 	// traversalConfig contains Phase 3 traversal configuration
 	traversalConfig *v1beta1.TraversalConfig
+
+	// referencePatterns contains reference detection patterns from input
+	referencePatterns *v1beta1.ReferencePatternsConfig
+
+	// allowedKinds restricts the kinds fetch requests may name
+	allowedKinds *KindAllowlist
 }
 
 // NewEnhancedDiscoveryEngine creates a new enhanced discovery engine with Phase 3 capabilities
 func NewEnhancedDiscoveryEngine(config *rest.Config, registry registry.Registry, context DiscoveryContext, traversalConfig *v1beta1.TraversalConfig, logger logging.Logger) (*EnhancedDiscoveryEngine, error) {
+	if err := validateInputTraversalConfig(traversalConfig); err != nil {
+		return nil, fmt.Errorf("invalid traversal configuration: %w", err)
+	}
+
 	// Create base engine for Phase 1 & 2
 	baseEngine, err := NewEnhancedEngine(config, registry, context)
 	if err != nil {
@@ -47,6 +59,10 @@ func NewEnhancedDiscoveryEngine(config *rest.Config, registry registry.Registry,
 	if err != nil {
 		return nil, fmt.Errorf("failed to create traversal engine: %w", err)
 	}
+	if traversalConfig != nil && traversalConfig.Performance != nil {
+		performance := traversalConfig.Performance
+		traversalEngine.SetCRDListLimits(float64(performance.CRDListsPerSecond), performance.CRDListBurst, performance.MaxConcurrentCRDLists)
+	}
 
 	return &EnhancedDiscoveryEngine{
 		base:            baseEngine,
@@ -57,8 +73,39 @@ func NewEnhancedDiscoveryEngine(config *rest.Config, registry registry.Registry,
 	}, nil
 }
 
+// SetReferencePatterns sets the input-supplied reference detection patterns
+// used by transitive discovery
+func (ede *EnhancedDiscoveryEngine) SetReferencePatterns(patterns *v1beta1.ReferencePatternsConfig) {
+	ede.referencePatterns = patterns
+}
+
+// SetRESTMapper sets a RESTMapper consulted for kinds missing from the
+// registry, both by fetches and by transitive discovery
+func (ede *EnhancedDiscoveryEngine) SetRESTMapper(mapper meta.RESTMapper) {
+	if base, ok := ede.base.(interface{ SetRESTMapper(meta.RESTMapper) }); ok {
+		base.SetRESTMapper(mapper)
+	}
+	if traversalEngine, ok := ede.traversalEngine.(interface{ SetRESTMapper(meta.RESTMapper) }); ok {
+		traversalEngine.SetRESTMapper(mapper)
+	}
+}
+
+// SetKindAllowlist restricts the kinds fetch requests may name; nil allows every kind
+func (ede *EnhancedDiscoveryEngine) SetKindAllowlist(allowlist *KindAllowlist) {
+	ede.allowedKinds = allowlist
+}
+
+// Close releases resources held by the traversal engine
+func (ede *EnhancedDiscoveryEngine) Close() error {
+	return ede.traversalEngine.Close()
+}
+
 // FetchResources fetches resources using Phase 1, 2, or 3 based on configuration
 func (ede *EnhancedDiscoveryEngine) FetchResources(requests []v1beta1.ResourceRequest) (*FetchResult, error) {
+	if err := ede.allowedKinds.Check(requests); err != nil {
+		return nil, err
+	}
+
 	// Check if Phase 3 configuration is provided and enabled
 	hasPhase3Config := ede.traversalConfig != nil && ede.traversalConfig.Enabled
 
@@ -84,26 +131,26 @@ func (ede *EnhancedDiscoveryEngine) executePhase3Discovery(requests []v1beta1.Re
 	}
 
 	// Step 2: Extract root resources for traversal
-	var rootResources []*unstructured.Unstructured
-	for _, resource := range baseResult.Resources {
-		if resource.Resource != nil {
-			rootResources = append(rootResources, resource.Resource)
-		}
-	}
-
-	for _, resources := range baseResult.MultiResources {
-		for _, resource := range resources {
-			if resource.Resource != nil {
-				rootResources = append(rootResources, resource.Resource)
-			}
-		}
-	}
+	rootResources := collectRootResources(baseResult)
 
 	if len(rootResources) == 0 {
 		ede.logger.Info("No root resources found for Phase 3 traversal")
 		return baseResult, nil
 	}
 
+	var rootLimitError *DiscoveryError
+	if maxRoots := ede.traversalConfig.MaxRoots; maxRoots > 0 && len(rootResources) > maxRoots {
+		ede.logger.Info("Truncating Phase 3 root resources",
+			"rootResources", len(rootResources),
+			"maxRoots", maxRoots)
+
+		rootLimitError = &DiscoveryError{
+			Type:    "root_limit",
+			Message: fmt.Sprintf("only the first %d of %d root resources were traversed", maxRoots, len(rootResources)),
+		}
+		rootResources = rootResources[:maxRoots]
+	}
+
 	// Step 3: Build traversal configuration from input
 	traversalConfig := ede.buildTraversalConfigFromInput()
 
@@ -115,6 +162,9 @@ func (ede *EnhancedDiscoveryEngine) executePhase3Discovery(requests []v1beta1.Re
 
 	// Step 5: Merge results
 	mergedResult := ede.mergeResults(baseResult, traversalResult)
+	if rootLimitError != nil {
+		mergedResult.DiscoveryErrors = append(mergedResult.DiscoveryErrors, *rootLimitError)
+	}
 
 	ede.logger.Info("Phase 3 transitive discovery completed",
 		"rootResources", len(rootResources),
@@ -125,6 +175,39 @@ func (ede *EnhancedDiscoveryEngine) executePhase3Discovery(requests []v1beta1.Re
 	return mergedResult, nil
 }
 
+// collectRootResources returns the fetched resources in a stable order: single
+// results before multi-resource results, each sorted by 'into' name, with the
+// resources of a multi-resource result kept in the order they were fetched
+func collectRootResources(result *FetchResult) []*unstructured.Unstructured {
+	var rootResources []*unstructured.Unstructured
+
+	intoNames := make([]string, 0, len(result.Resources))
+	for into := range result.Resources {
+		intoNames = append(intoNames, into)
+	}
+	sort.Strings(intoNames)
+	for _, into := range intoNames {
+		if resource := result.Resources[into]; resource != nil && resource.Resource != nil {
+			rootResources = append(rootResources, resource.Resource)
+		}
+	}
+
+	intoNames = intoNames[:0]
+	for into := range result.MultiResources {
+		intoNames = append(intoNames, into)
+	}
+	sort.Strings(intoNames)
+	for _, into := range intoNames {
+		for _, resource := range result.MultiResources[into] {
+			if resource != nil && resource.Resource != nil {
+				rootResources = append(rootResources, resource.Resource)
+			}
+		}
+	}
+
+	return rootResources
+}
+
 // buildTraversalConfigFromInput builds traversal configuration from the input TraversalConfig
 func (ede *EnhancedDiscoveryEngine) buildTraversalConfigFromInput() *traversal.TraversalConfig {
 	// Start with default configuration
@@ -135,6 +218,20 @@ func (ede *EnhancedDiscoveryEngine) buildTraversalConfigFromInput() *traversal.T
 		ede.applyInputTraversalConfig(config, ede.traversalConfig)
 	}
 
+	// Apply input reference patterns
+	if ede.referencePatterns != nil {
+		for _, pattern := range ede.referencePatterns.Patterns {
+			config.ReferenceResolution.ReferencePatterns = append(
+				config.ReferenceResolution.ReferencePatterns,
+				convertInputReferencePattern(pattern),
+			)
+		}
+		config.ReferenceResolution.ReplaceDefaultPatterns = ede.referencePatterns.ReplaceDefaults
+	}
+
+	// Discovered resources are held to the same kind allowlist as fetch requests
+	config.ScopeFilter.AllowedKinds = ede.allowedKinds.Kinds()
+
 	// Apply discovery context settings
 	config.Performance.MaxConcurrentRequests = ede.config.MaxConcurrentRequests
 
@@ -146,17 +243,60 @@ func (ede *EnhancedDiscoveryEngine) buildTraversalConfigFromInput() *traversal.T
 	return config
 }
 
+// convertInputReferencePattern converts an input reference pattern, treating
+// patterns without a reference type as custom references
+func convertInputReferencePattern(pattern v1beta1.ReferencePattern) traversal.ReferencePattern {
+	refType := traversal.RefType(pattern.RefType)
+	if refType == "" {
+		refType = traversal.RefTypeCustom
+	}
+
+	return traversal.ReferencePattern{
+		Pattern:      pattern.Pattern,
+		TargetKind:   pattern.TargetKind,
+		TargetGroup:  pattern.TargetGroup,
+		Confidence:   pattern.Confidence,
+		RefType:      refType,
+		NameTemplate: pattern.NameTemplate,
+	}
+}
+
+// validateInputTraversalConfig rejects traversal input that cannot be applied
+func validateInputTraversalConfig(inputConfig *v1beta1.TraversalConfig) error {
+	if inputConfig == nil {
+		return nil
+	}
+
+	if inputConfig.MaxDepth != nil && *inputConfig.MaxDepth < 0 {
+		return fmt.Errorf("maxDepth must be 0 or greater, got %d", *inputConfig.MaxDepth)
+	}
+
+	if inputConfig.ReferenceResolution != nil {
+		for _, celRef := range inputConfig.ReferenceResolution.CELReferences {
+			if _, err := traversal.CompileCELReferenceExpression(celRef.Expression); err != nil {
+				return fmt.Errorf("invalid CEL reference expression for kind %s: %w", celRef.Kind, err)
+			}
+		}
+	}
+
+	return nil
+}
+
 // applyInputTraversalConfig applies input traversal configuration to the traversal config
 func (ede *EnhancedDiscoveryEngine) applyInputTraversalConfig(config *traversal.TraversalConfig, inputConfig *v1beta1.TraversalConfig) {
-	// Apply basic settings
-	if inputConfig.MaxDepth > 0 {
-		config.MaxDepth = inputConfig.MaxDepth
+	// Apply basic settings; an explicit maxDepth of 0 means roots only
+	if inputConfig.MaxDepth != nil && *inputConfig.MaxDepth >= 0 {
+		config.MaxDepth = *inputConfig.MaxDepth
 	}
 
 	if inputConfig.MaxResources > 0 {
 		config.MaxResources = inputConfig.MaxResources
 	}
 
+	if inputConfig.MaxEdges > 0 {
+		config.MaxEdges = inputConfig.MaxEdges
+	}
+
 	if inputConfig.Timeout != nil {
 		if timeout, err := time.ParseDuration(*inputConfig.Timeout); err == nil {
 			config.Timeout = timeout
@@ -174,10 +314,23 @@ func (ede *EnhancedDiscoveryEngine) applyInputTraversalConfig(config *traversal.
 		}
 	}
 
+	switch inputConfig.SchedulingMode {
+	case v1beta1.SchedulingModeBreadthFirst:
+		config.SchedulingMode = traversal.SchedulingModeBreadthFirst
+	case v1beta1.SchedulingModeBestFirst:
+		config.SchedulingMode = traversal.SchedulingModeBestFirst
+	}
+
+	if len(inputConfig.TargetKinds) > 0 {
+		config.TargetKinds = inputConfig.TargetKinds
+	}
+	config.IncludeCompositionMetadata = inputConfig.IncludeCompositionMetadata
+
 	// Apply scope filter configuration
 	if inputConfig.ScopeFilter != nil {
 		config.ScopeFilter.PlatformOnly = inputConfig.ScopeFilter.PlatformOnly
 		config.ScopeFilter.CrossNamespaceEnabled = inputConfig.ScopeFilter.CrossNamespaceEnabled
+		config.ScopeFilter.SkipTerminatingResources = inputConfig.ScopeFilter.SkipTerminatingResources
 
 		if len(inputConfig.ScopeFilter.IncludeAPIGroups) > 0 {
 			config.ScopeFilter.IncludeAPIGroups = inputConfig.ScopeFilter.IncludeAPIGroups
@@ -204,12 +357,21 @@ func (ede *EnhancedDiscoveryEngine) applyInputTraversalConfig(config *traversal.
 		}
 	}
 
+	// The top-level toggle takes precedence over the scope filter setting
+	if inputConfig.CrossNamespace != nil {
+		config.ScopeFilter.CrossNamespaceEnabled = *inputConfig.CrossNamespace
+	}
+
 	// Apply performance configuration
 	if inputConfig.Performance != nil {
 		if inputConfig.Performance.MaxConcurrentRequests > 0 {
 			config.Performance.MaxConcurrentRequests = inputConfig.Performance.MaxConcurrentRequests
 		}
 
+		if inputConfig.Performance.MaxConcurrentExtraction > 0 {
+			config.Performance.MaxConcurrentExtraction = inputConfig.Performance.MaxConcurrentExtraction
+		}
+
 		if inputConfig.Performance.RequestTimeout != nil {
 			if timeout, err := time.ParseDuration(*inputConfig.Performance.RequestTimeout); err == nil {
 				config.Performance.RequestTimeout = timeout
@@ -236,19 +398,42 @@ func (ede *EnhancedDiscoveryEngine) applyInputTraversalConfig(config *traversal.
 		config.ReferenceResolution.FollowOwnerReferences = inputConfig.ReferenceResolution.FollowOwnerReferences
 		config.ReferenceResolution.FollowCustomReferences = inputConfig.ReferenceResolution.FollowCustomReferences
 		config.ReferenceResolution.SkipMissingReferences = inputConfig.ReferenceResolution.SkipMissingReferences
+		config.ReferenceResolution.FailOnForbidden = inputConfig.ReferenceResolution.FailOnForbidden
 		config.ReferenceResolution.MinConfidenceThreshold = inputConfig.ReferenceResolution.MinConfidenceThreshold
+		config.ReferenceResolution.LowConfidenceWarningThreshold = inputConfig.ReferenceResolution.LowConfidenceWarningThreshold
+		config.ReferenceResolution.NamespaceRewrite = inputConfig.ReferenceResolution.NamespaceRewrite
+		config.ReferenceResolution.GroupAliases = inputConfig.ReferenceResolution.GroupAliases
+		config.ReferenceResolution.ClusterSourceNamespace = inputConfig.ReferenceResolution.ClusterSourceNamespace
+		config.ReferenceResolution.UseClaimNamespace = inputConfig.ReferenceResolution.UseClaimNamespace
+
+		for _, pattern := range inputConfig.ReferenceResolution.AnnotationReferences {
+			config.ReferenceResolution.AnnotationReferences = append(
+				config.ReferenceResolution.AnnotationReferences,
+				traversal.AnnotationReferencePattern{
+					KeyPrefix:     pattern.KeyPrefix,
+					TargetKind:    pattern.TargetKind,
+					TargetGroup:   pattern.TargetGroup,
+					TargetVersion: pattern.TargetVersion,
+					Confidence:    pattern.Confidence,
+				})
+		}
+
+		for _, celRef := range inputConfig.ReferenceResolution.CELReferences {
+			config.ReferenceResolution.CELReferences = append(
+				config.ReferenceResolution.CELReferences,
+				traversal.CELReferenceExtractor{
+					Kind:       celRef.Kind,
+					APIVersion: celRef.APIVersion,
+					Expression: celRef.Expression,
+					Confidence: celRef.Confidence,
+				})
+		}
 
 		// Convert additional patterns
 		for _, pattern := range inputConfig.ReferenceResolution.AdditionalPatterns {
 			config.ReferenceResolution.ReferencePatterns = append(
 				config.ReferenceResolution.ReferencePatterns,
-				traversal.ReferencePattern{
-					Pattern:     pattern.Pattern,
-					TargetKind:  pattern.TargetKind,
-					TargetGroup: pattern.TargetGroup,
-					Confidence:  pattern.Confidence,
-					RefType:     traversal.RefTypeCustom,
-				},
+				convertInputReferencePattern(pattern),
 			)
 		}
 	}
@@ -258,6 +443,7 @@ func (ede *EnhancedDiscoveryEngine) applyInputTraversalConfig(config *traversal.
 		config.CycleHandling.DetectionEnabled = inputConfig.CycleHandling.DetectionEnabled
 		config.CycleHandling.MaxCycles = inputConfig.CycleHandling.MaxCycles
 		config.CycleHandling.ReportCycles = inputConfig.CycleHandling.ReportCycles
+		config.CycleHandling.MinNodesForDetection = inputConfig.CycleHandling.MinNodesForDetection
 
 		switch inputConfig.CycleHandling.OnCycleDetected {
 		case v1beta1.CycleActionContinue:
@@ -306,6 +492,68 @@ func (ede *EnhancedDiscoveryEngine) applyInputTraversalConfig(config *traversal.
 }
 
 
+// resourceKey identifies a resource the same way the traversal engine does
+func resourceKey(resource *unstructured.Unstructured) string {
+	key := fmt.Sprintf("%s/%s/%s/%s",
+		resource.GetAPIVersion(),
+		resource.GetKind(),
+		resource.GetNamespace(),
+		resource.GetName())
+
+	if cluster := graph.ResourceCluster(resource); cluster != "" {
+		key += "@" + cluster
+	}
+	return key
+}
+
+// fetchedResourceKeys maps the key of every resource in a fetch result to the
+// 'into' name of the request that fetched it
+func fetchedResourceKeys(result *FetchResult) map[string]string {
+	keys := make(map[string]string)
+	for into, fetched := range result.Resources {
+		if fetched != nil && fetched.Resource != nil {
+			keys[resourceKey(fetched.Resource)] = into
+		}
+	}
+	for into, resources := range result.MultiResources {
+		for _, fetched := range resources {
+			if fetched != nil && fetched.Resource != nil {
+				keys[resourceKey(fetched.Resource)] = into
+			}
+		}
+	}
+	return keys
+}
+
+// discoveredFromInto walks the graph back from a discovered resource to the
+// nearest fetched resource and returns the 'into' name it was fetched under
+func discoveredFromInto(resourceGraph *graph.ResourceGraph, resourceID string, fetchedInto map[string]string) (string, bool) {
+	if resourceGraph == nil {
+		return "", false
+	}
+
+	visited := map[graph.NodeID]bool{graph.NodeID(resourceID): true}
+	queue := []graph.NodeID{graph.NodeID(resourceID)}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, edgeID := range resourceGraph.ReverseAdjacencyList[current] {
+			edge, exists := resourceGraph.Edges[edgeID]
+			if !exists || visited[edge.Source] {
+				continue
+			}
+			if into, fetched := fetchedInto[string(edge.Source)]; fetched {
+				return into, true
+			}
+			visited[edge.Source] = true
+			queue = append(queue, edge.Source)
+		}
+	}
+
+	return "", false
+}
+
 // mergeResults merges Phase 1/2 results with Phase 3 traversal results
 func (ede *EnhancedDiscoveryEngine) mergeResults(baseResult *FetchResult, traversalResult *traversal.TraversalResult) *FetchResult {
 	// Start with base result
@@ -327,13 +575,48 @@ func (ede *EnhancedDiscoveryEngine) mergeResults(baseResult *FetchResult, traver
 		mergedResult.Phase2Results.Performance.TotalResourcesScanned += traversalResult.Statistics.TotalResources
 	}
 
-	// Add discovered resources to the result
-	for resourceID, resource := range traversalResult.DiscoveredResources {
+	// Copy the map so discoveries are not added to the base result as well
+	mergedResult.MultiResources = make(map[string][]*FetchedResource, len(baseResult.MultiResources))
+	for into, resources := range baseResult.MultiResources {
+		mergedResult.MultiResources[into] = resources
+	}
+
+	// Resources fetched by requests are already in the result, so traversal
+	// roots and rediscovered fetches are not added or counted again
+	fetchedInto := fetchedResourceKeys(baseResult)
+
+	strategy := v1beta1.ResultMergeSeparate
+	if ede.traversalConfig != nil && ede.traversalConfig.ResultMerge != "" {
+		strategy = ede.traversalConfig.ResultMerge
+	}
+
+	// Add discovered resources to the result in a stable order
+	resourceIDs := make([]string, 0, len(traversalResult.DiscoveredResources))
+	for resourceID := range traversalResult.DiscoveredResources {
+		resourceIDs = append(resourceIDs, resourceID)
+	}
+	sort.Strings(resourceIDs)
+
+	added := 0
+	for _, resourceID := range resourceIDs {
+		resource := traversalResult.DiscoveredResources[resourceID]
+		if _, fetched := fetchedInto[resourceKey(resource)]; fetched {
+			continue
+		}
+
+		// Resources not reachable from a fetched resource fall back to their own key
+		into, mergeInto := resourceID, false
+		if strategy == v1beta1.ResultMergeInto {
+			if rootInto, found := discoveredFromInto(traversalResult.ResourceGraph, resourceID, fetchedInto); found {
+				into, mergeInto = rootInto, true
+			}
+		}
+
 		// Convert to FetchedResource format
 		namespace := resource.GetNamespace()
 		fetchedResource := &FetchedResource{
 			Request: v1beta1.ResourceRequest{
-				Into:       resourceID,
+				Into:       into,
 				APIVersion: resource.GetAPIVersion(),
 				Kind:       resource.GetKind(),
 				Name:       resource.GetName(),
@@ -352,16 +635,37 @@ func (ede *EnhancedDiscoveryEngine) mergeResults(baseResult *FetchResult, traver
 			},
 		}
 
-		// Add to multi-resources (Phase 3 can discover multiple resources)
-		key := fmt.Sprintf("phase3_%s", resourceID)
-		mergedResult.MultiResources[key] = []*FetchedResource{fetchedResource}
+		if mergeInto {
+			// Copy so the base result's slice is never appended to in place
+			existing := mergedResult.GetAll(into)
+			merged := make([]*FetchedResource, 0, len(existing)+1)
+			merged = append(merged, existing...)
+			mergedResult.MultiResources[into] = append(merged, fetchedResource)
+		} else {
+			// Add to multi-resources (Phase 3 can discover multiple resources)
+			mergedResult.MultiResources[fmt.Sprintf("phase3_%s", resourceID)] = []*FetchedResource{fetchedResource}
+		}
 
 		// Update summary
 		mergedResult.Summary.Successful++
+		added++
 	}
 
 	// Update summary with Phase 3 statistics
-	mergedResult.Summary.TotalRequested += len(traversalResult.DiscoveredResources)
+	mergedResult.Summary.TotalRequested += added
+
+	// Surface recoverable traversal errors to the composition author
+	for _, traversalErr := range traversalResult.Errors {
+		mergedResult.DiscoveryErrors = append(mergedResult.DiscoveryErrors, DiscoveryError{
+			Type:     string(traversalErr.Type),
+			Resource: traversalErr.ResourceID,
+			Message:  traversalErr.Message,
+			Depth:    traversalErr.Depth,
+			Count:    traversalErr.Count,
+		})
+	}
+
+	mergedResult.TraversalSummary = summarizeTraversal(traversalResult)
 
 	// Add cycle information if available
 	if traversalResult.CycleResults != nil && traversalResult.CycleResults.CyclesFound {
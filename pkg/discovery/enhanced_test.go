@@ -0,0 +1,306 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/crossplane/function-sdk-go/logging"
+
+	"github.com/crossplane/function-kubecore-schema-registry/input/v1beta1"
+	"github.com/crossplane/function-kubecore-schema-registry/pkg/graph"
+	"github.com/crossplane/function-kubecore-schema-registry/pkg/traversal"
+)
+
+func TestInputTraversalMaxDepth(t *testing.T) {
+	intPtr := func(i int) *int { return &i }
+
+	cases := map[string]struct {
+		maxDepth    *int
+		expected    int
+		expectError bool
+	}{
+		"Unset":     {maxDepth: nil, expected: 3},
+		"RootsOnly": {maxDepth: intPtr(0), expected: 0},
+		"Explicit":  {maxDepth: intPtr(5), expected: 5},
+		"Negative":  {maxDepth: intPtr(-1), expectError: true},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			input := &v1beta1.TraversalConfig{Enabled: true, MaxDepth: tc.maxDepth}
+
+			err := validateInputTraversalConfig(input)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			ede := &EnhancedDiscoveryEngine{traversalConfig: input}
+			config := ede.buildTraversalConfigFromInput()
+			assert.Equal(t, tc.expected, config.MaxDepth)
+		})
+	}
+}
+
+func TestInputReferencePatterns(t *testing.T) {
+	ede := &EnhancedDiscoveryEngine{
+		traversalConfig: &v1beta1.TraversalConfig{
+			Enabled: true,
+			ReferenceResolution: &v1beta1.ReferenceResolutionConfig{
+				AdditionalPatterns: []v1beta1.ReferencePattern{{Pattern: "teamRef", TargetKind: "Team"}},
+			},
+		},
+	}
+	ede.SetReferencePatterns(&v1beta1.ReferencePatternsConfig{
+		Patterns:        []v1beta1.ReferencePattern{{Pattern: "vaultBinding", TargetKind: "Secret", RefType: "secret", Confidence: 0.9}},
+		ReplaceDefaults: true,
+	})
+
+	config := ede.buildTraversalConfigFromInput()
+	assert.True(t, config.ReferenceResolution.ReplaceDefaultPatterns)
+	assert.Equal(t, []traversal.ReferencePattern{
+		{Pattern: "teamRef", TargetKind: "Team", RefType: traversal.RefTypeCustom},
+		{Pattern: "vaultBinding", TargetKind: "Secret", RefType: traversal.RefTypeSecret, Confidence: 0.9},
+	}, config.ReferenceResolution.ReferencePatterns)
+}
+
+func TestInputCrossNamespace(t *testing.T) {
+	boolPtr := func(b bool) *bool { return &b }
+
+	cases := map[string]struct {
+		crossNamespace *bool
+		scopeFilter    *v1beta1.ScopeFilterConfig
+		expected       bool
+	}{
+		"Unset":             {expected: false},
+		"ScopeFilterOnly":   {scopeFilter: &v1beta1.ScopeFilterConfig{CrossNamespaceEnabled: true}, expected: true},
+		"Enabled":           {crossNamespace: boolPtr(true), expected: true},
+		"DisabledOverrides": {crossNamespace: boolPtr(false), scopeFilter: &v1beta1.ScopeFilterConfig{CrossNamespaceEnabled: true}, expected: false},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ede := &EnhancedDiscoveryEngine{traversalConfig: &v1beta1.TraversalConfig{
+				Enabled:        true,
+				CrossNamespace: tc.crossNamespace,
+				ScopeFilter:    tc.scopeFilter,
+			}}
+
+			config := ede.buildTraversalConfigFromInput()
+			assert.Equal(t, tc.expected, config.ScopeFilter.CrossNamespaceEnabled)
+		})
+	}
+}
+
+func TestKindAllowlistScopesTraversal(t *testing.T) {
+	ede := &EnhancedDiscoveryEngine{traversalConfig: &v1beta1.TraversalConfig{Enabled: true}}
+
+	config := ede.buildTraversalConfigFromInput()
+	assert.Empty(t, config.ScopeFilter.AllowedKinds)
+
+	ede.SetKindAllowlist(NewKindAllowlist([]string{"KubeCluster.platform.kubecore.io", "ConfigMap"}))
+	config = ede.buildTraversalConfigFromInput()
+	assert.Equal(t, []string{"ConfigMap", "KubeCluster.platform.kubecore.io"}, config.ScopeFilter.AllowedKinds)
+}
+
+func TestMergeResultsDeduplicatesFetchedResources(t *testing.T) {
+	newResource := func(kind, name string) *unstructured.Unstructured {
+		resource := &unstructured.Unstructured{}
+		resource.SetAPIVersion("platform.kubecore.io/v1")
+		resource.SetKind(kind)
+		resource.SetName(name)
+		resource.SetNamespace("default")
+		return resource
+	}
+
+	cases := map[string]struct {
+		strategy     v1beta1.ResultMergeStrategy
+		expectedKeys []string
+		expectedInto int
+	}{
+		"Separate": {
+			strategy:     v1beta1.ResultMergeSeparate,
+			expectedKeys: []string{"phase3_platform.kubecore.io/v1/KubeCluster/default/cluster"},
+			expectedInto: 1,
+		},
+		"Into": {
+			strategy:     v1beta1.ResultMergeInto,
+			expectedKeys: []string{"env"},
+			expectedInto: 2,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			env := newResource("KubEnv", "dev")
+			cluster := newResource("KubeCluster", "cluster")
+
+			base := &FetchResult{
+				Resources: map[string]*FetchedResource{
+					"env": {Request: v1beta1.ResourceRequest{Into: "env"}, Resource: env},
+				},
+				MultiResources: map[string][]*FetchedResource{},
+				Summary:        FetchSummary{TotalRequested: 1, Successful: 1},
+			}
+
+			// The traversal rediscovers the fetched env root and finds its cluster
+			builder := graph.NewDefaultGraphBuilder(traversal.NewDefaultPlatformChecker([]string{"*.kubecore.io"}))
+			resourceGraph := builder.NewGraph()
+			envNode := builder.AddNode(resourceGraph, env.DeepCopy(), 0, nil)
+			clusterNode := builder.AddNode(resourceGraph, cluster, 1, nil)
+			builder.AddEdge(resourceGraph, envNode.ID, clusterNode.ID, graph.RelationTypeCustomRef, "spec.kubeClusterRef", "kubeClusterRef", 0.95)
+
+			traversalResult := &traversal.TraversalResult{
+				ResourceGraph: resourceGraph,
+				DiscoveredResources: map[string]*unstructured.Unstructured{
+					string(envNode.ID):     env.DeepCopy(),
+					string(clusterNode.ID): cluster,
+				},
+				Statistics: &traversal.TraversalStatistics{},
+			}
+
+			ede := &EnhancedDiscoveryEngine{
+				logger:          logging.NewNopLogger(),
+				traversalConfig: &v1beta1.TraversalConfig{Enabled: true, ResultMerge: tc.strategy},
+			}
+			merged := ede.mergeResults(base, traversalResult)
+
+			assert.Equal(t, 2, merged.Summary.TotalRequested, "the rediscovered root should not be counted again")
+			assert.Equal(t, 2, merged.Summary.Successful)
+
+			keys := make([]string, 0, len(merged.MultiResources))
+			for key := range merged.MultiResources {
+				keys = append(keys, key)
+			}
+			assert.ElementsMatch(t, tc.expectedKeys, keys)
+			assert.Len(t, merged.GetAll("env"), tc.expectedInto)
+			assert.Empty(t, base.MultiResources, "the base result should not be modified")
+		})
+	}
+}
+
+// stubEngine returns a fixed Phase 1/2 fetch result
+type stubEngine struct {
+	result *FetchResult
+}
+
+func (s *stubEngine) FetchResources(_ []v1beta1.ResourceRequest) (*FetchResult, error) {
+	return s.result, nil
+}
+
+// recordingTraversalEngine records the roots it is asked to traverse and
+// returns result, or an empty result when it is nil
+type recordingTraversalEngine struct {
+	traversal.TraversalEngine
+	roots  []*unstructured.Unstructured
+	result *traversal.TraversalResult
+}
+
+func (r *recordingTraversalEngine) ExecuteTransitiveDiscovery(_ context.Context, _ *traversal.TraversalConfig, rootResources []*unstructured.Unstructured) (*traversal.TraversalResult, error) {
+	r.roots = rootResources
+	if r.result != nil {
+		return r.result, nil
+	}
+	return &traversal.TraversalResult{
+		DiscoveredResources: map[string]*unstructured.Unstructured{},
+		TraversalPath:       &traversal.TraversalPath{},
+		Statistics:          &traversal.TraversalStatistics{},
+		Metadata:            &traversal.TraversalMetadata{},
+	}, nil
+}
+
+func TestPhase3MaxRoots(t *testing.T) {
+	cases := map[string]struct {
+		maxRoots       int
+		expectedRoots  int
+		expectedErrors int
+	}{
+		"Unlimited":   {maxRoots: 0, expectedRoots: 50},
+		"Truncated":   {maxRoots: 10, expectedRoots: 10, expectedErrors: 1},
+		"UnderTheCap": {maxRoots: 100, expectedRoots: 50},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fetched := make([]*FetchedResource, 0, 50)
+			for i := 0; i < 50; i++ {
+				resource := &unstructured.Unstructured{}
+				resource.SetAPIVersion("platform.kubecore.io/v1")
+				resource.SetKind("KubEnv")
+				resource.SetName(fmt.Sprintf("env-%02d", i))
+				resource.SetNamespace("default")
+				fetched = append(fetched, &FetchedResource{Request: v1beta1.ResourceRequest{Into: "envs"}, Resource: resource})
+			}
+
+			traversalEngine := &recordingTraversalEngine{}
+			ede := &EnhancedDiscoveryEngine{
+				base: &stubEngine{result: &FetchResult{
+					Resources:      map[string]*FetchedResource{},
+					MultiResources: map[string][]*FetchedResource{"envs": fetched},
+				}},
+				traversalEngine: traversalEngine,
+				logger:          logging.NewNopLogger(),
+				traversalConfig: &v1beta1.TraversalConfig{Enabled: true, MaxRoots: tc.maxRoots},
+			}
+
+			result, err := ede.FetchResources([]v1beta1.ResourceRequest{{Into: "envs"}})
+			require.NoError(t, err)
+
+			require.Len(t, traversalEngine.roots, tc.expectedRoots)
+			for i, root := range traversalEngine.roots {
+				assert.Equal(t, fmt.Sprintf("env-%02d", i), root.GetName(), "roots should keep the fetch order")
+			}
+			assert.Len(t, result.DiscoveryErrors, tc.expectedErrors)
+		})
+	}
+}
+
+func TestPhase3TraversalSummary(t *testing.T) {
+	newResource := func(kind, name string) *unstructured.Unstructured {
+		resource := &unstructured.Unstructured{}
+		resource.SetAPIVersion("platform.kubecore.io/v1")
+		resource.SetKind(kind)
+		resource.SetName(name)
+		resource.SetNamespace("default")
+		return resource
+	}
+
+	app := newResource("KubeApp", "shop")
+	discovered := map[string]*unstructured.Unstructured{
+		"default/KubeApp/shop":        app,
+		"default/KubEnv/prod":         newResource("KubEnv", "prod"),
+		"default/KubeCluster/primary": newResource("KubeCluster", "primary"),
+		"default/KubeNet/core":        newResource("KubeNet", "core"),
+	}
+
+	ede := &EnhancedDiscoveryEngine{
+		base: &stubEngine{result: &FetchResult{
+			Resources: map[string]*FetchedResource{"app": {Request: v1beta1.ResourceRequest{Into: "app"}, Resource: app}},
+		}},
+		traversalEngine: &recordingTraversalEngine{result: &traversal.TraversalResult{
+			DiscoveredResources: discovered,
+			TraversalPath:       &traversal.TraversalPath{MaxDepthReached: 3},
+			Statistics:          &traversal.TraversalStatistics{},
+			Metadata:            &traversal.TraversalMetadata{},
+			CycleResults:        &graph.CycleDetectionResult{CyclesFound: true, TotalCycles: 1},
+		}},
+		logger:          logging.NewNopLogger(),
+		traversalConfig: &v1beta1.TraversalConfig{Enabled: true, AnnotateSummary: true},
+	}
+
+	result, err := ede.FetchResources([]v1beta1.ResourceRequest{{Into: "app"}})
+	require.NoError(t, err)
+	require.NotNil(t, result.TraversalSummary)
+
+	assert.Equal(t, map[string]string{
+		AnnotationDiscoveredResources: "4",
+		AnnotationMaxDepthReached:     "3",
+		AnnotationCyclesDetected:      "1",
+	}, result.TraversalSummary.Annotations())
+}
@@ -8,13 +8,16 @@ import (
 
 	"golang.org/x/sync/errgroup"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 
 	"github.com/crossplane/function-kubecore-schema-registry/input/v1beta1"
+	"github.com/crossplane/function-kubecore-schema-registry/pkg/discovery/resolver"
 	functionerrors "github.com/crossplane/function-kubecore-schema-registry/pkg/errors"
 	"github.com/crossplane/function-kubecore-schema-registry/pkg/registry"
 )
@@ -26,6 +29,9 @@ type KubernetesEngine struct {
 	registry      registry.Registry
 	timeout       time.Duration
 	maxConcurrent int
+	restMapper    meta.RESTMapper
+	allowedKinds  *KindAllowlist
+	fetchRetry    resolver.RetryPolicy
 }
 
 // NewKubernetesEngine creates a new Kubernetes discovery engine
@@ -64,8 +70,28 @@ func NewKubernetesEngineWithTimeout(config *rest.Config, registry registry.Regis
 	return engine, nil
 }
 
+// SetRESTMapper sets a RESTMapper consulted for kinds missing from the registry
+func (e *KubernetesEngine) SetRESTMapper(mapper meta.RESTMapper) {
+	e.restMapper = mapper
+}
+
+// SetKindAllowlist restricts the kinds that may be fetched; nil allows every kind
+func (e *KubernetesEngine) SetKindAllowlist(allowlist *KindAllowlist) {
+	e.allowedKinds = allowlist
+}
+
+// SetRetryPolicy configures retries of the Get issued for each request. The
+// engine draws backoff jitter from its own source, seeded by the policy.
+func (e *KubernetesEngine) SetRetryPolicy(policy resolver.RetryPolicy) {
+	e.fetchRetry = policy.WithJitterSource()
+}
+
 // FetchResources fetches resources based on the provided requests
 func (e *KubernetesEngine) FetchResources(requests []v1beta1.ResourceRequest) (*FetchResult, error) {
+	if err := e.allowedKinds.Check(requests); err != nil {
+		return nil, err
+	}
+
 	startTime := time.Now()
 
 	result := &FetchResult{
@@ -96,50 +122,7 @@ func (e *KubernetesEngine) FetchResources(requests []v1beta1.ResourceRequest) (*
 
 			if fetchedResource != nil {
 				result.Resources[req.Into] = fetchedResource
-
-				// Update statistics
-				switch fetchedResource.Metadata.FetchStatus {
-				case FetchStatusSuccess:
-					result.Summary.Successful++
-				case FetchStatusNotFound:
-					result.Summary.NotFound++
-					if req.Optional {
-						result.Summary.Skipped++
-					} else {
-						result.Summary.Failed++
-					}
-				case FetchStatusForbidden:
-					result.Summary.Forbidden++
-					if req.Optional {
-						result.Summary.Skipped++
-					} else {
-						result.Summary.Failed++
-					}
-				case FetchStatusTimeout:
-					result.Summary.Timeout++
-					if req.Optional {
-						result.Summary.Skipped++
-					} else {
-						result.Summary.Failed++
-					}
-				case FetchStatusError:
-					if req.Optional {
-						result.Summary.Skipped++
-					} else {
-						result.Summary.Failed++
-					}
-				}
-
-				// Add to errors if failed and not optional
-				if fetchedResource.Metadata.FetchStatus != FetchStatusSuccess &&
-					fetchedResource.Metadata.Error != nil &&
-					!req.Optional {
-					result.Summary.Errors = append(result.Summary.Errors, &FetchError{
-						ResourceRequest: req,
-						Error:           fetchedResource.Metadata.Error,
-						Timestamp:       fetchedResource.FetchedAt,
-					})
-				}
+				result.recordFetchStatus(req, fetchedResource)
 			}
 
 			return nil // Don't propagate individual fetch errors
@@ -202,7 +185,12 @@ func (e *KubernetesEngine) fetchSingleResource(ctx context.Context,
 	}
 
 	// Fetch the resource
-	obj, err := resource.Get(fetchCtx, req.Name, metav1.GetOptions{})
+	var obj *unstructured.Unstructured
+	err = e.fetchRetry.Do(fetchCtx, func() error {
+		var getErr error
+		obj, getErr = resource.Get(fetchCtx, req.Name, metav1.GetOptions{})
+		return getErr
+	})
 	fetchedResource.Metadata.FetchDuration = time.Since(startTime)
 
 	if err != nil {
@@ -259,14 +247,21 @@ func (e *KubernetesEngine) getGVR(apiVersion, kind string) (schema.GroupVersionR
 		}, nil
 	}
 
-	// Fallback to basic pluralization for common cases
-	plural := e.pluralize(kind)
-
 	gv, err := schema.ParseGroupVersion(apiVersion)
 	if err != nil {
 		return schema.GroupVersionResource{}, err
 	}
 
+	// Then ask the cluster through the REST mapper, if one is configured
+	if e.restMapper != nil {
+		if mapping, err := e.restMapper.RESTMapping(gv.WithKind(kind).GroupKind(), gv.Version); err == nil {
+			return mapping.Resource, nil
+		}
+	}
+
+	// Fallback to basic pluralization for common cases
+	plural := e.pluralize(kind)
+
 	return schema.GroupVersionResource{
 		Group:    gv.Group,
 		Version:  gv.Version,
@@ -310,6 +305,35 @@ func (e *KubernetesEngine) pluralize(kind string) string {
 	}
 }
 
+// recordFetchStatus counts a fetched resource in the summary by its fetch
+// status, adding a fetch error when a non-optional request was not fetched
+func (r *FetchResult) recordFetchStatus(req v1beta1.ResourceRequest, fetched *FetchedResource) {
+	switch fetched.Metadata.FetchStatus {
+	case FetchStatusSuccess:
+		r.Summary.Successful++
+		return
+	case FetchStatusNotFound:
+		r.Summary.NotFound++
+	case FetchStatusForbidden:
+		r.Summary.Forbidden++
+	case FetchStatusTimeout:
+		r.Summary.Timeout++
+	}
+
+	if req.Optional {
+		r.Summary.Skipped++
+		return
+	}
+	r.Summary.Failed++
+	if fetched.Metadata.Error != nil {
+		r.Summary.Errors = append(r.Summary.Errors, &FetchError{
+			ResourceRequest: req,
+			Error:           fetched.Metadata.Error,
+			Timestamp:       fetched.FetchedAt,
+		})
+	}
+}
+
 // stringPtrValue safely gets the value of a string pointer
 func stringPtrValue(s *string) string {
 	if s == nil {
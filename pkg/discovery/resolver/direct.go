@@ -6,7 +6,9 @@ import (
 	"time"
 
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
@@ -21,6 +23,8 @@ type DirectResolver struct {
 	dynamicClient dynamic.Interface
 	typedClient   kubernetes.Interface
 	registry      registry.Registry
+	retry         RetryPolicy
+	restMapper    meta.RESTMapper
 }
 
 // NewDirectResolver creates a new direct resolver
@@ -32,6 +36,16 @@ func NewDirectResolver(dynamicClient dynamic.Interface, typedClient kubernetes.I
 	}
 }
 
+// SetRetryPolicy configures retries of the Get issued for each request
+func (r *DirectResolver) SetRetryPolicy(policy RetryPolicy) {
+	r.retry = policy
+}
+
+// SetRESTMapper sets a RESTMapper consulted for kinds missing from the registry
+func (r *DirectResolver) SetRESTMapper(mapper meta.RESTMapper) {
+	r.restMapper = mapper
+}
+
 // SupportsMatchType checks if this resolver supports the given match type
 func (r *DirectResolver) SupportsMatchType(matchType v1beta1.MatchType) bool {
 	return matchType == v1beta1.MatchTypeDirect
@@ -82,7 +96,12 @@ func (r *DirectResolver) Resolve(ctx context.Context, request v1beta1.ResourceRe
 	}
 
 	// Fetch the resource
-	obj, err := resource.Get(ctx, request.Name, metav1.GetOptions{})
+	var obj *unstructured.Unstructured
+	err = r.retry.Do(ctx, func() error {
+		var getErr error
+		obj, getErr = resource.Get(ctx, request.Name, metav1.GetOptions{})
+		return getErr
+	})
 	fetchedResource.Metadata.FetchDuration = time.Since(startTime)
 
 	if err != nil {
@@ -139,14 +158,19 @@ func (r *DirectResolver) getGVR(apiVersion, kind string) (schema.GroupVersionRes
 		}, nil
 	}
 
-	// Fallback to basic pluralization for common cases
-	plural := r.pluralize(kind)
-
 	gv, err := schema.ParseGroupVersion(apiVersion)
 	if err != nil {
 		return schema.GroupVersionResource{}, err
 	}
 
+	// Then ask the cluster through the REST mapper, if one is configured
+	if gvr, ok := restMapping(r.restMapper, gv, kind); ok {
+		return gvr, nil
+	}
+
+	// Fallback to basic pluralization for common cases
+	plural := r.pluralize(kind)
+
 	return schema.GroupVersionResource{
 		Group:    gv.Group,
 		Version:  gv.Version,
@@ -154,6 +178,19 @@ func (r *DirectResolver) getGVR(apiVersion, kind string) (schema.GroupVersionRes
 	}, nil
 }
 
+// restMapping returns the resource the REST mapper maps a kind to, or false
+// when there is no mapper or it does not know the kind
+func restMapping(mapper meta.RESTMapper, gv schema.GroupVersion, kind string) (schema.GroupVersionResource, bool) {
+	if mapper == nil {
+		return schema.GroupVersionResource{}, false
+	}
+	mapping, err := mapper.RESTMapping(gv.WithKind(kind).GroupKind(), gv.Version)
+	if err != nil {
+		return schema.GroupVersionResource{}, false
+	}
+	return mapping.Resource, true
+}
+
 // pluralize provides basic English pluralization rules
 func (r *DirectResolver) pluralize(kind string) string {
 	lower := kind
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -26,6 +27,7 @@ type ExpressionResolver struct {
 	typedClient   kubernetes.Interface
 	registry      registry.Registry
 	context       DiscoveryContext
+	restMapper    meta.RESTMapper
 }
 
 // NewExpressionResolver creates a new expression resolver
@@ -38,6 +40,11 @@ func NewExpressionResolver(dynamicClient dynamic.Interface, typedClient kubernet
 	}
 }
 
+// SetRESTMapper sets a RESTMapper consulted for kinds missing from the registry
+func (r *ExpressionResolver) SetRESTMapper(mapper meta.RESTMapper) {
+	r.restMapper = mapper
+}
+
 // SupportsMatchType checks if this resolver supports the given match type
 func (r *ExpressionResolver) SupportsMatchType(matchType v1beta1.MatchType) bool {
 	return matchType == v1beta1.MatchTypeExpression
@@ -202,7 +209,12 @@ func (r *ExpressionResolver) fetchFromNamespace(ctx context.Context, gvr schema.
 		listOptions.Limit = int64(*request.Strategy.MaxMatches * 2) // Get more than needed for filtering
 	}
 
-	list, err := resource.List(ctx, listOptions)
+	var list *unstructured.UnstructuredList
+	err := r.context.FetchRetry.Do(ctx, func() error {
+		var listErr error
+		list, listErr = resource.List(ctx, listOptions)
+		return listErr
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list resources in namespace %s: %v", namespace, err)
 	}
@@ -366,9 +378,11 @@ func (r *ExpressionResolver) applyMatchStrategy(resources []*FetchedResource,
 		}
 	}
 
-	// Apply sorting if specified
+	// Apply sorting if specified, otherwise order by namespace and name
 	if request.Strategy != nil && len(request.Strategy.SortBy) > 0 {
 		r.sortResources(resources, request.Strategy.SortBy)
+	} else {
+		sortByNamespaceAndName(resources)
 	}
 
 	// Apply limit constraints
@@ -443,14 +457,19 @@ func (r *ExpressionResolver) getGVR(apiVersion, kind string) (schema.GroupVersio
 		}, nil
 	}
 
-	// Fallback to basic pluralization for common cases
-	plural := pluralize(kind)
-
 	gv, err := schema.ParseGroupVersion(apiVersion)
 	if err != nil {
 		return schema.GroupVersionResource{}, err
 	}
 
+	// Then ask the cluster through the REST mapper, if one is configured
+	if gvr, ok := restMapping(r.restMapper, gv, kind); ok {
+		return gvr, nil
+	}
+
+	// Fallback to basic pluralization for common cases
+	plural := pluralize(kind)
+
 	return schema.GroupVersionResource{
 		Group:    gv.Group,
 		Version:  gv.Version,
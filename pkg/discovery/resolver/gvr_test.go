@@ -0,0 +1,48 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/crossplane/function-kubecore-schema-registry/pkg/registry"
+)
+
+func TestGetGVRRESTMapper(t *testing.T) {
+	// The registry does not know TenantQuota, and pluralizing the kind keeps
+	// its capital letter, so only the REST mapper finds the served resource
+	gvk := schema.GroupVersionKind{Group: "example.org", Version: "v1", Kind: "TenantQuota"}
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{gvk.GroupVersion()})
+	mapper.Add(gvk, meta.RESTScopeNamespace)
+
+	reg := registry.NewEmbeddedRegistry()
+	resolvers := map[string]interface {
+		SetRESTMapper(meta.RESTMapper)
+		getGVR(apiVersion, kind string) (schema.GroupVersionResource, error)
+	}{
+		"Direct":     NewDirectResolver(nil, nil, reg),
+		"Label":      NewLabelResolver(nil, nil, reg, DiscoveryContext{}),
+		"Expression": NewExpressionResolver(nil, nil, reg, DiscoveryContext{}),
+	}
+
+	for name, r := range resolvers {
+		t.Run(name, func(t *testing.T) {
+			gvr, err := r.getGVR("example.org/v1", "TenantQuota")
+			require.NoError(t, err)
+			assert.Equal(t, "tenantQuotas", gvr.Resource, "without a mapper the kind is pluralized")
+
+			r.SetRESTMapper(mapper)
+			gvr, err = r.getGVR("example.org/v1", "TenantQuota")
+			require.NoError(t, err)
+			assert.Equal(t, schema.GroupVersionResource{Group: "example.org", Version: "v1", Resource: "tenantquotas"}, gvr)
+
+			// Kinds the mapper does not know still fall back to pluralizing
+			gvr, err = r.getGVR("example.org/v1", "Widget")
+			require.NoError(t, err)
+			assert.Equal(t, "widgets", gvr.Resource)
+		})
+	}
+}
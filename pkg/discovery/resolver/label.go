@@ -6,7 +6,9 @@ import (
 	"sort"
 	"time"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/selection"
@@ -24,6 +26,7 @@ type LabelResolver struct {
 	typedClient   kubernetes.Interface
 	registry      registry.Registry
 	context       DiscoveryContext
+	restMapper    meta.RESTMapper
 }
 
 // NewLabelResolver creates a new label resolver
@@ -36,6 +39,11 @@ func NewLabelResolver(dynamicClient dynamic.Interface, typedClient kubernetes.In
 	}
 }
 
+// SetRESTMapper sets a RESTMapper consulted for kinds missing from the registry
+func (r *LabelResolver) SetRESTMapper(mapper meta.RESTMapper) {
+	r.restMapper = mapper
+}
+
 // SupportsMatchType checks if this resolver supports the given match type
 func (r *LabelResolver) SupportsMatchType(matchType v1beta1.MatchType) bool {
 	return matchType == v1beta1.MatchTypeLabel
@@ -181,7 +189,12 @@ func (r *LabelResolver) fetchFromNamespace(ctx context.Context, gvr schema.Group
 		listOptions.Limit = int64(*request.Strategy.MaxMatches)
 	}
 
-	list, err := resource.List(ctx, listOptions)
+	var list *unstructured.UnstructuredList
+	err := r.context.FetchRetry.Do(ctx, func() error {
+		var listErr error
+		list, listErr = resource.List(ctx, listOptions)
+		return listErr
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list resources in namespace %s: %v", namespace, err)
 	}
@@ -230,9 +243,11 @@ func (r *LabelResolver) applyMatchStrategy(resources []*FetchedResource,
 		}
 	}
 
-	// Apply sorting if specified
+	// Apply sorting if specified, otherwise order by namespace and name
 	if request.Strategy != nil && len(request.Strategy.SortBy) > 0 {
 		r.sortResources(resources, request.Strategy.SortBy)
+	} else {
+		sortByNamespaceAndName(resources)
 	}
 
 	// Apply limit constraints
@@ -307,14 +322,19 @@ func (r *LabelResolver) getGVR(apiVersion, kind string) (schema.GroupVersionReso
 		}, nil
 	}
 
-	// Fallback to basic pluralization for common cases
-	plural := pluralize(kind)
-
 	gv, err := schema.ParseGroupVersion(apiVersion)
 	if err != nil {
 		return schema.GroupVersionResource{}, err
 	}
 
+	// Then ask the cluster through the REST mapper, if one is configured
+	if gvr, ok := restMapping(r.restMapper, gv, kind); ok {
+		return gvr, nil
+	}
+
+	// Fallback to basic pluralization for common cases
+	plural := pluralize(kind)
+
 	return schema.GroupVersionResource{
 		Group:    gv.Group,
 		Version:  gv.Version,
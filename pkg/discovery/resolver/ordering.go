@@ -0,0 +1,21 @@
+package resolver
+
+import "sort"
+
+// sortByNamespaceAndName orders resources by namespace and then name, giving
+// selector results a deterministic order when no sortBy is requested
+func sortByNamespaceAndName(resources []*FetchedResource) {
+	sort.SliceStable(resources, func(i, j int) bool {
+		a, b := resources[i].Resource, resources[j].Resource
+		if a.GetNamespace() != b.GetNamespace() {
+			return a.GetNamespace() < b.GetNamespace()
+		}
+		return a.GetName() < b.GetName()
+	})
+
+	for i, resource := range resources {
+		if resource.Metadata.Phase2Metadata != nil {
+			resource.Metadata.Phase2Metadata.SortPosition = &i
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package resolver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	"github.com/crossplane/function-kubecore-schema-registry/input/v1beta1"
+	"github.com/crossplane/function-kubecore-schema-registry/pkg/registry"
+)
+
+func newTestConfigMap(namespace, name string) *unstructured.Unstructured {
+	configMap := &unstructured.Unstructured{}
+	configMap.SetAPIVersion("v1")
+	configMap.SetKind("ConfigMap")
+	configMap.SetNamespace(namespace)
+	configMap.SetName(name)
+	configMap.SetLabels(map[string]string{"app": "shop"})
+	return configMap
+}
+
+func TestSelectorResultsDefaultOrder(t *testing.T) {
+	objects := []runtime.Object{
+		newTestConfigMap("team-b", "app-a"),
+		newTestConfigMap("team-a", "app-z"),
+		newTestConfigMap("team-b", "app-c"),
+		newTestConfigMap("team-a", "app-b"),
+	}
+	want := []string{"team-a/app-b", "team-a/app-z", "team-b/app-a", "team-b/app-c"}
+
+	discoveryContext := DiscoveryContext{FunctionNamespace: "crossplane-system", Phase2Enabled: true}
+	reg := registry.NewEmbeddedRegistry()
+
+	cases := map[string]struct {
+		resolver Resolver
+		selector *v1beta1.Selector
+	}{
+		"Label": {
+			resolver: NewLabelResolver(dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), objects...), nil, reg, discoveryContext),
+			selector: &v1beta1.Selector{
+				Labels: &v1beta1.LabelSelector{MatchLabels: map[string]string{"app": "shop"}},
+			},
+		},
+		"Expression": {
+			resolver: NewExpressionResolver(dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), objects...), nil, reg, discoveryContext),
+			selector: &v1beta1.Selector{
+				Expressions: []v1beta1.Expression{{Field: "metadata.name", Operator: v1beta1.ExpressionOpStartsWith, Value: stringPtr("app-")}},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Namespaces are searched in reverse order so the listing order differs from the expected one
+			tc.selector.Namespaces = []string{"team-b", "team-a"}
+
+			resources, err := tc.resolver.Resolve(context.Background(), v1beta1.ResourceRequest{
+				Into:       "configs",
+				APIVersion: "v1",
+				Kind:       "ConfigMap",
+				Selector:   tc.selector,
+			})
+			require.NoError(t, err)
+
+			var got []string
+			for i, resource := range resources {
+				got = append(got, resource.Resource.GetNamespace()+"/"+resource.Resource.GetName())
+				assert.Equal(t, i, *resource.Metadata.Phase2Metadata.SortPosition)
+			}
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,113 @@
+package resolver
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+// RetryPolicy controls how individual Get and List calls are retried when
+// the API server reports a transient failure
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt; zero disables retries
+	MaxRetries int
+
+	// Backoff is the wait before the first retry, doubled for every further retry
+	Backoff time.Duration
+
+	// Jitter adds up to this fraction of each wait at random, so concurrent
+	// retries do not line up; zero waits exactly Backoff
+	Jitter float64
+
+	// JitterSeed seeds the jitter source created by WithJitterSource. Zero
+	// seeds it from the clock.
+	JitterSeed int64
+
+	// jitter is the random source shared by every copy of the policy
+	jitter *jitterSource
+}
+
+// jitterSource is a random source that is safe for concurrent retries
+type jitterSource struct {
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// newJitterSource creates a jitter source from seed, or from the clock when seed is 0
+func newJitterSource(seed int64) *jitterSource {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &jitterSource{rand: rand.New(rand.NewSource(seed))}
+}
+
+func (j *jitterSource) float64() float64 {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.rand.Float64()
+}
+
+// WithJitterSource returns a copy of the policy with its own jitter source,
+// seeded from JitterSeed. Engines call this once so that all their resolvers
+// draw jitter from one source instead of the global one.
+func (p RetryPolicy) WithJitterSource() RetryPolicy {
+	p.jitter = newJitterSource(p.JitterSeed)
+	return p
+}
+
+// backoff returns the wait before the given retry, counting from 0
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	wait := p.Backoff << retry
+	if p.Jitter > 0 && p.jitter != nil {
+		wait += time.Duration(float64(wait) * p.Jitter * p.jitter.float64())
+	}
+	return wait
+}
+
+// Do runs fn, retrying transient failures according to the policy. The error
+// from the last attempt is returned when all attempts fail.
+func (p RetryPolicy) Do(ctx context.Context, fn func() error) error {
+	if p.Jitter > 0 && p.jitter == nil {
+		p = p.WithJitterSource()
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= p.MaxRetries || !isRetryableFetchError(err) {
+			return err
+		}
+
+		if wait := p.backoff(attempt); wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			case <-timer.C:
+			}
+		} else if ctx.Err() != nil {
+			return err
+		}
+	}
+}
+
+// isRetryableFetchError reports whether err is worth retrying. Errors that
+// describe the request itself, such as not found or forbidden, are final.
+func isRetryableFetchError(err error) bool {
+	switch {
+	case errors.IsTimeout(err),
+		errors.IsServerTimeout(err),
+		errors.IsTooManyRequests(err),
+		errors.IsServiceUnavailable(err),
+		errors.IsInternalError(err),
+		errors.IsUnexpectedServerError(err):
+		return true
+	}
+
+	// Errors without an API status come from the transport, e.g. a dropped connection
+	_, isStatus := err.(errors.APIStatus)
+	return !isStatus && !errors.IsNotFound(err)
+}
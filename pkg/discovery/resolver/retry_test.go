@@ -0,0 +1,106 @@
+package resolver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/crossplane/function-kubecore-schema-registry/input/v1beta1"
+	"github.com/crossplane/function-kubecore-schema-registry/pkg/registry"
+)
+
+func TestDirectResolverFetchRetry(t *testing.T) {
+	configMaps := schema.GroupResource{Resource: "configmaps"}
+
+	cases := map[string]struct {
+		policy     RetryPolicy
+		failure    error
+		wantStatus FetchStatus
+		wantCalls  int
+	}{
+		"TransientFailureIsRetried": {
+			policy:     RetryPolicy{MaxRetries: 2, Backoff: time.Millisecond},
+			failure:    apierrors.NewServiceUnavailable("apiserver is restarting"),
+			wantStatus: FetchStatusSuccess,
+			wantCalls:  2,
+		},
+		"RetriesDisabled": {
+			policy:     RetryPolicy{},
+			failure:    apierrors.NewServiceUnavailable("apiserver is restarting"),
+			wantStatus: FetchStatusError,
+			wantCalls:  1,
+		},
+		"ForbiddenIsNotRetried": {
+			policy:     RetryPolicy{MaxRetries: 2, Backoff: time.Millisecond},
+			failure:    apierrors.NewForbidden(configMaps, "app-config", nil),
+			wantStatus: FetchStatusForbidden,
+			wantCalls:  1,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), newTestConfigMap("team-a", "app-config"))
+
+			calls := 0
+			client.PrependReactor("get", "configmaps", func(k8stesting.Action) (bool, runtime.Object, error) {
+				calls++
+				if calls == 1 {
+					return true, nil, tc.failure
+				}
+				return false, nil, nil
+			})
+
+			r := NewDirectResolver(client, nil, registry.NewEmbeddedRegistry())
+			r.SetRetryPolicy(tc.policy)
+
+			resources, err := r.Resolve(context.Background(), v1beta1.ResourceRequest{
+				Into:       "config",
+				Name:       "app-config",
+				Namespace:  stringPtr("team-a"),
+				APIVersion: "v1",
+				Kind:       "ConfigMap",
+			})
+			require.NoError(t, err)
+			require.Len(t, resources, 1)
+
+			assert.Equal(t, tc.wantStatus, resources[0].Metadata.FetchStatus)
+			assert.Equal(t, tc.wantCalls, calls)
+			if tc.wantStatus == FetchStatusSuccess {
+				assert.Equal(t, "app-config", resources[0].Resource.GetName())
+			}
+		})
+	}
+}
+
+func TestRetryPolicyJitterSeed(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 5, Backoff: 100 * time.Millisecond, Jitter: 0.5, JitterSeed: 42}
+
+	backoffs := func(policy RetryPolicy) []time.Duration {
+		var waits []time.Duration
+		for retry := 0; retry < policy.MaxRetries; retry++ {
+			waits = append(waits, policy.backoff(retry))
+		}
+		return waits
+	}
+
+	first := backoffs(policy.WithJitterSource())
+	assert.Equal(t, first, backoffs(policy.WithJitterSource()), "the same seed should give the same backoffs")
+
+	for retry, wait := range first {
+		base := policy.Backoff << retry
+		assert.GreaterOrEqual(t, wait, base)
+		assert.LessOrEqual(t, wait, base+base/2)
+	}
+
+	policy.JitterSeed = 7
+	assert.NotEqual(t, first, backoffs(policy.WithJitterSource()), "a different seed should give different backoffs")
+}
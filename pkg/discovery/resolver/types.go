@@ -135,4 +135,7 @@ type DiscoveryContext struct {
 
 	// Phase2Enabled indicates if Phase 2 features are enabled
 	Phase2Enabled bool
+
+	// FetchRetry controls retries of individual resource Gets and Lists
+	FetchRetry RetryPolicy
 }
@@ -0,0 +1,50 @@
+package discovery
+
+import (
+	"strconv"
+
+	"github.com/crossplane/function-kubecore-schema-registry/pkg/traversal"
+)
+
+// Annotations written to the desired XR when traversalConfig.annotateSummary is set
+const (
+	AnnotationDiscoveredResources = "kubecore.io/discovered-resources"
+	AnnotationMaxDepthReached     = "kubecore.io/max-depth-reached"
+	AnnotationCyclesDetected      = "kubecore.io/cycles-detected"
+)
+
+// TraversalSummary contains the headline counts of a Phase 3 traversal
+type TraversalSummary struct {
+	// DiscoveredResources is the number of resources in the traversal graph,
+	// including the roots
+	DiscoveredResources int `json:"discoveredResources"`
+
+	// MaxDepthReached is the deepest level traversal expanded
+	MaxDepthReached int `json:"maxDepthReached"`
+
+	// CyclesDetected is the number of reference cycles found
+	CyclesDetected int `json:"cyclesDetected"`
+}
+
+// summarizeTraversal computes the summary counts of a traversal result
+func summarizeTraversal(result *traversal.TraversalResult) *TraversalSummary {
+	summary := &TraversalSummary{
+		DiscoveredResources: len(result.DiscoveredResources),
+	}
+	if result.TraversalPath != nil {
+		summary.MaxDepthReached = result.TraversalPath.MaxDepthReached
+	}
+	if result.CycleResults != nil {
+		summary.CyclesDetected = result.CycleResults.TotalCycles
+	}
+	return summary
+}
+
+// Annotations renders the summary as XR annotations
+func (s *TraversalSummary) Annotations() map[string]string {
+	return map[string]string{
+		AnnotationDiscoveredResources: strconv.Itoa(s.DiscoveredResources),
+		AnnotationMaxDepthReached:     strconv.Itoa(s.MaxDepthReached),
+		AnnotationCyclesDetected:      strconv.Itoa(s.CyclesDetected),
+	}
+}
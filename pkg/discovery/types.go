@@ -6,6 +6,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	"github.com/crossplane/function-kubecore-schema-registry/input/v1beta1"
+	"github.com/crossplane/function-kubecore-schema-registry/pkg/discovery/resolver"
 	"github.com/crossplane/function-kubecore-schema-registry/pkg/errors"
 )
 
@@ -30,6 +31,10 @@ type DiscoveryContext struct {
 
 	// Phase2Enabled indicates if Phase 2 features are enabled
 	Phase2Enabled bool
+
+	// FetchRetry controls retries of individual resource Gets and Lists,
+	// independently of the retries used when resolving references
+	FetchRetry resolver.RetryPolicy
 }
 
 // FetchResult represents the result of a resource fetch operation
@@ -47,6 +52,67 @@ type FetchResult struct {
 
 	// Phase2Results contains Phase 2 specific metadata
 	Phase2Results *Phase2Results `json:"phase2Results,omitempty"`
+
+	// DiscoveryErrors contains recoverable errors from transitive discovery,
+	// such as references that could not be resolved
+	DiscoveryErrors []DiscoveryError `json:"discoveryErrors,omitempty"`
+
+	// TraversalSummary summarizes Phase 3 traversal, when it ran
+	TraversalSummary *TraversalSummary `json:"traversalSummary,omitempty"`
+}
+
+// DiscoveryError describes a recoverable error encountered during discovery
+type DiscoveryError struct {
+	// Type categorizes the error, e.g. reference_resolution
+	Type string `json:"type"`
+
+	// Resource identifies the resource being processed when the error occurred
+	Resource string `json:"resource,omitempty"`
+
+	// Message is the error message
+	Message string `json:"message"`
+
+	// Depth is the traversal depth at which the error first occurred
+	Depth int `json:"depth,omitempty"`
+
+	// Count is how many times the error occurred during traversal
+	Count int `json:"count,omitempty"`
+}
+
+// Get returns the resource fetched for the given 'into' name. When the request
+// matched multiple resources the first one is returned.
+func (r *FetchResult) Get(into string) (*FetchedResource, bool) {
+	if r == nil {
+		return nil, false
+	}
+
+	if resource, ok := r.Resources[into]; ok && resource != nil {
+		return resource, true
+	}
+
+	if resources := r.MultiResources[into]; len(resources) > 0 {
+		return resources[0], true
+	}
+
+	return nil, false
+}
+
+// GetAll returns every resource fetched for the given 'into' name, regardless
+// of whether it landed in Resources or MultiResources
+func (r *FetchResult) GetAll(into string) []*FetchedResource {
+	if r == nil {
+		return nil
+	}
+
+	if resources := r.MultiResources[into]; len(resources) > 0 {
+		return resources
+	}
+
+	if resource, ok := r.Resources[into]; ok && resource != nil {
+		return []*FetchedResource{resource}
+	}
+
+	return nil
 }
 
 // FetchedResource represents a single fetched resource with metadata
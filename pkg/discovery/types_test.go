@@ -0,0 +1,73 @@
+package discovery
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newFetchedResource(name string) *FetchedResource {
+	obj := &unstructured.Unstructured{}
+	obj.SetName(name)
+	return &FetchedResource{
+		Resource: obj,
+		Metadata: ResourceMetadata{FetchStatus: FetchStatusSuccess, ResourceExists: true},
+	}
+}
+
+func TestFetchResultAccessors(t *testing.T) {
+	single := newFetchedResource("single")
+	multiA := newFetchedResource("multi-a")
+	multiB := newFetchedResource("multi-b")
+
+	result := &FetchResult{
+		Resources: map[string]*FetchedResource{
+			"single": single,
+			"multi":  multiA,
+		},
+		MultiResources: map[string][]*FetchedResource{
+			"multi": {multiA, multiB},
+		},
+	}
+
+	t.Run("single result", func(t *testing.T) {
+		got, ok := result.Get("single")
+		require.True(t, ok)
+		assert.Equal(t, "single", got.Resource.GetName())
+
+		all := result.GetAll("single")
+		require.Len(t, all, 1)
+		assert.Same(t, single, all[0])
+	})
+
+	t.Run("multi result", func(t *testing.T) {
+		got, ok := result.Get("multi")
+		require.True(t, ok)
+		assert.Same(t, multiA, got)
+
+		all := result.GetAll("multi")
+		require.Len(t, all, 2)
+		assert.Equal(t, "multi-b", all[1].Resource.GetName())
+	})
+
+	t.Run("multi result only", func(t *testing.T) {
+		onlyMulti := &FetchResult{
+			MultiResources: map[string][]*FetchedResource{
+				"multi": {multiA, multiB},
+			},
+		}
+
+		got, ok := onlyMulti.Get("multi")
+		require.True(t, ok)
+		assert.Same(t, multiA, got)
+	})
+
+	t.Run("missing result", func(t *testing.T) {
+		got, ok := result.Get("missing")
+		assert.False(t, ok)
+		assert.Nil(t, got)
+		assert.Empty(t, result.GetAll("missing"))
+	})
+}
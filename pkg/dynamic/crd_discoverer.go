@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -12,6 +13,8 @@ import (
 	"github.com/crossplane/function-sdk-go/logging"
 	"github.com/pkg/errors"
 	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/singleflight"
+	"golang.org/x/time/rate"
 	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -31,6 +34,27 @@ type DefaultCRDDiscoverer struct {
 	cache   *CRDCache
 	metrics *DiscoveryMetrics
 	mu      sync.RWMutex
+
+	// discoveries coalesces concurrent discoveries of the same patterns into
+	// one CRD List
+	discoveries singleflight.Group
+
+	// results holds recent discovery results by pattern key, reused for
+	// resultTTL; guarded by mu
+	results   map[string]discoveryResult
+	resultTTL time.Duration
+
+	// listLimiter rate limits CRD Lists; nil means unlimited
+	listLimiter *rate.Limiter
+
+	// listSlots bounds concurrent CRD Lists; nil means unlimited
+	listSlots chan struct{}
+}
+
+// discoveryResult is the outcome of a finished CRD discovery
+type discoveryResult struct {
+	crdInfos     []*CRDInfo
+	discoveredAt time.Time
 }
 
 // CRDCache provides caching for discovered CRDs
@@ -62,10 +86,12 @@ type DiscoveryMetrics struct {
 // NewCRDDiscoverer creates a new CRD discoverer
 func NewCRDDiscoverer(client apiextensionsclientset.Interface, logger logging.Logger) *DefaultCRDDiscoverer {
 	return &DefaultCRDDiscoverer{
-		client:  client,
-		logger:  logger,
-		cache:   NewCRDCache(DefaultCacheTTL),
-		metrics: &DiscoveryMetrics{},
+		client:    client,
+		logger:    logger,
+		cache:     NewCRDCache(DefaultCacheTTL),
+		metrics:   &DiscoveryMetrics{},
+		results:   make(map[string]discoveryResult),
+		resultTTL: DefaultDiscoveryResultTTL,
 	}
 }
 
@@ -77,13 +103,121 @@ func NewCRDCache(ttl time.Duration) *CRDCache {
 	}
 }
 
+// SetRateLimit limits the CRD Lists discoveries make to qps per second, with
+// bursts of up to burst Lists. A qps of zero or less removes the limit. It
+// must be called before discovery starts.
+func (d *DefaultCRDDiscoverer) SetRateLimit(qps float64, burst int) {
+	if qps <= 0 {
+		d.listLimiter = nil
+		return
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	d.listLimiter = rate.NewLimiter(rate.Limit(qps), burst)
+}
+
+// SetResultTTL sets how long a discovery's result is reused by later
+// discoveries of the same patterns. Zero or less lists on every discovery.
+func (d *DefaultCRDDiscoverer) SetResultTTL(ttl time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.resultTTL = ttl
+}
+
+// SetMaxConcurrentDiscoveries bounds the CRD Lists in flight at once. Zero or
+// less removes the bound. It must be called before discovery starts.
+func (d *DefaultCRDDiscoverer) SetMaxConcurrentDiscoveries(limit int) {
+	if limit <= 0 {
+		d.listSlots = nil
+		return
+	}
+	d.listSlots = make(chan struct{}, limit)
+}
+
 // DiscoverCRDs discovers CRDs matching the given patterns
 func (d *DefaultCRDDiscoverer) DiscoverCRDs(ctx context.Context, patterns []string) ([]*CRDInfo, error) {
 	return d.DiscoverWithTimeout(ctx, patterns, DefaultDiscoveryTimeout)
 }
 
-// DiscoverWithTimeout discovers CRDs with a specified timeout
+// DiscoverWithTimeout discovers CRDs with a specified timeout. Concurrent
+// discoveries of the same patterns, in any order, share a single CRD List and
+// its result, and later discoveries reuse that result for the result TTL. The
+// shared List is bounded by the first caller's timeout but not cancelled with
+// its context; each caller stops waiting when its own context is done.
 func (d *DefaultCRDDiscoverer) DiscoverWithTimeout(ctx context.Context, patterns []string, timeout time.Duration) ([]*CRDInfo, error) {
+	key := discoveryKey(patterns)
+	if crdInfos, ok := d.recentResult(key); ok {
+		d.logger.Debug("Reusing recent CRD discovery", "patterns", patterns)
+		return append([]*CRDInfo(nil), crdInfos...), nil
+	}
+
+	results := d.discoveries.DoChan(key, func() (interface{}, error) {
+		crdInfos, err := d.discover(context.WithoutCancel(ctx), patterns, timeout)
+		if err == nil {
+			d.storeResult(key, crdInfos)
+		}
+		return crdInfos, err
+	})
+
+	select {
+	case result := <-results:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		if result.Shared {
+			d.logger.Debug("Shared concurrent CRD discovery", "patterns", patterns)
+		}
+
+		// Each caller gets its own slice of the shared CRD infos
+		crdInfos := result.Val.([]*CRDInfo)
+		return append([]*CRDInfo(nil), crdInfos...), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// recentResult returns the result of a discovery of the same patterns that
+// finished within the result TTL
+func (d *DefaultCRDDiscoverer) recentResult(key string) ([]*CRDInfo, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	result, ok := d.results[key]
+	if !ok || time.Since(result.discoveredAt) >= d.resultTTL {
+		return nil, false
+	}
+	return result.crdInfos, true
+}
+
+// storeResult records a discovery's result for reuse, dropping expired ones
+func (d *DefaultCRDDiscoverer) storeResult(key string, crdInfos []*CRDInfo) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.resultTTL <= 0 {
+		return
+	}
+	now := time.Now()
+	for existing, result := range d.results {
+		if now.Sub(result.discoveredAt) >= d.resultTTL {
+			delete(d.results, existing)
+		}
+	}
+	d.results[key] = discoveryResult{crdInfos: crdInfos, discoveredAt: now}
+}
+
+// discoveryKey identifies a set of patterns regardless of their order
+func discoveryKey(patterns []string) string {
+	sorted := append([]string(nil), patterns...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, "\x00")
+}
+
+// discover lists the cluster's CRDs, once the rate limit and concurrency
+// bound allow, and extracts the ones matching patterns
+func (d *DefaultCRDDiscoverer) discover(ctx context.Context, patterns []string, timeout time.Duration) ([]*CRDInfo, error) {
 	startTime := time.Now()
 
 	d.logger.Info("Starting CRD discovery", "patterns", patterns, "timeout", timeout)
@@ -96,7 +230,7 @@ func (d *DefaultCRDDiscoverer) DiscoverWithTimeout(ctx context.Context, patterns
 	d.resetMetrics()
 
 	// List all CRDs from cluster
-	crdList, err := d.client.ApiextensionsV1().CustomResourceDefinitions().List(ctx, metav1.ListOptions{})
+	crdList, err := d.listCRDs(ctx)
 	if err != nil {
 		d.recordError(errors.Wrap(err, "failed to list CRDs from cluster"))
 		return nil, errors.Wrap(err, "failed to list CRDs from cluster")
@@ -135,6 +269,27 @@ func (d *DefaultCRDDiscoverer) DiscoverWithTimeout(ctx context.Context, patterns
 	return crdInfos, nil
 }
 
+// listCRDs lists every CRD in the cluster within the rate limit and
+// concurrency bound
+func (d *DefaultCRDDiscoverer) listCRDs(ctx context.Context) (*apiextv1.CustomResourceDefinitionList, error) {
+	if d.listLimiter != nil {
+		if err := d.listLimiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if d.listSlots != nil {
+		select {
+		case d.listSlots <- struct{}{}:
+			defer func() { <-d.listSlots }()
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	return d.client.ApiextensionsV1().CustomResourceDefinitions().List(ctx, metav1.ListOptions{})
+}
+
 // processCRDsConcurrently processes CRDs using a worker pool
 func (d *DefaultCRDDiscoverer) processCRDsConcurrently(ctx context.Context, crds []apiextv1.CustomResourceDefinition) ([]*CRDInfo, error) {
 	g, gCtx := errgroup.WithContext(ctx)
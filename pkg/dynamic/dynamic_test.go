@@ -2,6 +2,9 @@ package dynamic
 
 import (
 	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -11,6 +14,8 @@ import (
 	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 func TestSchemaParser(t *testing.T) {
@@ -203,6 +208,294 @@ func TestReferenceDetector(t *testing.T) {
 	}
 }
 
+func TestReferenceDetectorManagedResourcePaths(t *testing.T) {
+	detector := NewReferenceDetector(logging.NewNopLogger())
+
+	// Crossplane managed resources nest references under spec.forProvider
+	schema := &ResourceSchema{
+		Fields: map[string]*FieldDefinition{
+			"forProvider": {
+				Type: "object",
+				Properties: map[string]*FieldDefinition{
+					"region": {Type: "string"},
+					"vpcIdRef": {
+						Type: "object",
+						Properties: map[string]*FieldDefinition{
+							"name": {Type: "string"},
+						},
+					},
+				},
+			},
+			"providerConfigRef": {
+				Type: "object",
+				Properties: map[string]*FieldDefinition{
+					"name": {Type: "string"},
+				},
+			},
+		},
+	}
+
+	references, err := detector.DetectReferences(schema)
+	require.NoError(t, err)
+
+	paths := make(map[string]string)
+	for _, ref := range references {
+		paths[ref.FieldName] = ref.FieldPath
+	}
+
+	assert.Equal(t, "spec.forProvider.vpcIdRef", paths["vpcIdRef"])
+	assert.Equal(t, "spec.providerConfigRef", paths["providerConfigRef"])
+}
+
+func TestReferenceDetectorMapValues(t *testing.T) {
+	logger := logging.NewNopLogger()
+
+	// Platform config blobs key their entries by name, e.g. databases.orders.secretRef
+	crdSchema := &apiextv1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]apiextv1.JSONSchemaProps{
+			"databases": {
+				Type: "object",
+				AdditionalProperties: &apiextv1.JSONSchemaPropsOrBool{
+					Allows: true,
+					Schema: &apiextv1.JSONSchemaProps{
+						Type: "object",
+						Properties: map[string]apiextv1.JSONSchemaProps{
+							"engine": {Type: "string"},
+							"secretRef": {
+								Type: "object",
+								Properties: map[string]apiextv1.JSONSchemaProps{
+									"name": {Type: "string"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	schema, err := NewSchemaParser(logger).ParseOpenAPISchema(crdSchema)
+	require.NoError(t, err)
+	require.NotNil(t, schema.Fields["databases"].AdditionalProperties)
+
+	references, err := NewReferenceDetector(logger).DetectReferences(schema)
+	require.NoError(t, err)
+
+	var secretRef *ReferenceField
+	for i := range references {
+		if references[i].FieldName == "secretRef" {
+			secretRef = &references[i]
+		}
+	}
+	require.NotNil(t, secretRef, "secretRef inside the map value schema should be detected")
+	assert.Equal(t, "databases.*.secretRef", secretRef.FieldPath)
+	assert.Equal(t, "Secret", secretRef.TargetKind)
+	assert.Equal(t, RefTypeSecret, secretRef.RefType)
+	assert.True(t, HasWildcard(secretRef.FieldPath))
+
+	// The map path expands to one readable path per key holding the field
+	obj := map[string]interface{}{
+		"databases": map[string]interface{}{
+			"orders":     map[string]interface{}{"secretRef": map[string]interface{}{"name": "orders-db"}},
+			"cache":      map[string]interface{}{"engine": "redis"},
+			"billing":    map[string]interface{}{"secretRef": map[string]interface{}{"name": "billing-db"}},
+			"legacy.eu":  map[string]interface{}{"secretRef": map[string]interface{}{"name": "unaddressable"}},
+			"not-an-obj": "ignored",
+		},
+	}
+
+	paths, err := ExpandFieldPath(obj, secretRef.FieldPath)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"databases.billing.secretRef", "databases.orders.secretRef"}, paths)
+
+	value, found, err := NestedFieldValue(obj, paths[1])
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, map[string]interface{}{"name": "orders-db"}, value)
+
+	_, _, err = NestedFieldValue(obj, secretRef.FieldPath)
+	assert.Error(t, err, "schema paths must be expanded before values are read")
+}
+
+func TestReferenceDetectorArraySchemaPath(t *testing.T) {
+	schema := &ResourceSchema{
+		Fields: map[string]*FieldDefinition{
+			"volumes": {
+				Type: "array",
+				Items: &FieldDefinition{
+					Type: "object",
+					Properties: map[string]*FieldDefinition{
+						"configMapRef": {Type: "string"},
+					},
+				},
+			},
+		},
+	}
+
+	references, err := NewReferenceDetector(logging.NewNopLogger()).DetectReferences(schema)
+	require.NoError(t, err)
+
+	var configMapRef *ReferenceField
+	for i := range references {
+		if references[i].FieldName == "configMapRef" {
+			configMapRef = &references[i]
+		}
+	}
+	require.NotNil(t, configMapRef)
+	assert.Equal(t, "volumes[*].configMapRef", configMapRef.SchemaPath)
+
+	obj := map[string]interface{}{
+		"volumes": []interface{}{
+			map[string]interface{}{"configMapRef": "app-settings"},
+			map[string]interface{}{"name": "scratch"},
+			map[string]interface{}{"configMapRef": "app-flags"},
+		},
+	}
+
+	paths, err := ExpandFieldPath(obj, configMapRef.SchemaPath)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"volumes[0].configMapRef", "volumes[2].configMapRef"}, paths)
+
+	value, found, err := NestedFieldValue(obj, paths[1])
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "app-flags", value)
+
+	_, found, err = NestedFieldValue(obj, "volumes[5].configMapRef")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	_, _, err = NestedFieldValue(obj, configMapRef.SchemaPath)
+	assert.Error(t, err, "schema paths must be expanded before values are read")
+}
+
+func TestReferenceDetectorRecursionDepth(t *testing.T) {
+	// nested builds a chain of "child" objects with a secretRef at the bottom
+	nested := func(levels int) *FieldDefinition {
+		field := &FieldDefinition{
+			Type: "object",
+			Properties: map[string]*FieldDefinition{
+				"secretRef": {Type: "string"},
+			},
+		}
+		for i := 0; i < levels; i++ {
+			field = &FieldDefinition{
+				Type:       "object",
+				Properties: map[string]*FieldDefinition{"child": field},
+			}
+		}
+		return field
+	}
+
+	// A self-referential schema, as a recursive CRD definition would produce
+	recursive := &FieldDefinition{Type: "object", Properties: map[string]*FieldDefinition{}}
+	recursive.Properties["child"] = recursive
+
+	cases := map[string]struct {
+		root            *FieldDefinition
+		maxDepth        int
+		expectReference bool
+		expectLimitHits bool
+		expectFields    int
+	}{
+		"WithinDefaultLimit": {
+			root:            nested(5),
+			expectReference: true,
+		},
+		"BeyondConfiguredLimit": {
+			root:            nested(5),
+			maxDepth:        3,
+			expectLimitHits: true,
+		},
+		"BeyondDefaultLimit": {
+			root:            nested(DefaultMaxRecursionDepth + 5),
+			expectLimitHits: true,
+		},
+		"SelfReferential": {
+			root:            recursive,
+			maxDepth:        4,
+			expectLimitHits: true,
+			// The root and one child at each of the 4 levels below it
+			expectFields: 5,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			detector := NewReferenceDetector(logging.NewNopLogger())
+			detector.SetMaxRecursionDepth(tc.maxDepth)
+
+			references, err := detector.DetectReferences(&ResourceSchema{Fields: map[string]*FieldDefinition{"spec": tc.root}})
+			require.NoError(t, err)
+
+			found := false
+			for _, ref := range references {
+				if ref.FieldName == "secretRef" {
+					found = true
+				}
+			}
+			assert.Equal(t, tc.expectReference, found)
+
+			stats := detector.GetDetectionStats()
+			assert.Equal(t, tc.expectLimitHits, stats.DepthLimitHits > 0)
+			if tc.expectFields > 0 {
+				assert.Equal(t, tc.expectFields, stats.FieldsAnalyzed)
+			}
+		})
+	}
+}
+
+func TestReferenceDetectorCumulativeStats(t *testing.T) {
+	detector := NewReferenceDetector(logging.NewNopLogger())
+
+	schemas := []*ResourceSchema{
+		{
+			Fields: map[string]*FieldDefinition{
+				"configMapRef": {Type: "string"},
+				"secretRef":    {Type: "string"},
+			},
+		},
+		{
+			Fields: map[string]*FieldDefinition{
+				"kubeClusterRef": {Type: "string"},
+				"description":    {Type: "string"},
+			},
+		},
+		{
+			Fields: map[string]*FieldDefinition{
+				"ownerName": {Type: "string"},
+				"value":     {Type: "integer"},
+			},
+		},
+	}
+
+	expected := &DetectionStats{}
+	for _, schema := range schemas {
+		_, err := detector.DetectReferences(schema)
+		require.NoError(t, err)
+
+		perCall := detector.GetDetectionStats()
+		expected.FieldsAnalyzed += perCall.FieldsAnalyzed
+		expected.ReferencesFound += perCall.ReferencesFound
+		expected.PatternMatches += perCall.PatternMatches
+		expected.HeuristicMatches += perCall.HeuristicMatches
+	}
+
+	cumulative := detector.GetCumulativeStats()
+	assert.Equal(t, 4, cumulative.ReferencesFound)
+	assert.Equal(t, expected.ReferencesFound, cumulative.ReferencesFound)
+	assert.Equal(t, expected.FieldsAnalyzed, cumulative.FieldsAnalyzed)
+	assert.Equal(t, expected.PatternMatches, cumulative.PatternMatches)
+	assert.Equal(t, expected.HeuristicMatches, cumulative.HeuristicMatches)
+
+	// Per-call stats still only reflect the last call
+	assert.Equal(t, 1, detector.GetDetectionStats().ReferencesFound)
+
+	detector.ResetCumulativeStats()
+	assert.Equal(t, 0, detector.GetCumulativeStats().ReferencesFound)
+}
+
 func TestCRDDiscovererMocked(t *testing.T) {
 	logger := logging.NewNopLogger()
 
@@ -314,6 +607,8 @@ func TestCRDDiscovererMocked(t *testing.T) {
 	t.Run("statistics", func(t *testing.T) {
 		patterns := []string{"platform.kubecore.io"}
 
+		// Statistics describe the last List, so list again rather than reuse
+		discoverer.SetResultTTL(0)
 		_, err := discoverer.DiscoverCRDs(context.Background(), patterns)
 		require.NoError(t, err)
 
@@ -325,6 +620,125 @@ func TestCRDDiscovererMocked(t *testing.T) {
 	})
 }
 
+func TestCRDDiscovererCoalescesConcurrentDiscoveries(t *testing.T) {
+	fakeClient := apiextensionsfake.NewSimpleClientset(&apiextv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "kubeclusters.platform.kubecore.io"},
+		Spec: apiextv1.CustomResourceDefinitionSpec{
+			Group: "platform.kubecore.io",
+			Names: apiextv1.CustomResourceDefinitionNames{Kind: "KubeCluster", Plural: "kubeclusters"},
+			Scope: apiextv1.NamespaceScoped,
+			Versions: []apiextv1.CustomResourceDefinitionVersion{
+				{Name: "v1alpha1", Served: true, Storage: true},
+			},
+		},
+	})
+
+	// Hold the first List open until the second discovery has joined it
+	var lists atomic.Int32
+	listing := make(chan struct{})
+	release := make(chan struct{})
+	fakeClient.PrependReactor("list", "customresourcedefinitions", func(k8stesting.Action) (bool, runtime.Object, error) {
+		if lists.Add(1) == 1 {
+			close(listing)
+			<-release
+		}
+		return false, nil, nil
+	})
+
+	discoverer := NewCRDDiscoverer(fakeClient, logging.NewNopLogger())
+	discoverer.SetMaxConcurrentDiscoveries(1)
+	discoverer.SetRateLimit(100, 1)
+
+	var wg sync.WaitGroup
+	results := make([][]*CRDInfo, 2)
+	errs := make([]error, 2)
+	discover := func(i int, patterns []string) {
+		defer wg.Done()
+		results[i], errs[i] = discoverer.DiscoverCRDs(context.Background(), patterns)
+	}
+
+	wg.Add(2)
+	go discover(0, []string{"platform.kubecore.io", "*.kubecore.io"})
+	<-listing
+	go discover(1, []string{"*.kubecore.io", "platform.kubecore.io"})
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	for i := range results {
+		require.NoError(t, errs[i])
+		require.Len(t, results[i], 1)
+		assert.Equal(t, "KubeCluster", results[i][0].Kind)
+	}
+	assert.Equal(t, int32(1), lists.Load(), "concurrent discoveries of the same patterns should share one List")
+
+	// Later discoveries reuse the result until it expires
+	reused, err := discoverer.DiscoverCRDs(context.Background(), []string{"*.kubecore.io", "platform.kubecore.io"})
+	require.NoError(t, err)
+	require.Len(t, reused, 1)
+	assert.Equal(t, int32(1), lists.Load(), "a recent result should be reused")
+
+	discoverer.SetResultTTL(0)
+	_, err = discoverer.DiscoverCRDs(context.Background(), []string{"platform.kubecore.io", "*.kubecore.io"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), lists.Load(), "an expired result should be listed again")
+}
+
+func TestCRDDiscovererSharedDiscoveryOutlivesCancelledCaller(t *testing.T) {
+	fakeClient := apiextensionsfake.NewSimpleClientset(&apiextv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "kubeclusters.platform.kubecore.io"},
+		Spec: apiextv1.CustomResourceDefinitionSpec{
+			Group: "platform.kubecore.io",
+			Names: apiextv1.CustomResourceDefinitionNames{Kind: "KubeCluster", Plural: "kubeclusters"},
+			Scope: apiextv1.NamespaceScoped,
+			Versions: []apiextv1.CustomResourceDefinitionVersion{
+				{Name: "v1alpha1", Served: true, Storage: true},
+			},
+		},
+	})
+
+	// Hold the List open until the first caller has given up
+	var lists atomic.Int32
+	listing := make(chan struct{})
+	release := make(chan struct{})
+	fakeClient.PrependReactor("list", "customresourcedefinitions", func(k8stesting.Action) (bool, runtime.Object, error) {
+		if lists.Add(1) == 1 {
+			close(listing)
+			<-release
+		}
+		return false, nil, nil
+	})
+
+	discoverer := NewCRDDiscoverer(fakeClient, logging.NewNopLogger())
+	patterns := []string{"platform.kubecore.io"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := make(chan error, 1)
+	go func() {
+		_, err := discoverer.DiscoverCRDs(ctx, patterns)
+		cancelled <- err
+	}()
+	<-listing
+
+	shared := make(chan []*CRDInfo, 1)
+	go func() {
+		crdInfos, err := discoverer.DiscoverCRDs(context.Background(), patterns)
+		assert.NoError(t, err)
+		shared <- crdInfos
+	}()
+
+	// The first caller stops waiting as soon as its context is cancelled
+	cancel()
+	assert.ErrorIs(t, <-cancelled, context.Canceled)
+
+	// The shared List carries on for the caller still waiting
+	close(release)
+	crdInfos := <-shared
+	require.Len(t, crdInfos, 1)
+	assert.Equal(t, "KubeCluster", crdInfos[0].Kind)
+	assert.Equal(t, int32(1), lists.Load())
+}
+
 func TestReferencePatterns(t *testing.T) {
 	logger := logging.NewNopLogger()
 	detector := NewReferenceDetector(logger)
@@ -649,3 +1063,49 @@ func BenchmarkReferenceDetector(b *testing.B) {
 		}
 	}
 }
+
+func TestReferenceDetectorConfidenceScorer(t *testing.T) {
+	schema := &ResourceSchema{Fields: map[string]*FieldDefinition{
+		"clusterRef": {
+			Type:        "object",
+			Description: "The KubeCluster hosting this environment",
+			Properties:  map[string]*FieldDefinition{"name": {Type: "string"}},
+		},
+		"secretRef": {
+			Type:       "object",
+			Properties: map[string]*FieldDefinition{"name": {Type: "string"}},
+		},
+	}}
+
+	// boostKind raises the confidence of fields whose description names the target kind
+	boostKind := func(_ string, fieldDef *FieldDefinition, base float64) float64 {
+		if strings.Contains(fieldDef.Description, "KubeCluster") {
+			return base + 0.5
+		}
+		return base
+	}
+
+	confidences := func(scorer ConfidenceScorer) map[string]float64 {
+		detector := NewReferenceDetector(logging.NewNopLogger())
+		detector.SetConfidenceScorer(scorer)
+
+		references, err := detector.DetectReferences(schema)
+		require.NoError(t, err)
+
+		byField := make(map[string]float64, len(references))
+		for _, ref := range references {
+			byField[ref.FieldName] = ref.Confidence
+		}
+		return byField
+	}
+
+	base := confidences(nil)
+	require.Contains(t, base, "clusterRef")
+	require.Contains(t, base, "secretRef")
+	require.Less(t, base["clusterRef"], 1.0)
+
+	boosted := confidences(boostKind)
+	assert.Greater(t, boosted["clusterRef"], base["clusterRef"])
+	assert.Equal(t, 1.0, boosted["clusterRef"], "boosted confidence is clamped")
+	assert.Equal(t, base["secretRef"], boosted["secretRef"])
+}
@@ -0,0 +1,183 @@
+package dynamic
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ArrayWildcard marks a schema path segment that matches every list element,
+// e.g. spec.containers[*].configMapRef
+const ArrayWildcard = "[*]"
+
+// MapWildcard is a schema path segment that matches every value of a map,
+// e.g. spec.databases.*.secretRef
+const MapWildcard = "*"
+
+// HasWildcard reports whether a field path is a schema path holding list or
+// map wildcards, which must be expanded before values are read
+func HasWildcard(path string) bool {
+	if strings.Contains(path, ArrayWildcard) {
+		return true
+	}
+	for _, segment := range strings.Split(path, ".") {
+		if segment == MapWildcard {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldPathToken is one step of a field path: a map key, or a list index.
+// An index of -1 is the [*] wildcard.
+type fieldPathToken struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// parseFieldPath splits a dotted field path with [i] and [*] list indices
+// into tokens
+func parseFieldPath(path string) ([]fieldPathToken, error) {
+	var tokens []fieldPathToken
+	for _, segment := range strings.Split(path, ".") {
+		key := segment
+		brackets := ""
+		if open := strings.Index(segment, "["); open >= 0 {
+			key, brackets = segment[:open], segment[open:]
+		}
+		if key != "" {
+			tokens = append(tokens, fieldPathToken{key: key})
+		}
+
+		for brackets != "" {
+			end := strings.Index(brackets, "]")
+			if !strings.HasPrefix(brackets, "[") || end < 0 {
+				return nil, fmt.Errorf("invalid list index in field path %q", path)
+			}
+			index := -1
+			if value := brackets[1:end]; value != "*" {
+				parsed, err := strconv.Atoi(value)
+				if err != nil || parsed < 0 {
+					return nil, fmt.Errorf("invalid list index %q in field path %q", value, path)
+				}
+				index = parsed
+			}
+			tokens = append(tokens, fieldPathToken{index: index, isIndex: true})
+			brackets = brackets[end+1:]
+		}
+	}
+	return tokens, nil
+}
+
+// ExpandFieldPath expands the [*] and .* wildcards of a schema path against an
+// object, returning the instance path of every element the full path exists
+// in, e.g. spec.containers[0].configMapRef and spec.containers[2].configMapRef,
+// or spec.databases.orders.secretRef. Map keys are expanded in sorted order;
+// keys holding dots or brackets cannot be addressed and are skipped. Paths
+// without wildcards are returned as-is when they exist.
+func ExpandFieldPath(obj map[string]interface{}, schemaPath string) ([]string, error) {
+	tokens, err := parseFieldPath(schemaPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	var expand func(value interface{}, tokens []fieldPathToken, prefix string)
+	expand = func(value interface{}, tokens []fieldPathToken, prefix string) {
+		if len(tokens) == 0 {
+			paths = append(paths, prefix)
+			return
+		}
+
+		token := tokens[0]
+		if !token.isIndex {
+			fields, ok := value.(map[string]interface{})
+			if !ok {
+				return
+			}
+			if prefix != "" {
+				prefix += "."
+			}
+			if token.key == MapWildcard {
+				keys := make([]string, 0, len(fields))
+				for key := range fields {
+					if !strings.ContainsAny(key, ".[]") {
+						keys = append(keys, key)
+					}
+				}
+				sort.Strings(keys)
+				for _, key := range keys {
+					expand(fields[key], tokens[1:], prefix+key)
+				}
+				return
+			}
+			next, found := fields[token.key]
+			if !found {
+				return
+			}
+			expand(next, tokens[1:], prefix+token.key)
+			return
+		}
+
+		items, ok := value.([]interface{})
+		if !ok {
+			return
+		}
+		if token.index >= 0 {
+			if token.index < len(items) {
+				expand(items[token.index], tokens[1:], fmt.Sprintf("%s[%d]", prefix, token.index))
+			}
+			return
+		}
+		for i, item := range items {
+			expand(item, tokens[1:], fmt.Sprintf("%s[%d]", prefix, i))
+		}
+	}
+	expand(obj, tokens, "")
+
+	return paths, nil
+}
+
+// NestedFieldValue returns the value at an instance field path, which may
+// index into lists with [i]. Wildcards are not allowed.
+func NestedFieldValue(obj map[string]interface{}, fieldPath string) (interface{}, bool, error) {
+	tokens, err := parseFieldPath(fieldPath)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var value interface{} = obj
+	for _, token := range tokens {
+		if !token.isIndex {
+			if token.key == MapWildcard {
+				return nil, false, fmt.Errorf("field path %q has a wildcard key", fieldPath)
+			}
+			fields, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, false, fmt.Errorf("%s is not an object in field path %q", token.key, fieldPath)
+			}
+			next, found := fields[token.key]
+			if !found {
+				return nil, false, nil
+			}
+			value = next
+			continue
+		}
+
+		if token.index < 0 {
+			return nil, false, fmt.Errorf("field path %q has a wildcard index", fieldPath)
+		}
+		items, ok := value.([]interface{})
+		if !ok {
+			return nil, false, fmt.Errorf("field path %q indexes a value that is not a list", fieldPath)
+		}
+		if token.index >= len(items) {
+			return nil, false, nil
+		}
+		value = items[token.index]
+	}
+
+	return value, true, nil
+}
@@ -2,12 +2,16 @@ package dynamic
 
 import (
 	"fmt"
+	"math"
 	"path/filepath"
 	"regexp"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/crossplane/function-sdk-go/logging"
+
+	"github.com/crossplane/function-kubecore-schema-registry/pkg/logfields"
 )
 
 // ReferenceDetector interface for detecting reference fields in schemas
@@ -30,6 +34,16 @@ type ReferenceMetadata struct {
 	MatchedPattern  string
 }
 
+// ConfidenceScorer adjusts the confidence assigned to a detected reference.
+// base is the confidence from the matching pattern or heuristic; the returned
+// value is clamped to [0, 1].
+type ConfidenceScorer func(fieldName string, fieldDef *FieldDefinition, base float64) float64
+
+// identityConfidenceScorer keeps the base confidence unchanged
+func identityConfidenceScorer(_ string, _ *FieldDefinition, base float64) float64 {
+	return base
+}
+
 // PatternBasedDetector implements reference detection using configurable patterns
 type PatternBasedDetector struct {
 	patterns   []ReferencePattern
@@ -37,6 +51,16 @@ type PatternBasedDetector struct {
 	logger     logging.Logger
 	stats      *DetectionStats
 	mu         sync.RWMutex
+
+	// cumulative aggregates stats across DetectReferences calls
+	cumulative *DetectionStats
+	statsMu    sync.Mutex
+
+	// maxRecursionDepth bounds how deep nested schemas are analyzed
+	maxRecursionDepth int
+
+	// confidenceScorer adjusts the confidence of every detected reference
+	confidenceScorer ConfidenceScorer
 }
 
 // NewReferenceDetector creates a new pattern-based reference detector
@@ -44,8 +68,12 @@ func NewReferenceDetector(logger logging.Logger) *PatternBasedDetector {
 	detector := &PatternBasedDetector{
 		patterns:   make([]ReferencePattern, len(DefaultReferencePatterns)),
 		regexCache: make(map[string]*regexp.Regexp),
-		logger:     logger,
+		logger:     logfields.ForComponent(logger, logfields.ComponentReferenceDetector),
 		stats:      &DetectionStats{},
+		cumulative: &DetectionStats{},
+
+		maxRecursionDepth: DefaultMaxRecursionDepth,
+		confidenceScorer:  identityConfidenceScorer,
 	}
 
 	// Copy default patterns
@@ -56,74 +84,129 @@ func NewReferenceDetector(logger logging.Logger) *PatternBasedDetector {
 
 // DetectReferences analyzes a schema and detects all reference fields
 func (d *PatternBasedDetector) DetectReferences(schema *ResourceSchema) ([]ReferenceField, error) {
-	d.resetStats()
+	startTime := time.Now()
+
+	// Each call counts into its own stats, so concurrent calls don't race
+	stats := &DetectionStats{}
 
 	var references []ReferenceField
 
 	// Analyze all fields recursively
 	for fieldName, fieldDef := range schema.Fields {
-		refs := d.analyzeFieldRecursively(fieldName, fieldDef, "")
+		refs := d.analyzeFieldRecursively(stats, fieldName, fieldDef, "", 0)
 		references = append(references, refs...)
 	}
 
-	d.stats.ReferencesFound = len(references)
+	stats.ReferencesFound = len(references)
+	stats.DetectionTime = time.Since(startTime)
+	d.accumulateStats(stats)
 
 	d.logger.Debug("Reference detection completed",
-		"fields_analyzed", d.stats.FieldsAnalyzed,
-		"references_found", d.stats.ReferencesFound,
-		"pattern_matches", d.stats.PatternMatches,
-		"heuristic_matches", d.stats.HeuristicMatches)
+		"fields_analyzed", stats.FieldsAnalyzed,
+		"references_found", stats.ReferencesFound,
+		"pattern_matches", stats.PatternMatches,
+		"heuristic_matches", stats.HeuristicMatches,
+		"depth_limit_hits", stats.DepthLimitHits)
 
 	return references, nil
 }
 
-// analyzeFieldRecursively analyzes a field and its nested properties for references
-func (d *PatternBasedDetector) analyzeFieldRecursively(fieldName string, fieldDef *FieldDefinition, basePath string) []ReferenceField {
+// SetMaxRecursionDepth sets how many levels of nested schema are analyzed
+// below the top-level fields. Values below 1 restore DefaultMaxRecursionDepth.
+func (d *PatternBasedDetector) SetMaxRecursionDepth(depth int) {
+	if depth < 1 {
+		depth = DefaultMaxRecursionDepth
+	}
+	d.maxRecursionDepth = depth
+}
+
+// SetConfidenceScorer installs a hook that adjusts the confidence of each
+// detected reference. A nil scorer keeps the base confidence.
+func (d *PatternBasedDetector) SetConfidenceScorer(scorer ConfidenceScorer) {
+	if scorer == nil {
+		scorer = identityConfidenceScorer
+	}
+	d.confidenceScorer = scorer
+}
+
+// scoreConfidence applies the confidence scorer to a base confidence
+func (d *PatternBasedDetector) scoreConfidence(fieldName string, fieldDef *FieldDefinition, base float64) float64 {
+	return math.Max(0, math.Min(1, d.confidenceScorer(fieldName, fieldDef, base)))
+}
+
+// analyzeFieldRecursively analyzes a field and its nested properties for
+// references. depth is the nesting level of the field, 0 for top-level fields;
+// nested schemas below maxRecursionDepth are not analyzed.
+func (d *PatternBasedDetector) analyzeFieldRecursively(stats *DetectionStats, fieldName string, fieldDef *FieldDefinition, basePath string, depth int) []ReferenceField {
 	var references []ReferenceField
 
-	d.stats.FieldsAnalyzed++
+	stats.FieldsAnalyzed++
 
 	// Build current field path
 	fieldPath := d.buildFieldPath(basePath, fieldName)
 
-	// Check if this field is a reference
-	if ref := d.analyzeFieldForReference(fieldName, fieldDef, fieldPath); ref != nil {
+	// Check if this field is a reference. Unnamed list elements and map values
+	// are only references through the field holding them; on their own they
+	// would be detected by structure alone, with no target kind to resolve.
+	var ref *ReferenceField
+	if fieldName != "" {
+		ref = d.analyzeFieldForReference(stats, fieldName, fieldDef, fieldPath)
+	}
+	if ref != nil {
 		references = append(references, *ref)
 	}
 
+	// Stop descending into deeply nested or self-referential schemas
+	if depth >= d.maxRecursionDepth {
+		if fieldDef.Properties != nil || fieldDef.Items != nil || fieldDef.AdditionalProperties != nil {
+			stats.DepthLimitHits++
+			d.logger.Debug("Reached maximum schema recursion depth",
+				logfields.FieldPath, fieldPath,
+				"maxRecursionDepth", d.maxRecursionDepth)
+		}
+		return references
+	}
+
 	// Recursively analyze nested properties
 	if fieldDef.Properties != nil {
 		for propName, propDef := range fieldDef.Properties {
-			nestedRefs := d.analyzeFieldRecursively(propName, propDef, fieldPath)
+			nestedRefs := d.analyzeFieldRecursively(stats, propName, propDef, fieldPath, depth+1)
 			references = append(references, nestedRefs...)
 		}
 	}
 
-	// Analyze array items
-	if fieldDef.Items != nil {
+	// Analyze array items, unless the list itself is a reference to its elements
+	if fieldDef.Items != nil && (ref == nil || fieldDef.Type != "array") {
 		arrayPath := fieldPath + "[*]"
-		itemRefs := d.analyzeFieldRecursively("", fieldDef.Items, arrayPath)
+		itemRefs := d.analyzeFieldRecursively(stats, "", fieldDef.Items, arrayPath, depth+1)
 		references = append(references, itemRefs...)
 	}
 
+	// Analyze map values; every key of a free-form map shares the value schema
+	if fieldDef.AdditionalProperties != nil {
+		mapPath := fieldPath + ".*"
+		valueRefs := d.analyzeFieldRecursively(stats, "", fieldDef.AdditionalProperties, mapPath, depth+1)
+		references = append(references, valueRefs...)
+	}
+
 	return references
 }
 
 // analyzeFieldForReference analyzes a single field to determine if it's a reference
-func (d *PatternBasedDetector) analyzeFieldForReference(fieldName string, fieldDef *FieldDefinition, fieldPath string) *ReferenceField {
-	// Pattern-based detection
-	if ref := d.detectByPattern(fieldName, fieldDef, fieldPath); ref != nil {
-		d.stats.PatternMatches++
-		return ref
-	}
-
-	// Heuristic-based detection
-	if ref := d.detectByHeuristics(fieldName, fieldDef, fieldPath); ref != nil {
-		d.stats.HeuristicMatches++
-		return ref
-	}
-
-	return nil
+func (d *PatternBasedDetector) analyzeFieldForReference(stats *DetectionStats, fieldName string, fieldDef *FieldDefinition, fieldPath string) *ReferenceField {
+	ref := d.detectByPattern(fieldName, fieldDef, fieldPath)
+	if ref != nil {
+		// Pattern-based detection
+		stats.PatternMatches++
+	} else if ref = d.detectByHeuristics(fieldName, fieldDef, fieldPath); ref != nil {
+		// Heuristic-based detection
+		stats.HeuristicMatches++
+	} else {
+		return nil
+	}
+
+	ref.Confidence = d.scoreConfidence(fieldName, fieldDef, ref.Confidence)
+	return ref
 }
 
 // detectByPattern detects references using configured patterns
@@ -134,7 +217,7 @@ func (d *PatternBasedDetector) detectByPattern(fieldName string, fieldDef *Field
 		
 		d.logger.Debug("Pattern matching attempt", 
 			"fieldName", fieldName, 
-			"fieldPath", fieldPath,
+			logfields.FieldPath, fieldPath,
 			"pattern", pattern.Pattern,
 			"matchesName", matchesName,
 			"compatibleType", compatibleType,
@@ -142,14 +225,8 @@ func (d *PatternBasedDetector) detectByPattern(fieldName string, fieldDef *Field
 			"hasProperties", fieldDef.Properties != nil)
 		
 		if matchesName && compatibleType {
-			// Construct proper field path: if we have a simple field name, 
-			// assume it's within spec unless already fully qualified
-			finalFieldPath := fieldPath
-			if fieldPath == fieldName && fieldName != "" {
-				// If fieldPath equals fieldName, it means we're at root level
-				// For most Kubernetes resources, references are in spec
-				finalFieldPath = "spec." + fieldName
-			}
+			// Construct proper field path: schema fields are relative to spec
+			finalFieldPath := d.qualifyFieldPath(fieldName, fieldPath)
 			
 			targetKind := d.inferTargetKind(fieldName, pattern)
 			
@@ -162,12 +239,15 @@ func (d *PatternBasedDetector) detectByPattern(fieldName string, fieldDef *Field
 			
 			return &ReferenceField{
 				FieldPath:       finalFieldPath,
+				SchemaPath:      finalFieldPath,
 				FieldName:       fieldName,
 				TargetKind:      targetKind,
 				TargetGroup:     pattern.TargetGroup,
 				RefType:         pattern.RefType,
 				Confidence:      pattern.Confidence,
 				DetectionMethod: "pattern_match",
+				NameTemplate:    pattern.NameTemplate,
+				MatchedPattern:  pattern.Pattern,
 			}
 		}
 	}
@@ -178,15 +258,13 @@ func (d *PatternBasedDetector) detectByPattern(fieldName string, fieldDef *Field
 // detectByHeuristics detects references using heuristic analysis
 func (d *PatternBasedDetector) detectByHeuristics(fieldName string, fieldDef *FieldDefinition, fieldPath string) *ReferenceField {
 	// Construct proper field path for heuristic matches too
-	finalFieldPath := fieldPath
-	if fieldPath == fieldName && fieldName != "" {
-		finalFieldPath = "spec." + fieldName
-	}
+	finalFieldPath := d.qualifyFieldPath(fieldName, fieldPath)
 
 	// Check description for reference keywords
 	if d.containsReferenceKeywords(fieldDef.Description) {
 		return &ReferenceField{
 			FieldPath:       finalFieldPath,
+			SchemaPath:      finalFieldPath,
 			FieldName:       fieldName,
 			RefType:         RefTypeCustom,
 			Confidence:      0.7,
@@ -198,6 +276,7 @@ func (d *PatternBasedDetector) detectByHeuristics(fieldName string, fieldDef *Fi
 	if d.looksLikeReference(fieldName) {
 		return &ReferenceField{
 			FieldPath:       finalFieldPath,
+			SchemaPath:      finalFieldPath,
 			FieldName:       fieldName,
 			RefType:         RefTypeCustom,
 			Confidence:      0.6,
@@ -209,6 +288,7 @@ func (d *PatternBasedDetector) detectByHeuristics(fieldName string, fieldDef *Fi
 	if d.hasReferenceStructure(fieldDef) {
 		return &ReferenceField{
 			FieldPath:       finalFieldPath,
+			SchemaPath:      finalFieldPath,
 			FieldName:       fieldName,
 			RefType:         RefTypeCustom,
 			Confidence:      0.8,
@@ -313,6 +393,9 @@ func (d *PatternBasedDetector) isCompatibleType(fieldDef *FieldDefinition, patte
 			"hasReferenceStructure", hasRefStructure)
 			
 		return hasRefStructure
+	case "array":
+		// Lists of names or name/namespace objects reference several targets
+		return fieldDef.Items != nil && fieldDef.Items.Type != "array" && d.isCompatibleType(fieldDef.Items, pattern)
 	default:
 		return false
 	}
@@ -481,7 +564,7 @@ func (d *PatternBasedDetector) ExtractReferenceMetadata(fieldName string, fieldD
 				TargetKind:      d.inferTargetKind(fieldName, pattern),
 				TargetGroup:     pattern.TargetGroup,
 				RefType:         pattern.RefType,
-				Confidence:      pattern.Confidence,
+				Confidence:      d.scoreConfidence(fieldName, fieldDef, pattern.Confidence),
 				DetectionMethod: "pattern_match",
 				MatchedPattern:  pattern.Pattern,
 			}
@@ -492,7 +575,7 @@ func (d *PatternBasedDetector) ExtractReferenceMetadata(fieldName string, fieldD
 	if d.containsReferenceKeywords(fieldDef.Description) {
 		return &ReferenceMetadata{
 			RefType:         RefTypeCustom,
-			Confidence:      0.7,
+			Confidence:      d.scoreConfidence(fieldName, fieldDef, 0.7),
 			DetectionMethod: "description_analysis",
 		}
 	}
@@ -520,6 +603,29 @@ func (d *PatternBasedDetector) GetPatterns() []ReferencePattern {
 
 // Helper methods
 
+// managedResourceParameterFields are the spec fields Crossplane managed
+// resources nest their parameters, and so their references, under
+var managedResourceParameterFields = []string{"forProvider", "initProvider"}
+
+// qualifyFieldPath prefixes spec-relative field paths with "spec." so they can
+// be read from the resource. Root-level fields and fields nested under a
+// managed resource's forProvider or initProvider are spec-relative.
+func (d *PatternBasedDetector) qualifyFieldPath(fieldName, fieldPath string) string {
+	if fieldPath == fieldName && fieldName != "" {
+		// If fieldPath equals fieldName, it means we're at root level
+		// For most Kubernetes resources, references are in spec
+		return "spec." + fieldName
+	}
+
+	for _, parameters := range managedResourceParameterFields {
+		if strings.HasPrefix(fieldPath, parameters+".") {
+			return "spec." + fieldPath
+		}
+	}
+
+	return fieldPath
+}
+
 func (d *PatternBasedDetector) buildFieldPath(basePath, fieldName string) string {
 	if basePath == "" {
 		return fieldName
@@ -530,15 +636,48 @@ func (d *PatternBasedDetector) buildFieldPath(basePath, fieldName string) string
 	return fmt.Sprintf("%s.%s", basePath, fieldName)
 }
 
-func (d *PatternBasedDetector) resetStats() {
-	d.stats = &DetectionStats{}
-}
-
-// GetDetectionStats returns current detection statistics
+// GetDetectionStats returns detection statistics for the most recent DetectReferences call
 func (d *PatternBasedDetector) GetDetectionStats() *DetectionStats {
+	d.statsMu.Lock()
+	defer d.statsMu.Unlock()
+
 	return d.stats
 }
 
+// accumulateStats records the stats of a single detection run as the most
+// recent and adds them to the cumulative totals
+func (d *PatternBasedDetector) accumulateStats(stats *DetectionStats) {
+	d.statsMu.Lock()
+	defer d.statsMu.Unlock()
+
+	d.stats = stats
+
+	d.cumulative.FieldsAnalyzed += stats.FieldsAnalyzed
+	d.cumulative.ReferencesFound += stats.ReferencesFound
+	d.cumulative.PatternMatches += stats.PatternMatches
+	d.cumulative.HeuristicMatches += stats.HeuristicMatches
+	d.cumulative.DetectionTime += stats.DetectionTime
+	d.cumulative.DepthLimitHits += stats.DepthLimitHits
+}
+
+// GetCumulativeStats returns detection statistics summed across all
+// DetectReferences calls since creation or the last ResetCumulativeStats
+func (d *PatternBasedDetector) GetCumulativeStats() *DetectionStats {
+	d.statsMu.Lock()
+	defer d.statsMu.Unlock()
+
+	stats := *d.cumulative
+	return &stats
+}
+
+// ResetCumulativeStats clears the cumulative detection statistics
+func (d *PatternBasedDetector) ResetCumulativeStats() {
+	d.statsMu.Lock()
+	defer d.statsMu.Unlock()
+
+	d.cumulative = &DetectionStats{}
+}
+
 // ClearRegexCache clears the compiled regex cache
 func (d *PatternBasedDetector) ClearRegexCache() {
 	d.mu.Lock()
@@ -151,6 +151,17 @@ func (p *DefaultSchemaParser) parseFieldDefinitionWithValidation(name string, sc
 		}
 	}
 
+	// Handle map values, which share a single schema across all keys
+	if schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
+		valuePath := p.buildFieldPath(path, "*")
+		valueField, err := p.parseFieldDefinitionWithValidation("", schema.AdditionalProperties.Schema, valuePath)
+		if err != nil {
+			p.logger.Debug("Failed to parse additional properties", "field", name, "error", err)
+		} else {
+			field.AdditionalProperties = valueField
+		}
+	}
+
 	// Cache the result
 	p.cacheField(cacheKey, field)
 
@@ -224,6 +235,11 @@ func (p *DefaultSchemaParser) inferFieldType(schema *apiextv1.JSONSchemaProps) s
 		return "object"
 	}
 
+	// Infer from map values
+	if schema.AdditionalProperties != nil {
+		return "object"
+	}
+
 	// Infer from items
 	if schema.Items != nil {
 		return "array"
@@ -61,11 +61,21 @@ type FieldDefinition struct {
 	Enum        []string
 	Pattern     string
 	Default     interface{}
+	// AdditionalProperties is the value schema of map-typed objects
+	AdditionalProperties *FieldDefinition
 }
 
 // ReferenceField represents a field that references another resource
 type ReferenceField struct {
-	FieldPath       string
+	// FieldPath locates the reference in a resource. Detected references
+	// use the schema path until they are expanded against an instance, when
+	// [*] wildcards are replaced by concrete list indices and .* wildcards by
+	// map keys.
+	FieldPath string
+	// SchemaPath is the schema-level path of the reference, with [*] for
+	// every list element and .* for every map value, e.g.
+	// spec.containers[*].configMapRef
+	SchemaPath      string
 	FieldName       string
 	TargetKind      string
 	TargetGroup     string
@@ -73,6 +83,21 @@ type ReferenceField struct {
 	RefType         RefType
 	Confidence      float64
 	DetectionMethod string
+	// NameTemplate derives the target name from the source resource when the
+	// field value itself is not the target name (see ReferencePattern.NameTemplate)
+	NameTemplate string
+	// MatchedPattern is the reference pattern that detected this field, if any
+	MatchedPattern string
+	// AlsoDetectedBy records further detections of the same field by other
+	// methods or patterns, merged in when duplicate references are dropped
+	AlsoDetectedBy []ReferenceDetection
+}
+
+// ReferenceDetection describes one detection of a reference field
+type ReferenceDetection struct {
+	DetectionMethod string
+	Confidence      float64
+	MatchedPattern  string
 }
 
 // ReferencePattern defines patterns for detecting reference fields
@@ -82,6 +107,10 @@ type ReferencePattern struct {
 	TargetGroup string
 	RefType     RefType
 	Confidence  float64
+	// NameTemplate optionally derives the target name from the source resource,
+	// e.g. "{metadata.name}-config". Placeholders are source field paths, and
+	// "{value}" expands to the matched field's own string value.
+	NameTemplate string
 }
 
 // DiscoveryStatistics contains metrics about the discovery process
@@ -110,6 +139,10 @@ type DetectionStats struct {
 	PatternMatches   int
 	HeuristicMatches int
 	DetectionTime    time.Duration
+
+	// DepthLimitHits counts fields whose nested schema was not analyzed
+	// because the maximum recursion depth was reached
+	DepthLimitHits int
 }
 
 // RegistryMode defines the mode of operation for the registry
@@ -217,6 +250,29 @@ var DefaultReferencePatterns = []ReferencePattern{
 		RefType:     RefTypePVC,
 		Confidence:  0.95,
 	},
+	// Storage chain: Pod volumes name their claim, a bound claim names its
+	// PersistentVolume and both name their StorageClass
+	{
+		Pattern:     "claimName",
+		TargetKind:  "PersistentVolumeClaim",
+		TargetGroup: "",
+		RefType:     RefTypePVC,
+		Confidence:  0.95,
+	},
+	{
+		Pattern:     "volumeName",
+		TargetKind:  "PersistentVolume",
+		TargetGroup: "",
+		RefType:     RefTypeCustom,
+		Confidence:  0.95,
+	},
+	{
+		Pattern:     "storageClassName",
+		TargetKind:  "StorageClass",
+		TargetGroup: "storage.k8s.io",
+		RefType:     RefTypeCustom,
+		Confidence:  0.95,
+	},
 	{
 		Pattern:    "providerConfigRef*",
 		RefType:    RefTypeCustom,
@@ -245,6 +301,14 @@ const (
 	DefaultDiscoveryTimeout = 5 * time.Second
 	DefaultCacheTTL         = 10 * time.Minute
 	DefaultMaxConcurrency   = 5
+
+	// DefaultDiscoveryResultTTL is how long a CRD discovery's result is reused
+	// by later discoveries of the same patterns
+	DefaultDiscoveryResultTTL = 5 * time.Second
+
+	// DefaultMaxRecursionDepth bounds how deep reference detection descends
+	// into nested schemas, which may be recursive
+	DefaultMaxRecursionDepth = 20
 )
 
 // Default API group patterns for KubeCore
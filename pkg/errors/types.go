@@ -21,6 +21,7 @@ const (
 	// Input validation errors
 	ErrorCodeInvalidInput       ErrorCode = "INVALID_INPUT"
 	ErrorCodeInvalidResourceRef ErrorCode = "INVALID_RESOURCE_REF"
+	ErrorCodeKindNotAllowed     ErrorCode = "KIND_NOT_ALLOWED"
 
 	// System errors
 	ErrorCodeKubernetesClient ErrorCode = "KUBERNETES_CLIENT_ERROR"
@@ -176,6 +177,12 @@ func ResourceTimeoutError(ref ResourceRef, timeout time.Duration) *FunctionError
 		WithContext("timeout", timeout.String())
 }
 
+// KindNotAllowedError creates an error for a request whose kind is not on the allowlist
+func KindNotAllowedError(ref ResourceRef) *FunctionError {
+	return New(ErrorCodeKindNotAllowed,
+		fmt.Sprintf("kind %s (%s) is not in the allowed kinds", ref.Kind, ref.APIVersion)).WithResource(ref)
+}
+
 // KubernetesClientError creates a Kubernetes client error
 func KubernetesClientError(message string) *FunctionError {
 	return New(ErrorCodeKubernetesClient, message)
@@ -2,8 +2,8 @@ package graph
 
 import (
 	"fmt"
+	"sort"
 	"strings"
-	"time"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
@@ -22,6 +22,10 @@ type GraphBuilder interface {
 	// AddEdge adds a relationship edge between two nodes
 	AddEdge(graph *ResourceGraph, source, target NodeID, relationType RelationType, fieldPath, fieldName string, confidence float64) *ResourceEdge
 
+	// AddEdgeWithProvenance adds a relationship edge, or records the detection on
+	// the existing edge when one already links the nodes over the same field
+	AddEdgeWithProvenance(graph *ResourceGraph, source, target NodeID, relationType RelationType, fieldPath, fieldName string, provenance EdgeProvenance) *ResourceEdge
+
 	// BuildGraph builds a graph from a set of root resources and their references
 	BuildGraph(rootResources []*unstructured.Unstructured, references map[string][]dynamic.ReferenceField) (*ResourceGraph, error)
 
@@ -36,6 +40,23 @@ type GraphBuilder interface {
 type DefaultGraphBuilder struct {
 	// platformChecker determines if a resource belongs to platform scope
 	platformChecker PlatformChecker
+
+	// clock provides timestamps for graphs, nodes, and edges
+	clock Clock
+
+	// edgeIDIncludesRelationType keeps edges of different relation types over
+	// the same field path distinct
+	edgeIDIncludesRelationType bool
+
+	// minEdgeConfidence is the confidence below which edges are not added
+	minEdgeConfidence float64
+
+	// reversedRelationTypes are the relation types whose edges point from
+	// the referenced resource back to the resource holding the reference
+	reversedRelationTypes map[RelationType]bool
+
+	// categoryClassifier assigns external resources their category
+	categoryClassifier CategoryClassifier
 }
 
 // PlatformChecker determines if resources belong to platform scope
@@ -47,10 +68,53 @@ type PlatformChecker interface {
 // NewDefaultGraphBuilder creates a new default graph builder
 func NewDefaultGraphBuilder(platformChecker PlatformChecker) *DefaultGraphBuilder {
 	return &DefaultGraphBuilder{
-		platformChecker: platformChecker,
+		platformChecker:    platformChecker,
+		clock:              NewRealClock(),
+		categoryClassifier: DefaultCategoryClassifier,
+	}
+}
+
+// SetClock overrides the clock used for timestamps; nil restores the system clock
+func (gb *DefaultGraphBuilder) SetClock(clock Clock) {
+	if clock == nil {
+		clock = NewRealClock()
+	}
+	gb.clock = clock
+}
+
+// SetEdgeIDIncludesRelationType controls whether edge IDs include the relation
+// type, so overlapping detectors reporting the same field path do not collide
+func (gb *DefaultGraphBuilder) SetEdgeIDIncludesRelationType(enabled bool) {
+	gb.edgeIDIncludesRelationType = enabled
+}
+
+// SetMinEdgeConfidence sets the confidence floor for new edges. Edges below it
+// are not added; the source node records them as skipped references instead.
+// Zero, the default, accepts every edge.
+func (gb *DefaultGraphBuilder) SetMinEdgeConfidence(floor float64) {
+	gb.minEdgeConfidence = floor
+}
+
+// SetReversedRelationTypes sets the relation types whose edges are added
+// pointing from the target to the source. Reversing RelationTypeOwnerRef makes
+// owners point at what they own, so TopologicalSort orders owners first.
+// Calling it with no types restores the default direction for every type.
+func (gb *DefaultGraphBuilder) SetReversedRelationTypes(relationTypes ...RelationType) {
+	gb.reversedRelationTypes = make(map[RelationType]bool, len(relationTypes))
+	for _, relationType := range relationTypes {
+		gb.reversedRelationTypes[relationType] = true
 	}
 }
 
+// SetCategoryClassifier overrides how external resources are categorized;
+// nil restores DefaultCategoryClassifier
+func (gb *DefaultGraphBuilder) SetCategoryClassifier(classifier CategoryClassifier) {
+	if classifier == nil {
+		classifier = DefaultCategoryClassifier
+	}
+	gb.categoryClassifier = classifier
+}
+
 // NewGraph creates a new empty resource graph
 func (gb *DefaultGraphBuilder) NewGraph() *ResourceGraph {
 	return &ResourceGraph{
@@ -62,7 +126,7 @@ func (gb *DefaultGraphBuilder) NewGraph() *ResourceGraph {
 			RootNodes:           make([]NodeID, 0),
 			CyclesDetected:      make([]Cycle, 0),
 			TraversalStatistics: &TraversalStats{},
-			CreatedAt:           time.Now(),
+			CreatedAt:           gb.clock.Now(),
 		},
 	}
 }
@@ -81,12 +145,18 @@ func (gb *DefaultGraphBuilder) AddNode(graph *ResourceGraph, resource *unstructu
 		return existingNode
 	}
 
+	// Roots are added at depth 0; everything deeper was referenced by them
+	source := DiscoverySourceReference
+	if depth == 0 {
+		source = DiscoverySourceRoot
+	}
+
 	// Create new node
 	node := &ResourceNode{
 		ID:             nodeID,
 		Resource:       resource,
 		UID:            resource.GetUID(),
-		DiscoveredAt:   time.Now(),
+		DiscoveredAt:   gb.clock.Now(),
 		DiscoveryDepth: depth,
 		DiscoveryPath:  discoveryPath,
 		Platform:       gb.platformChecker.IsPlatformResource(resource),
@@ -96,9 +166,14 @@ func (gb *DefaultGraphBuilder) AddNode(graph *ResourceGraph, resource *unstructu
 			Namespace:         resource.GetNamespace(),
 			Name:              resource.GetName(),
 			SkippedReferences: make([]SkippedReference, 0),
+			DiscoverySource:   source,
 		},
 	}
 
+	if !node.Platform {
+		node.Metadata.Category = gb.categoryClassifier(resource)
+	}
+
 	// Add to graph
 	graph.Nodes[nodeID] = node
 	graph.AdjacencyList[nodeID] = make([]EdgeID, 0)
@@ -120,10 +195,28 @@ func (gb *DefaultGraphBuilder) AddNode(graph *ResourceGraph, resource *unstructu
 
 // AddEdge adds a relationship edge between two nodes
 func (gb *DefaultGraphBuilder) AddEdge(graph *ResourceGraph, source, target NodeID, relationType RelationType, fieldPath, fieldName string, confidence float64) *ResourceEdge {
-	edgeID := gb.generateEdgeID(source, target, fieldPath)
+	return gb.AddEdgeWithProvenance(graph, source, target, relationType, fieldPath, fieldName, EdgeProvenance{
+		DetectionMethod: defaultDetectionMethod,
+		Confidence:      confidence,
+	})
+}
+
+// AddEdgeWithProvenance adds a relationship edge between two nodes, merging the
+// detection into the provenance of an existing edge with the same ID
+func (gb *DefaultGraphBuilder) AddEdgeWithProvenance(graph *ResourceGraph, source, target NodeID, relationType RelationType, fieldPath, fieldName string, provenance EdgeProvenance) *ResourceEdge {
+	reversed := gb.reversedRelationTypes[relationType]
+	if reversed {
+		source, target = target, source
+	}
+
+	edgeID := gb.generateEdgeID(source, target, relationType, fieldPath)
+	belowFloor := provenance.Confidence < gb.minEdgeConfidence
 
 	// Check if edge already exists
 	if existingEdge, exists := graph.Edges[edgeID]; exists {
+		if !belowFloor {
+			mergeProvenance(existingEdge, provenance)
+		}
 		return existingEdge
 	}
 
@@ -134,6 +227,29 @@ func (gb *DefaultGraphBuilder) AddEdge(graph *ResourceGraph, source, target Node
 		return nil
 	}
 
+	// Low-confidence edges would drag down path confidence, so record them instead
+	if belowFloor {
+		// Skipped references are recorded on the resource holding the reference
+		referrerNode, referencedNode := sourceNode, targetNode
+		if reversed {
+			referrerNode, referencedNode = targetNode, sourceNode
+		}
+		referrerNode.Metadata.SkippedReferences = append(referrerNode.Metadata.SkippedReferences, SkippedReference{
+			FieldPath:   fieldPath,
+			FieldName:   fieldName,
+			Reason:      SkipReasonBelowConfidenceFloor,
+			TargetKind:  referencedNode.Metadata.Kind,
+			TargetGroup: referencedNode.Metadata.APIGroup,
+		})
+		return nil
+	}
+
+	// Dense graphs stop growing once the edge cap is hit
+	if graph.Metadata.MaxEdges > 0 && len(graph.Edges) >= graph.Metadata.MaxEdges {
+		graph.Metadata.EdgesTruncated = true
+		return nil
+	}
+
 	// Create new edge
 	edge := &ResourceEdge{
 		ID:              edgeID,
@@ -142,12 +258,14 @@ func (gb *DefaultGraphBuilder) AddEdge(graph *ResourceGraph, source, target Node
 		RelationType:    relationType,
 		FieldPath:       fieldPath,
 		FieldName:       fieldName,
-		Confidence:      confidence,
-		DetectionMethod: "reference_field_analysis",
-		DiscoveredAt:    time.Now(),
+		Confidence:      provenance.Confidence,
+		DetectionMethod: provenance.DetectionMethod,
+		DiscoveredAt:    gb.clock.Now(),
 		Metadata: &EdgeMetadata{
 			IsCrossNamespace: sourceNode.Metadata.Namespace != targetNode.Metadata.Namespace,
 			TargetExists:     true,
+			Provenance:       []EdgeProvenance{provenance},
+			Reversed:         reversed,
 		},
 	}
 
@@ -197,13 +315,15 @@ func (gb *DefaultGraphBuilder) BuildGraph(rootResources []*unstructured.Unstruct
 			targetNodeID := NodeID(targetKey)
 
 			if _, targetExists := graph.Nodes[targetNodeID]; targetExists {
-				gb.AddEdge(graph, sourceNodeID, targetNodeID, relationType, refField.FieldPath, refField.FieldName, refField.Confidence)
+				for _, provenance := range ReferenceProvenances(refField) {
+					gb.AddEdgeWithProvenance(graph, sourceNodeID, targetNodeID, relationType, refField.FieldPath, refField.FieldName, provenance)
+				}
 			} else {
 				// Record skipped reference
 				sourceNode.Metadata.SkippedReferences = append(sourceNode.Metadata.SkippedReferences, SkippedReference{
 					FieldPath:   refField.FieldPath,
 					FieldName:   refField.FieldName,
-					Reason:      "target_not_discovered",
+					Reason:      SkipReasonTargetNotDiscovered,
 					TargetKind:  refField.TargetKind,
 					TargetGroup: refField.TargetGroup,
 				})
@@ -227,12 +347,13 @@ func (gb *DefaultGraphBuilder) MergeGraphs(graphs []*ResourceGraph) (*ResourceGr
 	mergedGraph := gb.NewGraph()
 	nodeMapping := make(map[NodeID]NodeID) // Original to merged mapping
 
-	// Merge all nodes (deduplicating by UID)
+	// Merge all nodes (deduplicating by UID, and by resource key through
+	// AddNode for resources without one)
 	uidToNodeID := make(map[types.UID]NodeID)
 	for _, graph := range graphs {
 		for _, node := range graph.Nodes {
 			// Check for duplicate by UID
-			if existingNodeID, exists := uidToNodeID[node.UID]; exists {
+			if existingNodeID, exists := uidToNodeID[node.UID]; exists && node.UID != "" {
 				nodeMapping[node.ID] = existingNodeID
 				// Update discovery path if shorter
 				existingNode := mergedGraph.Nodes[existingNodeID]
@@ -249,10 +370,10 @@ func (gb *DefaultGraphBuilder) MergeGraphs(graphs []*ResourceGraph) (*ResourceGr
 		}
 	}
 
-	// Merge all edges (using node mapping)
-	edgeSet := make(map[string]bool) // Deduplication set
+	// Merge all edges (using node mapping); duplicates share an edge ID and
+	// only contribute their provenance
 	for _, graph := range graphs {
-		for _, edge := range graph.Edges {
+		for _, edge := range SortedEdges(graph) {
 			mappedSource, sourceExists := nodeMapping[edge.Source]
 			mappedTarget, targetExists := nodeMapping[edge.Target]
 
@@ -260,14 +381,9 @@ func (gb *DefaultGraphBuilder) MergeGraphs(graphs []*ResourceGraph) (*ResourceGr
 				continue
 			}
 
-			// Create deduplication key
-			edgeKey := fmt.Sprintf("%s->%s:%s", mappedSource, mappedTarget, edge.FieldPath)
-			if edgeSet[edgeKey] {
-				continue
+			for _, provenance := range edgeProvenance(edge) {
+				gb.AddEdgeWithProvenance(mergedGraph, mappedSource, mappedTarget, edge.RelationType, edge.FieldPath, edge.FieldName, provenance)
 			}
-
-			gb.AddEdge(mergedGraph, mappedSource, mappedTarget, edge.RelationType, edge.FieldPath, edge.FieldName, edge.Confidence)
-			edgeSet[edgeKey] = true
 		}
 	}
 
@@ -324,7 +440,7 @@ func (gb *DefaultGraphBuilder) ValidateGraph(graph *ResourceGraph) *GraphValidat
 		},
 	}
 
-	startTime := time.Now()
+	startTime := gb.clock.Now()
 
 	// Validate nodes
 	for nodeID, node := range graph.Nodes {
@@ -342,7 +458,7 @@ func (gb *DefaultGraphBuilder) ValidateGraph(graph *ResourceGraph) *GraphValidat
 	// Validate graph metadata consistency
 	gb.validateGraphMetadata(graph, result)
 
-	result.Statistics.ValidationTime = time.Since(startTime)
+	result.Statistics.ValidationTime = gb.clock.Now().Sub(startTime)
 	result.Statistics.ErrorCount = len(result.Errors)
 	result.Statistics.WarningCount = len(result.Warnings)
 
@@ -353,18 +469,51 @@ func (gb *DefaultGraphBuilder) ValidateGraph(graph *ResourceGraph) *GraphValidat
 	return result
 }
 
+// SortedEdges returns the edges of a graph ordered by source, target and field
+// path. Exports iterate edges through it so their output is reproducible.
+func SortedEdges(graph *ResourceGraph) []*ResourceEdge {
+	edges := make([]*ResourceEdge, 0, len(graph.Edges))
+	for _, edge := range graph.Edges {
+		edges = append(edges, edge)
+	}
+
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].Source != edges[j].Source {
+			return edges[i].Source < edges[j].Source
+		}
+		if edges[i].Target != edges[j].Target {
+			return edges[i].Target < edges[j].Target
+		}
+		if edges[i].FieldPath != edges[j].FieldPath {
+			return edges[i].FieldPath < edges[j].FieldPath
+		}
+		return edges[i].ID < edges[j].ID
+	})
+
+	return edges
+}
+
 // Helper methods
 
 func (gb *DefaultGraphBuilder) generateNodeID(resource *unstructured.Unstructured) NodeID {
 	// Generate a unique node ID based on resource identity
-	return NodeID(fmt.Sprintf("%s/%s/%s/%s",
+	id := fmt.Sprintf("%s/%s/%s/%s",
 		resource.GetAPIVersion(),
 		resource.GetKind(),
 		resource.GetNamespace(),
-		resource.GetName()))
+		resource.GetName())
+
+	// Resources in other clusters may share a name with local ones
+	if cluster := ResourceCluster(resource); cluster != "" {
+		id += "@" + cluster
+	}
+	return NodeID(id)
 }
 
-func (gb *DefaultGraphBuilder) generateEdgeID(source, target NodeID, fieldPath string) EdgeID {
+func (gb *DefaultGraphBuilder) generateEdgeID(source, target NodeID, relationType RelationType, fieldPath string) EdgeID {
+	if gb.edgeIDIncludesRelationType {
+		return EdgeID(fmt.Sprintf("%s->%s:%s:%s", source, target, relationType, fieldPath))
+	}
 	return EdgeID(fmt.Sprintf("%s->%s:%s", source, target, fieldPath))
 }
 
@@ -0,0 +1,696 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// fixedClock always returns the same time
+type fixedClock struct {
+	now time.Time
+}
+
+func (c fixedClock) Now() time.Time {
+	return c.now
+}
+
+// testPlatformChecker treats *.kubecore.io API groups as platform scope
+type testPlatformChecker struct{}
+
+func (c testPlatformChecker) IsPlatformResource(resource *unstructured.Unstructured) bool {
+	return c.GetAPIGroupScope(resource.GetAPIVersion()) == "platform"
+}
+
+func (testPlatformChecker) GetAPIGroupScope(apiVersion string) string {
+	if strings.Contains(apiVersion, ".kubecore.io/") {
+		return "platform"
+	}
+	return "external"
+}
+
+func newTestResource(kind, name, uid string) *unstructured.Unstructured {
+	resource := &unstructured.Unstructured{}
+	resource.SetAPIVersion("platform.kubecore.io/v1")
+	resource.SetKind(kind)
+	resource.SetName(name)
+	resource.SetNamespace("default")
+	resource.SetUID(types.UID(uid))
+	return resource
+}
+
+func TestFixedClockTimestamps(t *testing.T) {
+	fixed := time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC)
+	clock := fixedClock{now: fixed}
+
+	builder := NewDefaultGraphBuilder(testPlatformChecker{})
+	builder.SetClock(clock)
+
+	graph := builder.NewGraph()
+	assert.Equal(t, fixed, graph.Metadata.CreatedAt)
+
+	source := builder.AddNode(graph, newTestResource("KubEnv", "env", "uid-env"), 0, nil)
+	target := builder.AddNode(graph, newTestResource("KubeCluster", "cluster", "uid-cluster"), 1, nil)
+	require.NotNil(t, source)
+	require.NotNil(t, target)
+	assert.Equal(t, fixed, source.DiscoveredAt)
+	assert.Equal(t, fixed, target.DiscoveredAt)
+
+	edge := builder.AddEdge(graph, source.ID, target.ID, RelationTypeCustomRef, "spec.kubeClusterRef", "kubeClusterRef", 0.9)
+	require.NotNil(t, edge)
+	assert.Equal(t, fixed, edge.DiscoveredAt)
+
+	tracker := NewDefaultPathTracker(false)
+	tracker.SetClock(clock)
+	tracker.TrackPath(graph, source.ID, target.ID, []NodeID{source.ID, target.ID}, []EdgeID{edge.ID}, nil)
+
+	paths := tracker.GetDiscoveryPaths(graph, target.ID)
+	require.Len(t, paths, 1)
+	assert.Equal(t, fixed, paths[0].DiscoveredAt)
+}
+
+func TestTrackPathDedupNodeSequences(t *testing.T) {
+	graph, ids := newDiamondGraph(t)
+	edge := graph.AdjacencyList[ids["root"]][0]
+	path := []NodeID{ids["root"], graph.Edges[edge].Target}
+	target := path[1]
+
+	track := func(tracker *DefaultPathTracker) {
+		for _, confidence := range []float64{0.7, 0.95, 0.8} {
+			tracker.TrackPath(graph, path[0], target, path, []EdgeID{edge}, &PathMetadata{TotalConfidence: confidence})
+		}
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		tracker := NewDefaultPathTracker(false)
+		track(tracker)
+		assert.Len(t, tracker.GetDiscoveryPaths(graph, target), 3)
+	})
+
+	t.Run("Enabled", func(t *testing.T) {
+		tracker := NewDefaultPathTracker(false)
+		tracker.SetDedupNodeSequences(true)
+		track(tracker)
+
+		paths := tracker.GetDiscoveryPaths(graph, target)
+		require.Len(t, paths, 1)
+		assert.Equal(t, 0.95, paths[0].Metadata.TotalConfidence)
+	})
+}
+
+// newDiamondGraph builds root -> a, root -> b, a -> b so b is reachable twice
+func newDiamondGraph(t *testing.T) (*ResourceGraph, map[string]NodeID) {
+	t.Helper()
+
+	builder := NewDefaultGraphBuilder(testPlatformChecker{})
+	graph := builder.NewGraph()
+
+	root := builder.AddNode(graph, newTestResource("KubEnv", "root", "uid-root"), 0, nil)
+	a := builder.AddNode(graph, newTestResource("KubeCluster", "a", "uid-a"), 1, nil)
+	b := builder.AddNode(graph, newTestResource("KubeNet", "b", "uid-b"), 1, nil)
+	graph.Metadata.RootNodes = append(graph.Metadata.RootNodes, root.ID)
+
+	require.NotNil(t, builder.AddEdge(graph, root.ID, a.ID, RelationTypeCustomRef, "spec.aRef", "aRef", 0.9))
+	require.NotNil(t, builder.AddEdge(graph, root.ID, b.ID, RelationTypeCustomRef, "spec.bRef", "bRef", 0.8))
+	require.NotNil(t, builder.AddEdge(graph, a.ID, b.ID, RelationTypeCustomRef, "spec.bRef", "bRef", 0.9))
+
+	return graph, map[string]NodeID{"root": root.ID, "a": a.ID, "b": b.ID}
+}
+
+func TestDiscoveryTreePathLimits(t *testing.T) {
+	t.Run("NoLimits", func(t *testing.T) {
+		graph, _ := newDiamondGraph(t)
+		tree := NewDefaultPathTracker(false).GetDiscoveryTree(context.Background(), graph)
+
+		assert.Len(t, tree.AllPaths, 3)
+		assert.False(t, tree.TreeMetadata.PathsDeduplicated)
+		assert.False(t, tree.TreeMetadata.PathsCapped)
+	})
+
+	t.Run("DedupByTarget", func(t *testing.T) {
+		graph, ids := newDiamondGraph(t)
+		tracker := NewDefaultPathTracker(false)
+		tracker.SetTreeOptions(TreeOptions{DedupByTarget: true})
+		tree := tracker.GetDiscoveryTree(context.Background(), graph)
+
+		seen := make(map[NodeID]int)
+		for _, path := range tree.AllPaths {
+			seen[path.Target]++
+			if path.Target == ids["b"] {
+				assert.Equal(t, 1, path.Length, "shortest path to b should be kept")
+			}
+		}
+		for target, count := range seen {
+			assert.Equal(t, 1, count, "target %s should have at most one path", target)
+		}
+		assert.Len(t, tree.AllPaths, 2)
+		assert.True(t, tree.TreeMetadata.PathsDeduplicated)
+		assert.Equal(t, 1, tree.TreeMetadata.DroppedPaths)
+	})
+
+	t.Run("MaxPaths", func(t *testing.T) {
+		graph, _ := newDiamondGraph(t)
+		tracker := NewDefaultPathTracker(false)
+		tracker.SetTreeOptions(TreeOptions{MaxPaths: 1})
+		tree := tracker.GetDiscoveryTree(context.Background(), graph)
+
+		assert.Len(t, tree.AllPaths, 1)
+		assert.True(t, tree.TreeMetadata.PathsCapped)
+		assert.Equal(t, 2, tree.TreeMetadata.DroppedPaths)
+		assert.NotEmpty(t, tree.TreeMetadata.Warnings)
+	})
+
+	t.Run("MaxPathsKeepsShallowestByNodeID", func(t *testing.T) {
+		graph, ids := newDiamondGraph(t)
+
+		// Reverse the root's edges so build order disagrees with node ID order
+		slices.Reverse(graph.AdjacencyList[ids["root"]])
+
+		tracker := NewDefaultPathTracker(false)
+		tracker.SetTreeOptions(TreeOptions{MaxPaths: 2})
+		tree := tracker.GetDiscoveryTree(context.Background(), graph)
+
+		want := []NodeID{ids["a"], ids["b"]}
+		slices.Sort(want)
+		require.Len(t, tree.AllPaths, 2)
+		for i, path := range tree.AllPaths {
+			assert.Equal(t, 1, path.Depth, "the deeper path root -> a -> b should be dropped")
+			assert.Equal(t, want[i], path.Target)
+		}
+	})
+}
+
+func TestDiscoveryTreeCancellation(t *testing.T) {
+	t.Run("Cancelled", func(t *testing.T) {
+		graph, ids := newDiamondGraph(t)
+		tracker := NewDefaultPathTracker(true)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		tree := tracker.GetDiscoveryTree(ctx, graph)
+		assert.True(t, tree.TreeMetadata.Truncated)
+		require.Contains(t, tree.Children, ids["root"])
+		assert.Empty(t, tree.Children[ids["root"]].Children)
+		assert.Empty(t, tree.AllPaths)
+
+		// A truncated tree is not cached, so a later complete build succeeds
+		tree = tracker.GetDiscoveryTree(context.Background(), graph)
+		assert.False(t, tree.TreeMetadata.Truncated)
+		assert.Len(t, tree.AllPaths, 3)
+	})
+
+	t.Run("Complete", func(t *testing.T) {
+		graph, _ := newDiamondGraph(t)
+		tree := NewDefaultPathTracker(false).GetDiscoveryTree(context.Background(), graph)
+		assert.False(t, tree.TreeMetadata.Truncated)
+		assert.Len(t, tree.AllPaths, 3)
+	})
+}
+
+func TestDiscoveryTreePathConfidence(t *testing.T) {
+	graph, _ := newDiamondGraph(t)
+	tree := NewDefaultPathTracker(false).GetDiscoveryTree(context.Background(), graph)
+	require.Len(t, tree.AllPaths, 3)
+
+	// Paths are root->a (0.9), root->b (0.8) and root->a->b (0.9 * 0.9 = 0.81)
+	assert.InDelta(t, (0.9+0.8+0.81)/3, tree.TreeMetadata.AveragePathConfidence, 1e-9)
+	assert.InDelta(t, 0.8, tree.TreeMetadata.MinPathConfidence, 1e-9)
+}
+
+func TestSortedEdgesDeterministic(t *testing.T) {
+	// exportEdges renders edges the way an edge-list export would
+	exportEdges := func(graph *ResourceGraph) string {
+		var out strings.Builder
+		for _, edge := range SortedEdges(graph) {
+			out.WriteString(fmt.Sprintf("%s -> %s [%s %.2f]\n", edge.Source, edge.Target, edge.FieldPath, edge.Confidence))
+		}
+		for _, node := range []string{"uid-root", "uid-a"} {
+			for nodeID, edgeIDs := range graph.AdjacencyList {
+				if graph.Nodes[nodeID].UID == types.UID(node) {
+					out.WriteString(fmt.Sprintf("%s: %v\n", nodeID, edgeIDs))
+				}
+			}
+		}
+		return out.String()
+	}
+
+	first, _ := newDiamondGraph(t)
+	second, _ := newDiamondGraph(t)
+	builder := NewDefaultGraphBuilder(testPlatformChecker{})
+
+	expected := ""
+	for i := 0; i < 10; i++ {
+		merged, err := builder.MergeGraphs([]*ResourceGraph{first, second})
+		require.NoError(t, err)
+		require.Len(t, merged.Edges, 3)
+
+		export := exportEdges(merged)
+		if i == 0 {
+			expected = export
+			continue
+		}
+		assert.Equal(t, expected, export, "exports of the same graph should be byte-identical")
+	}
+
+	edges := SortedEdges(first)
+	require.Len(t, edges, 3)
+	for i := 1; i < len(edges); i++ {
+		previous, current := edges[i-1], edges[i]
+		assert.True(t, previous.Source < current.Source ||
+			(previous.Source == current.Source && previous.Target <= current.Target),
+			"edges should be ordered by source then target")
+	}
+}
+
+func TestResourceGraphClone(t *testing.T) {
+	builder := NewDefaultGraphBuilder(testPlatformChecker{})
+	original := builder.NewGraph()
+
+	env := builder.AddNode(original, newTestResource("KubEnv", "dev", "uid-env"), 0, nil)
+	cluster := builder.AddNode(original, newTestResource("KubeCluster", "cluster", "uid-cluster"), 1, []NodeID{env.ID})
+	edge := builder.AddEdge(original, env.ID, cluster.ID, RelationTypeCustomRef, "spec.kubeClusterRef", "kubeClusterRef", 0.9)
+	require.NotNil(t, edge)
+	original.Metadata.RootNodes = []NodeID{env.ID}
+	original.Metadata.CyclesDetected = []Cycle{{Nodes: []NodeID{env.ID, cluster.ID}}}
+
+	clone := original.Clone()
+	require.Equal(t, original, clone)
+
+	// Mutate every part of the clone
+	clone.Metadata.RootNodes[0] = cluster.ID
+	clone.Metadata.CyclesDetected[0].Nodes[0] = cluster.ID
+	clone.Metadata.TotalNodes = 99
+	clone.Nodes[cluster.ID].DiscoveryPath[0] = "changed"
+	clone.Nodes[cluster.ID].DiscoveryDepth = 7
+	clone.Nodes[env.ID].Metadata.SkippedReferences = append(clone.Nodes[env.ID].Metadata.SkippedReferences, SkippedReference{FieldName: "extra"})
+	clone.Edges[edge.ID].Confidence = 0.1
+	clone.Edges[edge.ID].Metadata.TargetExists = false
+	clone.AdjacencyList[env.ID][0] = "changed"
+	clone.ReverseAdjacencyList[cluster.ID] = nil
+	delete(clone.Nodes, env.ID)
+
+	assert.Equal(t, []NodeID{env.ID}, original.Metadata.RootNodes)
+	assert.Equal(t, env.ID, original.Metadata.CyclesDetected[0].Nodes[0])
+	assert.Equal(t, 2, original.Metadata.TotalNodes)
+	assert.Equal(t, []NodeID{env.ID}, original.Nodes[cluster.ID].DiscoveryPath)
+	assert.Equal(t, 1, original.Nodes[cluster.ID].DiscoveryDepth)
+	require.Contains(t, original.Nodes, env.ID)
+	assert.Empty(t, original.Nodes[env.ID].Metadata.SkippedReferences)
+	assert.Equal(t, 0.9, original.Edges[edge.ID].Confidence)
+	assert.True(t, original.Edges[edge.ID].Metadata.TargetExists)
+	assert.Equal(t, []EdgeID{edge.ID}, original.AdjacencyList[env.ID])
+	assert.Equal(t, []EdgeID{edge.ID}, original.ReverseAdjacencyList[cluster.ID])
+
+	// Resources are shared rather than copied
+	assert.Same(t, original.Nodes[cluster.ID].Resource, clone.Nodes[cluster.ID].Resource)
+}
+
+func TestEdgeIDIncludesRelationType(t *testing.T) {
+	cases := map[string]struct {
+		includeRelationType bool
+		wantEdges           int
+	}{
+		"Default": {
+			includeRelationType: false,
+			wantEdges:           1,
+		},
+		"IncludeRelationType": {
+			includeRelationType: true,
+			wantEdges:           2,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			builder := NewDefaultGraphBuilder(testPlatformChecker{})
+			builder.SetEdgeIDIncludesRelationType(tc.includeRelationType)
+
+			graph := builder.NewGraph()
+			source := builder.AddNode(graph, newTestResource("KubeApp", "app", "uid-app"), 0, nil)
+			target := builder.AddNode(graph, newTestResource("KubeCluster", "cluster", "uid-cluster"), 1, nil)
+
+			custom := builder.AddEdge(graph, source.ID, target.ID, RelationTypeCustomRef, "spec.ref", "ref", 0.9)
+			secret := builder.AddEdge(graph, source.ID, target.ID, RelationTypeSecretRef, "spec.ref", "ref", 0.8)
+			require.NotNil(t, custom)
+			require.NotNil(t, secret)
+
+			assert.Len(t, graph.Edges, tc.wantEdges)
+			assert.Equal(t, tc.wantEdges, graph.Metadata.TotalEdges)
+			assert.Len(t, graph.AdjacencyList[source.ID], tc.wantEdges)
+			assert.Equal(t, tc.includeRelationType, custom.ID != secret.ID)
+		})
+	}
+}
+
+func TestEdgeProvenance(t *testing.T) {
+	pattern := EdgeProvenance{DetectionMethod: "pattern_match", Confidence: 0.9, MatchedPattern: ".*Ref$"}
+	heuristic := EdgeProvenance{DetectionMethod: "naming_heuristic", Confidence: 0.6}
+
+	builder := NewDefaultGraphBuilder(testPlatformChecker{})
+	graph := builder.NewGraph()
+	source := builder.AddNode(graph, newTestResource("KubeApp", "app", "uid-app"), 0, nil)
+	target := builder.AddNode(graph, newTestResource("KubeCluster", "cluster", "uid-cluster"), 1, nil)
+
+	first := builder.AddEdgeWithProvenance(graph, source.ID, target.ID, RelationTypeCustomRef, "spec.clusterRef", "clusterRef", heuristic)
+	second := builder.AddEdgeWithProvenance(graph, source.ID, target.ID, RelationTypeCustomRef, "spec.clusterRef", "clusterRef", pattern)
+	require.NotNil(t, first)
+	assert.Same(t, first, second)
+	assert.Len(t, graph.Edges, 1)
+
+	edge := graph.Edges[first.ID]
+	assert.Equal(t, []EdgeProvenance{heuristic, pattern}, edge.Metadata.Provenance)
+	assert.Equal(t, "naming_heuristic", edge.DetectionMethod)
+	assert.Equal(t, 0.9, edge.Confidence)
+
+	// Merging graphs that found the same edge by different methods keeps both
+	other := builder.NewGraph()
+	otherSource := builder.AddNode(other, newTestResource("KubeApp", "app", "uid-app"), 0, nil)
+	otherTarget := builder.AddNode(other, newTestResource("KubeCluster", "cluster", "uid-cluster"), 1, nil)
+	owner := EdgeProvenance{DetectionMethod: "ownerReference", Confidence: 1.0}
+	builder.AddEdgeWithProvenance(other, otherSource.ID, otherTarget.ID, RelationTypeCustomRef, "spec.clusterRef", "clusterRef", owner)
+	builder.AddEdgeWithProvenance(other, otherSource.ID, otherTarget.ID, RelationTypeCustomRef, "spec.clusterRef", "clusterRef", pattern)
+
+	merged, err := builder.MergeGraphs([]*ResourceGraph{graph, other})
+	require.NoError(t, err)
+	require.Len(t, merged.Edges, 1)
+	for _, mergedEdge := range merged.Edges {
+		assert.ElementsMatch(t, []EdgeProvenance{heuristic, pattern, owner}, mergedEdge.Metadata.Provenance)
+		assert.Equal(t, 1.0, mergedEdge.Confidence)
+	}
+	assert.Equal(t, 1, merged.Metadata.TotalEdges)
+}
+
+func TestUnresolvedEdges(t *testing.T) {
+	builder := NewDefaultGraphBuilder(testPlatformChecker{})
+	graph := builder.NewGraph()
+
+	source := builder.AddNode(graph, newTestResource("KubeApp", "app", "uid-app"), 0, nil)
+	resolved := builder.AddNode(graph, newTestResource("KubeCluster", "cluster", "uid-cluster"), 1, nil)
+	placeholder := builder.AddNode(graph, newTestResource("KubeNet", "", "uid-net"), 1, nil)
+
+	// A node without a backing resource, as left behind by a failed resolution
+	missingID := NodeID("platform.kubecore.io/v1/KubeSecret/default/creds")
+	graph.Nodes[missingID] = &ResourceNode{ID: missingID, Metadata: &NodeMetadata{}}
+
+	resolvedEdge := builder.AddEdge(graph, source.ID, resolved.ID, RelationTypeCustomRef, "spec.clusterRef", "clusterRef", 0.9)
+	placeholderEdge := builder.AddEdge(graph, source.ID, placeholder.ID, RelationTypeCustomRef, "spec.netRef", "netRef", 0.9)
+	missingEdge := builder.AddEdge(graph, source.ID, missingID, RelationTypeCustomRef, "spec.secretRef", "secretRef", 0.9)
+	require.NotNil(t, resolvedEdge)
+	require.NotNil(t, placeholderEdge)
+	require.NotNil(t, missingEdge)
+
+	unresolved := graph.UnresolvedEdges()
+	assert.ElementsMatch(t, []EdgeID{placeholderEdge.ID, missingEdge.ID}, unresolved)
+	assert.NotContains(t, unresolved, resolvedEdge.ID)
+
+	complete := builder.NewGraph()
+	a := builder.AddNode(complete, newTestResource("KubeApp", "app", "uid-app"), 0, nil)
+	b := builder.AddNode(complete, newTestResource("KubeCluster", "cluster", "uid-cluster"), 1, nil)
+	builder.AddEdge(complete, a.ID, b.ID, RelationTypeCustomRef, "spec.clusterRef", "clusterRef", 0.9)
+	assert.Empty(t, complete.UnresolvedEdges())
+}
+
+func TestMinEdgeConfidence(t *testing.T) {
+	builder := NewDefaultGraphBuilder(testPlatformChecker{})
+	builder.SetMinEdgeConfidence(0.5)
+	graph := builder.NewGraph()
+
+	root := builder.AddNode(graph, newTestResource("KubEnv", "root", "uid-root"), 0, nil)
+	cluster := builder.AddNode(graph, newTestResource("KubeCluster", "cluster", "uid-cluster"), 1, nil)
+	network := builder.AddNode(graph, newTestResource("KubeNet", "network", "uid-network"), 1, nil)
+
+	kept := builder.AddEdge(graph, root.ID, cluster.ID, RelationTypeCustomRef, "spec.clusterRef", "clusterRef", 0.9)
+	dropped := builder.AddEdge(graph, root.ID, network.ID, RelationTypeCustomRef, "spec.description", "description", 0.4)
+
+	require.NotNil(t, kept)
+	assert.Nil(t, dropped)
+	assert.Len(t, graph.Edges, 1)
+	assert.Contains(t, graph.Edges, kept.ID)
+	assert.Equal(t, 1, graph.Metadata.TotalEdges)
+	assert.Empty(t, graph.ReverseAdjacencyList[network.ID])
+	assert.Equal(t, 0, network.Metadata.InboundReferenceCount)
+
+	require.Len(t, root.Metadata.SkippedReferences, 1)
+	assert.Equal(t, SkippedReference{
+		FieldPath:   "spec.description",
+		FieldName:   "description",
+		Reason:      SkipReasonBelowConfidenceFloor,
+		TargetKind:  "KubeNet",
+		TargetGroup: "platform.kubecore.io",
+	}, root.Metadata.SkippedReferences[0])
+}
+
+func TestGraphHealthScore(t *testing.T) {
+	// newHighConfidenceGraph links a chain of three resources with near-certain edges
+	newHighConfidenceGraph := func() (*ResourceGraph, []NodeID) {
+		builder := NewDefaultGraphBuilder(testPlatformChecker{})
+		graph := builder.NewGraph()
+
+		env := builder.AddNode(graph, newTestResource("KubEnv", "env", "uid-env"), 0, nil)
+		cluster := builder.AddNode(graph, newTestResource("KubeCluster", "cluster", "uid-cluster"), 1, nil)
+		net := builder.AddNode(graph, newTestResource("KubeNet", "net", "uid-net"), 2, nil)
+		require.NotNil(t, builder.AddEdge(graph, env.ID, cluster.ID, RelationTypeCustomRef, "spec.clusterRef", "clusterRef", 0.98))
+		require.NotNil(t, builder.AddEdge(graph, cluster.ID, net.ID, RelationTypeCustomRef, "spec.netRef", "netRef", 0.96))
+		return graph, []NodeID{env.ID, cluster.ID, net.ID}
+	}
+
+	t.Run("Clean", func(t *testing.T) {
+		graph, _ := newHighConfidenceGraph()
+		health := GraphHealthScore(graph, &CycleDetectionResult{})
+
+		assert.InDelta(t, 0.97, health.Confidence, 1e-9)
+		assert.Equal(t, 1.0, health.Completeness)
+		assert.Equal(t, 1.0, health.Acyclicity)
+		assert.InDelta(t, 1.0, health.Score, 0.02)
+	})
+
+	t.Run("ManySkippedReferences", func(t *testing.T) {
+		graph, ids := newHighConfidenceGraph()
+		clean := GraphHealthScore(graph, nil)
+
+		for i := 0; i < 6; i++ {
+			graph.Nodes[ids[0]].Metadata.SkippedReferences = append(graph.Nodes[ids[0]].Metadata.SkippedReferences,
+				SkippedReference{FieldPath: fmt.Sprintf("spec.refs[%d]", i), Reason: "below confidence threshold"})
+		}
+		health := GraphHealthScore(graph, nil)
+
+		assert.Equal(t, 6, health.SkippedReferences)
+		assert.InDelta(t, 0.25, health.Completeness, 1e-9)
+		assert.Less(t, health.Score, clean.Score)
+		assert.InDelta(t, HealthWeightConfidence*0.97+HealthWeightCompleteness*0.25+HealthWeightCycles, health.Score, 1e-9)
+	})
+
+	t.Run("Cycle", func(t *testing.T) {
+		graph, ids := newHighConfidenceGraph()
+		cycles := &CycleDetectionResult{CyclesFound: true, TotalCycles: 1, Cycles: []DetectedCycle{
+			{Cycle: Cycle{Nodes: []NodeID{ids[1], ids[2]}}},
+		}}
+		health := GraphHealthScore(graph, cycles)
+
+		assert.Equal(t, 2, health.CyclicNodes)
+		assert.InDelta(t, 1.0/3, health.Acyclicity, 1e-9)
+		assert.Less(t, health.Score, GraphHealthScore(graph, nil).Score)
+	})
+}
+
+func TestExportEdgesCollapseParallelEdges(t *testing.T) {
+	builder := NewDefaultGraphBuilder(testPlatformChecker{})
+	graph := builder.NewGraph()
+
+	app := builder.AddNode(graph, newTestResource("KubeApp", "app", "uid-app"), 0, nil)
+	env := builder.AddNode(graph, newTestResource("KubEnv", "env", "uid-env"), 1, nil)
+	cluster := builder.AddNode(graph, newTestResource("KubeCluster", "cluster", "uid-cluster"), 2, nil)
+	require.NotNil(t, builder.AddEdge(graph, app.ID, env.ID, RelationTypeCustomRef, "spec.envRef", "envRef", 0.9))
+	require.NotNil(t, builder.AddEdge(graph, app.ID, env.ID, RelationTypeCustomRef, "spec.deployment.envRef", "envRef", 0.7))
+	require.NotNil(t, builder.AddEdge(graph, app.ID, env.ID, RelationTypeSecretRef, "spec.credentialsEnv", "credentialsEnv", 0.5))
+	require.NotNil(t, builder.AddEdge(graph, env.ID, cluster.ID, RelationTypeCustomRef, "spec.clusterRef", "clusterRef", 0.95))
+
+	t.Run("Separate", func(t *testing.T) {
+		edges := ExportEdges(graph, EdgeExportOptions{})
+		require.Len(t, edges, 4)
+		for _, edge := range edges {
+			assert.Equal(t, 1, edge.Count)
+		}
+	})
+
+	cases := map[string]struct {
+		merge      EdgeConfidenceMerge
+		confidence float64
+	}{
+		"DefaultMax": {confidence: 0.9},
+		"Min":        {merge: EdgeConfidenceMin, confidence: 0.5},
+		"Average":    {merge: EdgeConfidenceAverage, confidence: 0.7},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			edges := ExportEdges(graph, EdgeExportOptions{CollapseParallelEdges: true, ConfidenceMerge: tc.merge})
+			require.Len(t, edges, 2)
+
+			collapsed := edges[0]
+			if collapsed.Source != app.ID {
+				collapsed = edges[1]
+			}
+			assert.Equal(t, env.ID, collapsed.Target)
+			assert.Equal(t, 3, collapsed.Count)
+			assert.Equal(t, "3 edges: customRef, secretRef", collapsed.Label())
+			assert.Len(t, collapsed.FieldPaths, 3)
+			assert.InDelta(t, tc.confidence, collapsed.Confidence, 1e-9)
+		})
+	}
+}
+
+func TestPlatformSubgraph(t *testing.T) {
+	builder := NewDefaultGraphBuilder(testPlatformChecker{})
+	graph := builder.NewGraph()
+
+	coreResource := func(kind, name, uid string) *unstructured.Unstructured {
+		resource := newTestResource(kind, name, uid)
+		resource.SetAPIVersion("v1")
+		return resource
+	}
+
+	// app -> creds (core) -> env, app -> cluster, and app -> cm (core) -> cluster
+	app := builder.AddNode(graph, newTestResource("KubeApp", "app", "uid-app"), 0, nil)
+	creds := builder.AddNode(graph, coreResource("Secret", "creds", "uid-creds"), 1, nil)
+	env := builder.AddNode(graph, newTestResource("KubEnv", "env", "uid-env"), 2, nil)
+	cluster := builder.AddNode(graph, newTestResource("KubeCluster", "cluster", "uid-cluster"), 1, nil)
+	cm := builder.AddNode(graph, coreResource("ConfigMap", "cm", "uid-cm"), 1, nil)
+	graph.Metadata.RootNodes = []NodeID{app.ID}
+	require.NotNil(t, builder.AddEdge(graph, app.ID, creds.ID, RelationTypeSecretRef, "spec.secretRef", "secretRef", 0.9))
+	require.NotNil(t, builder.AddEdge(graph, creds.ID, env.ID, RelationTypeCustomRef, "metadata.annotations[env]", "env", 0.5))
+	require.NotNil(t, builder.AddEdge(graph, app.ID, cluster.ID, RelationTypeCustomRef, "spec.clusterRef", "clusterRef", 0.9))
+	require.NotNil(t, builder.AddEdge(graph, app.ID, cm.ID, RelationTypeConfigMapRef, "spec.configMapRef", "configMapRef", 0.9))
+	require.NotNil(t, builder.AddEdge(graph, cm.ID, cluster.ID, RelationTypeCustomRef, "data.cluster", "cluster", 0.9))
+
+	subgraph := PlatformSubgraph(graph)
+
+	assert.ElementsMatch(t, []NodeID{app.ID, env.ID, cluster.ID}, slices.Collect(maps.Keys(subgraph.Nodes)))
+	assert.Equal(t, 3, subgraph.Metadata.TotalNodes)
+	assert.Equal(t, 0, subgraph.Metadata.ExternalNodes)
+	require.Len(t, subgraph.Edges, 2, "the direct app -> cluster edge makes the contraction through cm redundant")
+
+	var contracted *ResourceEdge
+	for _, edge := range subgraph.Edges {
+		if edge.RelationType == RelationTypeContracted {
+			contracted = edge
+		}
+	}
+	require.NotNil(t, contracted)
+	assert.Equal(t, app.ID, contracted.Source)
+	assert.Equal(t, env.ID, contracted.Target)
+	assert.Equal(t, []NodeID{creds.ID}, contracted.Metadata.ContractedThrough)
+	assert.InDelta(t, 0.45, contracted.Confidence, 1e-9)
+	assert.Equal(t, []EdgeID{contracted.ID}, subgraph.ReverseAdjacencyList[env.ID])
+
+	// The original graph is left untouched
+	assert.Len(t, graph.Nodes, 5)
+	assert.Len(t, graph.Edges, 5)
+}
+
+func TestNodeCategory(t *testing.T) {
+	newExternal := func(apiVersion, kind, name string) *unstructured.Unstructured {
+		resource := newTestResource(kind, name, "uid-"+name)
+		resource.SetAPIVersion(apiVersion)
+		return resource
+	}
+
+	cases := map[string]struct {
+		classifier CategoryClassifier
+		resource   *unstructured.Unstructured
+		want       string
+	}{
+		"ServiceIsNetworking": {
+			resource: newExternal("v1", "Service", "api"),
+			want:     CategoryNetworking,
+		},
+		"SecretIsCore": {
+			resource: newExternal("v1", "Secret", "creds"),
+			want:     CategoryCore,
+		},
+		"GroupCategory": {
+			resource: newExternal("rbac.authorization.k8s.io/v1", "ClusterRole", "admin"),
+			want:     CategoryRBAC,
+		},
+		"UnknownGroupKeepsGroup": {
+			resource: newExternal("cert-manager.io/v1", "Certificate", "tls"),
+			want:     "cert-manager.io",
+		},
+		"PlatformResourceUncategorized": {
+			resource: newTestResource("KubEnv", "env", "uid-env"),
+			want:     "",
+		},
+		"ConfiguredClassifier": {
+			classifier: func(resource *unstructured.Unstructured) string {
+				if resource.GetKind() == "Service" {
+					return "edge"
+				}
+				return DefaultCategoryClassifier(resource)
+			},
+			resource: newExternal("v1", "Service", "api"),
+			want:     "edge",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			builder := NewDefaultGraphBuilder(testPlatformChecker{})
+			if tc.classifier != nil {
+				builder.SetCategoryClassifier(tc.classifier)
+			}
+			graph := builder.NewGraph()
+
+			node := builder.AddNode(graph, tc.resource, 0, nil)
+			assert.Equal(t, tc.want, node.Metadata.Category)
+		})
+	}
+}
+
+func TestExplainDiscovery(t *testing.T) {
+	builder := NewDefaultGraphBuilder(testPlatformChecker{})
+	graph := builder.NewGraph()
+
+	creds := newTestResource("Secret", "creds", "uid-creds")
+	creds.SetAPIVersion("v1")
+	creds.SetNamespace("vault")
+
+	// app -> creds (core, another namespace) -> env
+	app := builder.AddNode(graph, newTestResource("KubeApp", "app", "uid-app"), 0, nil)
+	secret := builder.AddNode(graph, creds, 1, nil)
+	env := builder.AddNode(graph, newTestResource("KubEnv", "env", "uid-env"), 2, nil)
+	secretEdge := builder.AddEdge(graph, app.ID, secret.ID, RelationTypeSecretRef, "spec.secretRef", "secretRef", 0.9)
+	envEdge := builder.AddEdge(graph, secret.ID, env.ID, RelationTypeCustomRef, "metadata.annotations[env]", "env", 0.5)
+	require.NotNil(t, secretEdge)
+	require.NotNil(t, envEdge)
+
+	tracker := NewDefaultPathTracker(false)
+	tracker.TrackPath(graph, app.ID, env.ID, []NodeID{app.ID, secret.ID, env.ID}, []EdgeID{secretEdge.ID, envEdge.ID}, nil)
+
+	explanation := tracker.ExplainDiscoveryPath(graph, env.ID)
+	require.True(t, explanation.InGraph)
+	assert.Equal(t, app.ID, explanation.Root)
+	require.Len(t, explanation.Hops, 2)
+	assert.Equal(t, RelationTypeSecretRef, explanation.Hops[0].RelationType)
+	assert.Equal(t, RelationTypeCustomRef, explanation.Hops[1].RelationType)
+	assert.InDelta(t, 0.45, explanation.TotalConfidence, 1e-9)
+	assert.Equal(t, 2, explanation.CrossNamespaceHops)
+	assert.Equal(t, 2, explanation.PlatformBoundaryHops)
+
+	text := tracker.ExplainDiscovery(graph, env.ID)
+	assert.Contains(t, text, "via secretRef (spec.secretRef), confidence 0.90, crosses namespaces, crosses the platform boundary")
+	assert.Contains(t, text, "via customRef (metadata.annotations[env]), confidence 0.50")
+	assert.Contains(t, text, "total confidence 0.45")
+
+	assert.Contains(t, tracker.ExplainDiscovery(graph, app.ID), "is a root of the traversal")
+	assert.Contains(t, tracker.ExplainDiscovery(graph, "missing"), "is not in the graph")
+}
@@ -0,0 +1,66 @@
+package graph
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Categories assigned to external resources by DefaultCategoryClassifier
+const (
+	// CategoryCore covers core API resources such as Secrets and ConfigMaps
+	CategoryCore = "core"
+	// CategoryWorkloads covers Pods and the controllers that run them
+	CategoryWorkloads = "workloads"
+	// CategoryNetworking covers Services, Ingresses and network policies
+	CategoryNetworking = "networking"
+	// CategoryStorage covers volumes, claims and storage classes
+	CategoryStorage = "storage"
+	// CategoryRBAC covers service accounts, roles and their bindings
+	CategoryRBAC = "rbac"
+)
+
+// CategoryClassifier assigns an external resource a coarse category used to
+// group it in reports, e.g. "networking" rather than networking.k8s.io
+type CategoryClassifier func(resource *unstructured.Unstructured) string
+
+// categoriesByGroup maps well-known API groups to their category
+var categoriesByGroup = map[string]string{
+	"apps":                      CategoryWorkloads,
+	"batch":                     CategoryWorkloads,
+	"autoscaling":               CategoryWorkloads,
+	"policy":                    CategoryWorkloads,
+	"networking.k8s.io":         CategoryNetworking,
+	"discovery.k8s.io":          CategoryNetworking,
+	"gateway.networking.k8s.io": CategoryNetworking,
+	"storage.k8s.io":            CategoryStorage,
+	"snapshot.storage.k8s.io":   CategoryStorage,
+	"rbac.authorization.k8s.io": CategoryRBAC,
+}
+
+// coreCategoriesByKind maps core API kinds that belong to a more specific
+// category than CategoryCore
+var coreCategoriesByKind = map[string]string{
+	"Pod":                   CategoryWorkloads,
+	"ReplicationController": CategoryWorkloads,
+	"Service":               CategoryNetworking,
+	"Endpoints":             CategoryNetworking,
+	"PersistentVolume":      CategoryStorage,
+	"PersistentVolumeClaim": CategoryStorage,
+	"ServiceAccount":        CategoryRBAC,
+}
+
+// DefaultCategoryClassifier categorizes common Kubernetes resources by API
+// group, and core resources by kind. Resources of other groups are
+// categorized by their API group.
+func DefaultCategoryClassifier(resource *unstructured.Unstructured) string {
+	group := resource.GroupVersionKind().Group
+	if group == "" {
+		if category, ok := coreCategoriesByKind[resource.GetKind()]; ok {
+			return category
+		}
+		return CategoryCore
+	}
+	if category, ok := categoriesByGroup[group]; ok {
+		return category
+	}
+	return group
+}
@@ -0,0 +1,85 @@
+package graph
+
+// Clone returns an independent copy of the graph's nodes, edges, adjacency
+// lists and metadata, so it can be analyzed or modified without affecting
+// the original. The Kubernetes resources are shared since nodes only read them.
+func (g *ResourceGraph) Clone() *ResourceGraph {
+	if g == nil {
+		return nil
+	}
+
+	clone := &ResourceGraph{
+		Nodes:                make(map[NodeID]*ResourceNode, len(g.Nodes)),
+		Edges:                make(map[EdgeID]*ResourceEdge, len(g.Edges)),
+		AdjacencyList:        cloneAdjacencyList(g.AdjacencyList),
+		ReverseAdjacencyList: cloneAdjacencyList(g.ReverseAdjacencyList),
+		Metadata:             g.Metadata.clone(),
+	}
+
+	for nodeID, node := range g.Nodes {
+		nodeCopy := *node
+		nodeCopy.DiscoveryPath = cloneSlice(node.DiscoveryPath)
+		if node.Metadata != nil {
+			metadata := *node.Metadata
+			metadata.SkippedReferences = cloneSlice(node.Metadata.SkippedReferences)
+			nodeCopy.Metadata = &metadata
+		}
+		clone.Nodes[nodeID] = &nodeCopy
+	}
+
+	for edgeID, edge := range g.Edges {
+		edgeCopy := *edge
+		if edge.Metadata != nil {
+			metadata := *edge.Metadata
+			metadata.Provenance = cloneSlice(edge.Metadata.Provenance)
+			metadata.ContractedThrough = cloneSlice(edge.Metadata.ContractedThrough)
+			edgeCopy.Metadata = &metadata
+		}
+		clone.Edges[edgeID] = &edgeCopy
+	}
+
+	return clone
+}
+
+// clone returns a copy of the graph metadata that shares no slices with it
+func (m *GraphMetadata) clone() *GraphMetadata {
+	if m == nil {
+		return nil
+	}
+
+	clone := *m
+	clone.RootNodes = cloneSlice(m.RootNodes)
+	if m.CyclesDetected != nil {
+		clone.CyclesDetected = make([]Cycle, len(m.CyclesDetected))
+		for i, cycle := range m.CyclesDetected {
+			cycle.Nodes = cloneSlice(cycle.Nodes)
+			cycle.Edges = cloneSlice(cycle.Edges)
+			clone.CyclesDetected[i] = cycle
+		}
+	}
+	if m.TraversalStatistics != nil {
+		stats := *m.TraversalStatistics
+		clone.TraversalStatistics = &stats
+	}
+
+	return &clone
+}
+
+func cloneAdjacencyList(adjacency map[NodeID][]EdgeID) map[NodeID][]EdgeID {
+	if adjacency == nil {
+		return nil
+	}
+
+	clone := make(map[NodeID][]EdgeID, len(adjacency))
+	for nodeID, edges := range adjacency {
+		clone[nodeID] = cloneSlice(edges)
+	}
+	return clone
+}
+
+func cloneSlice[T any](s []T) []T {
+	if s == nil {
+		return nil
+	}
+	return append(make([]T, 0, len(s)), s...)
+}
@@ -0,0 +1,138 @@
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// EdgeConfidenceMerge chooses the confidence of a collapsed edge from the
+// confidences of the edges it replaces
+type EdgeConfidenceMerge string
+
+const (
+	// EdgeConfidenceMax keeps the highest confidence
+	EdgeConfidenceMax EdgeConfidenceMerge = "max"
+	// EdgeConfidenceMin keeps the lowest confidence
+	EdgeConfidenceMin EdgeConfidenceMerge = "min"
+	// EdgeConfidenceAverage averages the confidences
+	EdgeConfidenceAverage EdgeConfidenceMerge = "average"
+)
+
+// EdgeExportOptions controls how ExportEdges lists the edges of a graph
+type EdgeExportOptions struct {
+	// CollapseParallelEdges merges edges that share a source and target, e.g.
+	// several fields of one resource naming the same target, into one edge
+	CollapseParallelEdges bool
+
+	// ConfidenceMerge picks the confidence of a collapsed edge; empty means
+	// EdgeConfidenceMax
+	ConfidenceMerge EdgeConfidenceMerge
+}
+
+// ExportEdge is an edge as listed for export. Unless parallel edges are
+// collapsed, every ExportEdge stands for exactly one graph edge.
+type ExportEdge struct {
+	Source NodeID
+	Target NodeID
+
+	// Count is the number of graph edges this edge stands for
+	Count int
+
+	// RelationTypes are the distinct relation types of those edges, sorted
+	RelationTypes []RelationType
+
+	// FieldPaths are the field paths of those edges, in SortedEdges order
+	FieldPaths []string
+
+	// Confidence is the edge confidence, merged per EdgeExportOptions
+	Confidence float64
+}
+
+// Label describes the edge for rendering, e.g. "customRef" for a single edge
+// or "3 edges: customRef, secretRef" for a collapsed one
+func (e ExportEdge) Label() string {
+	types := make([]string, 0, len(e.RelationTypes))
+	for _, relationType := range e.RelationTypes {
+		types = append(types, string(relationType))
+	}
+
+	if e.Count <= 1 {
+		return strings.Join(types, ", ")
+	}
+	return fmt.Sprintf("%d edges: %s", e.Count, strings.Join(types, ", "))
+}
+
+// ExportEdges lists the edges of a graph in SortedEdges order, optionally
+// collapsing parallel edges into one
+func ExportEdges(graph *ResourceGraph, options EdgeExportOptions) []ExportEdge {
+	var exported []ExportEdge
+	var confidences [][]float64
+
+	for _, edge := range SortedEdges(graph) {
+		last := len(exported) - 1
+		if options.CollapseParallelEdges && last >= 0 &&
+			exported[last].Source == edge.Source && exported[last].Target == edge.Target {
+			exported[last].Count++
+			exported[last].FieldPaths = append(exported[last].FieldPaths, edge.FieldPath)
+			if !containsRelationType(exported[last].RelationTypes, edge.RelationType) {
+				exported[last].RelationTypes = append(exported[last].RelationTypes, edge.RelationType)
+			}
+			confidences[last] = append(confidences[last], edge.Confidence)
+			continue
+		}
+
+		exported = append(exported, ExportEdge{
+			Source:        edge.Source,
+			Target:        edge.Target,
+			Count:         1,
+			RelationTypes: []RelationType{edge.RelationType},
+			FieldPaths:    []string{edge.FieldPath},
+		})
+		confidences = append(confidences, []float64{edge.Confidence})
+	}
+
+	for i := range exported {
+		sort.Slice(exported[i].RelationTypes, func(a, b int) bool {
+			return exported[i].RelationTypes[a] < exported[i].RelationTypes[b]
+		})
+		exported[i].Confidence = mergeConfidences(confidences[i], options.ConfidenceMerge)
+	}
+
+	return exported
+}
+
+// containsRelationType reports whether types includes relationType
+func containsRelationType(types []RelationType, relationType RelationType) bool {
+	for _, existing := range types {
+		if existing == relationType {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeConfidences combines the confidences of parallel edges
+func mergeConfidences(confidences []float64, merge EdgeConfidenceMerge) float64 {
+	merged := confidences[0]
+	switch merge {
+	case EdgeConfidenceMin:
+		for _, confidence := range confidences[1:] {
+			if confidence < merged {
+				merged = confidence
+			}
+		}
+	case EdgeConfidenceAverage:
+		for _, confidence := range confidences[1:] {
+			merged += confidence
+		}
+		merged /= float64(len(confidences))
+	default:
+		for _, confidence := range confidences[1:] {
+			if confidence > merged {
+				merged = confidence
+			}
+		}
+	}
+	return merged
+}
@@ -0,0 +1,101 @@
+package graph
+
+// Weights of the components of a graph health score. They sum to 1, so the
+// score stays within [0, 1].
+const (
+	// HealthWeightConfidence weights the average edge confidence
+	HealthWeightConfidence = 0.4
+	// HealthWeightCompleteness weights the share of references that resolved
+	HealthWeightCompleteness = 0.4
+	// HealthWeightCycles weights the share of nodes outside any cycle
+	HealthWeightCycles = 0.2
+)
+
+// GraphHealth is a 0-1 health score for a discovered graph and the components
+// it is computed from. Each component is itself in [0, 1], higher is better.
+type GraphHealth struct {
+	// Score is the weighted sum of the components
+	Score float64
+
+	// Confidence is the average confidence of the graph's edges, 1 when the
+	// graph has no edges
+	Confidence float64
+
+	// Completeness is the share of references that resolved to a resource,
+	// counting edges to unresolved targets and skipped references against it.
+	// 1 when no references were found.
+	Completeness float64
+
+	// Acyclicity is the share of nodes that are not part of any cycle
+	Acyclicity float64
+
+	// ResolvedReferences is the number of edges whose target resolved
+	ResolvedReferences int
+
+	// UnresolvedReferences is the number of edges whose target did not resolve
+	UnresolvedReferences int
+
+	// SkippedReferences is the number of references that were not followed
+	SkippedReferences int
+
+	// CyclicNodes is the number of nodes that are part of at least one cycle
+	CyclicNodes int
+}
+
+// GraphHealthScore computes the health of a graph. Cycles are read from
+// cycles when given and from the graph metadata otherwise. The score is
+// HealthWeightConfidence*Confidence + HealthWeightCompleteness*Completeness +
+// HealthWeightCycles*Acyclicity.
+func GraphHealthScore(graph *ResourceGraph, cycles *CycleDetectionResult) *GraphHealth {
+	health := &GraphHealth{Confidence: 1, Completeness: 1, Acyclicity: 1}
+	if graph == nil {
+		health.Score = 1
+		return health
+	}
+
+	if len(graph.Edges) > 0 {
+		total := 0.0
+		for _, edge := range graph.Edges {
+			total += edge.Confidence
+		}
+		health.Confidence = total / float64(len(graph.Edges))
+	}
+
+	health.UnresolvedReferences = len(graph.UnresolvedEdges())
+	health.ResolvedReferences = len(graph.Edges) - health.UnresolvedReferences
+	for _, node := range graph.Nodes {
+		if node.Metadata != nil {
+			health.SkippedReferences += len(node.Metadata.SkippedReferences)
+		}
+	}
+	if references := len(graph.Edges) + health.SkippedReferences; references > 0 {
+		health.Completeness = float64(health.ResolvedReferences) / float64(references)
+	}
+
+	cyclic := make(map[NodeID]bool)
+	if cycles != nil {
+		for _, cycle := range cycles.Cycles {
+			for _, nodeID := range cycle.Nodes {
+				cyclic[nodeID] = true
+			}
+		}
+	} else if graph.Metadata != nil {
+		for _, cycle := range graph.Metadata.CyclesDetected {
+			for _, nodeID := range cycle.Nodes {
+				cyclic[nodeID] = true
+			}
+		}
+	}
+	health.CyclicNodes = len(cyclic)
+	if len(graph.Nodes) > 0 {
+		health.Acyclicity = 1 - float64(health.CyclicNodes)/float64(len(graph.Nodes))
+		if health.Acyclicity < 0 {
+			health.Acyclicity = 0
+		}
+	}
+
+	health.Score = HealthWeightConfidence*health.Confidence +
+		HealthWeightCompleteness*health.Completeness +
+		HealthWeightCycles*health.Acyclicity
+	return health
+}
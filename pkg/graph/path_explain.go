@@ -0,0 +1,159 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DiscoveryExplanation describes why a node is in a graph: the shortest
+// recorded path from a root to it, hop by hop
+type DiscoveryExplanation struct {
+	// NodeID is the node being explained
+	NodeID NodeID
+
+	// InGraph is false when the node is not in the graph
+	InGraph bool
+
+	// IsRoot is true when the node was a starting point of the traversal
+	IsRoot bool
+
+	// Root is the node the discovery path starts from
+	Root NodeID
+
+	// Hops contains the edges followed from Root to the node, in order
+	Hops []DiscoveryHop
+
+	// TotalConfidence is the product of the hop confidences
+	TotalConfidence float64
+
+	// CrossNamespaceHops is the number of hops between namespaces
+	CrossNamespaceHops int
+
+	// PlatformBoundaryHops is the number of hops between a platform and a
+	// non-platform resource
+	PlatformBoundaryHops int
+}
+
+// DiscoveryHop is one edge followed on a discovery path
+type DiscoveryHop struct {
+	// EdgeID is the edge followed
+	EdgeID EdgeID
+
+	// Source is the node the edge leaves
+	Source NodeID
+
+	// Target is the node the edge reaches
+	Target NodeID
+
+	// RelationType is the type of the edge
+	RelationType RelationType
+
+	// FieldPath is the field the reference was found in
+	FieldPath string
+
+	// Confidence is the edge's confidence
+	Confidence float64
+
+	// CrossNamespace is true when Source and Target are in different namespaces
+	CrossNamespace bool
+
+	// CrossPlatformBoundary is true when exactly one of Source and Target is a
+	// platform resource
+	CrossPlatformBoundary bool
+}
+
+// ExplainDiscoveryPath returns the structured explanation of why a node was
+// discovered, built from its shortest discovery path
+func (pt *DefaultPathTracker) ExplainDiscoveryPath(graph *ResourceGraph, nodeID NodeID) *DiscoveryExplanation {
+	explanation := &DiscoveryExplanation{
+		NodeID:          nodeID,
+		TotalConfidence: 1.0,
+	}
+	node, exists := graph.Nodes[nodeID]
+	if !exists {
+		return explanation
+	}
+	explanation.InGraph = true
+
+	path := pt.GetShortestDiscoveryPath(graph, nodeID)
+	if path == nil || len(path.Edges) == 0 {
+		explanation.IsRoot = node.DiscoveryDepth == 0
+		if explanation.IsRoot {
+			explanation.Root = nodeID
+		}
+		return explanation
+	}
+	explanation.Root = path.Source
+
+	for _, edgeID := range path.Edges {
+		edge, exists := graph.Edges[edgeID]
+		if !exists {
+			continue
+		}
+
+		hop := DiscoveryHop{
+			EdgeID:       edgeID,
+			Source:       edge.Source,
+			Target:       edge.Target,
+			RelationType: edge.RelationType,
+			FieldPath:    edge.FieldPath,
+			Confidence:   edge.Confidence,
+		}
+		sourceNode, sourceExists := graph.Nodes[edge.Source]
+		targetNode, targetExists := graph.Nodes[edge.Target]
+		if sourceExists && targetExists {
+			hop.CrossNamespace = nodeNamespace(sourceNode) != nodeNamespace(targetNode)
+			hop.CrossPlatformBoundary = sourceNode.Platform != targetNode.Platform
+		}
+
+		explanation.Hops = append(explanation.Hops, hop)
+		explanation.TotalConfidence *= hop.Confidence
+		if hop.CrossNamespace {
+			explanation.CrossNamespaceHops++
+		}
+		if hop.CrossPlatformBoundary {
+			explanation.PlatformBoundaryHops++
+		}
+	}
+
+	return explanation
+}
+
+// ExplainDiscovery returns a human-readable explanation of why a node was
+// discovered, listing each hop from a root with its relation type and confidence
+func (pt *DefaultPathTracker) ExplainDiscovery(graph *ResourceGraph, nodeID NodeID) string {
+	return pt.ExplainDiscoveryPath(graph, nodeID).String()
+}
+
+// String formats the explanation, one line per hop
+func (e *DiscoveryExplanation) String() string {
+	switch {
+	case !e.InGraph:
+		return fmt.Sprintf("%s is not in the graph", e.NodeID)
+	case e.IsRoot:
+		return fmt.Sprintf("%s is a root of the traversal", e.NodeID)
+	case len(e.Hops) == 0:
+		return fmt.Sprintf("%s has no recorded discovery path", e.NodeID)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s was discovered from root %s in %d hop(s) with total confidence %.2f\n",
+		e.NodeID, e.Root, len(e.Hops), e.TotalConfidence)
+	for i, hop := range e.Hops {
+		fmt.Fprintf(&b, "  %d. %s -> %s via %s", i+1, hop.Source, hop.Target, hop.RelationType)
+		if hop.FieldPath != "" {
+			fmt.Fprintf(&b, " (%s)", hop.FieldPath)
+		}
+		fmt.Fprintf(&b, ", confidence %.2f", hop.Confidence)
+		if hop.CrossNamespace {
+			b.WriteString(", crosses namespaces")
+		}
+		if hop.CrossPlatformBoundary {
+			b.WriteString(", crosses the platform boundary")
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "%d cross-namespace hop(s), %d platform boundary hop(s)", e.CrossNamespaceHops, e.PlatformBoundaryHops)
+
+	return b.String()
+}
@@ -1,7 +1,9 @@
 package graph
 
 import (
+	"context"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 )
@@ -17,14 +19,21 @@ type PathTracker interface {
 	// GetShortestDiscoveryPath returns the shortest discovery path to a node
 	GetShortestDiscoveryPath(graph *ResourceGraph, nodeID NodeID) *DiscoveryPath
 
-	// GetDiscoveryTree builds a tree representation of discovery paths
-	GetDiscoveryTree(graph *ResourceGraph) *DiscoveryTree
+	// GetDiscoveryTree builds a tree representation of discovery paths, returning a
+	// truncated tree if ctx is done before it completes
+	GetDiscoveryTree(ctx context.Context, graph *ResourceGraph) *DiscoveryTree
 
 	// ValidateDiscoveryPaths validates all discovery paths in the graph
 	ValidateDiscoveryPaths(graph *ResourceGraph) *PathValidationResult
 
 	// GetPathStatistics calculates statistics about discovery paths
 	GetPathStatistics(graph *ResourceGraph) *PathStatistics
+
+	// ExplainDiscovery describes why a node was discovered in human-readable form
+	ExplainDiscovery(graph *ResourceGraph, nodeID NodeID) string
+
+	// ExplainDiscoveryPath describes why a node was discovered, hop by hop
+	ExplainDiscoveryPath(graph *ResourceGraph, nodeID NodeID) *DiscoveryExplanation
 }
 
 // DiscoveryPath represents a path from root to a discovered resource
@@ -170,6 +179,38 @@ type DiscoveryTreeMetadata struct {
 
 	// BalanceFactor indicates how balanced the tree is
 	BalanceFactor float64
+
+	// AveragePathConfidence is the average TotalConfidence of all paths in AllPaths
+	AveragePathConfidence float64
+
+	// MinPathConfidence is the lowest TotalConfidence of any path in AllPaths
+	MinPathConfidence float64
+
+	// PathsDeduplicated indicates AllPaths was reduced to one path per target
+	PathsDeduplicated bool
+
+	// PathsCapped indicates AllPaths was truncated to TreeOptions.MaxPaths
+	PathsCapped bool
+
+	// DroppedPaths is the number of paths removed by deduplication or capping
+	DroppedPaths int
+
+	// Truncated indicates the tree is partial because its context was
+	// cancelled or its deadline passed while it was being built
+	Truncated bool
+
+	// Warnings contains non-fatal issues encountered while building the tree
+	Warnings []string
+}
+
+// TreeOptions controls how GetDiscoveryTree collects AllPaths
+type TreeOptions struct {
+	// DedupByTarget keeps only the best path for each target, preferring the
+	// shortest path and then the highest total confidence
+	DedupByTarget bool
+
+	// MaxPaths caps the number of paths kept in AllPaths (0 means unlimited)
+	MaxPaths int
 }
 
 // PathValidationResult contains the result of path validation
@@ -278,6 +319,15 @@ type DefaultPathTracker struct {
 
 	// enableCaching controls whether to cache computed results
 	enableCaching bool
+
+	// clock provides timestamps for discovery paths
+	clock Clock
+
+	// treeOptions controls how discovery trees collect paths
+	treeOptions TreeOptions
+
+	// dedupNodeSequences keeps one path per node sequence for each target
+	dedupNodeSequences bool
 }
 
 // NewDefaultPathTracker creates a new default path tracker
@@ -286,7 +336,30 @@ func NewDefaultPathTracker(enableCaching bool) *DefaultPathTracker {
 		pathIndex:     make(map[NodeID][]DiscoveryPath),
 		pathCache:     make(map[string]interface{}),
 		enableCaching: enableCaching,
+		clock:         NewRealClock(),
+	}
+}
+
+// SetClock overrides the clock used for timestamps; nil restores the system clock
+func (pt *DefaultPathTracker) SetClock(clock Clock) {
+	if clock == nil {
+		clock = NewRealClock()
 	}
+	pt.clock = clock
+}
+
+// SetTreeOptions configures path deduplication and capping for GetDiscoveryTree
+func (pt *DefaultPathTracker) SetTreeOptions(options TreeOptions) {
+	pt.treeOptions = options
+	pt.clearCache()
+}
+
+// SetDedupNodeSequences controls whether TrackPath keeps a single path per
+// node sequence. Re-tracking a path over the same nodes, e.g. with metadata
+// recomputed after an edge was re-detected, then replaces the recorded path
+// only when the new one has a higher total confidence.
+func (pt *DefaultPathTracker) SetDedupNodeSequences(enabled bool) {
+	pt.dedupNodeSequences = enabled
 }
 
 // TrackPath records a discovery path from source to target
@@ -315,7 +388,7 @@ func (pt *DefaultPathTracker) TrackPath(graph *ResourceGraph, source, target Nod
 		Edges:        make([]EdgeID, len(edges)),
 		Length:       len(edges),
 		Depth:        len(edges),
-		DiscoveredAt: time.Now(),
+		DiscoveredAt: pt.clock.Now(),
 		PathType:     pathType,
 		Metadata:     metadata,
 	}
@@ -327,7 +400,9 @@ func (pt *DefaultPathTracker) TrackPath(graph *ResourceGraph, source, target Nod
 	if pt.pathIndex[target] == nil {
 		pt.pathIndex[target] = make([]DiscoveryPath, 0)
 	}
-	pt.pathIndex[target] = append(pt.pathIndex[target], discoveryPath)
+	if !pt.dedupNodeSequences || !pt.replaceSameNodePath(target, discoveryPath) {
+		pt.pathIndex[target] = append(pt.pathIndex[target], discoveryPath)
+	}
 
 	// Update graph node with discovery path
 	if targetNode, exists := graph.Nodes[target]; exists {
@@ -343,6 +418,31 @@ func (pt *DefaultPathTracker) TrackPath(graph *ResourceGraph, source, target Nod
 	}
 }
 
+// replaceSameNodePath looks for a recorded path to target over the same node
+// sequence, replacing it when candidate has a higher total confidence. It
+// reports whether such a path was recorded.
+func (pt *DefaultPathTracker) replaceSameNodePath(target NodeID, candidate DiscoveryPath) bool {
+	paths := pt.pathIndex[target]
+	for i := range paths {
+		if !slices.Equal(paths[i].Nodes, candidate.Nodes) {
+			continue
+		}
+		if pathConfidence(candidate) > pathConfidence(paths[i]) {
+			paths[i] = candidate
+		}
+		return true
+	}
+	return false
+}
+
+// pathConfidence returns the total confidence of a path, or zero without metadata
+func pathConfidence(path DiscoveryPath) float64 {
+	if path.Metadata == nil {
+		return 0
+	}
+	return path.Metadata.TotalConfidence
+}
+
 // GetDiscoveryPaths returns all discovery paths for a node
 func (pt *DefaultPathTracker) GetDiscoveryPaths(graph *ResourceGraph, nodeID NodeID) []DiscoveryPath {
 	if paths, exists := pt.pathIndex[nodeID]; exists {
@@ -380,8 +480,9 @@ func (pt *DefaultPathTracker) GetShortestDiscoveryPath(graph *ResourceGraph, nod
 	return shortest
 }
 
-// GetDiscoveryTree builds a tree representation of discovery paths
-func (pt *DefaultPathTracker) GetDiscoveryTree(graph *ResourceGraph) *DiscoveryTree {
+// GetDiscoveryTree builds a tree representation of discovery paths. Building
+// stops once ctx is done, and the partial tree is returned marked Truncated.
+func (pt *DefaultPathTracker) GetDiscoveryTree(ctx context.Context, graph *ResourceGraph) *DiscoveryTree {
 	cacheKey := "discovery_tree"
 
 	// Check cache
@@ -391,7 +492,7 @@ func (pt *DefaultPathTracker) GetDiscoveryTree(graph *ResourceGraph) *DiscoveryT
 		}
 	}
 
-	startTime := time.Now()
+	startTime := pt.clock.Now()
 
 	tree := &DiscoveryTree{
 		Children:     make(map[NodeID]*DiscoveryTreeNode),
@@ -414,20 +515,23 @@ func (pt *DefaultPathTracker) GetDiscoveryTree(graph *ResourceGraph) *DiscoveryT
 			}
 
 			tree.Children[rootID] = treeNode
-			pt.buildTreeNode(graph, treeNode, tree)
+			pt.buildTreeNode(ctx, graph, treeNode, tree)
 		}
 	}
 
+	// Bound AllPaths growth according to tree options
+	pt.limitTreePaths(tree)
+
 	// Calculate tree metadata
-	tree.TreeMetadata.BuildTime = time.Since(startTime)
+	tree.TreeMetadata.BuildTime = pt.clock.Now().Sub(startTime)
 	tree.TotalNodes = len(graph.Nodes)
 	tree.MaxDepth = graph.Metadata.MaxDepth
 
 	// Calculate additional metrics
 	pt.calculateTreeMetrics(tree)
 
-	// Cache result
-	if pt.enableCaching {
+	// Cache result; a truncated tree is only valid for the context that built it
+	if pt.enableCaching && !tree.TreeMetadata.Truncated {
 		pt.pathCache[cacheKey] = tree
 	}
 
@@ -436,7 +540,7 @@ func (pt *DefaultPathTracker) GetDiscoveryTree(graph *ResourceGraph) *DiscoveryT
 
 // ValidateDiscoveryPaths validates all discovery paths in the graph
 func (pt *DefaultPathTracker) ValidateDiscoveryPaths(graph *ResourceGraph) *PathValidationResult {
-	startTime := time.Now()
+	startTime := pt.clock.Now()
 
 	result := &PathValidationResult{
 		Valid:              true,
@@ -458,7 +562,7 @@ func (pt *DefaultPathTracker) ValidateDiscoveryPaths(graph *ResourceGraph) *Path
 	}
 
 	result.ValidPaths = result.TotalPaths - result.InvalidPaths
-	result.ValidationTime = time.Since(startTime)
+	result.ValidationTime = pt.clock.Now().Sub(startTime)
 
 	if len(result.ValidationErrors) > 0 {
 		result.Valid = false
@@ -705,17 +809,25 @@ func (pt *DefaultPathTracker) reconstructPath(graph *ResourceGraph, nodePath []N
 		Edges:        edges,
 		Length:       len(edges),
 		Depth:        len(edges),
-		DiscoveredAt: time.Now(),
+		DiscoveredAt: pt.clock.Now(),
 		PathType:     pathType,
 		Metadata:     metadata,
 	}
 }
 
 // buildTreeNode recursively builds a discovery tree node
-func (pt *DefaultPathTracker) buildTreeNode(graph *ResourceGraph, node *DiscoveryTreeNode, tree *DiscoveryTree) {
+func (pt *DefaultPathTracker) buildTreeNode(ctx context.Context, graph *ResourceGraph, node *DiscoveryTreeNode, tree *DiscoveryTree) {
 	// Find child nodes
 	if adjacentEdges, exists := graph.AdjacencyList[node.NodeID]; exists {
 		for _, edgeID := range adjacentEdges {
+			if tree.TreeMetadata.Truncated {
+				return
+			}
+			if ctx.Err() != nil {
+				tree.TreeMetadata.Truncated = true
+				return
+			}
+
 			edge, edgeExists := graph.Edges[edgeID]
 			if !edgeExists {
 				continue
@@ -761,7 +873,7 @@ func (pt *DefaultPathTracker) buildTreeNode(graph *ResourceGraph, node *Discover
 				Edges:        childEdges,
 				Length:       len(childEdges),
 				Depth:        len(childEdges),
-				DiscoveredAt: time.Now(),
+				DiscoveredAt: pt.clock.Now(),
 				PathType:     pathType,
 				Metadata:     metadata,
 			}
@@ -769,11 +881,76 @@ func (pt *DefaultPathTracker) buildTreeNode(graph *ResourceGraph, node *Discover
 			tree.AllPaths = append(tree.AllPaths, discoveryPath)
 
 			// Recursively build child nodes
-			pt.buildTreeNode(graph, childTreeNode, tree)
+			pt.buildTreeNode(ctx, graph, childTreeNode, tree)
 		}
 	}
 }
 
+// limitTreePaths applies TreeOptions deduplication and capping to tree.AllPaths.
+// Paths are first ordered by depth, then target node ID, so the paths kept do
+// not depend on the order the tree was built in.
+func (pt *DefaultPathTracker) limitTreePaths(tree *DiscoveryTree) {
+	originalCount := len(tree.AllPaths)
+
+	if pt.treeOptions.DedupByTarget || pt.treeOptions.MaxPaths > 0 {
+		slices.SortStableFunc(tree.AllPaths, func(a, b DiscoveryPath) int {
+			if a.Depth != b.Depth {
+				return a.Depth - b.Depth
+			}
+			if a.Target != b.Target {
+				return strings.Compare(string(a.Target), string(b.Target))
+			}
+			return strings.Compare(a.ID, b.ID)
+		})
+	}
+
+	if pt.treeOptions.DedupByTarget {
+		bestByTarget := make(map[NodeID]int)
+		deduplicated := make([]DiscoveryPath, 0, len(tree.AllPaths))
+
+		for _, path := range tree.AllPaths {
+			index, exists := bestByTarget[path.Target]
+			if !exists {
+				bestByTarget[path.Target] = len(deduplicated)
+				deduplicated = append(deduplicated, path)
+				continue
+			}
+			if pt.isBetterPath(path, deduplicated[index]) {
+				deduplicated[index] = path
+			}
+		}
+
+		tree.AllPaths = deduplicated
+		tree.TreeMetadata.PathsDeduplicated = true
+	}
+
+	if pt.treeOptions.MaxPaths > 0 && len(tree.AllPaths) > pt.treeOptions.MaxPaths {
+		tree.TreeMetadata.Warnings = append(tree.TreeMetadata.Warnings,
+			fmt.Sprintf("discovery tree paths capped at %d (found %d)", pt.treeOptions.MaxPaths, len(tree.AllPaths)))
+		tree.AllPaths = tree.AllPaths[:pt.treeOptions.MaxPaths]
+		tree.TreeMetadata.PathsCapped = true
+	}
+
+	tree.TreeMetadata.DroppedPaths = originalCount - len(tree.AllPaths)
+}
+
+// isBetterPath reports whether candidate is preferred over current for the same target
+func (pt *DefaultPathTracker) isBetterPath(candidate, current DiscoveryPath) bool {
+	if candidate.Length != current.Length {
+		return candidate.Length < current.Length
+	}
+
+	candidateConfidence, currentConfidence := 0.0, 0.0
+	if candidate.Metadata != nil {
+		candidateConfidence = candidate.Metadata.TotalConfidence
+	}
+	if current.Metadata != nil {
+		currentConfidence = current.Metadata.TotalConfidence
+	}
+
+	return candidateConfidence > currentConfidence
+}
+
 // calculateTreeMetrics calculates additional metrics for the discovery tree
 func (pt *DefaultPathTracker) calculateTreeMetrics(tree *DiscoveryTree) {
 	branchCount := 0
@@ -813,6 +990,25 @@ func (pt *DefaultPathTracker) calculateTreeMetrics(tree *DiscoveryTree) {
 	if tree.MaxDepth > 0 {
 		tree.TreeMetadata.BalanceFactor = tree.TreeMetadata.AverageDepth / float64(tree.MaxDepth)
 	}
+
+	// Calculate confidence across all paths, using each path's edge confidence product
+	confidenceSum := 0.0
+	confidenceCount := 0
+	for _, path := range tree.AllPaths {
+		if path.Metadata == nil {
+			continue
+		}
+		confidence := path.Metadata.TotalConfidence
+		if confidenceCount == 0 || confidence < tree.TreeMetadata.MinPathConfidence {
+			tree.TreeMetadata.MinPathConfidence = confidence
+		}
+		confidenceSum += confidence
+		confidenceCount++
+	}
+
+	if confidenceCount > 0 {
+		tree.TreeMetadata.AveragePathConfidence = confidenceSum / float64(confidenceCount)
+	}
 }
 
 // validatePath validates a single discovery path
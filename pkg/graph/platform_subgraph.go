@@ -0,0 +1,174 @@
+package graph
+
+import (
+	"fmt"
+	"sort"
+)
+
+// PlatformSubgraph returns a copy of the graph holding only platform nodes.
+// Edges between platform nodes are kept. Chains that leave the platform, e.g.
+// a platform resource referencing a Secret that references another platform
+// resource, are contracted into one RelationTypeContracted edge from the first
+// platform node to the next, listing the skipped nodes in ContractedThrough and
+// carrying the product of the chain's confidences. A contracted edge is only
+// added when no platform edge already links the two nodes, and follows the
+// shortest chain between them.
+func PlatformSubgraph(graph *ResourceGraph) *ResourceGraph {
+	if graph == nil {
+		return nil
+	}
+	clone := graph.Clone()
+
+	subgraph := &ResourceGraph{
+		Nodes:                make(map[NodeID]*ResourceNode),
+		Edges:                make(map[EdgeID]*ResourceEdge),
+		AdjacencyList:        make(map[NodeID][]EdgeID),
+		ReverseAdjacencyList: make(map[NodeID][]EdgeID),
+		Metadata:             clone.Metadata,
+	}
+	if subgraph.Metadata == nil {
+		subgraph.Metadata = &GraphMetadata{}
+	}
+
+	for nodeID, node := range clone.Nodes {
+		if !node.Platform {
+			continue
+		}
+		subgraph.Nodes[nodeID] = node
+		subgraph.AdjacencyList[nodeID] = make([]EdgeID, 0)
+		subgraph.ReverseAdjacencyList[nodeID] = make([]EdgeID, 0)
+	}
+
+	linked := make(map[NodeID]map[NodeID]bool)
+	for _, edge := range SortedEdges(clone) {
+		if subgraph.Nodes[edge.Source] == nil || subgraph.Nodes[edge.Target] == nil {
+			continue
+		}
+		addSubgraphEdge(subgraph, edge)
+		if linked[edge.Source] == nil {
+			linked[edge.Source] = make(map[NodeID]bool)
+		}
+		linked[edge.Source][edge.Target] = true
+	}
+
+	// Contract from sources in a fixed order so edge adjacency is deterministic
+	sources := make([]NodeID, 0, len(subgraph.Nodes))
+	for nodeID := range subgraph.Nodes {
+		sources = append(sources, nodeID)
+	}
+	sort.Slice(sources, func(i, j int) bool { return sources[i] < sources[j] })
+	for _, source := range sources {
+		for _, edge := range contractedEdges(clone, source) {
+			if linked[source][edge.Target] {
+				continue
+			}
+			addSubgraphEdge(subgraph, edge)
+		}
+	}
+
+	roots := make([]NodeID, 0, len(subgraph.Metadata.RootNodes))
+	for _, nodeID := range subgraph.Metadata.RootNodes {
+		if subgraph.Nodes[nodeID] != nil {
+			roots = append(roots, nodeID)
+		}
+	}
+	subgraph.Metadata.RootNodes = roots
+	subgraph.Metadata.TotalNodes = len(subgraph.Nodes)
+	subgraph.Metadata.TotalEdges = len(subgraph.Edges)
+	subgraph.Metadata.PlatformNodes = len(subgraph.Nodes)
+	subgraph.Metadata.ExternalNodes = 0
+	subgraph.Metadata.CyclesDetected = make([]Cycle, 0)
+
+	return subgraph
+}
+
+// contractedEdges walks breadth-first from a platform node through non-platform
+// nodes and returns one contracted edge to each platform node first reached
+// through at least one of them
+func contractedEdges(graph *ResourceGraph, source NodeID) []*ResourceEdge {
+	type hop struct {
+		nodeID     NodeID
+		through    []NodeID
+		confidence float64
+	}
+
+	var queue []hop
+	visited := map[NodeID]bool{source: true}
+	for _, edge := range outboundEdges(graph, source) {
+		if target := graph.Nodes[edge.Target]; target != nil && !target.Platform && !visited[edge.Target] {
+			visited[edge.Target] = true
+			queue = append(queue, hop{nodeID: edge.Target, through: []NodeID{edge.Target}, confidence: edge.Confidence})
+		}
+	}
+
+	var contracted []*ResourceEdge
+	reached := make(map[NodeID]bool)
+	for head := 0; head < len(queue); head++ {
+		current := queue[head]
+		for _, edge := range outboundEdges(graph, current.nodeID) {
+			target := graph.Nodes[edge.Target]
+			if target == nil || edge.Target == source {
+				continue
+			}
+			confidence := current.confidence * edge.Confidence
+
+			if target.Platform {
+				if reached[edge.Target] {
+					continue
+				}
+				reached[edge.Target] = true
+				contracted = append(contracted, &ResourceEdge{
+					ID:              EdgeID(fmt.Sprintf("%s->%s:%s", source, edge.Target, RelationTypeContracted)),
+					Source:          source,
+					Target:          edge.Target,
+					RelationType:    RelationTypeContracted,
+					FieldName:       string(RelationTypeContracted),
+					Confidence:      confidence,
+					DetectionMethod: string(RelationTypeContracted),
+					DiscoveredAt:    edge.DiscoveredAt,
+					Metadata: &EdgeMetadata{
+						IsCrossNamespace:  nodeNamespace(graph.Nodes[source]) != nodeNamespace(target),
+						TargetExists:      true,
+						ContractedThrough: cloneSlice(current.through),
+					},
+				})
+				continue
+			}
+
+			if visited[edge.Target] {
+				continue
+			}
+			visited[edge.Target] = true
+			through := append(cloneSlice(current.through), edge.Target)
+			queue = append(queue, hop{nodeID: edge.Target, through: through, confidence: confidence})
+		}
+	}
+
+	return contracted
+}
+
+// outboundEdges returns the existing outbound edges of a node in adjacency order
+func outboundEdges(graph *ResourceGraph, nodeID NodeID) []*ResourceEdge {
+	edges := make([]*ResourceEdge, 0, len(graph.AdjacencyList[nodeID]))
+	for _, edgeID := range graph.AdjacencyList[nodeID] {
+		if edge, exists := graph.Edges[edgeID]; exists {
+			edges = append(edges, edge)
+		}
+	}
+	return edges
+}
+
+// nodeNamespace returns the namespace recorded for a node
+func nodeNamespace(node *ResourceNode) string {
+	if node.Metadata == nil {
+		return ""
+	}
+	return node.Metadata.Namespace
+}
+
+// addSubgraphEdge adds an edge and its adjacency entries to the subgraph
+func addSubgraphEdge(subgraph *ResourceGraph, edge *ResourceEdge) {
+	subgraph.Edges[edge.ID] = edge
+	subgraph.AdjacencyList[edge.Source] = append(subgraph.AdjacencyList[edge.Source], edge.ID)
+	subgraph.ReverseAdjacencyList[edge.Target] = append(subgraph.ReverseAdjacencyList[edge.Target], edge.ID)
+}
@@ -0,0 +1,72 @@
+package graph
+
+import "github.com/crossplane/function-kubecore-schema-registry/pkg/dynamic"
+
+// defaultDetectionMethod is recorded for edges added without a known detection method
+const defaultDetectionMethod = "reference_field_analysis"
+
+// ReferenceProvenance returns the provenance of an edge created from a detected reference field
+func ReferenceProvenance(ref dynamic.ReferenceField) EdgeProvenance {
+	method := ref.DetectionMethod
+	if method == "" {
+		method = defaultDetectionMethod
+	}
+	return EdgeProvenance{
+		DetectionMethod: method,
+		Confidence:      ref.Confidence,
+		MatchedPattern:  ref.MatchedPattern,
+	}
+}
+
+// ReferenceProvenances returns the provenance of every detection of a
+// reference field: its own, then those merged into it by deduplication
+func ReferenceProvenances(ref dynamic.ReferenceField) []EdgeProvenance {
+	provenances := []EdgeProvenance{ReferenceProvenance(ref)}
+	for _, detection := range ref.AlsoDetectedBy {
+		provenances = append(provenances, ReferenceProvenance(dynamic.ReferenceField{
+			DetectionMethod: detection.DetectionMethod,
+			Confidence:      detection.Confidence,
+			MatchedPattern:  detection.MatchedPattern,
+		}))
+	}
+	return provenances
+}
+
+// mergeProvenance records a detection on an existing edge. Repeated detections by
+// the same method and pattern keep the highest confidence, and the edge confidence
+// is the highest of all contributing detections.
+func mergeProvenance(edge *ResourceEdge, provenance EdgeProvenance) {
+	if edge.Metadata == nil {
+		edge.Metadata = &EdgeMetadata{}
+	}
+
+	merged := false
+	for i, existing := range edge.Metadata.Provenance {
+		if existing.DetectionMethod == provenance.DetectionMethod && existing.MatchedPattern == provenance.MatchedPattern {
+			if provenance.Confidence > existing.Confidence {
+				edge.Metadata.Provenance[i].Confidence = provenance.Confidence
+			}
+			merged = true
+			break
+		}
+	}
+	if !merged {
+		edge.Metadata.Provenance = append(edge.Metadata.Provenance, provenance)
+	}
+
+	if provenance.Confidence > edge.Confidence {
+		edge.Confidence = provenance.Confidence
+	}
+}
+
+// edgeProvenance returns the detections recorded on an edge, falling back to the
+// edge's own detection method for edges built without provenance
+func edgeProvenance(edge *ResourceEdge) []EdgeProvenance {
+	if edge.Metadata != nil && len(edge.Metadata.Provenance) > 0 {
+		return edge.Metadata.Provenance
+	}
+	return []EdgeProvenance{{
+		DetectionMethod: edge.DetectionMethod,
+		Confidence:      edge.Confidence,
+	}}
+}
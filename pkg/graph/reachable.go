@@ -0,0 +1,120 @@
+package graph
+
+import "sort"
+
+// ReachableSet returns every node reachable from the start nodes by following
+// outbound edges, mapped to its minimum discovery depth, with the start nodes
+// at depth 0. Nodes deeper than maxDepth are left out; a negative maxDepth
+// means no limit. Unlike BreadthFirstTraversal it runs a single BFS without
+// recording paths, visit order or statistics, so it is the cheaper choice when
+// only the flat set of dependencies is needed.
+func ReachableSet(graph *ResourceGraph, start []NodeID, maxDepth int) map[NodeID]int {
+	depths := make(map[NodeID]int)
+	if graph == nil {
+		return depths
+	}
+
+	queue := make([]NodeID, 0, len(start))
+	for _, nodeID := range start {
+		if _, exists := graph.Nodes[nodeID]; !exists {
+			continue
+		}
+		if _, seen := depths[nodeID]; !seen {
+			depths[nodeID] = 0
+			queue = append(queue, nodeID)
+		}
+	}
+
+	// Nodes are dequeued in depth order, so the first depth recorded is the minimum
+	for head := 0; head < len(queue); head++ {
+		nodeID := queue[head]
+		depth := depths[nodeID]
+		if maxDepth >= 0 && depth >= maxDepth {
+			continue
+		}
+
+		for _, edgeID := range graph.AdjacencyList[nodeID] {
+			edge, exists := graph.Edges[edgeID]
+			if !exists {
+				continue
+			}
+			if _, seen := depths[edge.Target]; seen {
+				continue
+			}
+			if _, exists := graph.Nodes[edge.Target]; !exists {
+				continue
+			}
+			depths[edge.Target] = depth + 1
+			queue = append(queue, edge.Target)
+		}
+	}
+
+	return depths
+}
+
+// OrphanedNodes returns, sorted, the nodes that cannot be reached from any of
+// the graph's root nodes by following outbound edges. Traversal only adds
+// nodes it reached from a root, so orphans point at a consistency problem,
+// e.g. a merge or augmentation that added a node without the edge leading to
+// it. A graph without root nodes reports every node.
+func OrphanedNodes(graph *ResourceGraph) []NodeID {
+	if graph == nil {
+		return nil
+	}
+
+	reachable := ReachableSet(graph, graph.Metadata.RootNodes, -1)
+	var orphaned []NodeID
+	for nodeID := range graph.Nodes {
+		if _, found := reachable[nodeID]; !found {
+			orphaned = append(orphaned, nodeID)
+		}
+	}
+	sort.Slice(orphaned, func(i, j int) bool { return orphaned[i] < orphaned[j] })
+
+	return orphaned
+}
+
+// PathCounts returns, for every node reachable from the start nodes, the
+// number of distinct simple paths that reach it by following outbound edges.
+// Parallel edges between the same two nodes count as one hop, and paths from
+// different start nodes are counted separately. Start nodes are only counted
+// when another start node reaches them. Enumerating paths is exponential in
+// the worst case, so this is meant for the bounded graphs traversal produces.
+func PathCounts(graph *ResourceGraph, start []NodeID) map[NodeID]int {
+	counts := make(map[NodeID]int)
+	if graph == nil {
+		return counts
+	}
+
+	onPath := make(map[NodeID]bool)
+	var walk func(nodeID NodeID)
+	walk = func(nodeID NodeID) {
+		onPath[nodeID] = true
+		defer delete(onPath, nodeID)
+
+		followed := make(map[NodeID]bool)
+		for _, edgeID := range graph.AdjacencyList[nodeID] {
+			edge, exists := graph.Edges[edgeID]
+			if !exists || onPath[edge.Target] || followed[edge.Target] {
+				continue
+			}
+			if _, exists := graph.Nodes[edge.Target]; !exists {
+				continue
+			}
+			followed[edge.Target] = true
+			counts[edge.Target]++
+			walk(edge.Target)
+		}
+	}
+
+	seen := make(map[NodeID]bool, len(start))
+	for _, nodeID := range start {
+		if _, exists := graph.Nodes[nodeID]; !exists || seen[nodeID] {
+			continue
+		}
+		seen[nodeID] = true
+		walk(nodeID)
+	}
+
+	return counts
+}
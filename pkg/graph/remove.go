@@ -0,0 +1,60 @@
+package graph
+
+import "slices"
+
+// RemoveEdge removes an edge and its adjacency entries from the graph,
+// updating the reference counts of its endpoints. It reports whether the edge
+// was present.
+func RemoveEdge(graph *ResourceGraph, edgeID EdgeID) bool {
+	edge, exists := graph.Edges[edgeID]
+	if !exists {
+		return false
+	}
+
+	delete(graph.Edges, edgeID)
+	graph.AdjacencyList[edge.Source] = slices.DeleteFunc(graph.AdjacencyList[edge.Source], func(id EdgeID) bool { return id == edgeID })
+	graph.ReverseAdjacencyList[edge.Target] = slices.DeleteFunc(graph.ReverseAdjacencyList[edge.Target], func(id EdgeID) bool { return id == edgeID })
+
+	if source := graph.Nodes[edge.Source]; source != nil && source.Metadata != nil {
+		source.Metadata.OutboundReferenceCount--
+	}
+	if target := graph.Nodes[edge.Target]; target != nil && target.Metadata != nil {
+		target.Metadata.InboundReferenceCount--
+	}
+	if graph.Metadata != nil {
+		graph.Metadata.TotalEdges--
+	}
+	return true
+}
+
+// RemoveNode removes a node, every edge into or out of it and its root entry
+// from the graph. It reports whether the node was present.
+func RemoveNode(graph *ResourceGraph, nodeID NodeID) bool {
+	node, exists := graph.Nodes[nodeID]
+	if !exists {
+		return false
+	}
+
+	// Copied, since removing an edge rewrites the adjacency lists
+	for _, edgeID := range slices.Clone(graph.AdjacencyList[nodeID]) {
+		RemoveEdge(graph, edgeID)
+	}
+	for _, edgeID := range slices.Clone(graph.ReverseAdjacencyList[nodeID]) {
+		RemoveEdge(graph, edgeID)
+	}
+
+	delete(graph.Nodes, nodeID)
+	delete(graph.AdjacencyList, nodeID)
+	delete(graph.ReverseAdjacencyList, nodeID)
+
+	if graph.Metadata != nil {
+		graph.Metadata.RootNodes = slices.DeleteFunc(graph.Metadata.RootNodes, func(id NodeID) bool { return id == nodeID })
+		graph.Metadata.TotalNodes--
+		if node.Platform {
+			graph.Metadata.PlatformNodes--
+		} else {
+			graph.Metadata.ExternalNodes--
+		}
+	}
+	return true
+}
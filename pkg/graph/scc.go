@@ -0,0 +1,127 @@
+package graph
+
+import (
+	"slices"
+	"sort"
+)
+
+// StronglyConnectedComponents returns the groups of nodes that reference each
+// other, directly or transitively, found with Tarjan's algorithm. Nodes that
+// form no cycle are left out, while a node referencing itself is its own
+// component. Every component is sorted, and components are ordered by their
+// first node, so the result is the same for the same graph however it was
+// built. Collapsing each component into one node leaves a graph that can be
+// sorted topologically.
+func StronglyConnectedComponents(graph *ResourceGraph) [][]NodeID {
+	return stronglyConnectedComponents(graph, false)
+}
+
+// StronglyConnectedComponentsWithSingletons is StronglyConnectedComponents
+// also returning every node outside a cycle as a component of its own, so the
+// components partition the graph
+func StronglyConnectedComponentsWithSingletons(graph *ResourceGraph) [][]NodeID {
+	return stronglyConnectedComponents(graph, true)
+}
+
+// stronglyConnectedComponents runs Tarjan's algorithm with an explicit stack,
+// so long reference chains cannot exhaust the goroutine stack
+func stronglyConnectedComponents(graph *ResourceGraph, includeSingletons bool) [][]NodeID {
+	if graph == nil {
+		return nil
+	}
+
+	nodeIDs := make([]NodeID, 0, len(graph.Nodes))
+	for nodeID := range graph.Nodes {
+		nodeIDs = append(nodeIDs, nodeID)
+	}
+	sort.Slice(nodeIDs, func(i, j int) bool { return nodeIDs[i] < nodeIDs[j] })
+
+	indices := make(map[NodeID]int, len(nodeIDs))
+	lowLinks := make(map[NodeID]int, len(nodeIDs))
+	onStack := make(map[NodeID]bool)
+	var stack []NodeID
+	var components [][]NodeID
+
+	visit := func(nodeID NodeID) {
+		indices[nodeID] = len(indices)
+		lowLinks[nodeID] = indices[nodeID]
+		stack = append(stack, nodeID)
+		onStack[nodeID] = true
+	}
+
+	// frame is a node being explored and the next of its edges to follow
+	type frame struct {
+		nodeID   NodeID
+		nextEdge int
+	}
+
+	for _, start := range nodeIDs {
+		if _, visited := indices[start]; visited {
+			continue
+		}
+		visit(start)
+		frames := []frame{{nodeID: start}}
+
+		for len(frames) > 0 {
+			current := &frames[len(frames)-1]
+			edgeIDs := graph.AdjacencyList[current.nodeID]
+
+			if current.nextEdge < len(edgeIDs) {
+				edge, exists := graph.Edges[edgeIDs[current.nextEdge]]
+				current.nextEdge++
+				if !exists || graph.Nodes[edge.Target] == nil {
+					continue
+				}
+
+				if _, visited := indices[edge.Target]; !visited {
+					visit(edge.Target)
+					frames = append(frames, frame{nodeID: edge.Target})
+				} else if onStack[edge.Target] {
+					lowLinks[current.nodeID] = min(lowLinks[current.nodeID], indices[edge.Target])
+				}
+				continue
+			}
+
+			// Every edge is followed, so the node's low link is final
+			nodeID := current.nodeID
+			frames = frames[:len(frames)-1]
+			if len(frames) > 0 {
+				parent := frames[len(frames)-1].nodeID
+				lowLinks[parent] = min(lowLinks[parent], lowLinks[nodeID])
+			}
+			if lowLinks[nodeID] != indices[nodeID] {
+				continue
+			}
+
+			// nodeID is the root of a component: pop it off the stack
+			var component []NodeID
+			for {
+				member := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[member] = false
+				component = append(component, member)
+				if member == nodeID {
+					break
+				}
+			}
+
+			if len(component) > 1 || includeSingletons || hasSelfReference(graph, nodeID) {
+				slices.Sort(component)
+				components = append(components, component)
+			}
+		}
+	}
+
+	sort.Slice(components, func(i, j int) bool { return components[i][0] < components[j][0] })
+	return components
+}
+
+// hasSelfReference reports whether a node has an edge to itself
+func hasSelfReference(graph *ResourceGraph, nodeID NodeID) bool {
+	for _, edgeID := range graph.AdjacencyList[nodeID] {
+		if edge, exists := graph.Edges[edgeID]; exists && edge.Target == nodeID {
+			return true
+		}
+	}
+	return false
+}
@@ -2,6 +2,12 @@ package graph
 
 import (
 	"container/heap"
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
 )
 
 // GraphTraverser provides functionality to traverse resource dependency graphs
@@ -21,13 +27,30 @@ type GraphTraverser interface {
 	// ShortestPath finds the shortest path between two nodes
 	ShortestPath(graph *ResourceGraph, source, target NodeID) *PathResult
 
-	// FindAllPaths finds all paths between two nodes up to maxDepth
-	FindAllPaths(graph *ResourceGraph, source, target NodeID, maxDepth int) *PathsResult
+	// AStarPath finds the shortest path between two nodes, guided by a
+	// heuristic estimate of the remaining distance
+	AStarPath(graph *ResourceGraph, source, target NodeID, heuristic HeuristicFunc) *PathResult
+
+	// FindAllPaths finds all paths between two nodes up to maxDepth, stopping
+	// early with the paths found so far once ctx is done
+	FindAllPaths(ctx context.Context, graph *ResourceGraph, source, target NodeID, maxDepth int) *PathsResult
 
 	// TopologicalSort performs topological sorting of the graph
 	TopologicalSort(graph *ResourceGraph) *TopologicalResult
+
+	// ApplicationOrder returns the nodes reachable from the selected roots in
+	// the order they should be applied, dependencies first
+	ApplicationOrder(graph *ResourceGraph, rootFilter, includePredicate NodePredicate) ([]*ResourceNode, error)
 }
 
+// NodePredicate selects nodes of a graph
+type NodePredicate func(node *ResourceNode) bool
+
+// HeuristicFunc estimates the remaining distance from current to target for
+// AStarPath. Paths are only guaranteed shortest when the estimate never
+// exceeds the true distance; returning 0 everywhere searches like Dijkstra.
+type HeuristicFunc func(current, target NodeID, graph *ResourceGraph) float64
+
 // TraversalResult contains the result of a graph traversal
 type TraversalResult struct {
 	// VisitedNodes contains all nodes visited during traversal in order
@@ -60,11 +83,14 @@ type PathResult struct {
 	// PathLength is the number of edges in the path
 	PathLength int
 
-	// TotalDistance is the sum of edge weights (if applicable)
+	// TotalDistance is the sum of the path's edge weights
 	TotalDistance float64
 
 	// Found indicates whether a path was found
 	Found bool
+
+	// NodesExpanded is the number of nodes whose edges the search followed
+	NodesExpanded int
 }
 
 // PathsResult contains the result of an all-paths search
@@ -80,6 +106,10 @@ type PathsResult struct {
 
 	// SearchDepthReached is the maximum depth reached during search
 	SearchDepthReached int
+
+	// TimedOut indicates the search was cut short because its context was
+	// done; Paths only holds the paths found before that
+	TimedOut bool
 }
 
 // TopologicalResult contains the result of topological sorting
@@ -143,6 +173,118 @@ type TraversalStatistics struct {
 type DefaultGraphTraverser struct {
 	// visitationStrategy defines how nodes are selected for visitation
 	visitationStrategy VisitationStrategy
+
+	// levelComparator orders nodes within a topological level
+	levelComparator LevelComparator
+
+	// sortNodesByDepth orders each NodesByDepth band by kind and name
+	sortNodesByDepth bool
+
+	// weightFunc weighs edges for path searches
+	weightFunc WeightFunc
+
+	// clock provides timestamps for detected cycles
+	clock Clock
+}
+
+// LevelComparator orders nodes that share a topological level. It reports
+// whether node a should come before node b.
+type LevelComparator func(graph *ResourceGraph, a, b NodeID) bool
+
+// CompareByNodeID orders nodes by their ID
+func CompareByNodeID(_ *ResourceGraph, a, b NodeID) bool {
+	return a < b
+}
+
+// CompareByInboundConfidence orders nodes by the highest confidence of their
+// inbound edges, most confident first, falling back to node ID
+func CompareByInboundConfidence(graph *ResourceGraph, a, b NodeID) bool {
+	confidenceA := maxInboundConfidence(graph, a)
+	confidenceB := maxInboundConfidence(graph, b)
+	if confidenceA != confidenceB {
+		return confidenceA > confidenceB
+	}
+	return a < b
+}
+
+// EdgeComparator reports whether edge a takes precedence over edge b, e.g.
+// by relation type and confidence
+type EdgeComparator func(a, b *ResourceEdge) bool
+
+// CompareByRelationPriority returns an EdgeComparator ranking edges by the
+// position of their relation type in priority, earliest first, with types not
+// listed ranked last. Edges of equally ranked types are ordered by confidence,
+// most confident first.
+func CompareByRelationPriority(priority ...RelationType) EdgeComparator {
+	rank := func(relationType RelationType) int {
+		for i, prioritized := range priority {
+			if relationType == prioritized {
+				return i
+			}
+		}
+		return len(priority)
+	}
+
+	return func(a, b *ResourceEdge) bool {
+		rankA, rankB := rank(a.RelationType), rank(b.RelationType)
+		if rankA != rankB {
+			return rankA < rankB
+		}
+		return a.Confidence > b.Confidence
+	}
+}
+
+// CompareByInboundEdges returns a LevelComparator ordering nodes by their
+// highest-precedence inbound edge under compare, e.g.
+// CompareByRelationPriority(RelationTypeOwnerRef) to put owned resources
+// ahead of weakly referenced ones. Nodes without inbound edges come last, and
+// ties fall back to node ID. A nil compare orders by confidence alone.
+func CompareByInboundEdges(compare EdgeComparator) LevelComparator {
+	if compare == nil {
+		compare = CompareByRelationPriority()
+	}
+
+	return func(graph *ResourceGraph, a, b NodeID) bool {
+		edgeA := strongestInboundEdge(graph, a, compare)
+		edgeB := strongestInboundEdge(graph, b, compare)
+		switch {
+		case edgeA != nil && edgeB != nil:
+			if compare(edgeA, edgeB) {
+				return true
+			}
+			if compare(edgeB, edgeA) {
+				return false
+			}
+		case edgeA != nil:
+			return true
+		case edgeB != nil:
+			return false
+		}
+		return a < b
+	}
+}
+
+// strongestInboundEdge returns the edge targeting the node that takes
+// precedence over all others under compare, or nil without inbound edges
+func strongestInboundEdge(graph *ResourceGraph, nodeID NodeID, compare EdgeComparator) *ResourceEdge {
+	var strongest *ResourceEdge
+	for _, edgeID := range graph.ReverseAdjacencyList[nodeID] {
+		if edge, exists := graph.Edges[edgeID]; exists && (strongest == nil || compare(edge, strongest)) {
+			strongest = edge
+		}
+	}
+	return strongest
+}
+
+// maxInboundConfidence returns the highest confidence among edges targeting the node
+func maxInboundConfidence(graph *ResourceGraph, nodeID NodeID) float64 {
+	maxConfidence := 0.0
+	for _, edgeID := range graph.ReverseAdjacencyList[nodeID] {
+		if edge, exists := graph.Edges[edgeID]; exists && edge.Confidence > maxConfidence {
+			maxConfidence = edge.Confidence
+		}
+	}
+	return maxConfidence
 }
 
 // VisitationStrategy defines how nodes are prioritized during traversal
@@ -161,9 +303,38 @@ type VisitationStrategy interface {
 func NewDefaultGraphTraverser(strategy VisitationStrategy) *DefaultGraphTraverser {
 	return &DefaultGraphTraverser{
 		visitationStrategy: strategy,
+		clock:              NewRealClock(),
 	}
 }
 
+// SetClock overrides the clock used for timestamps; nil restores the system clock
+func (gt *DefaultGraphTraverser) SetClock(clock Clock) {
+	if clock == nil {
+		clock = NewRealClock()
+	}
+	gt.clock = clock
+}
+
+// SetLevelComparator sets how nodes within a topological level are ordered.
+// Passing nil restores ordering by node ID.
+func (gt *DefaultGraphTraverser) SetLevelComparator(comparator LevelComparator) {
+	gt.levelComparator = comparator
+}
+
+// SetWeightFunc sets how edges are weighed by ShortestPath, AStarPath and
+// FindAllPaths, e.g. ConfidenceWeight to prefer confident paths over short
+// ones. Edges weighed negative, infinite or NaN are not followed. Passing nil
+// restores UniformWeight.
+func (gt *DefaultGraphTraverser) SetWeightFunc(weight WeightFunc) {
+	gt.weightFunc = weight
+}
+
+// SetSortNodesByDepth controls whether the nodes in each TraversalResult
+// NodesByDepth band are sorted by kind, then name, instead of visitation order
+func (gt *DefaultGraphTraverser) SetSortNodesByDepth(enabled bool) {
+	gt.sortNodesByDepth = enabled
+}
+
 // BreadthFirstTraversal performs breadth-first traversal starting from root nodes
 func (gt *DefaultGraphTraverser) BreadthFirstTraversal(graph *ResourceGraph, maxDepth int) *TraversalResult {
 	result := &TraversalResult{
@@ -280,6 +451,7 @@ func (gt *DefaultGraphTraverser) BreadthFirstTraversal(graph *ResourceGraph, max
 	}
 
 	result.TraversalMetadata.Statistics.MaxQueueSize = maxQueueSize
+	gt.sortDepthBands(graph, result)
 	return result
 }
 
@@ -310,6 +482,7 @@ func (gt *DefaultGraphTraverser) DepthFirstTraversal(graph *ResourceGraph, maxDe
 		}
 	}
 
+	gt.sortDepthBands(graph, result)
 	return result
 }
 
@@ -419,6 +592,7 @@ func (gt *DefaultGraphTraverser) ReverseTraversal(graph *ResourceGraph, targetNo
 		}
 	}
 
+	gt.sortDepthBands(graph, result)
 	return result
 }
 
@@ -436,26 +610,40 @@ func (gt *DefaultGraphTraverser) ShortestPath(graph *ResourceGraph, source, targ
 		return result
 	}
 
-	// Use Dijkstra's algorithm with uniform edge weights
+	// Use Dijkstra's algorithm with the traverser's edge weights
 	distances := make(map[NodeID]float64)
-	previous := make(map[NodeID]NodeID)
+	// previousEdge records the edge each node was reached by, which picks the
+	// lightest of several parallel edges
+	previousEdge := make(map[NodeID]EdgeID)
 	unvisited := &NodePriorityQueue{}
 	heap.Init(unvisited)
+	// queueItems indexes the heap by node, so a relaxed node's item is found
+	// without scanning the heap
+	queueItems := make(map[NodeID]*PriorityQueueItem, len(graph.Nodes))
 
 	// Initialize distances
+	unreachable := float64(^uint(0) >> 1)
 	for nodeID := range graph.Nodes {
-		distances[nodeID] = float64(^uint(0) >> 1) // Max float64
-		heap.Push(unvisited, &PriorityQueueItem{
+		distances[nodeID] = unreachable
+		if nodeID == source {
+			distances[nodeID] = 0
+		}
+		item := &PriorityQueueItem{
 			NodeID:   nodeID,
 			Distance: distances[nodeID],
-		})
+		}
+		queueItems[nodeID] = item
+		heap.Push(unvisited, item)
 	}
-	distances[source] = 0
-	heap.Fix(unvisited, 0) // Update source distance in heap
 
 	for unvisited.Len() > 0 {
 		current := heap.Pop(unvisited).(*PriorityQueueItem)
 
+		// Every node left is unreachable from the source
+		if current.Distance >= unreachable {
+			break
+		}
+
 		if current.NodeID == target {
 			// Found shortest path to target
 			result.Found = true
@@ -468,19 +656,9 @@ func (gt *DefaultGraphTraverser) ShortestPath(graph *ResourceGraph, source, targ
 			currentNode := target
 			for currentNode != source {
 				path = append([]NodeID{currentNode}, path...)
-				prevNode := previous[currentNode]
-
-				// Find edge between previous and current node
-				if adjacentEdges, exists := graph.AdjacencyList[prevNode]; exists {
-					for _, edgeID := range adjacentEdges {
-						if edge, edgeExists := graph.Edges[edgeID]; edgeExists && edge.Target == currentNode {
-							edges = append([]EdgeID{edgeID}, edges...)
-							break
-						}
-					}
-				}
-
-				currentNode = prevNode
+				edgeID := previousEdge[currentNode]
+				edges = append([]EdgeID{edgeID}, edges...)
+				currentNode = graph.Edges[edgeID].Source
 			}
 			path = append([]NodeID{source}, path...)
 
@@ -491,6 +669,7 @@ func (gt *DefaultGraphTraverser) ShortestPath(graph *ResourceGraph, source, targ
 		}
 
 		// Update distances to neighbors
+		result.NodesExpanded++
 		if adjacentEdges, exists := graph.AdjacencyList[current.NodeID]; exists {
 			for _, edgeID := range adjacentEdges {
 				edge, edgeExists := graph.Edges[edgeID]
@@ -498,20 +677,23 @@ func (gt *DefaultGraphTraverser) ShortestPath(graph *ResourceGraph, source, targ
 					continue
 				}
 
-				// Use uniform weight of 1 for all edges
-				alt := distances[current.NodeID] + 1
+				// Skip edges to missing nodes and nodes already popped
+				item, queued := queueItems[edge.Target]
+				if !queued || item.Index < 0 {
+					continue
+				}
+
+				weight, valid := gt.edgeWeight(edge)
+				if !valid {
+					continue
+				}
+
+				alt := distances[current.NodeID] + weight
 				if alt < distances[edge.Target] {
 					distances[edge.Target] = alt
-					previous[edge.Target] = current.NodeID
-
-					// Update heap (simplified - would need proper heap update in production)
-					for i, item := range *unvisited {
-						if item.NodeID == edge.Target {
-							(*unvisited)[i].Distance = alt
-							heap.Fix(unvisited, i)
-							break
-						}
-					}
+					previousEdge[edge.Target] = edgeID
+					item.Distance = alt
+					heap.Fix(unvisited, item.Index)
 				}
 			}
 		}
@@ -520,8 +702,103 @@ func (gt *DefaultGraphTraverser) ShortestPath(graph *ResourceGraph, source, targ
 	return result
 }
 
-// FindAllPaths finds all paths between two nodes up to maxDepth
-func (gt *DefaultGraphTraverser) FindAllPaths(graph *ResourceGraph, source, target NodeID, maxDepth int) *PathsResult {
+// AStarPath finds the shortest path between two nodes, expanding nodes in
+// order of their distance from the source plus the heuristic's estimate of
+// their distance to the target. Edges are weighed as by ShortestPath. With
+// an admissible heuristic, one that never overestimates, the path
+// is as short as ShortestPath's; a nil heuristic, or one returning 0, searches
+// like Dijkstra. Negative estimates are treated as 0.
+func (gt *DefaultGraphTraverser) AStarPath(graph *ResourceGraph, source, target NodeID, heuristic HeuristicFunc) *PathResult {
+	result := &PathResult{
+		Found: false,
+	}
+
+	// Verify source and target exist
+	if _, exists := graph.Nodes[source]; !exists {
+		return result
+	}
+	if _, exists := graph.Nodes[target]; !exists {
+		return result
+	}
+
+	// Estimates are computed once per node so stale queue entries compare equal
+	estimates := make(map[NodeID]float64)
+	estimate := func(nodeID NodeID) float64 {
+		if h, known := estimates[nodeID]; known {
+			return h
+		}
+		var h float64
+		// The comparison also rejects NaN
+		if heuristic != nil {
+			if value := heuristic(nodeID, target, graph); value > 0 {
+				h = value
+			}
+		}
+		estimates[nodeID] = h
+		return h
+	}
+
+	// Nodes are queued by estimated total distance; a node is queued again
+	// when a shorter route to it is found and its stale entries skipped
+	distances := map[NodeID]float64{source: 0}
+	previousEdge := make(map[NodeID]EdgeID)
+	open := &NodePriorityQueue{}
+	heap.Push(open, &PriorityQueueItem{NodeID: source, Distance: estimate(source)})
+
+	for open.Len() > 0 {
+		current := heap.Pop(open).(*PriorityQueueItem)
+		distance := distances[current.NodeID]
+		if current.Distance > distance+estimate(current.NodeID) {
+			continue
+		}
+
+		if current.NodeID == target {
+			result.Found = true
+			result.TotalDistance = distance
+
+			// Walk the recorded edges back to the source
+			for nodeID := target; nodeID != source; {
+				edgeID := previousEdge[nodeID]
+				result.Path = append(result.Path, nodeID)
+				result.Edges = append(result.Edges, edgeID)
+				nodeID = graph.Edges[edgeID].Source
+			}
+			result.Path = append(result.Path, source)
+			slices.Reverse(result.Path)
+			slices.Reverse(result.Edges)
+			result.PathLength = len(result.Edges)
+			break
+		}
+
+		result.NodesExpanded++
+		for _, edgeID := range graph.AdjacencyList[current.NodeID] {
+			edge, exists := graph.Edges[edgeID]
+			if !exists || graph.Nodes[edge.Target] == nil {
+				continue
+			}
+
+			weight, valid := gt.edgeWeight(edge)
+			if !valid {
+				continue
+			}
+
+			alt := distance + weight
+			if known, seen := distances[edge.Target]; seen && alt >= known {
+				continue
+			}
+			distances[edge.Target] = alt
+			previousEdge[edge.Target] = edgeID
+			heap.Push(open, &PriorityQueueItem{NodeID: edge.Target, Distance: alt + estimate(edge.Target)})
+		}
+	}
+
+	return result
+}
+
+// FindAllPaths finds all paths between two nodes up to maxDepth. Dense graphs
+// can have very many paths, so the search checks ctx as it goes and returns
+// what it has found, marked TimedOut, once ctx is done.
+func (gt *DefaultGraphTraverser) FindAllPaths(ctx context.Context, graph *ResourceGraph, source, target NodeID, maxDepth int) *PathsResult {
 	result := &PathsResult{
 		Paths: make([]*PathResult, 0),
 	}
@@ -539,7 +816,7 @@ func (gt *DefaultGraphTraverser) FindAllPaths(graph *ResourceGraph, source, targ
 	currentPath := []NodeID{source}
 	currentEdges := []EdgeID{}
 
-	gt.findAllPathsDFS(graph, source, target, maxDepth, 0, visited, currentPath, currentEdges, result)
+	gt.findAllPathsDFS(ctx, graph, source, target, maxDepth, 0, visited, currentPath, currentEdges, result)
 
 	result.TotalPathsFound = len(result.Paths)
 
@@ -547,7 +824,7 @@ func (gt *DefaultGraphTraverser) FindAllPaths(graph *ResourceGraph, source, targ
 	if len(result.Paths) > 0 {
 		shortest := result.Paths[0]
 		for _, path := range result.Paths[1:] {
-			if path.PathLength < shortest.PathLength {
+			if path.TotalDistance < shortest.TotalDistance {
 				shortest = path
 			}
 		}
@@ -582,8 +859,18 @@ func (gt *DefaultGraphTraverser) TopologicalSort(graph *ResourceGraph) *Topologi
 		}
 	}
 
+	comparator := gt.levelComparator
+	if comparator == nil {
+		comparator = CompareByNodeID
+	}
+
 	level := 0
 	for len(queue) > 0 {
+		// Order the level deterministically; the queue is built from map iteration
+		sort.SliceStable(queue, func(i, j int) bool {
+			return comparator(graph, queue[i], queue[j])
+		})
+
 		// Process current level
 		currentLevel := make([]NodeID, len(queue))
 		copy(currentLevel, queue)
@@ -616,13 +903,162 @@ func (gt *DefaultGraphTraverser) TopologicalSort(graph *ResourceGraph) *Topologi
 	// Check for cycles
 	if len(result.SortedNodes) != len(graph.Nodes) {
 		result.CyclesFound = true
-		// Could implement cycle detection algorithm here
-		// For now, just mark that cycles exist
+		result.DetectedCycles = componentCycles(graph, gt.clock.Now())
 	}
 
 	return result
 }
 
+// componentCycles returns one cycle for each strongly connected component
+// that contains a cycle, holding the component's nodes and the edges between
+// them. A component whose edges form a single ring is a simple cycle; one with
+// more edges than nodes contains several cycles and is reported as complex.
+func componentCycles(graph *ResourceGraph, detectedAt time.Time) []Cycle {
+	components := StronglyConnectedComponents(graph)
+	cycles := make([]Cycle, 0, len(components))
+
+	for _, component := range components {
+		members := make(map[NodeID]bool, len(component))
+		for _, nodeID := range component {
+			members[nodeID] = true
+		}
+
+		edges := make([]EdgeID, 0, len(component))
+		for _, nodeID := range component {
+			for _, edgeID := range graph.AdjacencyList[nodeID] {
+				if edge, exists := graph.Edges[edgeID]; exists && members[edge.Target] {
+					edges = append(edges, edgeID)
+				}
+			}
+		}
+
+		cycleType := "simple"
+		if len(edges) > len(component) {
+			cycleType = "complex"
+		}
+		cycles = append(cycles, Cycle{
+			Nodes:      component,
+			Edges:      edges,
+			DetectedAt: detectedAt,
+			CycleType:  cycleType,
+		})
+	}
+
+	return cycles
+}
+
+// ApplicationOrder returns the nodes reachable from the roots in the order they
+// should be applied: a resource comes after every resource it references. Roots
+// are the nodes matching rootFilter, or the graph's root nodes when rootFilter
+// is nil. Nodes rejected by includePredicate, e.g. ones that already exist, are
+// left out of the result but still constrain the order of the others. Nodes on
+// or behind a dependency cycle cannot be ordered; they are omitted and reported
+// in the returned error alongside the nodes that could be ordered.
+func (gt *DefaultGraphTraverser) ApplicationOrder(graph *ResourceGraph, rootFilter, includePredicate NodePredicate) ([]*ResourceNode, error) {
+	var roots []NodeID
+	if rootFilter == nil {
+		roots = graph.Metadata.RootNodes
+	} else {
+		for nodeID, node := range graph.Nodes {
+			if rootFilter(node) {
+				roots = append(roots, nodeID)
+			}
+		}
+	}
+
+	reachable := gt.reachableNodes(graph, roots)
+
+	// Sorting the reachable subgraph with its edges reversed puts every
+	// resource after the resources it references
+	dependencies := gt.reversedSubgraph(graph, reachable)
+	ordered := make([]*ResourceNode, 0, len(reachable))
+	for _, nodeID := range gt.TopologicalSort(dependencies).SortedNodes {
+		delete(reachable, nodeID)
+
+		node := graph.Nodes[nodeID]
+		if includePredicate == nil || includePredicate(node) {
+			ordered = append(ordered, node)
+		}
+	}
+
+	// Whatever is left is on a cycle or depends on one
+	if len(reachable) > 0 {
+		unordered := make([]string, 0, len(reachable))
+		for nodeID := range reachable {
+			unordered = append(unordered, string(nodeID))
+		}
+		sort.Strings(unordered)
+		return ordered, fmt.Errorf("cannot order %d resources involved in dependency cycles: %s",
+			len(unordered), strings.Join(unordered, ", "))
+	}
+
+	return ordered, nil
+}
+
+// reversedSubgraph returns the subgraph induced by the given nodes with every
+// edge pointing from the referenced resource back to the referencing one
+func (gt *DefaultGraphTraverser) reversedSubgraph(graph *ResourceGraph, nodes map[NodeID]bool) *ResourceGraph {
+	subgraph := &ResourceGraph{
+		Nodes:                make(map[NodeID]*ResourceNode, len(nodes)),
+		Edges:                make(map[EdgeID]*ResourceEdge),
+		AdjacencyList:        make(map[NodeID][]EdgeID),
+		ReverseAdjacencyList: make(map[NodeID][]EdgeID),
+		Metadata:             &GraphMetadata{},
+	}
+
+	for nodeID := range nodes {
+		subgraph.Nodes[nodeID] = graph.Nodes[nodeID]
+	}
+
+	for _, edge := range SortedEdges(graph) {
+		if !nodes[edge.Source] || !nodes[edge.Target] {
+			continue
+		}
+		edgeID := edge.ID
+		reversed := *edge
+		// Edges the builder already reversed point from dependency to dependent
+		if edge.Metadata == nil || !edge.Metadata.Reversed {
+			reversed.Source, reversed.Target = edge.Target, edge.Source
+		}
+		subgraph.Edges[edgeID] = &reversed
+		subgraph.AdjacencyList[reversed.Source] = append(subgraph.AdjacencyList[reversed.Source], edgeID)
+		subgraph.ReverseAdjacencyList[reversed.Target] = append(subgraph.ReverseAdjacencyList[reversed.Target], edgeID)
+	}
+
+	return subgraph
+}
+
+// reachableNodes returns every node reachable from the given nodes along outbound edges
+func (gt *DefaultGraphTraverser) reachableNodes(graph *ResourceGraph, startNodes []NodeID) map[NodeID]bool {
+	reachable := make(map[NodeID]bool)
+	stack := make([]NodeID, 0, len(startNodes))
+	for _, nodeID := range startNodes {
+		if _, exists := graph.Nodes[nodeID]; exists && !reachable[nodeID] {
+			reachable[nodeID] = true
+			stack = append(stack, nodeID)
+		}
+	}
+
+	for len(stack) > 0 {
+		nodeID := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		for _, edgeID := range graph.AdjacencyList[nodeID] {
+			edge, exists := graph.Edges[edgeID]
+			if !exists || reachable[edge.Target] {
+				continue
+			}
+			if _, exists := graph.Nodes[edge.Target]; !exists {
+				continue
+			}
+			reachable[edge.Target] = true
+			stack = append(stack, edge.Target)
+		}
+	}
+
+	return reachable
+}
+
 // Helper methods
 
 // TraversalQueueItem represents an item in the traversal queue
@@ -633,6 +1069,38 @@ type TraversalQueueItem struct {
 	Path   []NodeID
 }
 
+// sortDepthBands sorts each NodesByDepth band by kind, name, namespace and
+// finally node ID when SetSortNodesByDepth is enabled
+func (gt *DefaultGraphTraverser) sortDepthBands(graph *ResourceGraph, result *TraversalResult) {
+	if !gt.sortNodesByDepth {
+		return
+	}
+
+	sortKey := func(nodeID NodeID) (kind, name, namespace string) {
+		if node, exists := graph.Nodes[nodeID]; exists && node.Resource != nil {
+			return node.Resource.GetKind(), node.Resource.GetName(), node.Resource.GetNamespace()
+		}
+		return "", "", ""
+	}
+
+	for _, nodes := range result.NodesByDepth {
+		sort.Slice(nodes, func(i, j int) bool {
+			kindI, nameI, namespaceI := sortKey(nodes[i])
+			kindJ, nameJ, namespaceJ := sortKey(nodes[j])
+			if kindI != kindJ {
+				return kindI < kindJ
+			}
+			if nameI != nameJ {
+				return nameI < nameJ
+			}
+			if namespaceI != namespaceJ {
+				return namespaceI < namespaceJ
+			}
+			return nodes[i] < nodes[j]
+		})
+	}
+}
+
 // dfsVisit performs depth-first search recursively
 func (gt *DefaultGraphTraverser) dfsVisit(graph *ResourceGraph, nodeID NodeID, depth int, maxDepth int, visited map[NodeID]bool, path []NodeID, result *TraversalResult) {
 	visited[nodeID] = true
@@ -684,7 +1152,15 @@ func (gt *DefaultGraphTraverser) dfsVisit(graph *ResourceGraph, nodeID NodeID, d
 }
 
 // findAllPathsDFS recursively finds all paths using DFS
-func (gt *DefaultGraphTraverser) findAllPathsDFS(graph *ResourceGraph, current, target NodeID, maxDepth, currentDepth int, visited map[NodeID]bool, currentPath []NodeID, currentEdges []EdgeID, result *PathsResult) {
+func (gt *DefaultGraphTraverser) findAllPathsDFS(ctx context.Context, graph *ResourceGraph, current, target NodeID, maxDepth, currentDepth int, visited map[NodeID]bool, currentPath []NodeID, currentEdges []EdgeID, result *PathsResult) {
+	if result.TimedOut {
+		return
+	}
+	if ctx.Err() != nil {
+		result.TimedOut = true
+		return
+	}
+
 	if currentDepth > result.SearchDepthReached {
 		result.SearchDepthReached = currentDepth
 	}
@@ -692,14 +1168,17 @@ func (gt *DefaultGraphTraverser) findAllPathsDFS(graph *ResourceGraph, current,
 	if current == target {
 		// Found a path
 		pathResult := &PathResult{
-			Path:          make([]NodeID, len(currentPath)),
-			Edges:         make([]EdgeID, len(currentEdges)),
-			PathLength:    len(currentEdges),
-			TotalDistance: float64(len(currentEdges)),
-			Found:         true,
+			Path:       make([]NodeID, len(currentPath)),
+			Edges:      make([]EdgeID, len(currentEdges)),
+			PathLength: len(currentEdges),
+			Found:      true,
 		}
 		copy(pathResult.Path, currentPath)
 		copy(pathResult.Edges, currentEdges)
+		for _, edgeID := range currentEdges {
+			weight, _ := gt.edgeWeight(graph.Edges[edgeID])
+			pathResult.TotalDistance += weight
+		}
 
 		result.Paths = append(result.Paths, pathResult)
 		return
@@ -718,6 +1197,9 @@ func (gt *DefaultGraphTraverser) findAllPathsDFS(graph *ResourceGraph, current,
 			if !edgeExists || visited[edge.Target] {
 				continue
 			}
+			if _, valid := gt.edgeWeight(edge); !valid {
+				continue
+			}
 
 			// Add to current path
 			newPath := make([]NodeID, len(currentPath))
@@ -728,7 +1210,7 @@ func (gt *DefaultGraphTraverser) findAllPathsDFS(graph *ResourceGraph, current,
 			copy(newEdges, currentEdges)
 			newEdges = append(newEdges, edgeID)
 
-			gt.findAllPathsDFS(graph, edge.Target, target, maxDepth, currentDepth+1, visited, newPath, newEdges, result)
+			gt.findAllPathsDFS(ctx, graph, edge.Target, target, maxDepth, currentDepth+1, visited, newPath, newEdges, result)
 		}
 	}
 
@@ -0,0 +1,735 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopologicalSortLevelOrdering(t *testing.T) {
+	builder := NewDefaultGraphBuilder(testPlatformChecker{})
+	graph := builder.NewGraph()
+
+	root := builder.AddNode(graph, newTestResource("KubEnv", "root", "uid-root"), 0, nil)
+	confidences := []float64{0.6, 0.9, 0.7, 0.95, 0.8}
+	children := make([]NodeID, 0, len(confidences))
+	for i, confidence := range confidences {
+		child := builder.AddNode(graph, newTestResource("KubeCluster", fmt.Sprintf("child-%d", i), fmt.Sprintf("uid-child-%d", i)), 1, nil)
+		require.NotNil(t, builder.AddEdge(graph, root.ID, child.ID, RelationTypeCustomRef, "spec.clusterRef", "clusterRef", confidence))
+		children = append(children, child.ID)
+	}
+
+	t.Run("ByNodeID", func(t *testing.T) {
+		expected := append([]NodeID(nil), children...)
+		sort.Slice(expected, func(i, j int) bool { return expected[i] < expected[j] })
+
+		traverser := NewDefaultGraphTraverser(nil)
+		for i := 0; i < 20; i++ {
+			result := traverser.TopologicalSort(graph)
+			assert.Equal(t, []NodeID{root.ID}, result.Levels[0])
+			assert.Equal(t, expected, result.Levels[1])
+		}
+	})
+
+	t.Run("ByInboundConfidence", func(t *testing.T) {
+		expected := []NodeID{children[3], children[1], children[4], children[2], children[0]}
+
+		traverser := NewDefaultGraphTraverser(nil)
+		traverser.SetLevelComparator(CompareByInboundConfidence)
+		for i := 0; i < 20; i++ {
+			result := traverser.TopologicalSort(graph)
+			assert.Equal(t, expected, result.Levels[1])
+		}
+	})
+}
+
+func TestTopologicalSortRelationPriority(t *testing.T) {
+	builder := NewDefaultGraphBuilder(testPlatformChecker{})
+	graph := builder.NewGraph()
+
+	root := builder.AddNode(graph, newTestResource("KubEnv", "root", "uid-root"), 0, nil)
+	child := func(name string, relationType RelationType, confidence float64) NodeID {
+		node := builder.AddNode(graph, newTestResource("KubeCluster", name, "uid-"+name), 1, nil)
+		require.NotNil(t, builder.AddEdge(graph, root.ID, node.ID, relationType, "spec."+name, name, confidence))
+		return node.ID
+	}
+	confidentRef := child("confident-ref", RelationTypeCustomRef, 0.95)
+	weakOwner := child("weak-owner", RelationTypeOwnerRef, 0.6)
+	weakRef := child("weak-ref", RelationTypeCustomRef, 0.5)
+	owner := child("owner", RelationTypeOwnerRef, 0.9)
+
+	cases := map[string]struct {
+		compare EdgeComparator
+		want    []NodeID
+	}{
+		"OwnerRefsFirst": {
+			compare: CompareByRelationPriority(RelationTypeOwnerRef),
+			want:    []NodeID{owner, weakOwner, confidentRef, weakRef},
+		},
+		"ConfidenceOnly": {
+			want: []NodeID{confidentRef, owner, weakOwner, weakRef},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			traverser := NewDefaultGraphTraverser(nil)
+			traverser.SetLevelComparator(CompareByInboundEdges(tc.compare))
+			for i := 0; i < 20; i++ {
+				result := traverser.TopologicalSort(graph)
+				assert.Equal(t, []NodeID{root.ID}, result.Levels[0])
+				assert.Equal(t, tc.want, result.Levels[1])
+				assert.False(t, result.CyclesFound)
+				assert.Empty(t, result.DetectedCycles)
+			}
+		})
+	}
+}
+
+func TestTopologicalSortDetectedCycles(t *testing.T) {
+	builder := NewDefaultGraphBuilder(testPlatformChecker{})
+	graph := builder.NewGraph()
+
+	// root -> a <-> b, and c referencing itself
+	root := builder.AddNode(graph, newTestResource("KubEnv", "root", "uid-root"), 0, nil)
+	a := builder.AddNode(graph, newTestResource("KubeCluster", "a", "uid-a"), 1, nil)
+	b := builder.AddNode(graph, newTestResource("KubeCluster", "b", "uid-b"), 2, nil)
+	c := builder.AddNode(graph, newTestResource("KubeCluster", "c", "uid-c"), 0, nil)
+	require.NotNil(t, builder.AddEdge(graph, root.ID, a.ID, RelationTypeCustomRef, "spec.aRef", "aRef", 0.9))
+	ab := builder.AddEdge(graph, a.ID, b.ID, RelationTypeCustomRef, "spec.bRef", "bRef", 0.9)
+	ba := builder.AddEdge(graph, b.ID, a.ID, RelationTypeCustomRef, "spec.aRef", "aRef", 0.9)
+	cc := builder.AddEdge(graph, c.ID, c.ID, RelationTypeCustomRef, "spec.cRef", "cRef", 0.9)
+	require.NotNil(t, ab)
+	require.NotNil(t, ba)
+	require.NotNil(t, cc)
+
+	fixed := time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC)
+	traverser := NewDefaultGraphTraverser(nil)
+	traverser.SetClock(fixedClock{now: fixed})
+
+	result := traverser.TopologicalSort(graph)
+	assert.True(t, result.CyclesFound)
+	assert.Equal(t, []NodeID{root.ID}, result.SortedNodes)
+
+	require.Len(t, result.DetectedCycles, 2)
+	assert.Equal(t, []NodeID{a.ID, b.ID}, result.DetectedCycles[0].Nodes)
+	assert.Equal(t, []EdgeID{ab.ID, ba.ID}, result.DetectedCycles[0].Edges)
+	assert.Equal(t, "simple", result.DetectedCycles[0].CycleType)
+	assert.Equal(t, fixed, result.DetectedCycles[0].DetectedAt)
+	assert.Equal(t, []NodeID{c.ID}, result.DetectedCycles[1].Nodes)
+	assert.Equal(t, []EdgeID{cc.ID}, result.DetectedCycles[1].Edges)
+	assert.Equal(t, fixed, result.DetectedCycles[1].DetectedAt)
+}
+
+func TestTopologicalSortReversedOwnerRefs(t *testing.T) {
+	buildGraph := func(builder *DefaultGraphBuilder) (*ResourceGraph, *ResourceEdge) {
+		graph := builder.NewGraph()
+		// The cluster holds an owner reference to the environment that owns it
+		owner := builder.AddNode(graph, newTestResource("KubEnv", "env", "uid-env"), 0, nil)
+		owned := builder.AddNode(graph, newTestResource("KubeCluster", "cluster", "uid-cluster"), 1, nil)
+		edge := builder.AddEdge(graph, owned.ID, owner.ID, RelationTypeOwnerRef, "metadata.ownerReferences[0]", "ownerReference", 1.0)
+		require.NotNil(t, edge)
+		return graph, edge
+	}
+
+	names := func(graph *ResourceGraph, nodeIDs []NodeID) []string {
+		result := make([]string, 0, len(nodeIDs))
+		for _, nodeID := range nodeIDs {
+			result = append(result, graph.Nodes[nodeID].Resource.GetName())
+		}
+		return result
+	}
+
+	cases := map[string]struct {
+		reversed     []RelationType
+		wantSorted   []string
+		wantReversed bool
+	}{
+		"OwnedFirstByDefault": {
+			wantSorted: []string{"cluster", "env"},
+		},
+		"OwnerFirstWhenReversed": {
+			reversed:     []RelationType{RelationTypeOwnerRef},
+			wantSorted:   []string{"env", "cluster"},
+			wantReversed: true,
+		},
+		"OtherTypesUnaffected": {
+			reversed:   []RelationType{RelationTypeCustomRef},
+			wantSorted: []string{"cluster", "env"},
+		},
+	}
+
+	traverser := NewDefaultGraphTraverser(nil)
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			builder := NewDefaultGraphBuilder(testPlatformChecker{})
+			builder.SetReversedRelationTypes(tc.reversed...)
+			graph, edge := buildGraph(builder)
+
+			assert.Equal(t, tc.wantReversed, edge.Metadata.Reversed)
+			assert.Equal(t, tc.wantSorted, names(graph, traverser.TopologicalSort(graph).SortedNodes))
+
+			// Application order puts owners first either way
+			graph.Metadata.RootNodes = []NodeID{edge.Source}
+			ordered, err := traverser.ApplicationOrder(graph, nil, nil)
+			require.NoError(t, err)
+			require.Len(t, ordered, 2)
+			assert.Equal(t, "env", ordered[0].Resource.GetName())
+		})
+	}
+}
+
+func TestApplicationOrder(t *testing.T) {
+	builder := NewDefaultGraphBuilder(testPlatformChecker{})
+	graph := builder.NewGraph()
+
+	// app -> cluster -> env -> secret, with an unrelated node off to the side
+	app := builder.AddNode(graph, newTestResource("KubeApp", "app", "uid-app"), 0, nil)
+	cluster := builder.AddNode(graph, newTestResource("KubeCluster", "cluster", "uid-cluster"), 1, nil)
+	env := builder.AddNode(graph, newTestResource("KubEnv", "env", "uid-env"), 2, nil)
+	secret := builder.AddNode(graph, newTestResource("Secret", "creds", "uid-secret"), 3, nil)
+	builder.AddNode(graph, newTestResource("KubeNet", "unrelated", "uid-unrelated"), 0, nil)
+	require.NotNil(t, builder.AddEdge(graph, app.ID, cluster.ID, RelationTypeCustomRef, "spec.clusterRef", "clusterRef", 0.9))
+	require.NotNil(t, builder.AddEdge(graph, cluster.ID, env.ID, RelationTypeCustomRef, "spec.envRef", "envRef", 0.9))
+	require.NotNil(t, builder.AddEdge(graph, env.ID, secret.ID, RelationTypeCustomRef, "spec.secretRef", "secretRef", 0.9))
+	graph.Metadata.RootNodes = []NodeID{app.ID}
+
+	names := func(nodes []*ResourceNode) []string {
+		result := make([]string, 0, len(nodes))
+		for _, node := range nodes {
+			result = append(result, node.Resource.GetName())
+		}
+		return result
+	}
+
+	cases := map[string]struct {
+		rootFilter       NodePredicate
+		includePredicate NodePredicate
+		want             []string
+	}{
+		"DependenciesFirst": {
+			want: []string{"creds", "env", "cluster", "app"},
+		},
+		"RootFilterSelectsSubgraph": {
+			rootFilter: func(node *ResourceNode) bool { return node.Resource.GetName() == "cluster" },
+			want:       []string{"creds", "env", "cluster"},
+		},
+		"PredicateExcludesExisting": {
+			includePredicate: func(node *ResourceNode) bool { return node.Resource.GetKind() != "Secret" },
+			want:             []string{"env", "cluster", "app"},
+		},
+	}
+
+	traverser := NewDefaultGraphTraverser(nil)
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ordered, err := traverser.ApplicationOrder(graph, tc.rootFilter, tc.includePredicate)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, names(ordered))
+		})
+	}
+
+	t.Run("CyclesAreReported", func(t *testing.T) {
+		require.NotNil(t, builder.AddEdge(graph, env.ID, cluster.ID, RelationTypeCustomRef, "spec.clusterRef", "clusterRef", 0.9))
+
+		ordered, err := traverser.ApplicationOrder(graph, nil, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), string(cluster.ID))
+		assert.Contains(t, err.Error(), string(env.ID))
+		// The secret can still be applied; the app waits on the cycle
+		assert.Equal(t, []string{"creds"}, names(ordered))
+		assert.Contains(t, err.Error(), string(app.ID))
+	})
+}
+
+func TestFindAllPathsTimeBudget(t *testing.T) {
+	builder := NewDefaultGraphBuilder(testPlatformChecker{})
+	graph := builder.NewGraph()
+
+	// Fully connected layers: the number of paths grows as width^(layers-1)
+	const layers, width = 12, 6
+	source := builder.AddNode(graph, newTestResource("KubEnv", "source", "uid-source"), 0, nil)
+	previous := []NodeID{source.ID}
+	var secondLayer []NodeID
+	for layer := 1; layer < layers; layer++ {
+		current := make([]NodeID, 0, width)
+		for i := 0; i < width; i++ {
+			name := fmt.Sprintf("node-%d-%d", layer, i)
+			node := builder.AddNode(graph, newTestResource("KubeCluster", name, "uid-"+name), layer, nil)
+			for _, from := range previous {
+				require.NotNil(t, builder.AddEdge(graph, from, node.ID, RelationTypeCustomRef, "spec.clusterRef", "clusterRef", 0.9))
+			}
+			current = append(current, node.ID)
+		}
+		if layer == 2 {
+			secondLayer = current
+		}
+		previous = current
+	}
+	target := builder.AddNode(graph, newTestResource("KubeApp", "target", "uid-target"), layers, nil)
+	for _, from := range previous {
+		require.NotNil(t, builder.AddEdge(graph, from, target.ID, RelationTypeCustomRef, "spec.appRef", "appRef", 0.9))
+	}
+
+	traverser := NewDefaultGraphTraverser(nil)
+
+	t.Run("Cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		result := traverser.FindAllPaths(ctx, graph, source.ID, target.ID, layers+1)
+		assert.True(t, result.TimedOut)
+		assert.Empty(t, result.Paths)
+	})
+
+	t.Run("Deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		result := traverser.FindAllPaths(ctx, graph, source.ID, target.ID, layers+1)
+		assert.Less(t, time.Since(start), 5*time.Second)
+		assert.True(t, result.TimedOut)
+		assert.NotEmpty(t, result.Paths, "paths found before the deadline should be kept")
+		assert.Equal(t, len(result.Paths), result.TotalPathsFound)
+		require.NotNil(t, result.ShortestPath)
+		assert.Equal(t, layers, result.ShortestPath.PathLength)
+	})
+
+	t.Run("WithinBudget", func(t *testing.T) {
+		result := traverser.FindAllPaths(context.Background(), graph, source.ID, secondLayer[0], 2)
+		assert.False(t, result.TimedOut)
+		assert.Len(t, result.Paths, width)
+	})
+}
+
+// visitAllStrategy visits every node and traverses every edge
+type visitAllStrategy struct{}
+
+func (visitAllStrategy) ShouldVisit(_ *ResourceNode, _, _ int) bool { return true }
+
+func (visitAllStrategy) ShouldTraverseEdge(_ *ResourceEdge, _, _ int) bool { return true }
+
+func (visitAllStrategy) GetPriority(_ *ResourceNode, depth int) int { return depth }
+
+func TestSortNodesByDepth(t *testing.T) {
+	builder := NewDefaultGraphBuilder(testPlatformChecker{})
+	graph := builder.NewGraph()
+
+	root := builder.AddNode(graph, newTestResource("KubEnv", "root", "uid-root"), 0, nil)
+	graph.Metadata.RootNodes = append(graph.Metadata.RootNodes, root.ID)
+
+	children := map[string]NodeID{}
+	for _, child := range []struct{ kind, name string }{
+		{"KubeNet", "b"},
+		{"KubeCluster", "z"},
+		{"KubeNet", "a"},
+		{"KubeCluster", "m"},
+	} {
+		node := builder.AddNode(graph, newTestResource(child.kind, child.name, "uid-"+child.kind+"-"+child.name), 1, nil)
+		require.NotNil(t, builder.AddEdge(graph, root.ID, node.ID, RelationTypeCustomRef, "spec."+child.name+"Ref", child.name+"Ref", 0.9))
+		children[child.kind+"/"+child.name] = node.ID
+	}
+
+	expected := []NodeID{
+		children["KubeCluster/m"],
+		children["KubeCluster/z"],
+		children["KubeNet/a"],
+		children["KubeNet/b"],
+	}
+
+	traverser := NewDefaultGraphTraverser(visitAllStrategy{})
+	traverser.SetSortNodesByDepth(true)
+
+	for i := 0; i < 20; i++ {
+		for name, result := range map[string]*TraversalResult{
+			"BreadthFirst": traverser.BreadthFirstTraversal(graph, 3),
+			"DepthFirst":   traverser.DepthFirstTraversal(graph, 3),
+		} {
+			assert.Equal(t, []NodeID{root.ID}, result.NodesByDepth[0], name)
+			assert.Equal(t, expected, result.NodesByDepth[1], name)
+		}
+	}
+}
+
+func TestOrphanedNodes(t *testing.T) {
+	builder := NewDefaultGraphBuilder(testPlatformChecker{})
+	graph := builder.NewGraph()
+
+	// root -> a, with b -> c disconnected from the root and c pointing back at a
+	ids := map[string]NodeID{}
+	for _, name := range []string{"root", "a", "b", "c"} {
+		ids[name] = builder.AddNode(graph, newTestResource("KubeNet", name, "uid-"+name), 0, nil).ID
+	}
+	graph.Metadata.RootNodes = []NodeID{ids["root"]}
+	for _, edge := range [][2]string{{"root", "a"}, {"b", "c"}, {"c", "a"}} {
+		require.NotNil(t, builder.AddEdge(graph, ids[edge[0]], ids[edge[1]], RelationTypeCustomRef, "spec."+edge[1]+"Ref", edge[1]+"Ref", 0.9))
+	}
+
+	want := []NodeID{ids["b"], ids["c"]}
+	sort.Slice(want, func(i, j int) bool { return want[i] < want[j] })
+	assert.Equal(t, want, OrphanedNodes(graph))
+
+	require.NotNil(t, builder.AddEdge(graph, ids["a"], ids["b"], RelationTypeCustomRef, "spec.bRef", "bRef", 0.9))
+	assert.Empty(t, OrphanedNodes(graph))
+}
+
+func TestReachableSet(t *testing.T) {
+	builder := NewDefaultGraphBuilder(testPlatformChecker{})
+	graph := builder.NewGraph()
+
+	// root -> a -> c -> d -> e -> a, root -> b -> d
+	ids := map[string]NodeID{}
+	for _, name := range []string{"root", "a", "b", "c", "d", "e"} {
+		ids[name] = builder.AddNode(graph, newTestResource("KubeNet", name, "uid-"+name), 0, nil).ID
+	}
+	graph.Metadata.RootNodes = append(graph.Metadata.RootNodes, ids["root"])
+	for _, edge := range [][2]string{{"root", "a"}, {"root", "b"}, {"a", "c"}, {"b", "d"}, {"c", "d"}, {"d", "e"}, {"e", "a"}} {
+		require.NotNil(t, builder.AddEdge(graph, ids[edge[0]], ids[edge[1]], RelationTypeCustomRef, "spec."+edge[1]+"Ref", edge[1]+"Ref", 0.9))
+	}
+
+	cases := map[string]struct {
+		start    []NodeID
+		maxDepth int
+		want     map[string]int
+	}{
+		"FromRoot": {
+			start:    []NodeID{ids["root"]},
+			maxDepth: 10,
+			want:     map[string]int{"root": 0, "a": 1, "b": 1, "c": 2, "d": 2, "e": 3},
+		},
+		"DepthLimited": {
+			start:    []NodeID{ids["root"]},
+			maxDepth: 1,
+			want:     map[string]int{"root": 0, "a": 1, "b": 1},
+		},
+		"ThroughCycle": {
+			start:    []NodeID{ids["c"], NodeID("missing")},
+			maxDepth: -1,
+			want:     map[string]int{"c": 0, "d": 1, "e": 2, "a": 3},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			want := make(map[NodeID]int, len(tc.want))
+			for node, depth := range tc.want {
+				want[ids[node]] = depth
+			}
+			assert.Equal(t, want, ReachableSet(graph, tc.start, tc.maxDepth))
+		})
+	}
+
+	// The depths agree with the bands of a full breadth-first traversal
+	bfs := NewDefaultGraphTraverser(visitAllStrategy{}).BreadthFirstTraversal(graph, 10)
+	reachable := ReachableSet(graph, graph.Metadata.RootNodes, 10)
+	for depth, nodes := range bfs.NodesByDepth {
+		for _, nodeID := range nodes {
+			assert.Equal(t, depth, reachable[nodeID], nodeID)
+		}
+	}
+	assert.Len(t, reachable, len(bfs.VisitedNodes))
+}
+
+func TestAStarPath(t *testing.T) {
+	builder := NewDefaultGraphBuilder(testPlatformChecker{})
+	graph := builder.NewGraph()
+
+	addNode := func(name string) NodeID {
+		return builder.AddNode(graph, newTestResource("KubEnv", name, "uid-"+name), 0, nil).ID
+	}
+	link := func(source, target NodeID) {
+		require.NotNil(t, builder.AddEdge(graph, source, target, RelationTypeCustomRef, "spec.ref", "ref", 0.9))
+	}
+
+	// The target is three hops away; ten two-hop branches lead nowhere
+	source := addNode("source")
+	a, b, target := addNode("a"), addNode("b"), addNode("target")
+	link(source, a)
+	link(a, b)
+	link(b, target)
+	for i := 0; i < 10; i++ {
+		branch := addNode(fmt.Sprintf("branch-%d", i))
+		link(source, branch)
+		link(branch, addNode(fmt.Sprintf("leaf-%d", i)))
+	}
+	isolated := addNode("isolated")
+
+	// remaining holds the exact hop count to the target, the best admissible estimate
+	remaining := map[NodeID]float64{target: 0, b: 1, a: 2, source: 3}
+	exact := func(current, _ NodeID, _ *ResourceGraph) float64 {
+		if hops, ok := remaining[current]; ok {
+			return hops
+		}
+		return 100
+	}
+
+	traverser := NewDefaultGraphTraverser(nil)
+	dijkstra := traverser.ShortestPath(graph, source, target)
+	require.True(t, dijkstra.Found)
+	require.Equal(t, 3, dijkstra.PathLength)
+
+	cases := map[string]struct {
+		heuristic    HeuristicFunc
+		wantExpanded int
+	}{
+		"ExactEstimate": {
+			heuristic:    exact,
+			wantExpanded: 3,
+		},
+		"ZeroEstimate": {
+			heuristic: func(_, _ NodeID, _ *ResourceGraph) float64 { return 0 },
+		},
+		"NoHeuristic": {},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			result := traverser.AStarPath(graph, source, target, tc.heuristic)
+			require.True(t, result.Found)
+			assert.Equal(t, []NodeID{source, a, b, target}, result.Path)
+			assert.Len(t, result.Edges, 3)
+			assert.Equal(t, dijkstra.PathLength, result.PathLength)
+			assert.Equal(t, dijkstra.TotalDistance, result.TotalDistance)
+
+			if tc.wantExpanded > 0 {
+				assert.Equal(t, tc.wantExpanded, result.NodesExpanded)
+				assert.Less(t, result.NodesExpanded, dijkstra.NodesExpanded)
+			} else {
+				// Without an estimate every node nearer than the target is expanded
+				assert.Equal(t, dijkstra.NodesExpanded, result.NodesExpanded)
+			}
+		})
+	}
+
+	t.Run("Unreachable", func(t *testing.T) {
+		result := traverser.AStarPath(graph, source, isolated, exact)
+		assert.False(t, result.Found)
+		assert.Empty(t, result.Path)
+		assert.False(t, traverser.ShortestPath(graph, source, isolated).Found)
+	})
+}
+
+func TestConfidenceWeight(t *testing.T) {
+	cases := map[string]struct {
+		confidence float64
+		want       float64
+	}{
+		"Certain":      {confidence: 1, want: 0},
+		"Half":         {confidence: 0.5, want: math.Ln2},
+		"Floor":        {confidence: 1e-6, want: maxEdgeWeight},
+		"BelowFloor":   {confidence: 1e-9, want: maxEdgeWeight},
+		"Zero":         {confidence: 0, want: maxEdgeWeight},
+		"NotANumber":   {confidence: math.NaN(), want: maxEdgeWeight},
+		"AboveCertain": {confidence: 1.5, want: 0},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.InDelta(t, tc.want, ConfidenceWeight(&ResourceEdge{Confidence: tc.confidence}), 1e-9)
+		})
+	}
+}
+
+// fieldWeight weighs edges by their field name, and every other edge 1
+func fieldWeight(weights map[string]float64) WeightFunc {
+	return func(edge *ResourceEdge) float64 {
+		if weight, ok := weights[edge.FieldName]; ok {
+			return weight
+		}
+		return 1
+	}
+}
+
+func TestShortestPathWeightFunc(t *testing.T) {
+	builder := NewDefaultGraphBuilder(testPlatformChecker{})
+	graph := builder.NewGraph()
+
+	addNode := func(name string) NodeID {
+		return builder.AddNode(graph, newTestResource("KubEnv", name, "uid-"+name), 0, nil).ID
+	}
+	link := func(source, target NodeID, fieldName string, confidence float64) {
+		require.NotNil(t, builder.AddEdge(graph, source, target, RelationTypeCustomRef, "spec."+fieldName, fieldName, confidence))
+	}
+
+	// Three confident hops compete with two heuristic guesses
+	source, target := addNode("source"), addNode("target")
+	owner, parent := addNode("owner"), addNode("parent")
+	guess := addNode("guess")
+	link(source, owner, "ownerRef", 1.0)
+	link(owner, parent, "parentRef", 0.95)
+	link(parent, target, "targetRef", 0.95)
+	link(source, guess, "guessRef", 0.4)
+	link(guess, target, "guessRef", 0.4)
+
+	// An unrelated branch only reachable through an edge without confidence
+	unknown, beyond := addNode("unknown"), addNode("beyond")
+	link(source, unknown, "unknownRef", 0)
+	link(unknown, beyond, "beyondRef", 0.9)
+
+	cases := map[string]struct {
+		weight       WeightFunc
+		target       NodeID
+		wantPath     []NodeID
+		wantDistance float64
+	}{
+		"UniformByDefault": {
+			target:       target,
+			wantPath:     []NodeID{source, guess, target},
+			wantDistance: 2,
+		},
+		"Confidence": {
+			weight:       ConfidenceWeight,
+			target:       target,
+			wantPath:     []NodeID{source, owner, parent, target},
+			wantDistance: -2 * math.Log(0.95),
+		},
+		"ZeroConfidenceStaysFinite": {
+			weight:       ConfidenceWeight,
+			target:       beyond,
+			wantPath:     []NodeID{source, unknown, beyond},
+			wantDistance: maxEdgeWeight - math.Log(0.9),
+		},
+		"LargeWeightsNotClamped": {
+			weight:       fieldWeight(map[string]float64{"ownerRef": 15, "parentRef": 15, "targetRef": 15, "guessRef": 20}),
+			target:       target,
+			wantPath:     []NodeID{source, guess, target},
+			wantDistance: 40,
+		},
+		"NaNWeightNotFollowed": {
+			weight:       fieldWeight(map[string]float64{"guessRef": math.NaN()}),
+			target:       target,
+			wantPath:     []NodeID{source, owner, parent, target},
+			wantDistance: 3,
+		},
+		"NegativeWeightNotFollowed": {
+			weight:       fieldWeight(map[string]float64{"guessRef": -1}),
+			target:       target,
+			wantPath:     []NodeID{source, owner, parent, target},
+			wantDistance: 3,
+		},
+		"InfiniteWeightNotFollowed": {
+			weight:       fieldWeight(map[string]float64{"guessRef": math.Inf(1)}),
+			target:       target,
+			wantPath:     []NodeID{source, owner, parent, target},
+			wantDistance: 3,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			traverser := NewDefaultGraphTraverser(nil)
+			traverser.SetWeightFunc(tc.weight)
+
+			result := traverser.ShortestPath(graph, source, tc.target)
+			require.True(t, result.Found)
+			assert.Equal(t, tc.wantPath, result.Path)
+			assert.Equal(t, len(tc.wantPath)-1, result.PathLength)
+			assert.InDelta(t, tc.wantDistance, result.TotalDistance, 1e-9)
+
+			aStar := traverser.AStarPath(graph, source, tc.target, nil)
+			assert.Equal(t, tc.wantPath, aStar.Path)
+			assert.InDelta(t, tc.wantDistance, aStar.TotalDistance, 1e-9)
+
+			all := traverser.FindAllPaths(context.Background(), graph, source, tc.target, 5)
+			require.NotNil(t, all.ShortestPath)
+			assert.Equal(t, tc.wantPath, all.ShortestPath.Path)
+			assert.InDelta(t, tc.wantDistance, all.ShortestPath.TotalDistance, 1e-9)
+		})
+	}
+}
+
+func TestStronglyConnectedComponents(t *testing.T) {
+	builder := NewDefaultGraphBuilder(testPlatformChecker{})
+	graph := builder.NewGraph()
+
+	nodes := make(map[string]NodeID)
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i"} {
+		nodes[name] = builder.AddNode(graph, newTestResource("KubEnv", name, "uid-"+name), 0, nil).ID
+	}
+	link := func(source, target string) {
+		require.NotNil(t, builder.AddEdge(graph, nodes[source], nodes[target], RelationTypeCustomRef, "spec."+target+"Ref", target+"Ref", 0.9))
+	}
+
+	// One three-node cluster, two independent 2-cycles linked by a plain
+	// edge, a node outside any cycle and a node referencing itself
+	link("a", "b")
+	link("b", "c")
+	link("c", "a")
+	link("c", "h")
+	link("d", "e")
+	link("e", "d")
+	link("e", "f")
+	link("f", "g")
+	link("g", "f")
+	link("i", "i")
+
+	ids := func(names ...string) []NodeID {
+		result := make([]NodeID, 0, len(names))
+		for _, name := range names {
+			result = append(result, nodes[name])
+		}
+		return result
+	}
+
+	cases := map[string]struct {
+		find func(*ResourceGraph) [][]NodeID
+		want [][]NodeID
+	}{
+		"CyclicOnly": {
+			find: StronglyConnectedComponents,
+			want: [][]NodeID{ids("a", "b", "c"), ids("d", "e"), ids("f", "g"), ids("i")},
+		},
+		"WithSingletons": {
+			find: StronglyConnectedComponentsWithSingletons,
+			want: [][]NodeID{ids("a", "b", "c"), ids("d", "e"), ids("f", "g"), ids("h"), ids("i")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			for i := 0; i < 20; i++ {
+				assert.Equal(t, tc.want, tc.find(graph))
+			}
+		})
+	}
+
+	assert.Nil(t, StronglyConnectedComponents(nil))
+}
+
+func BenchmarkShortestPath(b *testing.B) {
+	const nodeCount = 5000
+
+	builder := NewDefaultGraphBuilder(testPlatformChecker{})
+	graph := builder.NewGraph()
+
+	nodes := make([]NodeID, nodeCount)
+	for i := range nodes {
+		name := fmt.Sprintf("node-%d", i)
+		nodes[i] = builder.AddNode(graph, newTestResource("KubEnv", name, "uid-"+name), 0, nil).ID
+	}
+	// A chain with shortcuts, so most nodes are relaxed several times
+	for i := range nodes {
+		for _, step := range []int{1, 7, 31} {
+			if target := i + step; target < nodeCount {
+				builder.AddEdge(graph, nodes[i], nodes[target], RelationTypeCustomRef, fmt.Sprintf("spec.ref%d", step), "ref", 0.5+float64(step%3)/10)
+			}
+		}
+	}
+
+	traverser := NewDefaultGraphTraverser(nil)
+	traverser.SetWeightFunc(ConfidenceWeight)
+	source, target := nodes[0], nodes[nodeCount-1]
+	require.True(b, traverser.ShortestPath(graph, source, target).Found)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		traverser.ShortestPath(graph, source, target)
+	}
+}
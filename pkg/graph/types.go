@@ -7,9 +7,38 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 )
 
+// Clock provides the current time to graph components
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+}
+
+// realClock implements Clock using the system time
+type realClock struct{}
+
+// Now returns the current system time
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// NewRealClock returns a Clock backed by the system time
+func NewRealClock() Clock {
+	return realClock{}
+}
+
 // NodeID represents a unique identifier for a node in the resource graph
 type NodeID string
 
+// ResolvedClusterAnnotation names, on a resource resolved from a cluster other
+// than the one being traversed, the cluster it was resolved from
+const ResolvedClusterAnnotation = "kubecore.io/resolved-cluster"
+
+// ResourceCluster returns the cluster a resource was resolved from, or "" for
+// the cluster being traversed
+func ResourceCluster(resource *unstructured.Unstructured) string {
+	return resource.GetAnnotations()[ResolvedClusterAnnotation]
+}
+
 // EdgeID represents a unique identifier for an edge in the resource graph
 type EdgeID string
 
@@ -29,6 +58,13 @@ const (
 	RelationTypeServiceRef RelationType = "serviceRef"
 	// RelationTypePVCRef represents a PersistentVolumeClaim reference relationship
 	RelationTypePVCRef RelationType = "pvcRef"
+	// RelationTypeDefinedBy links a composite resource to its CompositeResourceDefinition
+	RelationTypeDefinedBy RelationType = "definedBy"
+	// RelationTypeComposedBy links a composite resource to its Composition
+	RelationTypeComposedBy RelationType = "composedBy"
+	// RelationTypeContracted links two platform resources related through
+	// resources left out of a platform subgraph
+	RelationTypeContracted RelationType = "contracted"
 )
 
 // ResourceGraph represents a directed acyclic graph of Kubernetes resources
@@ -122,6 +158,13 @@ type GraphMetadata struct {
 	// TotalEdges is the total number of edges in the graph
 	TotalEdges int
 
+	// MaxEdges caps the number of edges the builder will add; zero means
+	// unlimited
+	MaxEdges int
+
+	// EdgesTruncated indicates an edge was dropped because MaxEdges was reached
+	EdgesTruncated bool
+
 	// MaxDepth is the maximum depth reached during traversal
 	MaxDepth int
 
@@ -163,8 +206,27 @@ type NodeMetadata struct {
 
 	// Name is the name of this resource
 	Name string
+
+	// DiscoverySource records how the resource came to be in the graph
+	DiscoverySource DiscoverySource
+
+	// Category groups external resources in reports, e.g. "networking"; it is
+	// empty for platform resources
+	Category string
 }
 
+// DiscoverySource records how a node was added to the graph
+type DiscoverySource string
+
+const (
+	// DiscoverySourceRoot marks the resources traversal started from
+	DiscoverySourceRoot DiscoverySource = "root"
+	// DiscoverySourceReference marks resources reached by following a reference
+	DiscoverySourceReference DiscoverySource = "reference"
+	// DiscoverySourceSelector marks resources seeded by an augmentation selector
+	DiscoverySourceSelector DiscoverySource = "selector"
+)
+
 // EdgeMetadata contains metadata about a specific edge
 type EdgeMetadata struct {
 	// ReferenceValue is the actual value of the reference field
@@ -181,6 +243,29 @@ type EdgeMetadata struct {
 
 	// ResolutionError contains any error that occurred during reference resolution
 	ResolutionError error
+
+	// Provenance records every detection that contributed to this edge
+	Provenance []EdgeProvenance
+
+	// Reversed indicates the edge points from the referenced resource to the
+	// resource holding the reference, e.g. from an owner to what it owns
+	Reversed bool
+
+	// ContractedThrough lists, in order, the nodes a contracted edge stands
+	// in for
+	ContractedThrough []NodeID
+}
+
+// EdgeProvenance describes one detection that produced an edge
+type EdgeProvenance struct {
+	// DetectionMethod indicates how the reference was detected
+	DetectionMethod string
+
+	// Confidence is the confidence reported by this detection
+	Confidence float64
+
+	// MatchedPattern is the reference pattern that matched, if any
+	MatchedPattern string
 }
 
 // Cycle represents a detected cycle in the graph
@@ -216,6 +301,16 @@ type SkippedReference struct {
 	TargetGroup string
 }
 
+// Reasons a reference is skipped
+const (
+	// SkipReasonTargetNotDiscovered marks references whose target was not found
+	SkipReasonTargetNotDiscovered = "target_not_discovered"
+
+	// SkipReasonBelowConfidenceFloor marks references to discovered targets
+	// whose confidence is below the graph's confidence floor
+	SkipReasonBelowConfidenceFloor = "below_confidence_floor"
+)
+
 // TraversalStats contains statistics about graph traversal
 type TraversalStats struct {
 	// TotalTraversalTime is the total time spent building the graph
@@ -0,0 +1,41 @@
+package graph
+
+import (
+	"sort"
+	"strings"
+)
+
+// UnresolvedEdges returns the IDs of edges whose target was never resolved to
+// a resource: the target node is missing, has no backing Resource, or has a
+// synthesized ID with an empty name. A complete graph has none. The IDs are
+// sorted so the result can be compared across runs.
+func (g *ResourceGraph) UnresolvedEdges() []EdgeID {
+	if g == nil {
+		return nil
+	}
+
+	var unresolved []EdgeID
+	for edgeID, edge := range g.Edges {
+		if !g.isResolvedNode(edge.Target) {
+			unresolved = append(unresolved, edgeID)
+		}
+	}
+
+	sort.Slice(unresolved, func(i, j int) bool {
+		return unresolved[i] < unresolved[j]
+	})
+
+	return unresolved
+}
+
+// isResolvedNode reports whether a node exists and is backed by a named resource
+func (g *ResourceGraph) isResolvedNode(nodeID NodeID) bool {
+	node, exists := g.Nodes[nodeID]
+	if !exists || node.Resource == nil {
+		return false
+	}
+
+	// Node IDs end with the resource name, so a trailing separator means the
+	// ID was synthesized for a target without one
+	return !strings.HasSuffix(string(nodeID), "/") && node.Resource.GetName() != ""
+}
@@ -0,0 +1,48 @@
+package graph
+
+import "math"
+
+// WeightFunc returns the weight of an edge for path searches; shorter paths
+// have a lower total weight
+type WeightFunc func(edge *ResourceEdge) float64
+
+// maxEdgeWeight bounds ConfidenceWeight so distances stay finite. It is the
+// ConfidenceWeight of an edge with a confidence of 1e-6.
+var maxEdgeWeight = -math.Log(1e-6)
+
+// UniformWeight weighs every edge 1, so the shortest path has the fewest hops
+func UniformWeight(_ *ResourceEdge) float64 {
+	return 1
+}
+
+// ConfidenceWeight weighs an edge -ln(confidence), so the shortest path is the
+// one whose confidences have the highest product. A fully confident edge
+// weighs 0. Every confidence below 1e-6, including 0 or less, weighs the most
+// an edge can, so paths through such edges are not told apart by confidence.
+func ConfidenceWeight(edge *ResourceEdge) float64 {
+	switch {
+	case edge.Confidence >= 1:
+		return 0
+	case !(edge.Confidence > 0):
+		// Also catches NaN, which no comparison holds for
+		return maxEdgeWeight
+	default:
+		return math.Min(-math.Log(edge.Confidence), maxEdgeWeight)
+	}
+}
+
+// edgeWeight returns the traverser's weight for an edge, or false when the
+// weight is negative, infinite or NaN, which would break Dijkstra, so the edge
+// cannot be followed
+func (gt *DefaultGraphTraverser) edgeWeight(edge *ResourceEdge) (float64, bool) {
+	weight := gt.weightFunc
+	if weight == nil {
+		weight = UniformWeight
+	}
+
+	value := weight(edge)
+	if math.IsNaN(value) || math.IsInf(value, 0) || value < 0 {
+		return 0, false
+	}
+	return value, true
+}
@@ -20,6 +20,9 @@ func LoadConfigFromEnvironment() *types.RegistryConfig {
 		CacheEnabled:     true,
 		CacheTTL:         types.DefaultCacheTTL,
 		LogLevel:         "info",
+
+		FetchRetryBackoff: types.DefaultFetchRetryBackoff,
+		FetchRetryJitter:  types.DefaultFetchRetryJitter,
 	}
 
 	// Registry mode
@@ -73,6 +76,32 @@ func LoadConfigFromEnvironment() *types.RegistryConfig {
 		}
 	}
 
+	// Allowed kinds
+	if kinds := os.Getenv("ALLOWED_KINDS"); kinds != "" {
+		for _, kind := range strings.Split(kinds, ",") {
+			if kind = strings.TrimSpace(kind); kind != "" {
+				config.AllowedKinds = append(config.AllowedKinds, kind)
+			}
+		}
+	}
+
+	// Fetch retries
+	if retries := os.Getenv("FETCH_MAX_RETRIES"); retries != "" {
+		if n, err := strconv.Atoi(retries); err == nil && n >= 0 {
+			config.FetchMaxRetries = n
+		}
+	}
+	if backoff := os.Getenv("FETCH_RETRY_BACKOFF"); backoff != "" {
+		if duration, err := time.ParseDuration(backoff); err == nil {
+			config.FetchRetryBackoff = duration
+		}
+	}
+	if jitter := os.Getenv("FETCH_RETRY_JITTER"); jitter != "" {
+		if fraction, err := strconv.ParseFloat(jitter, 64); err == nil && fraction >= 0 {
+			config.FetchRetryJitter = fraction
+		}
+	}
+
 	// Log level
 	if level := os.Getenv("LOG_LEVEL"); level != "" {
 		config.LogLevel = level
@@ -2,6 +2,7 @@ package initialization
 
 import (
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -36,6 +37,7 @@ func TestLoadConfigFromEnvironmentWithCustomValues(t *testing.T) {
 	os.Setenv("DISCOVERY_TIMEOUT", "10s")
 	os.Setenv("FALLBACK_ENABLED", "false")
 	os.Setenv("CACHE_ENABLED", "false")
+	os.Setenv("ALLOWED_KINDS", "ConfigMap, Deployment.apps")
 
 	defer func() {
 		// Clean up environment variables
@@ -44,6 +46,7 @@ func TestLoadConfigFromEnvironmentWithCustomValues(t *testing.T) {
 		os.Unsetenv("DISCOVERY_TIMEOUT")
 		os.Unsetenv("FALLBACK_ENABLED")
 		os.Unsetenv("CACHE_ENABLED")
+		os.Unsetenv("ALLOWED_KINDS")
 	}()
 
 	config := LoadConfigFromEnvironment()
@@ -74,6 +77,10 @@ func TestLoadConfigFromEnvironmentWithCustomValues(t *testing.T) {
 	if config.CacheEnabled {
 		t.Error("Expected cache to be disabled")
 	}
+
+	if strings.Join(config.AllowedKinds, ",") != "ConfigMap,Deployment.apps" {
+		t.Errorf("Expected allowed kinds ConfigMap and Deployment.apps, got %v", config.AllowedKinds)
+	}
 }
 
 func TestLoadConfigInvalidValues(t *testing.T) {
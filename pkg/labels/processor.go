@@ -88,16 +88,99 @@ func (p *Processor) ProcessLabels(ctx context.Context, xr *resource.Composite, c
 		return errors.Wrapf(err, "failed to apply merge strategy")
 	}
 
+	// Apply label removals after adds and merges
+	removed, err := p.applyRemovals(xr, finalLabels, config)
+	if err != nil {
+		return errors.Wrapf(err, "failed to apply label removals")
+	}
+
 	// Update XR labels
 	xr.Resource.SetLabels(finalLabels)
 
 	p.log.Info("XR label processing completed",
 		"total_labels_applied", len(finalLabels),
-		"new_labels_added", len(newLabels))
+		"new_labels_added", len(newLabels),
+		"labels_removed", removed)
 
 	return nil
 }
 
+// applyRemovals deletes labels listed in RemoveLabels and ConditionalRemovals
+// from the final label set, returning the number of labels removed
+func (p *Processor) applyRemovals(xr *resource.Composite, labels map[string]string, config *v1beta1.XRLabelConfig) (int, error) {
+	enforceSet := make(map[string]bool)
+	for _, label := range config.EnforceLabels {
+		enforceSet[label] = true
+	}
+
+	removed := 0
+	remove := func(key string) error {
+		if _, exists := labels[key]; !exists {
+			return nil
+		}
+		if enforceSet[key] {
+			return errors.ValidationError(fmt.Sprintf("cannot remove enforced label '%s'", key))
+		}
+		delete(labels, key)
+		removed++
+		return nil
+	}
+
+	for _, key := range config.RemoveLabels {
+		if err := remove(key); err != nil {
+			return removed, err
+		}
+	}
+
+	for i := range config.ConditionalRemovals {
+		removal := &config.ConditionalRemovals[i]
+		matches, err := p.removalConditionMatches(xr, labels, removal)
+		if err != nil {
+			return removed, errors.Wrapf(err, "failed to evaluate removal condition for label '%s'", removal.Key)
+		}
+		if !matches {
+			continue
+		}
+		if err := remove(removal.Key); err != nil {
+			return removed, err
+		}
+	}
+
+	return removed, nil
+}
+
+// removalConditionMatches reports whether a conditional removal applies
+func (p *Processor) removalConditionMatches(xr *resource.Composite, labels map[string]string, removal *v1beta1.LabelRemoval) (bool, error) {
+	switch removal.Condition {
+	case "", v1beta1.RemovalConditionAlways:
+		return true, nil
+
+	case v1beta1.RemovalConditionXRFieldMissing:
+		if removal.SourcePath == "" {
+			return false, errors.ValidationError("sourcePath required for xr-field-missing condition")
+		}
+		_, err := p.fieldExtractor.ExtractFromXR(xr.Resource.Object, removal.SourcePath)
+		return err != nil, nil
+
+	case v1beta1.RemovalConditionXRFieldEquals:
+		if removal.SourcePath == "" {
+			return false, errors.ValidationError("sourcePath required for xr-field-equals condition")
+		}
+		value, err := p.fieldExtractor.ExtractFromXR(xr.Resource.Object, removal.SourcePath)
+		if err != nil {
+			return false, nil
+		}
+		return value == removal.Value, nil
+
+	case v1beta1.RemovalConditionLabelMismatch:
+		current, exists := labels[removal.Key]
+		return exists && current != removal.Value, nil
+
+	default:
+		return false, errors.ValidationError(fmt.Sprintf("unsupported removal condition: %s", removal.Condition))
+	}
+}
+
 // processDynamicLabel processes a single dynamic label
 func (p *Processor) processDynamicLabel(ctx context.Context, xr *resource.Composite, label *v1beta1.DynamicLabel) (string, error) {
 	var value string
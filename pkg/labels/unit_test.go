@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/crossplane/function-sdk-go/logging"
+	"github.com/crossplane/function-sdk-go/resource"
+	"github.com/crossplane/function-sdk-go/resource/composite"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -372,6 +374,103 @@ func TestMergeStrategies(t *testing.T) {
 	}
 }
 
+func TestLabelRemovals(t *testing.T) {
+	log := logging.NewNopLogger()
+	processor := NewProcessor(log, "test-namespace")
+
+	xr := &resource.Composite{Resource: composite.New()}
+	xr.Resource.Object = map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name": "test-xr",
+		},
+		"spec": map[string]interface{}{
+			"tier": "legacy",
+		},
+	}
+
+	tests := []struct {
+		name        string
+		config      *v1beta1.XRLabelConfig
+		expected    map[string]string
+		expectError bool
+	}{
+		{
+			name: "static removal",
+			config: &v1beta1.XRLabelConfig{
+				RemoveLabels: []string{"stale", "not-present"},
+			},
+			expected: map[string]string{
+				"scope": "namespace-staging",
+				"team":  "platform",
+			},
+		},
+		{
+			name: "label mismatch removes stale value",
+			config: &v1beta1.XRLabelConfig{
+				ConditionalRemovals: []v1beta1.LabelRemoval{
+					{Key: "scope", Condition: v1beta1.RemovalConditionLabelMismatch, Value: "namespace-production"},
+				},
+			},
+			expected: map[string]string{
+				"stale": "true",
+				"team":  "platform",
+			},
+		},
+		{
+			name: "label mismatch keeps current value",
+			config: &v1beta1.XRLabelConfig{
+				ConditionalRemovals: []v1beta1.LabelRemoval{
+					{Key: "scope", Condition: v1beta1.RemovalConditionLabelMismatch, Value: "namespace-staging"},
+				},
+			},
+			expected: map[string]string{
+				"scope": "namespace-staging",
+				"stale": "true",
+				"team":  "platform",
+			},
+		},
+		{
+			name: "xr field equals and missing",
+			config: &v1beta1.XRLabelConfig{
+				ConditionalRemovals: []v1beta1.LabelRemoval{
+					{Key: "stale", Condition: v1beta1.RemovalConditionXRFieldEquals, SourcePath: "spec.tier", Value: "legacy"},
+					{Key: "team", Condition: v1beta1.RemovalConditionXRFieldMissing, SourcePath: "spec.team"},
+				},
+			},
+			expected: map[string]string{
+				"scope": "namespace-staging",
+			},
+		},
+		{
+			name: "enforced label cannot be removed",
+			config: &v1beta1.XRLabelConfig{
+				RemoveLabels:  []string{"team"},
+				EnforceLabels: []string{"team"},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			labels := map[string]string{
+				"scope": "namespace-staging",
+				"stale": "true",
+				"team":  "platform",
+			}
+
+			_, err := processor.applyRemovals(xr, labels, tt.config)
+
+			if tt.expectError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, labels)
+			}
+		})
+	}
+}
+
 func TestNamespaceDetectionStrategies(t *testing.T) {
 	log := logging.NewNopLogger()
 
@@ -0,0 +1,55 @@
+// Package logfields defines the structured logging keys shared by the
+// discovery subsystems, so their logs can be aggregated and queried with the
+// same field names.
+package logfields
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/crossplane/function-sdk-go/logging"
+)
+
+// Standard log keys
+const (
+	// Component names the subsystem that logged the entry
+	Component = "component"
+
+	// ResourceGVK is the group, version and kind of the resource being handled
+	ResourceGVK = "resource.gvk"
+	// ResourceName is the name of the resource being handled
+	ResourceName = "resource.name"
+	// ResourceNamespace is the namespace of the resource being handled
+	ResourceNamespace = "resource.namespace"
+	// ResourceID is the traversal ID of the resource being handled
+	ResourceID = "resource.id"
+
+	// FieldPath is the path of the reference field being handled
+	FieldPath = "reference.fieldPath"
+
+	// Depth is the traversal depth
+	Depth = "depth"
+)
+
+// Subsystem component names
+const (
+	ComponentTraversalEngine   = "traversal-engine"
+	ComponentBatchOptimizer    = "batch-optimizer"
+	ComponentReferenceResolver = "reference-resolver"
+	ComponentReferenceDetector = "reference-detector"
+)
+
+// ForComponent returns a logger that tags every entry with the component
+func ForComponent(logger logging.Logger, component string) logging.Logger {
+	return logger.WithValues(Component, component)
+}
+
+// Resource returns the standard key/value pairs identifying a resource,
+// followed by any additional pairs
+func Resource(resource *unstructured.Unstructured, keysAndValues ...interface{}) []interface{} {
+	fields := []interface{}{
+		ResourceGVK, resource.GroupVersionKind().String(),
+		ResourceName, resource.GetName(),
+		ResourceNamespace, resource.GetNamespace(),
+	}
+	return append(fields, keysAndValues...)
+}
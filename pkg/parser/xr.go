@@ -111,6 +111,22 @@ func (p *DefaultXRParser) validateRequest(request v1beta1.ResourceRequest, index
 	return nil
 }
 
+// ValidateUniqueInto rejects requests that share an 'into' name, since a later
+// result would silently overwrite an earlier one
+func ValidateUniqueInto(requests []v1beta1.ResourceRequest) error {
+	firstIndex := make(map[string]int, len(requests))
+	for i, request := range requests {
+		if first, exists := firstIndex[request.Into]; exists {
+			return errors.ValidationError(
+				fmt.Sprintf("fetchResources[%d].into '%s' duplicates fetchResources[%d]; use distinct names or set mergeDuplicateInto to collect both results",
+					i, request.Into, first))
+		}
+		firstIndex[request.Into] = i
+	}
+
+	return nil
+}
+
 // isValidFieldName checks if a string is a valid Go template field name
 func isValidFieldName(name string) bool {
 	if len(name) == 0 {
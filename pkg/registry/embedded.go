@@ -2,6 +2,8 @@ package registry
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/crossplane/function-kubecore-schema-registry/pkg/errors"
@@ -84,6 +86,123 @@ func (r *EmbeddedRegistry) RegisterType(rt *ResourceType) {
 	r.resourceTypes[key] = rt
 }
 
+// Validate checks that every registered type is complete and that no
+// group/version/kind or group/version/plural is registered twice
+func (r *EmbeddedRegistry) Validate() error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var problems []string
+	gvks := make(map[string]string)
+	gvrs := make(map[string]string)
+
+	for key, rt := range r.resourceTypes {
+		if rt == nil {
+			problems = append(problems, fmt.Sprintf("%s: nil resource type", key))
+			continue
+		}
+
+		if rt.Kind == "" {
+			problems = append(problems, fmt.Sprintf("%s: missing kind", key))
+		}
+		if rt.Plural == "" {
+			problems = append(problems, fmt.Sprintf("%s: missing plural", key))
+		}
+		if rt.Version == "" {
+			problems = append(problems, fmt.Sprintf("%s: missing version", key))
+		}
+
+		if expected := fmt.Sprintf("%s/%s", rt.APIVersion, rt.Kind); key != expected {
+			problems = append(problems, fmt.Sprintf("%s: registered under key that does not match %s", key, expected))
+		}
+
+		expectedAPIVersion := rt.Version
+		if rt.Group != "" {
+			expectedAPIVersion = rt.Group + "/" + rt.Version
+		}
+		if rt.APIVersion != expectedAPIVersion {
+			problems = append(problems, fmt.Sprintf("%s: apiVersion %q does not match group/version %q", key, rt.APIVersion, expectedAPIVersion))
+		}
+
+		gvk := fmt.Sprintf("%s/%s/%s", rt.Group, rt.Version, rt.Kind)
+		if other, exists := gvks[gvk]; exists {
+			problems = append(problems, fmt.Sprintf("%s: duplicate GVK %s also registered as %s", key, gvk, other))
+		} else {
+			gvks[gvk] = key
+		}
+
+		if rt.Plural != "" {
+			gvr := fmt.Sprintf("%s/%s/%s", rt.Group, rt.Version, rt.Plural)
+			if other, exists := gvrs[gvr]; exists {
+				problems = append(problems, fmt.Sprintf("%s: duplicate resource %s also registered as %s", key, gvr, other))
+			} else {
+				gvrs[gvr] = key
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		// Map iteration order is random, keep the message stable
+		sort.Strings(problems)
+		return errors.ValidationError(fmt.Sprintf("registry validation failed: %s", strings.Join(problems, "; ")))
+	}
+
+	return nil
+}
+
+// HealthCheck returns type and reference counts along with the validation result
+func (r *EmbeddedRegistry) HealthCheck() *HealthStatus {
+	status := &HealthStatus{
+		Healthy:      true,
+		TypesByGroup: make(map[string]int),
+	}
+
+	if err := r.Validate(); err != nil {
+		status.Healthy = false
+		status.Error = err.Error()
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, rt := range r.resourceTypes {
+		if rt == nil {
+			continue
+		}
+
+		status.TotalTypes++
+		if rt.Namespaced {
+			status.NamespacedTypes++
+		} else {
+			status.ClusterScopedTypes++
+		}
+
+		group := rt.Group
+		if group == "" {
+			group = "core"
+		}
+		status.TypesByGroup[group]++
+
+		for _, field := range rt.Fields {
+			status.ReferenceCount += countFieldReferences(field)
+		}
+	}
+
+	return status
+}
+
+// countFieldReferences counts references declared on a field and its nested schemas
+func countFieldReferences(field FieldSchema) int {
+	count := len(field.References)
+	for _, property := range field.Properties {
+		count += countFieldReferences(property)
+	}
+	if field.Items != nil {
+		count += countFieldReferences(*field.Items)
+	}
+	return count
+}
+
 // loadBuiltinTypes loads the predefined Kubernetes and KubeCore resource types
 func (r *EmbeddedRegistry) loadBuiltinTypes() {
 	// Core Kubernetes types
@@ -0,0 +1,75 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbeddedRegistryValidate(t *testing.T) {
+	r := NewEmbeddedRegistry()
+
+	require.NoError(t, r.Validate())
+
+	health := r.HealthCheck()
+	assert.True(t, health.Healthy)
+	assert.Empty(t, health.Error)
+	assert.Equal(t, health.TotalTypes, health.NamespacedTypes+health.ClusterScopedTypes)
+	assert.Positive(t, health.TypesByGroup["platform.kubecore.io"])
+	assert.Positive(t, health.ReferenceCount)
+}
+
+func TestEmbeddedRegistryValidateCorrupted(t *testing.T) {
+	cases := map[string]struct {
+		corrupt func(r *EmbeddedRegistry)
+		reason  string
+	}{
+		"MissingPlural": {
+			corrupt: func(r *EmbeddedRegistry) {
+				r.RegisterType(&ResourceType{
+					APIVersion: "example.kubecore.io/v1",
+					Kind:       "Widget",
+					Group:      "example.kubecore.io",
+					Version:    "v1",
+				})
+			},
+			reason: "missing plural",
+		},
+		"DuplicateGVK": {
+			corrupt: func(r *EmbeddedRegistry) {
+				pod, err := r.GetResourceType("v1", "Pod")
+				require.NoError(t, err)
+				r.resourceTypes["v1/PodAlias"] = pod
+			},
+			reason: "duplicate GVK",
+		},
+		"MismatchedAPIVersion": {
+			corrupt: func(r *EmbeddedRegistry) {
+				r.RegisterType(&ResourceType{
+					APIVersion: "v1",
+					Kind:       "Gadget",
+					Group:      "example.kubecore.io",
+					Version:    "v1",
+					Plural:     "gadgets",
+				})
+			},
+			reason: "does not match group/version",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := NewEmbeddedRegistry()
+			tc.corrupt(r)
+
+			err := r.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.reason)
+
+			health := r.HealthCheck()
+			assert.False(t, health.Healthy)
+			assert.Contains(t, health.Error, tc.reason)
+		})
+	}
+}
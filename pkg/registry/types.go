@@ -56,4 +56,21 @@ type Registry interface {
 
 	// GetReferences returns all reference relationships for a resource type
 	GetReferences(apiVersion, kind string) ([]ResourceReference, error)
+
+	// Validate checks the registry for internal consistency
+	Validate() error
+
+	// HealthCheck returns a summary of the registry contents
+	HealthCheck() *HealthStatus
+}
+
+// HealthStatus summarizes the state of a registry
+type HealthStatus struct {
+	Healthy            bool           `json:"healthy"`
+	TotalTypes         int            `json:"totalTypes"`
+	NamespacedTypes    int            `json:"namespacedTypes"`
+	ClusterScopedTypes int            `json:"clusterScopedTypes"`
+	ReferenceCount     int            `json:"referenceCount"`
+	TypesByGroup       map[string]int `json:"typesByGroup,omitempty"`
+	Error              string         `json:"error,omitempty"`
 }
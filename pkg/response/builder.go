@@ -13,6 +13,10 @@ import (
 	"github.com/crossplane/function-kubecore-schema-registry/pkg/errors"
 )
 
+// DiscoveryErrorsContextKey is the response context key holding recoverable
+// discovery errors, such as references that could not be resolved
+const DiscoveryErrorsContextKey = "kubecore-schema-registry.fn.kubecore.platform.io/discovery-errors"
+
 // Builder provides methods to build structured responses for Go templates
 type Builder interface {
 	// BuildContext creates the context data structure for templates
@@ -121,6 +125,11 @@ func (b *DefaultBuilder) BuildContext(fetchResult *discovery.FetchResult) (map[s
 		context["multiResources"] = multiResourcesContext
 	}
 
+	// Add recoverable discovery errors if present
+	if len(fetchResult.DiscoveryErrors) > 0 {
+		context["discoveryErrors"] = b.buildDiscoveryErrors(fetchResult.DiscoveryErrors)
+	}
+
 	return context, nil
 }
 
@@ -151,6 +160,17 @@ func (b *DefaultBuilder) SetContext(rsp *fnv1.RunFunctionResponse, fetchResult *
 		return errors.Wrap(err, "failed to create structured context")
 	}
 
+	// Always set discovery errors so pipelines can rely on the key existing
+	discoveryErrors := make([]interface{}, 0, len(fetchResult.DiscoveryErrors))
+	for _, discoveryError := range b.buildDiscoveryErrors(fetchResult.DiscoveryErrors) {
+		discoveryErrors = append(discoveryErrors, discoveryError)
+	}
+	discoveryErrorsList, err := structpb.NewList(discoveryErrors)
+	if err != nil {
+		return errors.Wrap(err, "failed to create discovery errors context")
+	}
+	response.SetContextKey(rsp, DiscoveryErrorsContextKey, structpb.NewListValue(discoveryErrorsList))
+
 	// Also set individual resource contexts for direct access
 	for into, fetchedResource := range fetchResult.Resources {
 		resourceContext := b.buildResourceContext(fetchedResource)
@@ -250,6 +270,20 @@ func (b *DefaultBuilder) buildResourceContext(fetchedResource *discovery.Fetched
 	return context
 }
 
+// buildDiscoveryErrors creates the context entries for recoverable discovery errors
+func (b *DefaultBuilder) buildDiscoveryErrors(discoveryErrors []discovery.DiscoveryError) []map[string]interface{} {
+	entries := make([]map[string]interface{}, 0, len(discoveryErrors))
+	for _, discoveryError := range discoveryErrors {
+		entries = append(entries, map[string]interface{}{
+			"type":     discoveryError.Type,
+			"resource": discoveryError.Resource,
+			"message":  discoveryError.Message,
+			"depth":    discoveryError.Depth,
+		})
+	}
+	return entries
+}
+
 // buildErrorSummary creates a summary of errors for the context
 func (b *DefaultBuilder) buildErrorSummary(fetchErrors []*discovery.FetchError) []map[string]interface{} {
 	var errors []map[string]interface{}
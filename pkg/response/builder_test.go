@@ -0,0 +1,55 @@
+package response
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	fnv1 "github.com/crossplane/function-sdk-go/proto/v1"
+
+	"github.com/crossplane/function-kubecore-schema-registry/pkg/discovery"
+)
+
+func TestSetContextDiscoveryErrors(t *testing.T) {
+	cases := map[string]struct {
+		discoveryErrors []discovery.DiscoveryError
+	}{
+		"NoErrors": {},
+		"ReferenceResolutionFailure": {
+			discoveryErrors: []discovery.DiscoveryError{
+				{
+					Type:     "reference_resolution",
+					Resource: "platform.kubecore.io/v1/KubEnv/default/env",
+					Message:  "failed to resolve reference to KubeCluster/missing",
+					Depth:    1,
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fetchResult := &discovery.FetchResult{
+				Resources:       map[string]*discovery.FetchedResource{},
+				DiscoveryErrors: tc.discoveryErrors,
+			}
+			rsp := &fnv1.RunFunctionResponse{}
+
+			require.NoError(t, NewDefaultBuilder().SetContext(rsp, fetchResult))
+
+			value, ok := rsp.GetContext().GetFields()[DiscoveryErrorsContextKey]
+			require.True(t, ok, "discovery errors key should always be set")
+
+			entries := value.GetListValue().GetValues()
+			require.Len(t, entries, len(tc.discoveryErrors))
+			for i, expected := range tc.discoveryErrors {
+				fields := entries[i].GetStructValue().GetFields()
+				assert.Equal(t, expected.Type, fields["type"].GetStringValue())
+				assert.Equal(t, expected.Resource, fields["resource"].GetStringValue())
+				assert.Equal(t, expected.Message, fields["message"].GetStringValue())
+				assert.Equal(t, float64(expected.Depth), fields["depth"].GetNumberValue())
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package response
+
+import (
+	"fmt"
+
+	fnv1 "github.com/crossplane/function-sdk-go/proto/v1"
+
+	"github.com/crossplane/function-kubecore-schema-registry/input/v1beta1"
+	"github.com/crossplane/function-kubecore-schema-registry/pkg/discovery"
+)
+
+// SetExtraResourceRequirements lists the fetched resources as extra resource
+// requirements on the response, so Crossplane fetches them itself and passes
+// them to the next invocation in the request's extra resources. Resources
+// found by a plain label selector are required through that selector under
+// their 'into' name; every other resource is required by name under its
+// 'into' name, suffixed with its index for requests that matched several.
+func SetExtraResourceRequirements(rsp *fnv1.RunFunctionResponse, fetchResult *discovery.FetchResult) {
+	selectors := buildExtraResourceSelectors(fetchResult)
+	if len(selectors) == 0 {
+		return
+	}
+
+	if rsp.Requirements == nil {
+		rsp.Requirements = &fnv1.Requirements{}
+	}
+	if rsp.Requirements.ExtraResources == nil {
+		rsp.Requirements.ExtraResources = make(map[string]*fnv1.ResourceSelector, len(selectors))
+	}
+	for key, selector := range selectors {
+		rsp.Requirements.ExtraResources[key] = selector
+	}
+}
+
+// buildExtraResourceSelectors builds the extra resource selectors keyed by
+// requirement name for every successfully fetched resource
+func buildExtraResourceSelectors(fetchResult *discovery.FetchResult) map[string]*fnv1.ResourceSelector {
+	selectors := make(map[string]*fnv1.ResourceSelector)
+	if fetchResult == nil {
+		return selectors
+	}
+
+	for into, fetchedResource := range fetchResult.Resources {
+		if fetchedResource == nil || fetchedResource.Resource == nil {
+			continue
+		}
+		selectors[into] = matchNameSelector(fetchedResource)
+	}
+
+	for into, fetchedResources := range fetchResult.MultiResources {
+		for i, fetchedResource := range fetchedResources {
+			if fetchedResource == nil || fetchedResource.Resource == nil {
+				continue
+			}
+			if selector := matchLabelsSelector(fetchedResource.Request); selector != nil {
+				selectors[into] = selector
+				continue
+			}
+			selectors[fmt.Sprintf("%s-%d", into, i)] = matchNameSelector(fetchedResource)
+		}
+	}
+
+	return selectors
+}
+
+// matchNameSelector selects exactly the fetched resource by name
+func matchNameSelector(fetchedResource *discovery.FetchedResource) *fnv1.ResourceSelector {
+	return &fnv1.ResourceSelector{
+		ApiVersion: fetchedResource.Resource.GetAPIVersion(),
+		Kind:       fetchedResource.Resource.GetKind(),
+		Match:      &fnv1.ResourceSelector_MatchName{MatchName: fetchedResource.Resource.GetName()},
+	}
+}
+
+// matchLabelsSelector returns a label selector equivalent to the request, or
+// nil when the request cannot be expressed as exact label matches
+func matchLabelsSelector(req v1beta1.ResourceRequest) *fnv1.ResourceSelector {
+	if req.MatchType != v1beta1.MatchTypeLabel || req.Selector == nil || req.Selector.Labels == nil {
+		return nil
+	}
+
+	labels := req.Selector.Labels
+	if len(labels.MatchLabels) == 0 || len(labels.MatchExpressions) > 0 {
+		return nil
+	}
+
+	matchLabels := make(map[string]string, len(labels.MatchLabels))
+	for key, value := range labels.MatchLabels {
+		matchLabels[key] = value
+	}
+
+	return &fnv1.ResourceSelector{
+		ApiVersion: req.APIVersion,
+		Kind:       req.Kind,
+		Match:      &fnv1.ResourceSelector_MatchLabels{MatchLabels: &fnv1.MatchLabels{Labels: matchLabels}},
+	}
+}
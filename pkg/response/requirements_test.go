@@ -0,0 +1,77 @@
+package response
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	fnv1 "github.com/crossplane/function-sdk-go/proto/v1"
+
+	"github.com/crossplane/function-kubecore-schema-registry/input/v1beta1"
+	"github.com/crossplane/function-kubecore-schema-registry/pkg/discovery"
+)
+
+func TestSetExtraResourceRequirements(t *testing.T) {
+	newFetched := func(req v1beta1.ResourceRequest, apiVersion, kind, name string) *discovery.FetchedResource {
+		resource := &unstructured.Unstructured{}
+		resource.SetAPIVersion(apiVersion)
+		resource.SetKind(kind)
+		resource.SetName(name)
+		resource.SetNamespace("default")
+		return &discovery.FetchedResource{Request: req, Resource: resource}
+	}
+
+	labelRequest := v1beta1.ResourceRequest{
+		Into:       "clusters",
+		MatchType:  v1beta1.MatchTypeLabel,
+		APIVersion: "platform.kubecore.io/v1alpha1",
+		Kind:       "KubeCluster",
+		Selector: &v1beta1.Selector{
+			Labels: &v1beta1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}},
+		},
+	}
+	traversed := v1beta1.ResourceRequest{Into: "clusters", MatchType: v1beta1.MatchTypeDirect}
+
+	fetchResult := &discovery.FetchResult{
+		Resources: map[string]*discovery.FetchedResource{
+			"config": newFetched(v1beta1.ResourceRequest{Into: "config"}, "v1", "ConfigMap", "app-config"),
+			"missing": {
+				Request: v1beta1.ResourceRequest{Into: "missing"},
+			},
+		},
+		MultiResources: map[string][]*discovery.FetchedResource{
+			"clusters": {
+				newFetched(labelRequest, "platform.kubecore.io/v1alpha1", "KubeCluster", "prod-a"),
+				newFetched(labelRequest, "platform.kubecore.io/v1alpha1", "KubeCluster", "prod-b"),
+				newFetched(traversed, "v1", "Secret", "prod-a-creds"),
+			},
+		},
+	}
+
+	rsp := &fnv1.RunFunctionResponse{}
+	SetExtraResourceRequirements(rsp, fetchResult)
+
+	extra := rsp.GetRequirements().GetExtraResources()
+	require.Len(t, extra, 3)
+
+	assert.Equal(t, "v1", extra["config"].GetApiVersion())
+	assert.Equal(t, "ConfigMap", extra["config"].GetKind())
+	assert.Equal(t, "app-config", extra["config"].GetMatchName())
+
+	// Label-selected resources are required through their selector
+	assert.Equal(t, "platform.kubecore.io/v1alpha1", extra["clusters"].GetApiVersion())
+	assert.Equal(t, "KubeCluster", extra["clusters"].GetKind())
+	assert.Equal(t, map[string]string{"env": "prod"}, extra["clusters"].GetMatchLabels().GetLabels())
+
+	// Resources traversed into the same group are required by name
+	assert.Equal(t, "Secret", extra["clusters-2"].GetKind())
+	assert.Equal(t, "prod-a-creds", extra["clusters-2"].GetMatchName())
+}
+
+func TestSetExtraResourceRequirementsNothingFetched(t *testing.T) {
+	rsp := &fnv1.RunFunctionResponse{}
+	SetExtraResourceRequirements(rsp, &discovery.FetchResult{})
+	assert.Nil(t, rsp.GetRequirements())
+}
@@ -0,0 +1,54 @@
+package traversal
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// listAugmentationResources lists the resources matched by the augmentation
+// selectors that apply at the given depth. A selector that cannot be listed
+// is reported as a recoverable error and does not stop the others.
+func (te *DefaultTraversalEngine) listAugmentationResources(ctx context.Context, config *TraversalConfig, depth int) ([]*unstructured.Unstructured, []TraversalError) {
+	var resources []*unstructured.Unstructured
+	var errs []TraversalError
+
+	for _, selector := range config.AugmentationSelectors {
+		if len(selector.Depths) > 0 && !slices.Contains(selector.Depths, depth) {
+			continue
+		}
+
+		gvk := schema.FromAPIVersionAndKind(selector.APIVersion, selector.Kind)
+		gvr, _ := meta.UnsafeGuessKindToResource(gvk)
+
+		list, err := te.components.DynamicClient.Resource(gvr).Namespace(selector.Namespace).List(ctx, metav1.ListOptions{
+			LabelSelector: selector.LabelSelector,
+		})
+		if err != nil {
+			errs = append(errs, TraversalError{
+				Type:        TraversalErrorAPICall,
+				Message:     fmt.Sprintf("failed to list %s for augmentation selector: %v", gvk.Kind, err),
+				Depth:       depth,
+				Timestamp:   time.Now(),
+				Recoverable: true,
+				Context: map[string]interface{}{
+					"targetGVK":     gvk,
+					"labelSelector": selector.LabelSelector,
+				},
+			})
+			continue
+		}
+
+		for i := range list.Items {
+			resources = append(resources, &list.Items[i])
+		}
+	}
+
+	return resources, errs
+}
@@ -12,6 +12,8 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	"github.com/crossplane/function-sdk-go/logging"
+
+	"github.com/crossplane/function-kubecore-schema-registry/pkg/logfields"
 )
 
 // BatchOptimizer optimizes batch processing of resources during traversal
@@ -209,7 +211,7 @@ type DefaultBatchOptimizer struct {
 // NewDefaultBatchOptimizer creates a new default batch optimizer
 func NewDefaultBatchOptimizer(logger logging.Logger) *DefaultBatchOptimizer {
 	return &DefaultBatchOptimizer{
-		logger: logger,
+		logger: logfields.ForComponent(logger, logfields.ComponentBatchOptimizer),
 		stats: &BatchOptimizationStats{
 			BatchTypes:        make(map[BatchType]int),
 			DepthDistribution: make(map[int]int),
@@ -281,6 +283,7 @@ func (bo *DefaultBatchOptimizer) ProcessBatch(ctx context.Context, batch Resourc
 
 	bo.logger.Debug("Processing batch",
 		"batchID", batch.ID,
+		logfields.Depth, batch.Depth,
 		"resourceCount", len(batch.Resources),
 		"batchType", batch.BatchType,
 		"processor", processor.GetProcessorName())
@@ -377,14 +380,18 @@ func (bo *DefaultBatchOptimizer) ProcessBatches(ctx context.Context, batches []R
 		"totalBatches", len(batches),
 		"processor", processor.GetProcessorName())
 
-	// Sort batches by priority and depth
-	sortedBatches := make([]ResourceBatch, len(batches))
-	copy(sortedBatches, batches)
-	sort.Slice(sortedBatches, func(i, j int) bool {
-		if sortedBatches[i].Priority != sortedBatches[j].Priority {
-			return sortedBatches[i].Priority > sortedBatches[j].Priority // Higher priority first
+	// Order processing by priority and depth, keeping each batch's input index
+	// so results[i] is the result of batches[i]
+	order := make([]int, len(batches))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := batches[order[i]], batches[order[j]]
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority // Higher priority first
 		}
-		return sortedBatches[i].Depth < sortedBatches[j].Depth // Lower depth first
+		return a.Depth < b.Depth // Lower depth first
 	})
 
 	// Process batches with limited concurrency
@@ -398,8 +405,8 @@ func (bo *DefaultBatchOptimizer) ProcessBatches(ctx context.Context, batches []R
 	}
 	sem := make(chan struct{}, maxConcurrency)
 
-	for i, batch := range sortedBatches {
-		i, batch := i, batch // Capture loop variables
+	for _, index := range order {
+		index, batch := index, batches[index] // Capture loop variables
 		g.Go(func() error {
 			// Acquire semaphore
 			sem <- struct{}{}
@@ -410,7 +417,7 @@ func (bo *DefaultBatchOptimizer) ProcessBatches(ctx context.Context, batches []R
 				return fmt.Errorf("failed to process batch %s: %w", batch.ID, err)
 			}
 
-			results[i] = result
+			results[index] = result
 			return nil
 		})
 	}
@@ -0,0 +1,189 @@
+package traversal
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	functionerrors "github.com/crossplane/function-kubecore-schema-registry/pkg/errors"
+	"github.com/crossplane/function-kubecore-schema-registry/pkg/graph"
+	"github.com/crossplane/function-kubecore-schema-registry/pkg/logfields"
+)
+
+// frontierItem is a resource waiting to have its references followed
+type frontierItem struct {
+	resource *unstructured.Unstructured
+	depth    int
+
+	// priority is the product of the reference confidences on the path that
+	// discovered the resource, so long chains of weak references sink
+	priority float64
+
+	// seq breaks ties in the order resources were queued
+	seq int
+}
+
+// frontierQueue is a max-heap of frontier items by priority, then by
+// shallower depth, then by queue order
+type frontierQueue []*frontierItem
+
+func (q frontierQueue) Len() int { return len(q) }
+
+func (q frontierQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	if q[i].depth != q[j].depth {
+		return q[i].depth < q[j].depth
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q frontierQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *frontierQueue) Push(x interface{}) { *q = append(*q, x.(*frontierItem)) }
+
+func (q *frontierQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+// executeBestFirstTraversal follows references one resource at a time,
+// always expanding the queued resource whose discovery path has the highest
+// confidence. Resources matched by augmentation selectors are folded in the
+// first time their depth is reached and are expanded after referenced ones.
+// A confident chain can reach a resource deeper than a weaker, shorter one;
+// the resource is queued again when the shorter path reaches it, so best-first
+// discovers the same resources as breadth-first within MaxDepth.
+func (te *DefaultTraversalEngine) executeBestFirstTraversal(ctx context.Context, config *TraversalConfig, rootResources []*unstructured.Unstructured, result *TraversalResult) error {
+	ctx = withRevisitShorterDepths(ctx)
+
+	frontier := &frontierQueue{}
+	seq := 0
+	// bestDepth is the shallowest depth each queued resource was reached at
+	bestDepth := make(map[string]int)
+	enqueue := func(resource *unstructured.Unstructured, depth int, priority float64) {
+		bestDepth[te.generateResourceID(resource)] = depth
+		heap.Push(frontier, &frontierItem{resource: resource, depth: depth, priority: priority, seq: seq})
+		seq++
+	}
+
+	if config.MaxDepth > 0 {
+		for _, resource := range rootResources {
+			enqueue(resource, 0, 1.0)
+		}
+	}
+	augmentedDepths := make(map[int]bool)
+	budget := resourceBudgetFrom(ctx)
+
+	for frontier.Len() > 0 {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if budget.exhausted() {
+			break
+		}
+
+		if result.ResourceGraph.Metadata.EdgesTruncated {
+			te.logger.Debug("Stopping traversal at edge limit", "maxEdges", config.MaxEdges)
+			break
+		}
+
+		item := heap.Pop(frontier).(*frontierItem)
+		depth := item.depth + 1
+		sourceID := te.generateResourceID(item.resource)
+
+		// The resource was queued again at a shallower depth since
+		if item.depth > bestDepth[sourceID] {
+			continue
+		}
+
+		te.logger.Debug("Expanding best-first frontier resource",
+			logfields.ResourceID, sourceID,
+			logfields.Depth, depth,
+			"priority", item.priority,
+			"queued", frontier.Len())
+
+		discoveryResult, err := te.DiscoverReferencedResources(ctx, []*unstructured.Unstructured{item.resource}, config)
+		if err != nil {
+			return functionerrors.Wrap(err, fmt.Sprintf("failed to discover references at depth %d", depth))
+		}
+
+		for _, err := range discoveryResult.Errors {
+			err.Depth = depth
+			result.Errors = appendTraversalError(result.Errors, err)
+		}
+
+		// Add targets in descending confidence so discovery order follows priority
+		resources := make(map[string]*unstructured.Unstructured, len(discoveryResult.Resources))
+		for _, resource := range discoveryResult.Resources {
+			resources[te.generateResourceID(resource)] = resource
+		}
+		edges := append([]ReferenceEdge(nil), discoveryResult.Edges...)
+		sort.SliceStable(edges, func(i, j int) bool {
+			return edges[i].Reference.Confidence > edges[j].Reference.Confidence
+		})
+
+		totalResources := result.Statistics.TotalResources
+		newResources := 0
+		for _, edge := range edges {
+			resource, ok := resources[edge.TargetID]
+			if !ok || te.addDiscoveredResource(ctx, result, resource, depth) == nil {
+				continue
+			}
+			newResources++
+			if depth < config.MaxDepth && te.isExpandable(resource, config) {
+				enqueue(resource, depth, item.priority*edge.Reference.Confidence)
+			}
+		}
+
+		if !augmentedDepths[depth] {
+			augmentedDepths[depth] = true
+
+			seeded, seedErrors := te.listAugmentationResources(ctx, config, depth)
+			for _, err := range seedErrors {
+				result.Errors = appendTraversalError(result.Errors, err)
+			}
+			for _, resource := range seeded {
+				node := te.addDiscoveredResource(ctx, result, resource, depth)
+				if node == nil {
+					continue
+				}
+				node.Metadata.DiscoverySource = graph.DiscoverySourceSelector
+				newResources++
+				if depth < config.MaxDepth && te.isExpandable(resource, config) {
+					enqueue(resource, depth, 0)
+				}
+			}
+		}
+
+		step := TraversalStep{
+			StepID:             len(result.TraversalPath.Steps),
+			Depth:              depth,
+			Action:             TraversalActionDiscover,
+			ResourceID:         sourceID,
+			ReferencesFound:    discoveryResult.Statistics.ReferencesDetected,
+			ReferencesFollowed: newResources,
+			ResourcesAdded:     result.Statistics.TotalResources - totalResources,
+			APICalls:           discoveryResult.Statistics.APICallsToThisDepth,
+			Timestamp:          time.Now(),
+			Duration:           discoveryResult.Statistics.DiscoveryTime,
+		}
+		result.TraversalPath.Steps = append(result.TraversalPath.Steps, step)
+		if depth > result.TraversalPath.MaxDepthReached {
+			result.TraversalPath.MaxDepthReached = depth
+		}
+		result.Statistics.APICallCount += step.APICalls
+
+		te.addReferencesToGraph(result, discoveryResult.Edges)
+	}
+
+	return nil
+}
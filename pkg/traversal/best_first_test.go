@@ -0,0 +1,165 @@
+package traversal
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	dynamictypes "github.com/crossplane/function-kubecore-schema-registry/pkg/dynamic"
+)
+
+// confidenceGraphResolver resolves each source to fixed targets, each
+// reached through a reference of its own confidence
+type confidenceGraphResolver struct {
+	mockReferenceResolver
+	targets map[string]map[string]float64
+}
+
+func (r *confidenceGraphResolver) ResolveReferenceResults(ctx context.Context, source *unstructured.Unstructured, references []dynamictypes.ReferenceField) []*ReferenceResolutionResult {
+	var results []*ReferenceResolutionResult
+	for name, confidence := range r.targets[source.GetName()] {
+		results = append(results, &ReferenceResolutionResult{
+			Reference: dynamictypes.ReferenceField{
+				FieldPath:  "spec." + name + "Ref",
+				FieldName:  name + "Ref",
+				TargetKind: "KubeCluster",
+				Confidence: confidence,
+			},
+			ResolvedResources: []*unstructured.Unstructured{newTestResource("KubeCluster", name)},
+		})
+	}
+	return results
+}
+
+func TestBestFirstRevisitsShallowerArrivals(t *testing.T) {
+	// The confident chain app -> x -> w -> z reaches z at MaxDepth, before the
+	// weaker app -> y -> z reaches it one level sooner with leaf still in range
+	targets := map[string]map[string]float64{
+		"app": {"x": 0.9, "y": 0.5},
+		"x":   {"w": 0.9},
+		"w":   {"z": 0.9},
+		"y":   {"z": 0.9},
+		"z":   {"leaf": 0.9},
+	}
+
+	discover := func(mode SchedulingMode) *TraversalResult {
+		resolver := &confidenceGraphResolver{
+			mockReferenceResolver: mockReferenceResolver{
+				references: []dynamictypes.ReferenceField{
+					{FieldPath: "spec.ref", FieldName: "ref", TargetKind: "KubeCluster", Confidence: 0.9},
+				},
+			},
+			targets: targets,
+		}
+		engine := newTestTraversalEngine(resolver)
+
+		config := NewDefaultTraversalConfig()
+		config.MaxDepth = 3
+		config.ScopeFilter = &ScopeFilterConfig{CrossNamespaceEnabled: true}
+		config.SchedulingMode = mode
+
+		result, err := engine.ExecuteTransitiveDiscovery(context.Background(), config, []*unstructured.Unstructured{newTestResource("KubeApp", "app")})
+		require.NoError(t, err)
+		return result
+	}
+
+	breadthFirst := discover(SchedulingModeBreadthFirst)
+	bestFirst := discover(SchedulingModeBestFirst)
+
+	assert.Len(t, breadthFirst.DiscoveredResources, 6)
+	assert.Equal(t, slices.Sorted(maps.Keys(breadthFirst.DiscoveredResources)), slices.Sorted(maps.Keys(bestFirst.DiscoveredResources)))
+
+	z := bestFirst.ResourceGraph.Nodes["platform.kubecore.io/v1/KubeCluster/default/z"]
+	require.NotNil(t, z)
+	assert.Equal(t, 2, z.DiscoveryDepth, "z should move to the depth of its shorter path")
+	assert.Equal(t, breadthFirst.Statistics.ResourcesByDepth, bestFirst.Statistics.ResourcesByDepth)
+	assert.Equal(t, breadthFirst.Statistics.TotalResources, bestFirst.Statistics.TotalResources)
+
+	// Moving z to its shorter depth does not count it as added a second time
+	added := 0
+	for _, step := range bestFirst.TraversalPath.Steps {
+		added += step.ResourcesAdded
+	}
+	assert.Equal(t, len(bestFirst.DiscoveredResources)-1, added)
+}
+
+func TestExecuteTransitiveDiscoverySchedulingMode(t *testing.T) {
+	// A strong chain app -> strong-1 -> strong-2 and a weak one app -> weak-1 -> weak-2
+	targets := map[string]map[string]float64{
+		"app":      {"strong-1": 0.95, "weak-1": 0.75},
+		"strong-1": {"strong-2": 0.95},
+		"weak-1":   {"weak-2": 0.75},
+	}
+
+	cases := map[string]struct {
+		mode SchedulingMode
+		// first and second are expanded in this order
+		first, second string
+	}{
+		"BreadthFirst": {mode: SchedulingModeBreadthFirst, first: "weak-1", second: "strong-2"},
+		"BestFirst":    {mode: SchedulingModeBestFirst, first: "strong-2", second: "weak-1"},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			resolver := &confidenceGraphResolver{
+				mockReferenceResolver: mockReferenceResolver{
+					references: []dynamictypes.ReferenceField{
+						{FieldPath: "spec.ref", FieldName: "ref", TargetKind: "KubeCluster", Confidence: 0.9},
+					},
+				},
+				targets: targets,
+			}
+			engine := newTestTraversalEngine(resolver)
+
+			config := NewDefaultTraversalConfig()
+			config.MaxDepth = 3
+			config.ScopeFilter = &ScopeFilterConfig{CrossNamespaceEnabled: true}
+			config.SchedulingMode = tc.mode
+
+			result, err := engine.ExecuteTransitiveDiscovery(context.Background(), config, []*unstructured.Unstructured{newTestResource("KubeApp", "app")})
+			require.NoError(t, err)
+
+			// Both modes discover the same resources, in a different order
+			assert.Len(t, result.DiscoveredResources, 5)
+			assert.Equal(t, 3, result.TraversalPath.MaxDepthReached)
+			assert.Less(t, slices.Index(resolver.extracted, tc.first), slices.Index(resolver.extracted, tc.second),
+				"extraction order %v", resolver.extracted)
+
+			// Every expansion's reference is counted, however many workers filtered them
+			statistics := engine.components.ScopeFilter.GetFilterStatistics()
+			assert.Equal(t, len(resolver.extracted), statistics.ReferencesEvaluated)
+			assert.Equal(t, statistics.ReferencesEvaluated, statistics.ReferencesIncluded+statistics.ReferencesExcluded)
+		})
+	}
+
+	t.Run("BestFirstWithinMaxResources", func(t *testing.T) {
+		resolver := &confidenceGraphResolver{
+			mockReferenceResolver: mockReferenceResolver{
+				references: []dynamictypes.ReferenceField{
+					{FieldPath: "spec.ref", FieldName: "ref", TargetKind: "KubeCluster", Confidence: 0.9},
+				},
+			},
+			targets: targets,
+		}
+		engine := newTestTraversalEngine(resolver)
+
+		config := NewDefaultTraversalConfig()
+		config.MaxDepth = 3
+		config.MaxResources = 4
+		config.ScopeFilter = &ScopeFilterConfig{CrossNamespaceEnabled: true}
+		config.SchedulingMode = SchedulingModeBestFirst
+
+		result, err := engine.ExecuteTransitiveDiscovery(context.Background(), config, []*unstructured.Unstructured{newTestResource("KubeApp", "app")})
+		require.NoError(t, err)
+
+		// The strong chain is followed to its end before the budget runs out
+		assert.Contains(t, result.DiscoveredResources, engine.generateResourceID(newTestResource("KubeCluster", "strong-2")))
+		assert.NotContains(t, result.DiscoveredResources, engine.generateResourceID(newTestResource("KubeCluster", "weak-2")))
+	})
+}
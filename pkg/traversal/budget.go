@@ -0,0 +1,61 @@
+package traversal
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// resourceBudget caps the resources one traversal discovers. Every
+// expansion, however concurrent, reserves from the same budget, so the total
+// never exceeds the limit. A nil budget is unlimited.
+type resourceBudget struct {
+	limit int64
+	used  atomic.Int64
+}
+
+// newResourceBudget returns a budget admitting up to limit resources
+func newResourceBudget(limit int) *resourceBudget {
+	return &resourceBudget{limit: int64(limit)}
+}
+
+// reserve claims one resource from the budget, reporting false once it is spent
+func (b *resourceBudget) reserve() bool {
+	if b == nil {
+		return true
+	}
+	for {
+		used := b.used.Load()
+		if used >= b.limit {
+			return false
+		}
+		if b.used.CompareAndSwap(used, used+1) {
+			return true
+		}
+	}
+}
+
+// charge counts resources that are admitted regardless of the limit, such as
+// the roots traversal starts from
+func (b *resourceBudget) charge(n int) {
+	if b != nil {
+		b.used.Add(int64(n))
+	}
+}
+
+// exhausted reports whether no more resources can be reserved
+func (b *resourceBudget) exhausted() bool {
+	return b != nil && b.used.Load() >= b.limit
+}
+
+type resourceBudgetKey struct{}
+
+// withResourceBudget returns a context carrying budget
+func withResourceBudget(ctx context.Context, budget *resourceBudget) context.Context {
+	return context.WithValue(ctx, resourceBudgetKey{}, budget)
+}
+
+// resourceBudgetFrom returns the resource budget carried by ctx, if any
+func resourceBudgetFrom(ctx context.Context) *resourceBudget {
+	budget, _ := ctx.Value(resourceBudgetKey{}).(*resourceBudget)
+	return budget
+}
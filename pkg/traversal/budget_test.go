@@ -0,0 +1,55 @@
+package traversal
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	dynamictypes "github.com/crossplane/function-kubecore-schema-registry/pkg/dynamic"
+)
+
+func TestExecuteTransitiveDiscoveryResourceBudget(t *testing.T) {
+	// Every root references two resources of its own, far more than the cap
+	const roots = 20
+	resolvedBySource := make(map[string][]*unstructured.Unstructured, roots)
+	rootResources := make([]*unstructured.Unstructured, 0, roots)
+	for i := 0; i < roots; i++ {
+		name := fmt.Sprintf("app-%d", i)
+		rootResources = append(rootResources, newTestResource("KubeApp", name))
+		resolvedBySource[name] = []*unstructured.Unstructured{
+			newTestResource("KubEnv", name+"-env"),
+			newTestResource("KubeNet", name+"-net"),
+		}
+	}
+
+	resolver := &mockReferenceResolver{
+		references: []dynamictypes.ReferenceField{
+			{FieldPath: "spec.ref", FieldName: "ref", TargetKind: "KubEnv", Confidence: 0.9},
+		},
+		resolvedBySource: resolvedBySource,
+	}
+
+	for _, mode := range []SchedulingMode{SchedulingModeBreadthFirst, SchedulingModeBestFirst} {
+		t.Run(string(mode), func(t *testing.T) {
+			engine := newTestTraversalEngine(resolver)
+
+			config := NewDefaultTraversalConfig()
+			config.ScopeFilter = &ScopeFilterConfig{CrossNamespaceEnabled: true}
+			config.SchedulingMode = mode
+			config.MaxResources = roots + 5
+			config.Performance.MaxConcurrentRequests = 8
+
+			result, err := engine.ExecuteTransitiveDiscovery(context.Background(), config, rootResources)
+			require.NoError(t, err)
+
+			assert.Equal(t, config.MaxResources, result.Statistics.TotalResources)
+			assert.Len(t, result.DiscoveredResources, config.MaxResources)
+			assert.Len(t, result.ResourceGraph.Nodes, config.MaxResources)
+			assert.Equal(t, TerminationReasonMaxResources, result.Metadata.TerminationReason)
+		})
+	}
+}
@@ -2,8 +2,11 @@ package traversal
 
 import (
 	"container/list"
+	"context"
 	"sync"
 	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
 // Cache provides execution-scoped caching for traversal operations
@@ -100,6 +103,9 @@ type LRUCache struct {
 
 	// stopCleanup stops the cleanup goroutine
 	stopCleanup chan struct{}
+
+	// closeOnce guards stopCleanup so Close can be called more than once
+	closeOnce sync.Once
 }
 
 // NewLRUCache creates a new LRU cache with the specified capacity and default TTL
@@ -274,12 +280,14 @@ func (c *LRUCache) Cleanup() {
 	}
 }
 
-// Close stops the cache cleanup goroutine
+// Close stops the cache cleanup goroutine. It is safe to call more than once.
 func (c *LRUCache) Close() {
-	close(c.stopCleanup)
-	if c.cleanupTicker != nil {
-		c.cleanupTicker.Stop()
-	}
+	c.closeOnce.Do(func() {
+		close(c.stopCleanup)
+		if c.cleanupTicker != nil {
+			c.cleanupTicker.Stop()
+		}
+	})
 }
 
 // Helper methods
@@ -339,6 +347,9 @@ type TTLCache struct {
 
 	// stopCleanup stops the cleanup goroutine
 	stopCleanup chan struct{}
+
+	// closeOnce guards stopCleanup so Close can be called more than once
+	closeOnce sync.Once
 }
 
 // NewTTLCache creates a new TTL-based cache
@@ -487,12 +498,14 @@ func (c *TTLCache) Cleanup() {
 	}
 }
 
-// Close stops the TTL cache cleanup goroutine
+// Close stops the TTL cache cleanup goroutine. It is safe to call more than once.
 func (c *TTLCache) Close() {
-	close(c.stopCleanup)
-	if c.cleanupTicker != nil {
-		c.cleanupTicker.Stop()
-	}
+	c.closeOnce.Do(func() {
+		close(c.stopCleanup)
+		if c.cleanupTicker != nil {
+			c.cleanupTicker.Stop()
+		}
+	})
 }
 
 // cleanupLoop runs periodic cleanup of expired entries for TTL cache
@@ -549,3 +562,60 @@ func (c *NoOpCache) Stats() *CacheStats {
 func (c *NoOpCache) Cleanup() {
 	// No-op
 }
+
+// closeCache stops the cleanup goroutine of caches that run one
+func closeCache(cache Cache) {
+	if closer, ok := cache.(interface{ Close() }); ok {
+		closer.Close()
+	}
+}
+
+// resolutionMemo shares target lookups between all resources resolved at one
+// depth, so a target referenced by several resources is fetched once
+type resolutionMemo struct {
+	mu      sync.Mutex
+	entries map[string]*resolutionMemoEntry
+}
+
+// resolutionMemoEntry holds the outcome of one target lookup; done is closed
+// once resource and err are set
+type resolutionMemoEntry struct {
+	done     chan struct{}
+	resource *unstructured.Unstructured
+	err      error
+}
+
+type resolutionMemoKey struct{}
+
+// withResolutionMemo returns a context carrying a fresh resolution memo
+func withResolutionMemo(ctx context.Context) context.Context {
+	return context.WithValue(ctx, resolutionMemoKey{}, &resolutionMemo{
+		entries: make(map[string]*resolutionMemoEntry),
+	})
+}
+
+// resolutionMemoFrom returns the resolution memo carried by ctx, if any
+func resolutionMemoFrom(ctx context.Context) *resolutionMemo {
+	memo, _ := ctx.Value(resolutionMemoKey{}).(*resolutionMemo)
+	return memo
+}
+
+// do returns the memoized outcome for key, running lookup only for the first
+// caller; concurrent callers for the same key wait for that lookup to finish
+func (m *resolutionMemo) do(key string, lookup func() (*unstructured.Unstructured, error)) (*unstructured.Unstructured, error) {
+	m.mu.Lock()
+	if entry, exists := m.entries[key]; exists {
+		m.mu.Unlock()
+		<-entry.done
+		return entry.resource, entry.err
+	}
+
+	entry := &resolutionMemoEntry{done: make(chan struct{})}
+	m.entries[key] = entry
+	m.mu.Unlock()
+
+	entry.resource, entry.err = lookup()
+	close(entry.done)
+
+	return entry.resource, entry.err
+}
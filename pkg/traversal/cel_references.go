@@ -0,0 +1,212 @@
+package traversal
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"google.golang.org/protobuf/types/known/structpb"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	dynamictypes "github.com/crossplane/function-kubecore-schema-registry/pkg/dynamic"
+	"github.com/crossplane/function-kubecore-schema-registry/pkg/logfields"
+)
+
+// celFieldPathPrefix marks the field paths of references produced by CEL
+// extractors, e.g. "cel[0][1]" for the second target of the first extractor
+const celFieldPathPrefix = "cel["
+
+const (
+	// celCostLimit bounds the estimated cost of one evaluation of a CEL
+	// reference expression, so an expression iterating over large lists fails
+	// instead of stalling extraction
+	celCostLimit = 1000000
+
+	// celInterruptCheckFrequency is how many comprehension iterations run
+	// between checks for a cancelled context
+	celInterruptCheckFrequency = 100
+)
+
+// compiledCELExtractor is a CEL reference extractor ready for evaluation
+type compiledCELExtractor struct {
+	CELReferenceExtractor
+	program cel.Program
+}
+
+// CompileCELReferenceExpression checks that expression is a valid CEL
+// reference expression and returns the program that evaluates it. The program
+// stops once it exceeds celCostLimit or the context it is evaluated with is done.
+func CompileCELReferenceExpression(expression string) (cel.Program, error) {
+	env, err := cel.NewEnv(cel.Variable("object", cel.MapType(cel.StringType, cel.DynType)))
+	if err != nil {
+		return nil, err
+	}
+
+	ast, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	return env.Program(ast,
+		cel.CostLimit(celCostLimit),
+		cel.InterruptCheckFrequency(celInterruptCheckFrequency))
+}
+
+// SetCELReferences compiles the CEL reference extractors used by
+// ExtractReferences. Extractors from a previous call are discarded, and none
+// are kept when any expression fails to compile.
+func (rr *DefaultReferenceResolver) SetCELReferences(extractors []CELReferenceExtractor) error {
+	compiled, err := compileCELExtractors(extractors)
+	rr.updateOptions(func(options *resolutionOptions) {
+		options.celExtractors = compiled
+	})
+	return err
+}
+
+// compileCELExtractors compiles CEL reference extractors, returning none when
+// any expression fails to compile
+func compileCELExtractors(extractors []CELReferenceExtractor) ([]compiledCELExtractor, error) {
+	if len(extractors) == 0 {
+		return nil, nil
+	}
+
+	compiled := make([]compiledCELExtractor, 0, len(extractors))
+	for _, extractor := range extractors {
+		program, err := CompileCELReferenceExpression(extractor.Expression)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CEL reference expression for kind %s: %w", extractor.Kind, err)
+		}
+		compiled = append(compiled, compiledCELExtractor{CELReferenceExtractor: extractor, program: program})
+	}
+	return compiled, nil
+}
+
+// appliesTo reports whether the extractor is configured for the resource's kind
+func (e *compiledCELExtractor) appliesTo(resource *unstructured.Unstructured) bool {
+	if e.Kind != resource.GetKind() {
+		return false
+	}
+	return e.APIVersion == "" || e.APIVersion == resource.GetAPIVersion()
+}
+
+// evaluate runs the extractor against a resource and returns the targets it
+// names. A single map result is treated as a list of one.
+func (e *compiledCELExtractor) evaluate(ctx context.Context, resource *unstructured.Unstructured) ([]map[string]interface{}, error) {
+	out, _, err := e.program.ContextEval(ctx, map[string]interface{}{"object": resource.Object})
+	if err != nil {
+		return nil, err
+	}
+
+	native, err := out.ConvertToNative(reflect.TypeOf(&structpb.Value{}))
+	if err != nil {
+		return nil, fmt.Errorf("unsupported result type %s", out.Type().TypeName())
+	}
+
+	switch result := native.(*structpb.Value).AsInterface().(type) {
+	case nil:
+		return nil, nil
+	case map[string]interface{}:
+		return []map[string]interface{}{result}, nil
+	case []interface{}:
+		targets := make([]map[string]interface{}, 0, len(result))
+		for i, item := range result {
+			target, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("result item %d is %T, not a map", i, item)
+			}
+			targets = append(targets, target)
+		}
+		return targets, nil
+	default:
+		return nil, fmt.Errorf("result is %T, not a map or list of maps", result)
+	}
+}
+
+// extractCELReferences evaluates the CEL extractors configured for the
+// resource's kind. Expressions that fail to evaluate, for example because a
+// field they read is missing or they exceed their cost limit, contribute no
+// references.
+func (rr *DefaultReferenceResolver) extractCELReferences(ctx context.Context, resource *unstructured.Unstructured, extractors []compiledCELExtractor) []dynamictypes.ReferenceField {
+	var references []dynamictypes.ReferenceField
+	for i := range extractors {
+		extractor := &extractors[i]
+		if !extractor.appliesTo(resource) {
+			continue
+		}
+
+		targets, err := extractor.evaluate(ctx, resource)
+		if err != nil {
+			rr.logger.Debug("CEL reference expression did not evaluate", logfields.Resource(resource,
+				"expression", extractor.Expression,
+				"error", err)...)
+			continue
+		}
+
+		confidence := extractor.Confidence
+		if confidence == 0 {
+			confidence = 0.9
+		}
+
+		for j, target := range targets {
+			kind, _ := target["kind"].(string)
+			name, _ := target["name"].(string)
+			if kind == "" || name == "" {
+				rr.logger.Debug("CEL reference result is missing a name or kind", logfields.Resource(resource,
+					"expression", extractor.Expression,
+					"result", target)...)
+				continue
+			}
+
+			var gv schema.GroupVersion
+			if apiVersion, _ := target["apiVersion"].(string); apiVersion != "" {
+				if gv, err = schema.ParseGroupVersion(apiVersion); err != nil {
+					rr.logger.Debug("CEL reference result has an invalid apiVersion", logfields.Resource(resource,
+						"apiVersion", apiVersion)...)
+					continue
+				}
+			}
+
+			references = append(references, dynamictypes.ReferenceField{
+				FieldPath:       celFieldPath(i, j),
+				FieldName:       celFieldPath(i, j),
+				TargetKind:      kind,
+				TargetGroup:     gv.Group,
+				TargetVersion:   gv.Version,
+				RefType:         dynamictypes.RefTypeCustom,
+				Confidence:      confidence,
+				DetectionMethod: "cel",
+				MatchedPattern:  extractor.Expression,
+			})
+		}
+	}
+
+	return references
+}
+
+// celFieldPath returns the field path of a CEL extractor's result
+func celFieldPath(extractor, target int) string {
+	return fmt.Sprintf("cel[%d][%d]", extractor, target)
+}
+
+// celReferenceValue re-evaluates the CEL extractor a field path points at and
+// returns the target it names, which carries the name and namespace
+func (rr *DefaultReferenceResolver) celReferenceValue(ctx context.Context, resource *unstructured.Unstructured, fieldPath string, extractors []compiledCELExtractor) (interface{}, error) {
+	var extractorIndex, targetIndex int
+	if _, err := fmt.Sscanf(strings.TrimPrefix(fieldPath, celFieldPathPrefix), "%d][%d]", &extractorIndex, &targetIndex); err != nil {
+		return nil, fmt.Errorf("invalid CEL reference path: %s", fieldPath)
+	}
+	if extractorIndex < 0 || extractorIndex >= len(extractors) {
+		return nil, fmt.Errorf("no CEL reference extractor for path: %s", fieldPath)
+	}
+
+	targets, err := extractors[extractorIndex].evaluate(ctx, resource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate CEL reference expression: %w", err)
+	}
+	if targetIndex < 0 || targetIndex >= len(targets) {
+		return nil, fmt.Errorf("CEL reference target not found: %s", fieldPath)
+	}
+	return targets[targetIndex], nil
+}
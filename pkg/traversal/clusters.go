@@ -0,0 +1,113 @@
+package traversal
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+
+	dynamictypes "github.com/crossplane/function-kubecore-schema-registry/pkg/dynamic"
+	"github.com/crossplane/function-kubecore-schema-registry/pkg/graph"
+)
+
+// ClusterHintKey is the label or annotation naming the cluster that the
+// references of a resource point into, for hub-and-spoke setups where some
+// targets live in a cluster other than the one being traversed
+const ClusterHintKey = "kubecore.io/cluster"
+
+// ClientFactory returns the dynamic client for the cluster with the given ID
+type ClientFactory func(clusterID string) (dynamic.Interface, error)
+
+// SingleClusterClientFactory returns a factory that answers every cluster ID
+// with the same client
+func SingleClusterClientFactory(client dynamic.Interface) ClientFactory {
+	return func(string) (dynamic.Interface, error) {
+		return client, nil
+	}
+}
+
+// SetClientFactory sets the factory used for references carrying a cluster
+// hint. A nil factory resolves every reference against the resolver's own
+// client. Clients and resolutions from the previous factory are dropped.
+func (rr *DefaultReferenceResolver) SetClientFactory(factory ClientFactory) {
+	rr.clientsMu.Lock()
+	defer rr.clientsMu.Unlock()
+
+	if factory == nil {
+		factory = SingleClusterClientFactory(rr.dynamicClient)
+	}
+	rr.clientFactory = factory
+	rr.clusterClients = nil
+	rr.factoryGeneration++
+	rr.cache.Clear()
+}
+
+// withResolvedCluster returns a copy of a resource resolved from cluster,
+// annotated with the cluster it came from
+func withResolvedCluster(resource *unstructured.Unstructured, cluster string) *unstructured.Unstructured {
+	resource = resource.DeepCopy()
+	annotations := resource.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[graph.ResolvedClusterAnnotation] = cluster
+	resource.SetAnnotations(annotations)
+	return resource
+}
+
+// targetCluster returns the cluster hinted by the source's label or
+// annotation, falling back to the cluster the source was itself resolved
+// from, or "" for the cluster being traversed. Owner references always point
+// into the cluster of the resource they are set on.
+func (rr *DefaultReferenceResolver) targetCluster(source *unstructured.Unstructured, reference dynamictypes.ReferenceField) string {
+	if reference.RefType != dynamictypes.RefTypeOwnerRef {
+		if cluster := source.GetLabels()[ClusterHintKey]; cluster != "" {
+			return cluster
+		}
+		if cluster := source.GetAnnotations()[ClusterHintKey]; cluster != "" {
+			return cluster
+		}
+	}
+	return graph.ResourceCluster(source)
+}
+
+// clientFor returns the client for a cluster, creating it through the client
+// factory the first time the cluster is seen. The factory runs without the
+// lock held, so a slow factory does not block lookups in other clusters; when
+// two callers race, the client stored first wins.
+func (rr *DefaultReferenceResolver) clientFor(cluster string) (dynamic.Interface, error) {
+	if cluster == "" {
+		return rr.dynamicClient, nil
+	}
+
+	rr.clientsMu.Lock()
+	if client, ok := rr.clusterClients[cluster]; ok {
+		rr.clientsMu.Unlock()
+		return client, nil
+	}
+	factory, generation := rr.clientFactory, rr.factoryGeneration
+	rr.clientsMu.Unlock()
+
+	if factory == nil {
+		factory = SingleClusterClientFactory(rr.dynamicClient)
+	}
+	client, err := factory(cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	rr.clientsMu.Lock()
+	defer rr.clientsMu.Unlock()
+
+	// The factory was replaced while this client was built, so it is used
+	// for this lookup but not remembered
+	if generation != rr.factoryGeneration {
+		return client, nil
+	}
+	if existing, ok := rr.clusterClients[cluster]; ok {
+		return existing, nil
+	}
+	if rr.clusterClients == nil {
+		rr.clusterClients = make(map[string]dynamic.Interface)
+	}
+	rr.clusterClients[cluster] = client
+	return client, nil
+}
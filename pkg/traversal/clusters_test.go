@@ -0,0 +1,265 @@
+package traversal
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/crossplane/function-sdk-go/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	dynamictypes "github.com/crossplane/function-kubecore-schema-registry/pkg/dynamic"
+	"github.com/crossplane/function-kubecore-schema-registry/pkg/graph"
+	"github.com/crossplane/function-kubecore-schema-registry/pkg/registry"
+)
+
+func TestExecuteTransitiveDiscoveryFollowsRemoteClusterReferences(t *testing.T) {
+	// Every cluster has a KubeNet called net; the spoke's KubeCluster names it
+	newResources := func(cluster string) []runtime.Object {
+		kubeCluster := newTestResource("KubeCluster", "primary")
+		kubeCluster.SetNamespace("")
+		kubeCluster.SetLabels(map[string]string{"cluster": cluster})
+		kubeCluster.Object["spec"] = map[string]interface{}{"kubeNetRef": "net"}
+
+		kubeNet := newTestResource("KubeNet", "net")
+		kubeNet.SetNamespace("")
+		kubeNet.SetLabels(map[string]string{"cluster": cluster})
+		return []runtime.Object{kubeCluster, kubeNet}
+	}
+	hub := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), newResources("hub")...)
+	spoke := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), newResources("spoke-1")...)
+
+	reg := &referencesRegistry{references: map[string][]registry.ResourceReference{
+		"KubeApp": {{
+			FieldPath:   "$.spec.kubeClusterRef",
+			TargetKind:  "KubeCluster",
+			TargetGroup: "platform.kubecore.io",
+			RefType:     registry.RefTypeCustom,
+		}},
+		"KubeCluster": {{
+			FieldPath:   "$.spec.kubeNetRef",
+			TargetKind:  "KubeNet",
+			TargetGroup: "platform.kubecore.io",
+			RefType:     registry.RefTypeCustom,
+		}},
+	}}
+	resolver := NewDefaultReferenceResolver(hub, reg, logging.NewNopLogger())
+	resolver.SetClientFactory(func(string) (dynamic.Interface, error) {
+		return spoke, nil
+	})
+	engine := newTestTraversalEngine(resolver)
+
+	config := NewDefaultTraversalConfig()
+	config.MaxDepth = 2
+	config.ScopeFilter = &ScopeFilterConfig{CrossNamespaceEnabled: true}
+
+	app := newTestResource("KubeApp", "remote-app")
+	app.SetAnnotations(map[string]string{ClusterHintKey: "spoke-1"})
+	app.Object["spec"] = map[string]interface{}{"kubeClusterRef": "primary"}
+
+	result, err := engine.ExecuteTransitiveDiscovery(context.Background(), config, []*unstructured.Unstructured{app})
+	require.NoError(t, err)
+
+	// The second hop is resolved in the spoke the KubeCluster came from
+	netID := "platform.kubecore.io/v1/KubeNet//net@spoke-1"
+	require.Contains(t, result.DiscoveredResources, netID)
+	assert.Equal(t, "spoke-1", result.DiscoveredResources[netID].GetLabels()["cluster"])
+	assert.NotContains(t, result.DiscoveredResources, "platform.kubecore.io/v1/KubeNet//net")
+
+	clusterID := graph.NodeID("platform.kubecore.io/v1/KubeCluster//primary@spoke-1")
+	var targets []graph.NodeID
+	for _, edge := range result.ResourceGraph.Edges {
+		if edge.Source == clusterID {
+			targets = append(targets, edge.Target)
+		}
+	}
+	assert.Equal(t, []graph.NodeID{graph.NodeID(netID)}, targets)
+}
+
+func TestTargetClusterFallsBackToResolvedCluster(t *testing.T) {
+	resolver, _ := newTestReferenceResolver()
+
+	resolved := withResolvedCluster(newTestResource("KubeCluster", "primary"), "spoke-1")
+	hinted := withResolvedCluster(newTestResource("KubeCluster", "primary"), "spoke-1")
+	hinted.SetLabels(map[string]string{ClusterHintKey: "spoke-2"})
+
+	cases := map[string]struct {
+		source  *unstructured.Unstructured
+		refType dynamictypes.RefType
+		want    string
+	}{
+		"Local":               {source: newTestResource("KubeCluster", "primary"), refType: dynamictypes.RefTypeCustom, want: ""},
+		"Resolved":            {source: resolved, refType: dynamictypes.RefTypeCustom, want: "spoke-1"},
+		"ResolvedOwnerRef":    {source: resolved, refType: dynamictypes.RefTypeOwnerRef, want: "spoke-1"},
+		"HintWins":            {source: hinted, refType: dynamictypes.RefTypeCustom, want: "spoke-2"},
+		"OwnerRefIgnoresHint": {source: hinted, refType: dynamictypes.RefTypeOwnerRef, want: "spoke-1"},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			reference := dynamictypes.ReferenceField{FieldPath: "spec.ref", TargetKind: "KubeNet", RefType: tc.refType}
+			assert.Equal(t, tc.want, resolver.targetCluster(tc.source, reference))
+		})
+	}
+}
+
+func TestResolveReferenceClientFactory(t *testing.T) {
+	newConfigMap := func(cluster string) *unstructured.Unstructured {
+		configMap := &unstructured.Unstructured{}
+		configMap.SetAPIVersion("v1")
+		configMap.SetKind("ConfigMap")
+		configMap.SetName("app-config")
+		configMap.SetNamespace("default")
+		configMap.SetLabels(map[string]string{"cluster": cluster})
+		return configMap
+	}
+	hub := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), newConfigMap("hub"))
+	spoke := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), newConfigMap("spoke-1"))
+
+	cases := map[string]struct {
+		clusterHint string
+		factory     ClientFactory
+		want        string
+		wantCluster string
+		wantErr     bool
+	}{
+		"NoHint": {
+			want: "hub",
+		},
+		"DefaultFactory": {
+			clusterHint: "spoke-1",
+			want:        "hub",
+			wantCluster: "spoke-1",
+		},
+		"HintedCluster": {
+			clusterHint: "spoke-1",
+			factory: func(clusterID string) (dynamic.Interface, error) {
+				if clusterID != "spoke-1" {
+					return nil, fmt.Errorf("unknown cluster %s", clusterID)
+				}
+				return spoke, nil
+			},
+			want:        "spoke-1",
+			wantCluster: "spoke-1",
+		},
+		"UnknownCluster": {
+			clusterHint: "spoke-2",
+			factory: func(clusterID string) (dynamic.Interface, error) {
+				return nil, fmt.Errorf("unknown cluster %s", clusterID)
+			},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			resolver := NewDefaultReferenceResolver(hub, &mockRegistry{}, logging.NewNopLogger())
+			if tc.factory != nil {
+				resolver.SetClientFactory(tc.factory)
+			}
+
+			source := newTestResource("XKubeApp", "my-app")
+			if tc.clusterHint != "" {
+				source.SetAnnotations(map[string]string{ClusterHintKey: tc.clusterHint})
+			}
+			source.Object["spec"] = map[string]interface{}{"configRef": "app-config"}
+
+			reference := dynamictypes.ReferenceField{FieldPath: "spec.configRef", TargetKind: "ConfigMap", Confidence: 0.9}
+			resolved, err := resolver.ResolveReference(context.Background(), source, reference)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, resolved.GetLabels()["cluster"])
+			assert.Equal(t, tc.wantCluster, graph.ResourceCluster(resolved))
+		})
+	}
+}
+
+func TestClientForBuildsClientsOutsideLock(t *testing.T) {
+	resolver, _ := newTestReferenceResolver()
+
+	started, release := make(chan struct{}), make(chan struct{})
+	var builds atomic.Int32
+	resolver.SetClientFactory(func(clusterID string) (dynamic.Interface, error) {
+		builds.Add(1)
+		if clusterID == "slow" {
+			close(started)
+			<-release
+		}
+		return dynamicfake.NewSimpleDynamicClient(runtime.NewScheme()), nil
+	})
+
+	slow := make(chan dynamic.Interface)
+	go func() {
+		client, _ := resolver.clientFor("slow")
+		slow <- client
+	}()
+	<-started
+
+	// Another cluster's client is built while the slow factory call is running
+	fast, err := resolver.clientFor("fast")
+	require.NoError(t, err)
+	require.NotNil(t, fast)
+
+	close(release)
+	slowClient := <-slow
+	require.NotNil(t, slowClient)
+
+	// Built clients are remembered
+	again, err := resolver.clientFor("slow")
+	require.NoError(t, err)
+	assert.Same(t, slowClient, again)
+	assert.Equal(t, int32(2), builds.Load())
+}
+
+func TestExecuteTransitiveDiscoveryCrossClusterTargets(t *testing.T) {
+	newCluster := func(cluster string) *unstructured.Unstructured {
+		kubeCluster := newTestResource("KubeCluster", "primary")
+		kubeCluster.SetNamespace("")
+		kubeCluster.SetLabels(map[string]string{"cluster": cluster})
+		return kubeCluster
+	}
+	spoke := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), newCluster("spoke-1"))
+
+	resolver, _ := newTestReferenceResolver(newCluster("hub"))
+	resolver.SetClientFactory(func(string) (dynamic.Interface, error) {
+		return spoke, nil
+	})
+	engine := newTestTraversalEngine(resolver)
+
+	config := NewDefaultTraversalConfig()
+	config.MaxDepth = 1
+	config.ScopeFilter = &ScopeFilterConfig{CrossNamespaceEnabled: true}
+
+	// Both apps name a KubeCluster called primary, one of them in the spoke
+	local := newTestResource("KubeApp", "local-app")
+	local.Object["spec"] = map[string]interface{}{"kubeClusterRef": "primary"}
+	remote := newTestResource("KubeApp", "remote-app")
+	remote.SetAnnotations(map[string]string{ClusterHintKey: "spoke-1"})
+	remote.Object["spec"] = map[string]interface{}{"kubeClusterRef": "primary"}
+
+	result, err := engine.ExecuteTransitiveDiscovery(context.Background(), config, []*unstructured.Unstructured{local, remote})
+	require.NoError(t, err)
+
+	localID := "platform.kubecore.io/v1/KubeCluster//primary"
+	remoteID := localID + "@spoke-1"
+	require.Contains(t, result.DiscoveredResources, localID)
+	require.Contains(t, result.DiscoveredResources, remoteID)
+	assert.Equal(t, "hub", result.DiscoveredResources[localID].GetLabels()["cluster"])
+	assert.Equal(t, "spoke-1", result.DiscoveredResources[remoteID].GetLabels()["cluster"])
+
+	targets := make(map[graph.NodeID]graph.NodeID)
+	for _, edge := range result.ResourceGraph.Edges {
+		targets[edge.Source] = edge.Target
+	}
+	assert.Equal(t, graph.NodeID(localID), targets[graph.NodeID(engine.generateResourceID(local))])
+	assert.Equal(t, graph.NodeID(remoteID), targets[graph.NodeID(engine.generateResourceID(remote))])
+}
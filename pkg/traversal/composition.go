@@ -0,0 +1,127 @@
+package traversal
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/crossplane/function-kubecore-schema-registry/pkg/graph"
+	"github.com/crossplane/function-kubecore-schema-registry/pkg/logfields"
+)
+
+var (
+	// compositeResourceDefinitionGVR identifies Crossplane XRDs
+	compositeResourceDefinitionGVR = schema.GroupVersionResource{Group: "apiextensions.crossplane.io", Version: "v1", Resource: "compositeresourcedefinitions"}
+
+	// compositionGVR identifies Crossplane Compositions
+	compositionGVR = schema.GroupVersionResource{Group: "apiextensions.crossplane.io", Version: "v1", Resource: "compositions"}
+)
+
+// compositionRefPaths are the fields a composite names its Composition in,
+// for Crossplane v1 and v2 composites respectively
+var compositionRefPaths = [][]string{
+	{"spec", "compositionRef", "name"},
+	{"spec", "crossplane", "compositionRef", "name"},
+}
+
+// compositionRef returns the name of the Composition a composite resource
+// selected and the field it was read from. Resources without one are not
+// treated as composites.
+func compositionRef(resource *unstructured.Unstructured) (name, fieldPath string, ok bool) {
+	for _, path := range compositionRefPaths {
+		if name, found, _ := unstructured.NestedString(resource.Object, path...); found && name != "" {
+			return name, strings.Join(path[:len(path)-1], "."), true
+		}
+	}
+	return "", "", false
+}
+
+// linkCompositionMetadata adds the XRD and Composition of every discovered
+// composite resource to the graph, linked from the composite. Lookups that
+// fail are reported as recoverable errors.
+func (te *DefaultTraversalEngine) linkCompositionMetadata(ctx context.Context, config *TraversalConfig, result *TraversalResult) {
+	resourceIDs := make([]string, 0, len(result.DiscoveredResources))
+	for resourceID := range result.DiscoveredResources {
+		resourceIDs = append(resourceIDs, resourceID)
+	}
+	sort.Strings(resourceIDs)
+
+	var xrds []unstructured.Unstructured
+	xrdsListed := false
+
+	for _, resourceID := range resourceIDs {
+		composite := result.DiscoveredResources[resourceID]
+		compositionName, fieldPath, ok := compositionRef(composite)
+		if !ok {
+			continue
+		}
+		compositeNode, exists := result.ResourceGraph.Nodes[graph.NodeID(resourceID)]
+		if !exists {
+			continue
+		}
+
+		// XRDs are listed once, the first time a composite needs one
+		if !xrdsListed {
+			xrdsListed = true
+			list, err := te.components.DynamicClient.Resource(compositeResourceDefinitionGVR).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				result.Errors = appendTraversalError(result.Errors, compositionMetadataError(resourceID, "failed to list CompositeResourceDefinitions", err))
+			} else {
+				xrds = list.Items
+			}
+		}
+
+		gvk := composite.GroupVersionKind()
+		for i := range xrds {
+			group, _, _ := unstructured.NestedString(xrds[i].Object, "spec", "group")
+			kind, _, _ := unstructured.NestedString(xrds[i].Object, "spec", "names", "kind")
+			if group == gvk.Group && kind == gvk.Kind {
+				te.linkCompositionResource(ctx, result, compositeNode, &xrds[i], graph.RelationTypeDefinedBy, "kind")
+				break
+			}
+		}
+
+		composition, err := te.components.DynamicClient.Resource(compositionGVR).Get(ctx, compositionName, metav1.GetOptions{})
+		if err != nil {
+			result.Errors = appendTraversalError(result.Errors, compositionMetadataError(resourceID, fmt.Sprintf("failed to get Composition %s", compositionName), err))
+			continue
+		}
+		te.linkCompositionResource(ctx, result, compositeNode, composition, graph.RelationTypeComposedBy, fieldPath)
+	}
+}
+
+// linkCompositionResource adds a composition metadata resource one level
+// below the composite, unless it is already in the graph, and links the two
+func (te *DefaultTraversalEngine) linkCompositionResource(ctx context.Context, result *TraversalResult, compositeNode *graph.ResourceNode, resource *unstructured.Unstructured, relationType graph.RelationType, fieldPath string) {
+	nodeID := graph.NodeID(te.generateResourceID(resource))
+	if _, exists := result.ResourceGraph.Nodes[nodeID]; !exists {
+		if te.addDiscoveredResource(ctx, result, resource, compositeNode.DiscoveryDepth+1) == nil {
+			return
+		}
+	}
+
+	te.logger.Debug("Linking composition metadata",
+		logfields.ResourceID, compositeNode.ID,
+		"target", nodeID,
+		"relationType", relationType)
+	edgeCount := len(result.ResourceGraph.Edges)
+	te.components.GraphBuilder.AddEdge(result.ResourceGraph, compositeNode.ID, nodeID, relationType, fieldPath, string(relationType), 1.0)
+	countNewEdge(result, relationType, edgeCount)
+}
+
+// compositionMetadataError reports a failed XRD or Composition lookup
+func compositionMetadataError(resourceID, message string, err error) TraversalError {
+	return TraversalError{
+		Type:        TraversalErrorAPICall,
+		Message:     fmt.Sprintf("%s: %v", message, err),
+		ResourceID:  resourceID,
+		Timestamp:   time.Now(),
+		Recoverable: true,
+	}
+}
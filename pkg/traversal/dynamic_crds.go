@@ -0,0 +1,202 @@
+package traversal
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	dynamictypes "github.com/crossplane/function-kubecore-schema-registry/pkg/dynamic"
+)
+
+// crdMissRetryInterval is how long a kind no CRD served is remembered as
+// missing before discovery is tried again, so CRDs installed since are found
+const crdMissRetryInterval = 30 * time.Second
+
+// discoveredCRD is the remembered outcome of discovering the CRD of a kind
+type discoveredCRD struct {
+	// crd is the CRD serving the kind, or nil if none did
+	crd *dynamictypes.CRDInfo
+
+	// checkedAt is when the discovery ran
+	checkedAt time.Time
+}
+
+// SetCRDDiscoverer sets the discoverer used to learn the GVR and scope of
+// target kinds the registry does not know
+func (rr *DefaultReferenceResolver) SetCRDDiscoverer(discoverer dynamictypes.CRDDiscoverer) {
+	rr.crdMu.Lock()
+	defer rr.crdMu.Unlock()
+
+	rr.crdDiscoverer = discoverer
+	rr.forgetDiscoveredCRDs()
+}
+
+// SetRESTMapper sets a RESTMapper consulted for the resource and scope of
+// target kinds, ahead of CRD discovery and the pluralization heuristics. It
+// must be called before discovery starts.
+func (rr *DefaultReferenceResolver) SetRESTMapper(mapper meta.RESTMapper) {
+	rr.restMapper = mapper
+}
+
+// restMapping returns the REST mapper's mapping for a kind, or false when
+// there is no mapper or it does not know the kind. An empty version maps to
+// the kind's preferred version.
+func (rr *DefaultReferenceResolver) restMapping(group, version, kind string) (*meta.RESTMapping, bool) {
+	if rr.restMapper == nil {
+		return nil, false
+	}
+	var versions []string
+	if version != "" {
+		versions = append(versions, version)
+	}
+	mapping, err := rr.restMapper.RESTMapping(schema.GroupKind{Group: group, Kind: kind}, versions...)
+	if err != nil {
+		return nil, false
+	}
+	return mapping, true
+}
+
+// SetDynamicCRDs controls whether target kinds unknown to the registry are
+// looked up through CRD discovery. Kinds discovered earlier are forgotten so
+// CRDs installed since then are picked up.
+func (rr *DefaultReferenceResolver) SetDynamicCRDs(enabled bool) {
+	rr.updateOptions(func(options *resolutionOptions) {
+		options.dynamicCRDs = enabled
+	})
+
+	rr.crdMu.Lock()
+	defer rr.crdMu.Unlock()
+	rr.forgetDiscoveredCRDs()
+}
+
+// forgetDiscoveredCRDs drops remembered discoveries, including those still
+// running. crdMu must be held.
+func (rr *DefaultReferenceResolver) forgetDiscoveredCRDs() {
+	rr.discoveredCRDs = nil
+	rr.crdGeneration++
+}
+
+// targetGVR returns the GVR and scope used to look up a reference's targets.
+// Kinds the REST mapper knows are resolved through it. Others the registry
+// does not know are resolved through CRD discovery when dynamic CRDs are
+// enabled, falling back to the pluralization heuristics.
+func (rr *DefaultReferenceResolver) targetGVR(ctx context.Context, reference dynamictypes.ReferenceField, options *resolutionOptions) (schema.GroupVersionResource, bool, error) {
+	gvr, err := rr.buildGVR(reference.TargetGroup, reference.TargetVersion, reference.TargetKind, options.groupAliases)
+	if err != nil {
+		return schema.GroupVersionResource{}, false, err
+	}
+	if mapping, ok := rr.restMapping(gvr.Group, reference.TargetVersion, reference.TargetKind); ok {
+		return mapping.Resource, mapping.Scope.Name() == meta.RESTScopeNameRoot, nil
+	}
+	isClusterScoped := rr.isClusterScopedResource(reference.TargetKind, reference.TargetGroup)
+
+	if !options.dynamicCRDs {
+		return gvr, isClusterScoped, nil
+	}
+	if crd := rr.discoverTargetCRD(ctx, gvr.Group, reference.TargetKind); crd != nil {
+		version := reference.TargetVersion
+		if version == "" {
+			version = crd.Version
+		}
+		gvr = schema.GroupVersionResource{Group: crd.Group, Version: version, Resource: crd.Plural}
+		isClusterScoped = !crd.Namespaced
+
+		rr.logger.Debug("Using dynamically discovered CRD for reference target",
+			"targetKind", reference.TargetKind,
+			"crd", crd.Name,
+			"gvr", gvr.String())
+	}
+
+	return gvr, isClusterScoped, nil
+}
+
+// discoverTargetCRD returns the CRD serving a kind the registry does not know,
+// or nil when the kind is known or no CRD serves it. Discovered CRDs are
+// remembered until SetDynamicCRDs or SetCRDDiscoverer is called, misses for
+// crdMissRetryInterval. Concurrent discoveries of the same kind share one
+// lookup, and no lock is held while it runs.
+func (rr *DefaultReferenceResolver) discoverTargetCRD(ctx context.Context, group, kind string) *dynamictypes.CRDInfo {
+	groupKind := schema.GroupKind{Group: group, Kind: kind}
+
+	rr.crdMu.Lock()
+	discoverer := rr.crdDiscoverer
+	generation := rr.crdGeneration
+	discovered, seen := rr.discoveredCRDs[groupKind]
+	rr.crdMu.Unlock()
+
+	if discoverer == nil {
+		return nil
+	}
+	if seen && (discovered.crd != nil || time.Since(discovered.checkedAt) < crdMissRetryInterval) {
+		return discovered.crd
+	}
+	if rr.isRegisteredKind(group, kind) {
+		return nil
+	}
+
+	key := fmt.Sprintf("%d/%s", generation, groupKind)
+	result, _, _ := rr.crdLookups.Do(key, func() (interface{}, error) {
+		return rr.lookupTargetCRD(ctx, discoverer, generation, groupKind), nil
+	})
+	crd, _ := result.(*dynamictypes.CRDInfo)
+	return crd
+}
+
+// lookupTargetCRD discovers the CRD serving a kind and remembers the outcome
+func (rr *DefaultReferenceResolver) lookupTargetCRD(ctx context.Context, discoverer dynamictypes.CRDDiscoverer, generation uint64, groupKind schema.GroupKind) *dynamictypes.CRDInfo {
+	pattern := groupKind.Group
+	if pattern == "" {
+		pattern = "*"
+	}
+
+	rr.apiCalls.Add(1)
+	crds, err := discoverer.DiscoverCRDs(ctx, []string{pattern})
+	if err != nil {
+		// Not remembered, so a later reference can retry the discovery
+		rr.logger.Debug("CRD discovery for reference target failed",
+			"group", groupKind.Group,
+			"targetKind", groupKind.Kind,
+			"error", err)
+		return nil
+	}
+
+	var match *dynamictypes.CRDInfo
+	for _, crd := range crds {
+		if crd.Kind == groupKind.Kind && (groupKind.Group == "" || crd.Group == groupKind.Group) {
+			match = crd
+			break
+		}
+	}
+
+	rr.crdMu.Lock()
+	defer rr.crdMu.Unlock()
+
+	// Discoveries forgotten while the lookup ran are not remembered again
+	if rr.crdGeneration != generation {
+		return match
+	}
+	if rr.discoveredCRDs == nil {
+		rr.discoveredCRDs = make(map[schema.GroupKind]discoveredCRD)
+	}
+	rr.discoveredCRDs[groupKind] = discoveredCRD{crd: match, checkedAt: time.Now()}
+
+	return match
+}
+
+// isRegisteredKind reports whether the registry knows a kind in any version
+func (rr *DefaultReferenceResolver) isRegisteredKind(group, kind string) bool {
+	resourceTypes, err := rr.registry.ListResourceTypes()
+	if err != nil {
+		return false
+	}
+
+	for _, resourceType := range resourceTypes {
+		if resourceType.Group == group && resourceType.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
@@ -2,13 +2,19 @@ package traversal
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 
 	"golang.org/x/sync/errgroup"
+	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -18,9 +24,13 @@ import (
 	dynamictypes "github.com/crossplane/function-kubecore-schema-registry/pkg/dynamic"
 	functionerrors "github.com/crossplane/function-kubecore-schema-registry/pkg/errors"
 	"github.com/crossplane/function-kubecore-schema-registry/pkg/graph"
+	"github.com/crossplane/function-kubecore-schema-registry/pkg/logfields"
 	"github.com/crossplane/function-kubecore-schema-registry/pkg/registry"
 )
 
+// defaultPlatformAPIGroups are the API group patterns of platform resources
+var defaultPlatformAPIGroups = []string{"*.kubecore.io"}
+
 // DefaultTraversalEngine implements the TraversalEngine interface
 type DefaultTraversalEngine struct {
 	// components contains all the required components
@@ -35,8 +45,17 @@ type DefaultTraversalEngine struct {
 	// metricsCollector collects performance metrics
 	metricsCollector *MetricsCollector
 
+	// resourceTransform rewrites resources before they enter the graph
+	resourceTransform ResourceTransform
+
+	// crdDiscoverer discovers the CRDs of target kinds missing from the registry
+	crdDiscoverer *dynamictypes.DefaultCRDDiscoverer
+
 	// mu protects internal state
 	mu sync.RWMutex
+
+	// closed indicates Close has already released the engine's resources
+	closed bool
 }
 
 // NewDefaultTraversalEngine creates a new default traversal engine
@@ -51,14 +70,24 @@ func NewDefaultTraversalEngine(config *rest.Config, registry registry.Registry,
 		return nil, functionerrors.Wrap(err, "failed to create typed client")
 	}
 
+	apiextensionsClient, err := apiextensionsclientset.NewForConfig(config)
+	if err != nil {
+		return nil, functionerrors.Wrap(err, "failed to create apiextensions client")
+	}
+
 	// Create platform checker for scope filtering
-	platformChecker := NewDefaultPlatformChecker([]string{"*.kubecore.io"})
+	platformChecker := NewDefaultPlatformChecker(defaultPlatformAPIGroups)
+
+	// Target kinds missing from the registry are learned from their CRDs
+	referenceResolver := NewDefaultReferenceResolver(dynamicClient, registry, logger)
+	crdDiscoverer := dynamictypes.NewCRDDiscoverer(apiextensionsClient, logger)
+	referenceResolver.SetCRDDiscoverer(crdDiscoverer)
 
 	components := TraversalEngineComponents{
 		DynamicClient:     dynamicClient,
 		TypedClient:       typedClient,
 		Registry:          registry,
-		ReferenceResolver: NewDefaultReferenceResolver(dynamicClient, registry, logger),
+		ReferenceResolver: referenceResolver,
 		ScopeFilter:       NewDefaultScopeFilter(platformChecker, logger),
 		BatchOptimizer:    NewDefaultBatchOptimizer(logger),
 		Cache:             NewLRUCache(DefaultCacheMaxSize, DefaultCacheTTL),
@@ -69,24 +98,87 @@ func NewDefaultTraversalEngine(config *rest.Config, registry registry.Registry,
 
 	engine := &DefaultTraversalEngine{
 		components:       components,
-		logger:           logger,
+		logger:           logfields.ForComponent(logger, logfields.ComponentTraversalEngine),
 		resourceTracker:  NewResourceTracker(),
 		metricsCollector: NewMetricsCollector(true),
+		crdDiscoverer:    crdDiscoverer,
 	}
 
 	return engine, nil
 }
 
+// SetRESTMapper sets a RESTMapper the reference resolver consults for the
+// resource and scope of target kinds. It must be called before discovery starts.
+func (te *DefaultTraversalEngine) SetRESTMapper(mapper meta.RESTMapper) {
+	if resolver, ok := te.components.ReferenceResolver.(interface{ SetRESTMapper(meta.RESTMapper) }); ok {
+		resolver.SetRESTMapper(mapper)
+	}
+}
+
+// SetCRDListLimits rate limits the CRD Lists made to discover target kinds to
+// qps per second, in bursts of up to burst, and bounds those in flight at once
+// to maxConcurrent. Zero leaves either unlimited. It must be called before
+// discovery starts.
+func (te *DefaultTraversalEngine) SetCRDListLimits(qps float64, burst, maxConcurrent int) {
+	if te.crdDiscoverer == nil {
+		return
+	}
+	te.crdDiscoverer.SetRateLimit(qps, burst)
+	te.crdDiscoverer.SetMaxConcurrentDiscoveries(maxConcurrent)
+}
+
+// Close flushes and stops the engine's caches and resets its metrics. It is
+// safe to call more than once.
+func (te *DefaultTraversalEngine) Close() error {
+	te.mu.Lock()
+	defer te.mu.Unlock()
+
+	if te.closed {
+		return nil
+	}
+	te.closed = true
+
+	if te.components.Cache != nil {
+		te.components.Cache.Clear()
+		closeCache(te.components.Cache)
+	}
+
+	if resolver, ok := te.components.ReferenceResolver.(interface{ Close() }); ok {
+		resolver.Close()
+	}
+
+	te.metricsCollector.Reset()
+	te.resourceTracker.Reset()
+
+	return nil
+}
+
 // ExecuteTransitiveDiscovery performs transitive discovery starting from root resources
 func (te *DefaultTraversalEngine) ExecuteTransitiveDiscovery(ctx context.Context, config *TraversalConfig, rootResources []*unstructured.Unstructured) (*TraversalResult, error) {
 	startTime := time.Now()
 
+	if config.MaxDepth < 0 {
+		return nil, fmt.Errorf("invalid max depth %d: must be 0 or greater", config.MaxDepth)
+	}
+	if config.MaxEdges < 0 {
+		return nil, fmt.Errorf("invalid max edges %d: must be 0 or greater", config.MaxEdges)
+	}
+
 	te.logger.Info("Starting transitive discovery",
 		"rootResourceCount", len(rootResources),
 		"maxDepth", config.MaxDepth,
 		"maxResources", config.MaxResources,
+		"maxEdges", config.MaxEdges,
 		"timeout", config.Timeout)
 
+	// Apply namespace rewrites and defaults, group aliases, reference patterns
+	// and the owner reference scope before any reference is extracted or resolved
+	ctx, err := te.withResolutionOptions(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	ctx, warnings := withWarningCollector(ctx)
+
 	// Apply timeout from config
 	if config.Timeout > 0 {
 		var cancel context.CancelFunc
@@ -102,10 +194,12 @@ func (te *DefaultTraversalEngine) ExecuteTransitiveDiscovery(ctx context.Context
 			Steps:     make([]TraversalStep, 0),
 			StartTime: startTime,
 		},
+		Errors: make([]TraversalError, 0),
 		Statistics: &TraversalStatistics{
 			ResourcesByDepth:    make(map[int]int),
 			ResourcesByKind:     make(map[string]int),
 			ResourcesByAPIGroup: make(map[string]int),
+			EdgesByRelationType: make(map[graph.RelationType]int),
 			MemoryUsage:         &MemoryUsageStats{},
 			PerformanceMetrics:  &PerformanceMetrics{},
 		},
@@ -118,6 +212,7 @@ func (te *DefaultTraversalEngine) ExecuteTransitiveDiscovery(ctx context.Context
 			},
 		},
 	}
+	result.ResourceGraph.Metadata.MaxEdges = config.MaxEdges
 
 	// Initialize metrics collection
 	if config.Performance.EnableMetrics {
@@ -127,11 +222,18 @@ func (te *DefaultTraversalEngine) ExecuteTransitiveDiscovery(ctx context.Context
 	// Reset resource tracker
 	te.resourceTracker.Reset()
 
+	// Every expansion reserves from one budget so concurrent resolution
+	// cannot overshoot MaxResources
+	budget := newResourceBudget(config.MaxResources)
+	budget.charge(len(rootResources))
+	ctx = withResourceBudget(ctx, budget)
+
 	// Add root resources to graph and resource tracker
 	for _, resource := range rootResources {
-		te.components.GraphBuilder.AddNode(result.ResourceGraph, resource, 0, []graph.NodeID{})
+		stored := te.transformResource(resource)
+		te.components.GraphBuilder.AddNode(result.ResourceGraph, stored, 0, []graph.NodeID{})
 		resourceID := te.generateResourceID(resource)
-		result.DiscoveredResources[resourceID] = resource
+		result.DiscoveredResources[resourceID] = stored
 		te.resourceTracker.MarkProcessed(resourceID, 0)
 
 		// Update statistics
@@ -154,6 +256,14 @@ func (te *DefaultTraversalEngine) ExecuteTransitiveDiscovery(ctx context.Context
 		traversalError = fmt.Errorf("unsupported traversal direction: %s", config.Direction)
 	}
 
+	// Link discovered composites to their XRD and Composition
+	if traversalError == nil && config.IncludeCompositionMetadata {
+		te.linkCompositionMetadata(ctx, config, result)
+	}
+
+	// Collect warnings the resolver noticed while extracting references
+	result.Warnings = append(result.Warnings, warnings.take()...)
+
 	// Complete traversal path
 	result.TraversalPath.EndTime = time.Now()
 	result.TraversalPath.Duration = result.TraversalPath.EndTime.Sub(result.TraversalPath.StartTime)
@@ -164,6 +274,8 @@ func (te *DefaultTraversalEngine) ExecuteTransitiveDiscovery(ctx context.Context
 		result.Metadata.TerminationReason = TerminationReasonError
 		te.logger.Info("Transitive discovery failed", "error", traversalError)
 		return result, traversalError
+	} else if result.ResourceGraph.Metadata.EdgesTruncated {
+		result.Metadata.TerminationReason = TerminationReasonMaxEdges
 	} else if result.Statistics.TotalResources >= config.MaxResources {
 		result.Metadata.TerminationReason = TerminationReasonMaxResources
 	} else if result.TraversalPath.MaxDepthReached >= config.MaxDepth {
@@ -172,8 +284,13 @@ func (te *DefaultTraversalEngine) ExecuteTransitiveDiscovery(ctx context.Context
 		result.Metadata.TerminationReason = TerminationReasonCompleted
 	}
 
-	// Detect cycles if enabled
-	if config.CycleHandling.DetectionEnabled {
+	// Detect cycles if enabled, skipping graphs too small to be worth checking
+	if config.CycleHandling.DetectionEnabled && len(result.ResourceGraph.Nodes) < config.CycleHandling.MinNodesForDetection {
+		te.logger.Debug("Skipping cycle detection below node threshold",
+			"nodes", len(result.ResourceGraph.Nodes),
+			"minNodesForDetection", config.CycleHandling.MinNodesForDetection)
+		result.Metadata.CycleDetectionSkipped = true
+	} else if config.CycleHandling.DetectionEnabled {
 		cycleResult := te.components.CycleDetector.DetectCycles(result.ResourceGraph)
 		result.CycleResults = cycleResult
 
@@ -183,6 +300,11 @@ func (te *DefaultTraversalEngine) ExecuteTransitiveDiscovery(ctx context.Context
 		}
 	}
 
+	// Goal-directed discovery reports only resources of the target kinds
+	if len(config.TargetKinds) > 0 {
+		te.collectTargetKinds(result, config.TargetKinds)
+	}
+
 	// Validate result
 	result.ValidationResult = te.ValidateTraversalResult(result)
 
@@ -206,6 +328,17 @@ func (te *DefaultTraversalEngine) ExecuteTransitiveDiscovery(ctx context.Context
 func (te *DefaultTraversalEngine) DiscoverReferencedResources(ctx context.Context, resources []*unstructured.Unstructured, config *TraversalConfig) (*DiscoveryResult, error) {
 	startTime := time.Now()
 
+	ctx, err := te.withResolutionOptions(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	// Within a transitive discovery warnings go to its collector instead
+	var warnings *warningCollector
+	if warningCollectorFrom(ctx) == nil {
+		ctx, warnings = withWarningCollector(ctx)
+	}
+
 	result := &DiscoveryResult{
 		Resources:  make([]*unstructured.Unstructured, 0),
 		References: make(map[string][]dynamictypes.ReferenceField),
@@ -216,11 +349,22 @@ func (te *DefaultTraversalEngine) DiscoverReferencedResources(ctx context.Contex
 		Errors: make([]TraversalError, 0),
 	}
 
+	apiCallsBefore := te.resolverAPICalls()
+
+	// Resources at this depth share target lookups, so each target is fetched once
+	ctx = withResolutionMemo(ctx)
+
 	// Use errgroup for concurrent processing
 	g, gCtx := errgroup.WithContext(ctx)
 
-	// Semaphore to limit concurrent requests
-	sem := make(chan struct{}, config.Performance.MaxConcurrentRequests)
+	// Extraction is CPU-bound and resolution IO-bound, so each has its own
+	// limit and one cannot starve the other
+	maxExtraction := config.Performance.MaxConcurrentExtraction
+	if maxExtraction <= 0 {
+		maxExtraction = config.Performance.MaxConcurrentRequests
+	}
+	extractSem := make(chan struct{}, maxExtraction)
+	resolveSem := make(chan struct{}, config.Performance.MaxConcurrentRequests)
 
 	// Results collection
 	var mu sync.Mutex
@@ -231,79 +375,61 @@ func (te *DefaultTraversalEngine) DiscoverReferencedResources(ctx context.Contex
 	for _, resource := range resources {
 		resource := resource // Capture loop variable
 		g.Go(func() error {
-			// Acquire semaphore
-			sem <- struct{}{}
-			defer func() { <-sem }()
-
 			resourceID := te.generateResourceID(resource)
 
-			// Extract references from this resource
-			references, err := te.components.ReferenceResolver.ExtractReferences(gCtx, resource)
+			// Nothing resolved now could be added to the traversal
+			if resourceBudgetFrom(gCtx).exhausted() {
+				return nil
+			}
+
+			filteredReferences, resolutionResults, err := te.resolveResourceReferences(gCtx, resource, config, extractSem, resolveSem)
 			if err != nil {
 				mu.Lock()
-				result.Errors = append(result.Errors, TraversalError{
-					Type:        TraversalErrorReferenceResolution,
-					Message:     fmt.Sprintf("Failed to extract references: %v", err),
-					ResourceID:  resourceID,
-					Depth:       1,
-					Timestamp:   time.Now(),
-					Recoverable: true,
-				})
+				result.Errors = appendTraversalError(result.Errors, extractionError(resourceID, err))
 				mu.Unlock()
 				return nil // Don't fail the entire operation
 			}
 
-			// Apply confidence threshold filtering to remove false positives
-			highConfidenceReferences := make([]dynamictypes.ReferenceField, 0)
-			for _, ref := range references {
-				// Skip references with low confidence AND empty TargetKind (likely false positives)
-				if ref.Confidence < 0.7 && ref.TargetKind == "" {
-					te.logger.Debug("Filtered out low-confidence reference with empty TargetKind",
-						"fieldName", ref.FieldName,
-						"fieldPath", ref.FieldPath,
-						"confidence", ref.Confidence,
-						"detectionMethod", ref.DetectionMethod)
-					continue
-				}
-				highConfidenceReferences = append(highConfidenceReferences, ref)
-			}
-
-			te.logger.Debug("Applied confidence threshold filtering",
-				"originalReferences", len(references),
-				"filteredReferences", len(highConfidenceReferences),
-				"filteredOut", len(references)-len(highConfidenceReferences))
-
-			// Filter references based on scope
-			filteredReferences := te.components.ScopeFilter.FilterReferences(highConfidenceReferences, config.ScopeFilter)
-
-			// Resolve references to actual resources
-			referencedResources, resolveErrors := te.components.ReferenceResolver.ResolveReferences(gCtx, resource, filteredReferences)
-
 			// Collect results
+			var forbidden error
 			mu.Lock()
 			allReferences[resourceID] = filteredReferences
 
-			for _, referencedResource := range referencedResources {
-				referencedID := te.generateResourceID(referencedResource)
-				if _, exists := discoveredResources[referencedID]; !exists {
-					discoveredResources[referencedID] = referencedResource
+			for _, resolution := range resolutionResults {
+				// List-valued references resolve to several resources, one edge each
+				for _, referencedResource := range resolution.ResolvedResources {
+					referencedID := te.generateResourceID(referencedResource)
+					if _, exists := discoveredResources[referencedID]; !exists {
+						discoveredResources[referencedID] = referencedResource
+					}
+					result.Edges = append(result.Edges, ReferenceEdge{
+						SourceID:  resourceID,
+						TargetID:  referencedID,
+						Reference: resolution.Reference,
+					})
 				}
-			}
 
-			// Add resolve errors
-			for _, resolveErr := range resolveErrors {
-				result.Errors = append(result.Errors, TraversalError{
-					Type:        TraversalErrorReferenceResolution,
-					Message:     resolveErr.Error(),
-					ResourceID:  resourceID,
-					Depth:       1,
-					Timestamp:   time.Now(),
-					Recoverable: config.ReferenceResolution.SkipMissingReferences,
-				})
+				// Access denied is reported by the resolver as a warning, not an error
+				if resolution.Error != nil && apierrors.IsForbidden(resolution.Error) {
+					if config.ReferenceResolution.FailOnForbidden && forbidden == nil {
+						forbidden = functionerrors.Wrap(resolution.Error, fmt.Sprintf("access denied resolving %s of %s", resolution.Reference.FieldPath, resourceID))
+					}
+					continue
+				}
+
+				// So is a target of the wrong kind
+				if errors.Is(resolution.Error, errKindMismatch) {
+					continue
+				}
+
+				// Add resolve errors
+				if resolution.Error != nil {
+					result.Errors = appendTraversalError(result.Errors, te.resolutionError(resourceID, resolution, config))
+				}
 			}
 
 			mu.Unlock()
-			return nil
+			return forbidden
 		})
 	}
 
@@ -318,13 +444,140 @@ func (te *DefaultTraversalEngine) DiscoverReferencedResources(ctx context.Contex
 	}
 
 	result.References = allReferences
+	if warnings != nil {
+		result.Warnings = warnings.take()
+	}
 	result.Statistics.ResourcesFound = len(result.Resources)
 	result.Statistics.ReferencesDetected = len(allReferences)
+	result.Statistics.APICallsToThisDepth = int(te.resolverAPICalls() - apiCallsBefore)
 	result.Statistics.DiscoveryTime = time.Since(startTime)
 
 	return result, nil
 }
 
+// resolveResourceReferences extracts the references of one resource, drops
+// likely false positives and out-of-scope references, and resolves the rest.
+// extractSem and resolveSem bound concurrent extraction and resolution.
+func (te *DefaultTraversalEngine) resolveResourceReferences(ctx context.Context, resource *unstructured.Unstructured, config *TraversalConfig, extractSem, resolveSem chan struct{}) ([]dynamictypes.ReferenceField, []*ReferenceResolutionResult, error) {
+	// Extract references from this resource
+	extractSem <- struct{}{}
+	references, err := te.components.ReferenceResolver.ExtractReferences(ctx, resource)
+	<-extractSem
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Apply confidence threshold filtering to remove false positives
+	highConfidenceReferences := make([]dynamictypes.ReferenceField, 0)
+	for _, ref := range references {
+		// Skip references with low confidence AND empty TargetKind (likely false positives)
+		if ref.Confidence < 0.7 && ref.TargetKind == "" {
+			te.logger.Debug("Filtered out low-confidence reference with empty TargetKind",
+				"fieldName", ref.FieldName,
+				logfields.FieldPath, ref.FieldPath,
+				"confidence", ref.Confidence,
+				"detectionMethod", ref.DetectionMethod)
+			continue
+		}
+		highConfidenceReferences = append(highConfidenceReferences, ref)
+	}
+
+	te.logger.Debug("Applied confidence threshold filtering",
+		"originalReferences", len(references),
+		"filteredReferences", len(highConfidenceReferences),
+		"filteredOut", len(references)-len(highConfidenceReferences))
+
+	// Filter references based on scope
+	filteredReferences := te.components.ScopeFilter.FilterReferences(highConfidenceReferences, config.ScopeFilter)
+
+	// Resolve references to actual resources
+	resolveSem <- struct{}{}
+	resolutionResults := te.components.ReferenceResolver.ResolveReferenceResults(ctx, resource, filteredReferences)
+	<-resolveSem
+
+	return filteredReferences, resolutionResults, nil
+}
+
+// extractionError reports a resource whose references could not be extracted
+func extractionError(resourceID string, err error) TraversalError {
+	return TraversalError{
+		Type:        TraversalErrorReferenceResolution,
+		Message:     fmt.Sprintf("Failed to extract references: %v", err),
+		ResourceID:  resourceID,
+		Depth:       1,
+		Timestamp:   time.Now(),
+		Recoverable: true,
+	}
+}
+
+// resolutionError reports a reference that failed to resolve. References
+// skipped by the namespace boundary are expected and never fail the traversal.
+func (te *DefaultTraversalEngine) resolutionError(resourceID string, resolution *ReferenceResolutionResult, config *TraversalConfig) TraversalError {
+	errorType := TraversalErrorReferenceResolution
+	recoverable := config.ReferenceResolution.SkipMissingReferences
+	if errors.Is(resolution.Error, errCrossNamespaceReference) {
+		errorType = TraversalErrorScopeFilter
+		recoverable = true
+		te.logger.Info("Skipped cross-namespace reference",
+			logfields.ResourceID, resourceID,
+			logfields.FieldPath, resolution.Reference.FieldPath)
+	}
+
+	return TraversalError{
+		Type:        errorType,
+		Message:     resolution.Error.Error(),
+		ResourceID:  resourceID,
+		Depth:       1,
+		Timestamp:   time.Now(),
+		Recoverable: recoverable,
+		Context: map[string]interface{}{
+			"fieldPath": resolution.Reference.FieldPath,
+			"targetGVK": schema.GroupVersionKind{
+				Group:   resolution.Reference.TargetGroup,
+				Version: resolution.Reference.TargetVersion,
+				Kind:    resolution.Reference.TargetKind,
+			},
+		},
+	}
+}
+
+// ResolveAllReferences resolves the references of the given resources to flat
+// source to target edges, skipping graph construction
+func (te *DefaultTraversalEngine) ResolveAllReferences(ctx context.Context, resources []*unstructured.Unstructured, config *TraversalConfig) ([]ResolvedReference, error) {
+	discoveryResult, err := te.DiscoverReferencedResources(ctx, resources, config)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make(map[string]*unstructured.Unstructured, len(resources))
+	for _, resource := range resources {
+		sources[te.generateResourceID(resource)] = resource
+	}
+	targets := make(map[string]*unstructured.Unstructured, len(discoveryResult.Resources))
+	for _, resource := range discoveryResult.Resources {
+		targets[te.generateResourceID(resource)] = resource
+	}
+
+	resolved := make([]ResolvedReference, 0, len(discoveryResult.Edges))
+	for _, edge := range discoveryResult.Edges {
+		source, target := sources[edge.SourceID], targets[edge.TargetID]
+		if source == nil || target == nil {
+			continue
+		}
+		resolved = append(resolved, ResolvedReference{
+			SourceGVK:       source.GroupVersionKind(),
+			SourceNamespace: source.GetNamespace(),
+			SourceName:      source.GetName(),
+			Target:          target,
+			RelationType:    relationTypeForReference(edge.Reference.RefType),
+			Confidence:      edge.Reference.Confidence,
+			FieldPath:       edge.Reference.FieldPath,
+		})
+	}
+
+	return resolved, nil
+}
+
 // BuildResourceGraph builds a resource dependency graph from discovered resources
 func (te *DefaultTraversalEngine) BuildResourceGraph(ctx context.Context, resources []*unstructured.Unstructured, config *TraversalConfig) (*graph.ResourceGraph, error) {
 	// Extract all references first
@@ -334,7 +587,7 @@ func (te *DefaultTraversalEngine) BuildResourceGraph(ctx context.Context, resour
 		resourceID := te.generateResourceID(resource)
 		references, err := te.components.ReferenceResolver.ExtractReferences(ctx, resource)
 		if err != nil {
-			te.logger.Debug("Failed to extract references for resource", "resourceID", resourceID, "error", err)
+			te.logger.Debug("Failed to extract references for resource", logfields.ResourceID, resourceID, "error", err)
 			continue
 		}
 
@@ -391,8 +644,8 @@ func (te *DefaultTraversalEngine) ValidateTraversalResult(result *TraversalResul
 		})
 	}
 
-	// Validate depth
-	if result.TraversalPath.MaxDepthReached >= result.Metadata.Config.MaxDepth {
+	// Validate depth (a max depth of 0 is an explicit roots-only request)
+	if result.Metadata.Config.MaxDepth > 0 && result.TraversalPath.MaxDepthReached >= result.Metadata.Config.MaxDepth {
 		validationResult.Warnings = append(validationResult.Warnings, ValidationWarning{
 			Type:     ValidationWarningDeepTraversal,
 			Message:  fmt.Sprintf("Reached maximum traversal depth of %d", result.Metadata.Config.MaxDepth),
@@ -400,6 +653,11 @@ func (te *DefaultTraversalEngine) ValidateTraversalResult(result *TraversalResul
 		})
 	}
 
+	// Flag resources whose references were all weak guesses
+	if resolution := result.Metadata.Config.ReferenceResolution; resolution != nil && resolution.LowConfidenceWarningThreshold > 0 {
+		validationResult.Warnings = append(validationResult.Warnings, lowConfidenceWarnings(result.ResourceGraph, resolution.LowConfidenceWarningThreshold)...)
+	}
+
 	// Validate performance
 	if result.TraversalPath.Duration > result.Metadata.Config.Timeout/2 {
 		validationResult.Warnings = append(validationResult.Warnings, ValidationWarning{
@@ -409,6 +667,18 @@ func (te *DefaultTraversalEngine) ValidateTraversalResult(result *TraversalResul
 		})
 	}
 
+	// Surface references skipped by scope rules
+	for _, traversalError := range result.Errors {
+		if traversalError.Type == TraversalErrorScopeFilter {
+			validationResult.Warnings = append(validationResult.Warnings, ValidationWarning{
+				Type:       ValidationWarningScopeFiltered,
+				Message:    traversalError.Message,
+				ResourceID: traversalError.ResourceID,
+				Severity:   "low",
+			})
+		}
+	}
+
 	// Validate cycles if detected
 	if result.CycleResults != nil && result.CycleResults.CyclesFound {
 		if result.Metadata.Config.CycleHandling.OnCycleDetected == CycleActionFail {
@@ -430,22 +700,71 @@ func (te *DefaultTraversalEngine) ValidateTraversalResult(result *TraversalResul
 	return validationResult
 }
 
+// lowConfidenceWarnings warns, in node ID order, about every resource whose
+// outbound references average a confidence below threshold
+func lowConfidenceWarnings(resourceGraph *graph.ResourceGraph, threshold float64) []ValidationWarning {
+	if resourceGraph == nil {
+		return nil
+	}
+
+	var warnings []ValidationWarning
+	for _, nodeID := range sortedNodeIDs(resourceGraph) {
+		edgeIDs := resourceGraph.AdjacencyList[nodeID]
+		if len(edgeIDs) == 0 {
+			continue
+		}
+
+		total, count := 0.0, 0
+		for _, edgeID := range edgeIDs {
+			if edge, exists := resourceGraph.Edges[edgeID]; exists {
+				total += edge.Confidence
+				count++
+			}
+		}
+		if count == 0 || total/float64(count) >= threshold {
+			continue
+		}
+
+		kind := ""
+		if node := resourceGraph.Nodes[nodeID]; node != nil && node.Metadata != nil {
+			kind = node.Metadata.Kind
+		}
+		warnings = append(warnings, ValidationWarning{
+			Type:       ValidationWarningLowConfidence,
+			Message:    fmt.Sprintf("References of %s average a confidence of %.2f, below %.2f; consider adding the %s kind to the registry", kind, total/float64(count), threshold, kind),
+			ResourceID: string(nodeID),
+			Severity:   "low",
+		})
+	}
+	return warnings
+}
+
 // Helper methods for different traversal strategies
 
 // executeForwardTraversal executes forward (following outbound references) traversal
 func (te *DefaultTraversalEngine) executeForwardTraversal(ctx context.Context, config *TraversalConfig, rootResources []*unstructured.Unstructured, result *TraversalResult) error {
+	if config.SchedulingMode == SchedulingModeBestFirst {
+		return te.executeBestFirstTraversal(ctx, config, rootResources, result)
+	}
+
 	currentResources := rootResources
+	budget := resourceBudgetFrom(ctx)
 
 	for depth := 1; depth <= config.MaxDepth && len(currentResources) > 0; depth++ {
 		if ctx.Err() != nil {
 			return ctx.Err()
 		}
 
-		if result.Statistics.TotalResources >= config.MaxResources {
+		if budget.exhausted() {
+			break
+		}
+
+		if result.ResourceGraph.Metadata.EdgesTruncated {
+			te.logger.Debug("Stopping traversal at edge limit", logfields.Depth, depth, "maxEdges", config.MaxEdges)
 			break
 		}
 
-		te.logger.Debug("Processing traversal depth", "depth", depth, "resourceCount", len(currentResources))
+		te.logger.Debug("Processing traversal depth", logfields.Depth, depth, "resourceCount", len(currentResources))
 
 		// Discover referenced resources at this depth
 		discoveryResult, err := te.DiscoverReferencedResources(ctx, currentResources, config)
@@ -455,7 +774,7 @@ func (te *DefaultTraversalEngine) executeForwardTraversal(ctx context.Context, c
 
 		// Add comprehensive debug logging for discovery results
 		te.logger.Debug("Discovery results at depth",
-			"depth", depth,
+			logfields.Depth, depth,
 			"inputResources", len(currentResources),
 			"discoveredResources", len(discoveryResult.Resources),
 			"totalReferences", discoveryResult.Statistics.ReferencesDetected,
@@ -464,41 +783,49 @@ func (te *DefaultTraversalEngine) executeForwardTraversal(ctx context.Context, c
 
 		// Log details of discovered resources
 		for i, resource := range discoveryResult.Resources {
-			te.logger.Debug("Discovered resource",
-				"index", i,
-				"kind", resource.GetKind(),
-				"name", resource.GetName(),
-				"namespace", resource.GetNamespace(),
-				"apiVersion", resource.GetAPIVersion())
+			te.logger.Debug("Discovered resource", logfields.Resource(resource, "index", i)...)
 		}
 
-		// Log resolution errors if any
+		// Log resolution errors if any and keep them on the result
 		for i, err := range discoveryResult.Errors {
 			te.logger.Debug("Discovery error",
 				"index", i,
 				"error", err.Message,
-				"resourceID", err.ResourceID,
+				logfields.ResourceID, err.ResourceID,
 				"recoverable", err.Recoverable)
+
+			err.Depth = depth
+			result.Errors = appendTraversalError(result.Errors, err)
 		}
 
-		// Filter new resources (not already discovered)
+		// Filter new resources (not already discovered). Resources moved to a
+		// shorter depth are followed again but were not added by this step.
+		totalResources := result.Statistics.TotalResources
 		newResources := make([]*unstructured.Unstructured, 0)
 		for _, resource := range discoveryResult.Resources {
-			resourceID := te.generateResourceID(resource)
-			if !te.resourceTracker.IsProcessed(resourceID) {
+			if te.addDiscoveredResource(ctx, result, resource, depth) != nil {
 				newResources = append(newResources, resource)
-				result.DiscoveredResources[resourceID] = resource
-				te.resourceTracker.MarkProcessed(resourceID, depth)
-
-				// Add to graph
-				discoveryPath := te.buildDiscoveryPath(resource, result.ResourceGraph)
-				te.components.GraphBuilder.AddNode(result.ResourceGraph, resource, depth, discoveryPath)
-
-				// Update statistics
-				result.Statistics.TotalResources++
-				result.Statistics.ResourcesByDepth[depth]++
-				result.Statistics.ResourcesByKind[resource.GetKind()]++
-				result.Statistics.ResourcesByAPIGroup[te.extractAPIGroup(resource.GetAPIVersion())]++
+			}
+		}
+
+		// Fold in resources matched by augmentation selectors at this depth
+		seeded, seedErrors := te.listAugmentationResources(ctx, config, depth)
+		for _, err := range seedErrors {
+			result.Errors = appendTraversalError(result.Errors, err)
+		}
+		for _, resource := range seeded {
+			if node := te.addDiscoveredResource(ctx, result, resource, depth); node != nil {
+				node.Metadata.DiscoverySource = graph.DiscoverySourceSelector
+				newResources = append(newResources, resource)
+			}
+		}
+
+		// Terminal kinds and resources rejected by the expand predicate stay in
+		// the graph but their references are not followed
+		expandable := make([]*unstructured.Unstructured, 0, len(newResources))
+		for _, resource := range newResources {
+			if te.isExpandable(resource, config) {
+				expandable = append(expandable, resource)
 			}
 		}
 
@@ -509,32 +836,191 @@ func (te *DefaultTraversalEngine) executeForwardTraversal(ctx context.Context, c
 			Action:             TraversalActionDiscover,
 			ReferencesFound:    discoveryResult.Statistics.ReferencesDetected,
 			ReferencesFollowed: len(newResources),
+			ResourcesAdded:     result.Statistics.TotalResources - totalResources,
+			APICalls:           discoveryResult.Statistics.APICallsToThisDepth,
 			Timestamp:          time.Now(),
 			Duration:           discoveryResult.Statistics.DiscoveryTime,
 		}
 
 		result.TraversalPath.Steps = append(result.TraversalPath.Steps, step)
 		result.TraversalPath.MaxDepthReached = depth
+		result.Statistics.APICallCount += step.APICalls
 
 		// Prepare for next iteration
-		currentResources = newResources
+		currentResources = expandable
 
 		// Add edges to graph based on references
-		te.addReferencesToGraph(result.ResourceGraph, discoveryResult.References)
+		te.addReferencesToGraph(result, discoveryResult.Edges)
 
-		te.logger.Debug("Completed traversal depth", "depth", depth, "newResources", len(newResources), "totalResources", result.Statistics.TotalResources)
+		te.logger.Debug("Completed traversal depth", logfields.Depth, depth, "newResources", len(newResources), "totalResources", result.Statistics.TotalResources)
 	}
 
 	return nil
 }
 
+// addDiscoveredResource adds a resource found at the given depth to the result
+// and graph. It returns nil when the resource was already processed, the
+// scope filter excludes it, or the resource budget carried by ctx is spent.
+// Within a reverse pass a resource already in the graph is returned again
+// when it is reached at a shorter depth, so its references are re-expanded.
+func (te *DefaultTraversalEngine) addDiscoveredResource(ctx context.Context, result *TraversalResult, resource *unstructured.Unstructured, depth int) *graph.ResourceNode {
+	resourceID := te.generateResourceID(resource)
+	if revisitsShorterDepths(ctx) {
+		if !te.resourceTracker.ShouldProcess(resourceID, depth) {
+			return nil
+		}
+		if _, exists := result.DiscoveredResources[resourceID]; exists {
+			return te.moveDiscoveredResource(result, resourceID, depth)
+		}
+	} else {
+		if te.resourceTracker.IsProcessed(resourceID) {
+			return nil
+		}
+		te.resourceTracker.MarkProcessed(resourceID, depth)
+	}
+
+	// Excluded resources are neither added to the graph nor expanded
+	if te.excludesResource(resource, result.Metadata.Config.ScopeFilter) {
+		te.logger.Debug("Excluding resource from graph", logfields.Resource(resource, logfields.Depth, depth)...)
+		return nil
+	}
+
+	if !resourceBudgetFrom(ctx).reserve() {
+		te.logger.Debug("Dropping resource over the resource limit", logfields.Resource(resource, logfields.Depth, depth)...)
+		return nil
+	}
+
+	stored := te.transformResource(resource)
+	result.DiscoveredResources[resourceID] = stored
+
+	// Add to graph
+	discoveryPath := te.buildDiscoveryPath(resource, result.ResourceGraph)
+	node := te.components.GraphBuilder.AddNode(result.ResourceGraph, stored, depth, discoveryPath)
+
+	// Update statistics
+	result.Statistics.TotalResources++
+	result.Statistics.ResourcesByDepth[depth]++
+	result.Statistics.ResourcesByKind[resource.GetKind()]++
+	result.Statistics.ResourcesByAPIGroup[te.extractAPIGroup(resource.GetAPIVersion())]++
+
+	return node
+}
+
+// moveDiscoveredResource moves a resource already in the graph to the shorter
+// depth it was reached at and returns its node
+func (te *DefaultTraversalEngine) moveDiscoveredResource(result *TraversalResult, resourceID string, depth int) *graph.ResourceNode {
+	node := result.ResourceGraph.Nodes[graph.NodeID(resourceID)]
+	if node == nil {
+		return nil
+	}
+
+	if result.Statistics.ResourcesByDepth[node.DiscoveryDepth]--; result.Statistics.ResourcesByDepth[node.DiscoveryDepth] == 0 {
+		delete(result.Statistics.ResourcesByDepth, node.DiscoveryDepth)
+	}
+	result.Statistics.ResourcesByDepth[depth]++
+
+	node.DiscoveryDepth = depth
+	return node
+}
+
+type revisitShorterDepthsKey struct{}
+
+// withRevisitShorterDepths returns a context under which resources already
+// discovered are processed again when reached at a shorter depth
+func withRevisitShorterDepths(ctx context.Context) context.Context {
+	return context.WithValue(ctx, revisitShorterDepthsKey{}, true)
+}
+
+// revisitsShorterDepths reports whether ctx was returned by withRevisitShorterDepths
+func revisitsShorterDepths(ctx context.Context) bool {
+	revisit, _ := ctx.Value(revisitShorterDepthsKey{}).(bool)
+	return revisit
+}
+
+// withResolutionOptions returns a context carrying the resolution options of
+// config for the default resolver. A context that already carries options,
+// as within a transitive discovery, is returned unchanged.
+func (te *DefaultTraversalEngine) withResolutionOptions(ctx context.Context, config *TraversalConfig) (context.Context, error) {
+	resolver, ok := te.components.ReferenceResolver.(*DefaultReferenceResolver)
+	if !ok || config.ReferenceResolution == nil || resolutionOptionsFrom(ctx) != nil {
+		return ctx, nil
+	}
+
+	options, err := resolver.newResolutionOptions(config, te.components.ScopeFilter)
+	if err != nil {
+		return nil, err
+	}
+	return withResolutionOptions(ctx, options), nil
+}
+
+// resolverAPICalls returns the API calls made by the reference resolver so far,
+// or 0 when the resolver does not count them
+func (te *DefaultTraversalEngine) resolverAPICalls() int64 {
+	if counter, ok := te.components.ReferenceResolver.(interface{ APICalls() int64 }); ok {
+		return counter.APICalls()
+	}
+	return 0
+}
+
+// excludesResource reports whether the scope filter drops a discovered resource
+func (te *DefaultTraversalEngine) excludesResource(resource *unstructured.Unstructured, config *ScopeFilterConfig) bool {
+	if excluder, ok := te.components.ScopeFilter.(interface {
+		ExcludesResource(*unstructured.Unstructured, *ScopeFilterConfig) bool
+	}); ok {
+		return excluder.ExcludesResource(resource, config)
+	}
+	return false
+}
+
+// isExpandable reports whether a discovered resource's references should be
+// followed; terminal kinds and resources rejected by the expand predicate are not
+func (te *DefaultTraversalEngine) isExpandable(resource *unstructured.Unstructured, config *TraversalConfig) bool {
+	if te.isTerminalKind(resource.GetKind(), config) {
+		te.logger.Debug("Stopping expansion at terminal kind", logfields.Resource(resource)...)
+		return false
+	}
+	if config.ExpandPredicate != nil && !config.ExpandPredicate(resource) {
+		te.logger.Debug("Stopping expansion rejected by expand predicate", logfields.Resource(resource)...)
+		return false
+	}
+	return true
+}
+
+// isTerminalKind reports whether traversal should stop expanding at the given kind
+func (te *DefaultTraversalEngine) isTerminalKind(kind string, config *TraversalConfig) bool {
+	for _, terminal := range config.TerminalKinds {
+		if terminal == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// collectTargetKinds drops resources whose kind is not a target kind from the
+// discovered resources. Their graph nodes are kept for pathing.
+func (te *DefaultTraversalEngine) collectTargetKinds(result *TraversalResult, targetKinds []string) {
+	excluded := 0
+	for resourceID, resource := range result.DiscoveredResources {
+		if !slices.Contains(targetKinds, resource.GetKind()) {
+			delete(result.DiscoveredResources, resourceID)
+			excluded++
+		}
+	}
+
+	te.logger.Debug("Collected target kinds from traversal",
+		"targetKinds", targetKinds,
+		"collected", len(result.DiscoveredResources),
+		"excluded", excluded)
+}
+
 // executeReverseTraversal executes reverse (following inbound references) traversal
 func (te *DefaultTraversalEngine) executeReverseTraversal(ctx context.Context, config *TraversalConfig, rootResources []*unstructured.Unstructured, result *TraversalResult) error {
 	// Reverse traversal is more complex as we need to find resources that reference our targets
 	// For now, implement a simplified version that discovers forward and then reverses the graph
 
-	// First, do forward discovery to build a complete picture
-	err := te.executeForwardTraversal(ctx, config, rootResources, result)
+	// First, do forward discovery to build a complete picture. Resources an
+	// earlier pass found deeper are re-expanded when this pass reaches them sooner.
+	err := te.executeForwardTraversal(withRevisitShorterDepths(ctx), config, rootResources, result)
 	if err != nil {
 		return err
 	}
@@ -576,11 +1062,22 @@ func (te *DefaultTraversalEngine) executeBidirectionalTraversal(ctx context.Cont
 
 // generateResourceID generates a unique ID for a resource
 func (te *DefaultTraversalEngine) generateResourceID(resource *unstructured.Unstructured) string {
-	return fmt.Sprintf("%s/%s/%s/%s",
+	return resourceIDOf(resource)
+}
+
+// resourceIDOf returns the traversal ID of a resource. Resources resolved from
+// another cluster carry the cluster, matching their graph node ID.
+func resourceIDOf(resource *unstructured.Unstructured) string {
+	id := fmt.Sprintf("%s/%s/%s/%s",
 		resource.GetAPIVersion(),
 		resource.GetKind(),
 		resource.GetNamespace(),
 		resource.GetName())
+
+	if cluster := graph.ResourceCluster(resource); cluster != "" {
+		id += "@" + cluster
+	}
+	return id
 }
 
 // resourceIDs extracts resource IDs from a slice of resources
@@ -594,6 +1091,11 @@ func (te *DefaultTraversalEngine) resourceIDs(resources []*unstructured.Unstruct
 
 // extractAPIGroup extracts the API group from an API version
 func (te *DefaultTraversalEngine) extractAPIGroup(apiVersion string) string {
+	return apiGroupOf(apiVersion)
+}
+
+// apiGroupOf returns the API group of an apiVersion, or "core" for the core group
+func apiGroupOf(apiVersion string) string {
 	parts := strings.Split(apiVersion, "/")
 	if len(parts) == 2 {
 		return parts[0]
@@ -611,37 +1113,67 @@ func (te *DefaultTraversalEngine) buildDiscoveryPath(resource *unstructured.Unst
 }
 
 // addReferencesToGraph adds reference edges to the graph
-func (te *DefaultTraversalEngine) addReferencesToGraph(resourceGraph *graph.ResourceGraph, references map[string][]dynamictypes.ReferenceField) {
-	for sourceResourceID, refFields := range references {
-		sourceNodeID := graph.NodeID(sourceResourceID)
-
-		for _, refField := range refFields {
-			// Build target resource ID (this is simplified)
-			targetResourceID := fmt.Sprintf("%s/%s/%s/%s",
-				refField.TargetGroup,
-				refField.TargetKind,
-				"", // namespace would need to be resolved
-				"") // name would need to be resolved
-
-			targetNodeID := graph.NodeID(targetResourceID)
-
-			// Map dynamic reference type to graph relation type
-			var relationType graph.RelationType
-			switch refField.RefType {
-			case dynamictypes.RefTypeOwnerRef:
-				relationType = graph.RelationTypeOwnerRef
-			case dynamictypes.RefTypeCustom:
-				relationType = graph.RelationTypeCustomRef
-			default:
-				relationType = graph.RelationTypeCustomRef
-			}
-
-			// Add edge if both nodes exist
-			if _, sourceExists := resourceGraph.Nodes[sourceNodeID]; sourceExists {
-				if _, targetExists := resourceGraph.Nodes[targetNodeID]; targetExists {
-					te.components.GraphBuilder.AddEdge(resourceGraph, sourceNodeID, targetNodeID, relationType, refField.FieldPath, refField.FieldName, refField.Confidence)
+func (te *DefaultTraversalEngine) addReferencesToGraph(result *TraversalResult, edges []ReferenceEdge) {
+	resourceGraph := result.ResourceGraph
+	for _, edge := range edges {
+		sourceNodeID := graph.NodeID(edge.SourceID)
+		targetNodeID := graph.NodeID(edge.TargetID)
+		refField := edge.Reference
+		relationType := relationTypeForReference(refField.RefType)
+
+		// Add edge if both nodes exist
+		if _, sourceExists := resourceGraph.Nodes[sourceNodeID]; sourceExists {
+			if _, targetExists := resourceGraph.Nodes[targetNodeID]; targetExists {
+				for _, provenance := range graph.ReferenceProvenances(refField) {
+					te.addEdge(result, sourceNodeID, targetNodeID, relationType, refField.FieldPath, refField.FieldName, provenance)
 				}
 			}
 		}
 	}
 }
+
+// addEdge adds an edge to the result graph, counting it by relation type when
+// it is new rather than merged into an existing edge
+func (te *DefaultTraversalEngine) addEdge(result *TraversalResult, source, target graph.NodeID, relationType graph.RelationType, fieldPath, fieldName string, provenance graph.EdgeProvenance) {
+	edgeCount := len(result.ResourceGraph.Edges)
+	te.components.GraphBuilder.AddEdgeWithProvenance(result.ResourceGraph, source, target, relationType, fieldPath, fieldName, provenance)
+	countNewEdge(result, relationType, edgeCount)
+}
+
+// countNewEdge records an edge of the relation type in the statistics if the
+// graph has grown past edgeCount edges
+func countNewEdge(result *TraversalResult, relationType graph.RelationType, edgeCount int) {
+	if len(result.ResourceGraph.Edges) > edgeCount {
+		result.Statistics.EdgesByRelationType[relationType]++
+	}
+}
+
+// relationTypeForReference maps a dynamic reference type to a graph relation type
+func relationTypeForReference(refType dynamictypes.RefType) graph.RelationType {
+	switch refType {
+	case dynamictypes.RefTypeOwnerRef:
+		return graph.RelationTypeOwnerRef
+	default:
+		return graph.RelationTypeCustomRef
+	}
+}
+
+// appendTraversalError appends err unless an error with the same type,
+// resource and message is already present, in which case that entry's count
+// is incremented instead. The first occurrence keeps its depth and timestamp.
+func appendTraversalError(errs []TraversalError, err TraversalError) []TraversalError {
+	occurrences := err.Count
+	if occurrences < 1 {
+		occurrences = 1
+	}
+
+	for i := range errs {
+		if errs[i].Type == err.Type && errs[i].ResourceID == err.ResourceID && errs[i].Message == err.Message {
+			errs[i].Count += occurrences
+			return errs
+		}
+	}
+
+	err.Count = occurrences
+	return append(errs, err)
+}
@@ -2,17 +2,37 @@ package traversal
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	goruntime "runtime"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/go-logr/logr/funcr"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
 
-	"github.com/crossplane/function-kubecore-schema-registry/pkg/registry"
 	"github.com/crossplane/function-sdk-go/logging"
+
+	dynamictypes "github.com/crossplane/function-kubecore-schema-registry/pkg/dynamic"
+	"github.com/crossplane/function-kubecore-schema-registry/pkg/graph"
+	"github.com/crossplane/function-kubecore-schema-registry/pkg/logfields"
+	"github.com/crossplane/function-kubecore-schema-registry/pkg/registry"
 )
 
 func TestDefaultTraversalConfig(t *testing.T) {
@@ -57,6 +77,60 @@ func TestResourceTracker(t *testing.T) {
 	assert.Equal(t, 1, stats.MaxDepth)
 }
 
+func TestResourceTrackerShouldProcess(t *testing.T) {
+	tracker := NewResourceTracker()
+
+	// First seen deep in a forward pass
+	assert.True(t, tracker.ShouldProcess("env", 3))
+	assert.False(t, tracker.ShouldProcess("env", 3))
+	assert.False(t, tracker.ShouldProcess("env", 4))
+
+	// Reached sooner by a reverse pass, so it is processed again
+	assert.True(t, tracker.ShouldProcess("env", 1))
+	assert.False(t, tracker.ShouldProcess("env", 2))
+
+	info := tracker.GetProcessedResource("env")
+	require.NotNil(t, info)
+	assert.Equal(t, 1, info.Depth)
+	assert.Equal(t, 2, info.ProcessingCount)
+	assert.Equal(t, []string{"env"}, tracker.GetResourcesByDepth(1))
+	assert.False(t, tracker.IsAtDepth(3))
+	assert.Equal(t, 1, tracker.Size())
+}
+
+func TestAddDiscoveredResourceRevisitsShorterDepths(t *testing.T) {
+	engine := newTestTraversalEngine(&mockReferenceResolver{})
+	result := &TraversalResult{
+		ResourceGraph:       engine.components.GraphBuilder.NewGraph(),
+		DiscoveredResources: make(map[string]*unstructured.Unstructured),
+		Statistics: &TraversalStatistics{
+			ResourcesByDepth:    make(map[int]int),
+			ResourcesByKind:     make(map[string]int),
+			ResourcesByAPIGroup: make(map[string]int),
+		},
+		Metadata: &TraversalMetadata{Config: NewDefaultTraversalConfig()},
+	}
+	cluster := newTestResource("KubeCluster", "cluster")
+
+	// A forward pass finds the cluster deep and never revisits it
+	ctx := context.Background()
+	require.NotNil(t, engine.addDiscoveredResource(ctx, result, cluster, 3))
+	assert.Nil(t, engine.addDiscoveredResource(ctx, result, cluster, 1))
+
+	// A reverse pass reaching it sooner re-expands the existing node
+	reverse := withRevisitShorterDepths(ctx)
+	assert.Nil(t, engine.addDiscoveredResource(reverse, result, cluster, 3))
+	node := engine.addDiscoveredResource(reverse, result, cluster, 1)
+	require.NotNil(t, node)
+	assert.Equal(t, 1, node.DiscoveryDepth)
+	assert.Nil(t, engine.addDiscoveredResource(reverse, result, cluster, 2))
+
+	assert.Len(t, result.ResourceGraph.Nodes, 1)
+	assert.Equal(t, 1, result.Statistics.TotalResources)
+	assert.Equal(t, map[int]int{1: 1}, result.Statistics.ResourcesByDepth)
+	assert.Equal(t, 1, result.Statistics.ResourcesByKind["KubeCluster"])
+}
+
 func TestLRUCache(t *testing.T) {
 	cache := NewLRUCache(2, 1*time.Minute)
 	defer cache.Close()
@@ -146,6 +220,43 @@ func TestDefaultScopeFilter(t *testing.T) {
 	filtered = filter.FilterResources(resources, config)
 	assert.Equal(t, 1, len(filtered))
 	assert.Equal(t, "KubeCluster", filtered[0].GetKind())
+
+	// Test allowed kinds, bare or qualified with their group
+	config.ExcludeKinds = nil
+	config.AllowedKinds = []string{"Pod", "KubeApp.platform.kubecore.io"}
+
+	filtered = filter.FilterResources(resources, config)
+	assert.Equal(t, 1, len(filtered))
+	assert.Equal(t, "Pod", filtered[0].GetKind())
+
+	config.AllowedKinds = []string{"KubeCluster.platform.kubecore.io"}
+	assert.True(t, filter.ShouldFollowReference(dynamictypes.ReferenceField{TargetKind: "KubeCluster", TargetGroup: "platform.kubecore.io"}, config))
+	assert.False(t, filter.ShouldFollowReference(dynamictypes.ReferenceField{TargetKind: "KubeCluster", TargetGroup: "legacy.kubecore.io"}, config))
+	assert.False(t, filter.ShouldFollowReference(dynamictypes.ReferenceField{TargetKind: "Secret"}, config))
+}
+
+func TestDefaultScopeFilterStatistics(t *testing.T) {
+	filter := NewDefaultScopeFilter(NewDefaultPlatformChecker([]string{"*.kubecore.io"}), logging.NewNopLogger())
+	filter.ExcludePredicate = HasAnnotation("kubecore.io/ephemeral")
+
+	deletedAt := metav1.NewTime(time.Now())
+	terminating := newTestResource("KubEnv", "terminating")
+	terminating.SetDeletionTimestamp(&deletedAt)
+	ephemeral := newTestResource("KubEnv", "ephemeral")
+	ephemeral.SetAnnotations(map[string]string{"kubecore.io/ephemeral": "true"})
+	pod := newTestResource("Pod", "pod")
+	pod.SetAPIVersion("v1")
+
+	config := &ScopeFilterConfig{PlatformOnly: true, SkipTerminatingResources: true}
+	filtered := filter.FilterResources([]*unstructured.Unstructured{newTestResource("KubEnv", "live"), terminating, ephemeral, pod}, config)
+	require.Len(t, filtered, 1)
+
+	// Every exclusion is counted once, with its reason
+	statistics := filter.GetFilterStatistics()
+	assert.Equal(t, 4, statistics.ResourcesEvaluated)
+	assert.Equal(t, 1, statistics.ResourcesIncluded)
+	assert.Equal(t, 3, statistics.ResourcesExcluded)
+	assert.Equal(t, map[string]int{"terminating": 1, "excluded_by_predicate": 1, "not_platform": 1}, statistics.FilterReasons)
 }
 
 func TestBatchOptimizer(t *testing.T) {
@@ -183,6 +294,48 @@ func TestBatchOptimizer(t *testing.T) {
 	assert.InDelta(t, 8.33, stats.AverageBatchSize, 0.1)
 }
 
+// passthroughBatchProcessor marks every resource as processed successfully
+type passthroughBatchProcessor struct{}
+
+func (passthroughBatchProcessor) ProcessResource(_ context.Context, resource *unstructured.Unstructured) (*ResourceProcessingResult, error) {
+	return &ResourceProcessingResult{ResourceID: resource.GetName(), ProcessedResource: resource, Success: true}, nil
+}
+
+func (p passthroughBatchProcessor) ProcessBatch(ctx context.Context, resources []*unstructured.Unstructured) ([]*ResourceProcessingResult, error) {
+	results := make([]*ResourceProcessingResult, 0, len(resources))
+	for _, resource := range resources {
+		result, _ := p.ProcessResource(ctx, resource)
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (passthroughBatchProcessor) GetProcessorName() string {
+	return "passthrough"
+}
+
+func TestProcessBatchesResultOrder(t *testing.T) {
+	optimizer := NewDefaultBatchOptimizer(logging.NewNopLogger())
+
+	// Priorities and depths reorder processing to high-2, mid-1, deep-3, low-0
+	batches := []ResourceBatch{
+		{ID: "low-0", Priority: 1, Depth: 0, Resources: []*unstructured.Unstructured{newTestResource("KubEnv", "a")}},
+		{ID: "mid-1", Priority: 5, Depth: 1, Resources: []*unstructured.Unstructured{newTestResource("KubEnv", "b")}},
+		{ID: "high-2", Priority: 9, Depth: 0, Resources: []*unstructured.Unstructured{newTestResource("KubEnv", "c")}},
+		{ID: "deep-3", Priority: 5, Depth: 2, Resources: []*unstructured.Unstructured{newTestResource("KubEnv", "d")}},
+	}
+
+	results, err := optimizer.ProcessBatches(context.Background(), batches, passthroughBatchProcessor{})
+	require.NoError(t, err)
+	require.Len(t, results, len(batches))
+
+	for i, batch := range batches {
+		require.NotNil(t, results[i], "batch %d should have a result", i)
+		assert.Equal(t, batch.ID, results[i].BatchID, "results[%d] should belong to batches[%d]", i, i)
+		assert.Equal(t, batch.Resources[0].GetName(), results[i].Results[0].ResourceID)
+	}
+}
+
 func TestMetricsCollector(t *testing.T) {
 	collector := NewMetricsCollector(true)
 
@@ -257,38 +410,2426 @@ func (mr *mockRegistry) GetReferences(apiVersion, kind string) ([]registry.Resou
 	return []registry.ResourceReference{}, nil
 }
 
-// Integration test for traversal engine (would require actual Kubernetes cluster)
-func TestTraversalEngineIntegration(t *testing.T) {
-	if testing.Short() {
-		t.Skip("Skipping integration test")
+func (mr *mockRegistry) Validate() error {
+	return nil
+}
+
+func (mr *mockRegistry) HealthCheck() *registry.HealthStatus {
+	return &registry.HealthStatus{Healthy: true, TotalTypes: 1, NamespacedTypes: 1}
+}
+
+// referencesRegistry is a mockRegistry that declares references per kind
+type referencesRegistry struct {
+	mockRegistry
+	references map[string][]registry.ResourceReference
+}
+
+func (rr *referencesRegistry) GetReferences(_, kind string) ([]registry.ResourceReference, error) {
+	return rr.references[kind], nil
+}
+
+// mockReferenceResolver records calls and returns canned references. When
+// referencesBySource or resolvedBySource is set, references or resolved
+// resources are looked up by source name.
+type mockReferenceResolver struct {
+	mu                 sync.Mutex
+	extractCalls       int
+	resolveCalls       int
+	extracted          []string
+	references         []dynamictypes.ReferenceField
+	referencesBySource map[string][]dynamictypes.ReferenceField
+	resolved           []*unstructured.Unstructured
+	resolvedBySource   map[string][]*unstructured.Unstructured
+	resolveErrors      []error
+}
+
+func (m *mockReferenceResolver) ExtractReferences(ctx context.Context, resource *unstructured.Unstructured) ([]dynamictypes.ReferenceField, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.extractCalls++
+	m.extracted = append(m.extracted, resource.GetName())
+	if references, ok := m.referencesBySource[resource.GetName()]; ok {
+		return references, nil
 	}
+	return m.references, nil
+}
 
-	// This test is designed to show what would happen with a real integration
-	// but in unit test environment, we cannot create real clients
-	// So we'll just skip it for now
-	t.Skip("Integration test requires real Kubernetes cluster")
+func (m *mockReferenceResolver) ResolveReferences(ctx context.Context, source *unstructured.Unstructured, references []dynamictypes.ReferenceField) ([]*unstructured.Unstructured, []error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.resolveCalls++
+	if m.resolvedBySource != nil {
+		return m.resolvedBySource[source.GetName()], m.resolveErrors
+	}
+	return m.resolved, m.resolveErrors
 }
 
-// Benchmark tests
-func BenchmarkLRUCache(b *testing.B) {
-	cache := NewLRUCache(1000, 1*time.Minute)
-	defer cache.Close()
+func (m *mockReferenceResolver) ResolveReferenceResults(ctx context.Context, source *unstructured.Unstructured, references []dynamictypes.ReferenceField) []*ReferenceResolutionResult {
+	resolved, errs := m.ResolveReferences(ctx, source, references)
 
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		key := fmt.Sprintf("key-%d", i%500)
-		cache.Set(key, fmt.Sprintf("value-%d", i), 1*time.Minute)
-		cache.Get(key)
+	var results []*ReferenceResolutionResult
+	if len(resolved) > 0 && len(references) > 0 {
+		results = append(results, &ReferenceResolutionResult{Reference: references[0], ResolvedResources: resolved})
 	}
+	for _, err := range errs {
+		results = append(results, &ReferenceResolutionResult{Error: err})
+	}
+	return results
 }
 
-func BenchmarkResourceTracker(b *testing.B) {
-	tracker := NewResourceTracker()
+func (m *mockReferenceResolver) ResolveReference(ctx context.Context, source *unstructured.Unstructured, reference dynamictypes.ReferenceField) (*unstructured.Unstructured, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.resolveCalls++
+	if len(m.resolved) == 0 {
+		return nil, fmt.Errorf("not found")
+	}
+	return m.resolved[0], nil
+}
 
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		resourceID := fmt.Sprintf("resource-%d", i)
-		tracker.MarkProcessed(resourceID, i%5)
-		tracker.IsProcessed(resourceID)
+func (m *mockReferenceResolver) ValidateReference(reference dynamictypes.ReferenceField) error {
+	return nil
+}
+
+// newTestTraversalEngine creates a traversal engine backed by the given resolver
+func newTestTraversalEngine(resolver ReferenceResolver) *DefaultTraversalEngine {
+	platformChecker := NewDefaultPlatformChecker([]string{"*.kubecore.io"})
+	logger := logging.NewNopLogger()
+
+	return &DefaultTraversalEngine{
+		components: TraversalEngineComponents{
+			Registry:          &mockRegistry{},
+			ReferenceResolver: resolver,
+			ScopeFilter:       NewDefaultScopeFilter(platformChecker, logger),
+			BatchOptimizer:    NewDefaultBatchOptimizer(logger),
+			GraphBuilder:      graph.NewDefaultGraphBuilder(platformChecker),
+			CycleDetector:     graph.NewDFSCycleDetector(10, true),
+			PathTracker:       graph.NewDefaultPathTracker(true),
+		},
+		logger:           logger,
+		resourceTracker:  NewResourceTracker(),
+		metricsCollector: NewMetricsCollector(true),
+	}
+}
+
+// newTestReferenceResolver creates a reference resolver with the mock registry
+// over a fake dynamic client holding the given objects, and returns the client
+func newTestReferenceResolver(objects ...runtime.Object) (*DefaultReferenceResolver, *dynamicfake.FakeDynamicClient) {
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), objects...)
+	return NewDefaultReferenceResolver(dynamicClient, &mockRegistry{}, logging.NewNopLogger()), dynamicClient
+}
+
+func newTestResource(kind, name string) *unstructured.Unstructured {
+	resource := &unstructured.Unstructured{}
+	resource.SetAPIVersion("platform.kubecore.io/v1")
+	resource.SetKind(kind)
+	resource.SetName(name)
+	resource.SetNamespace("default")
+	return resource
+}
+
+func TestExecuteTransitiveDiscoveryRootsOnly(t *testing.T) {
+	resolver := &mockReferenceResolver{
+		references: []dynamictypes.ReferenceField{
+			{FieldPath: "spec.clusterRef", FieldName: "clusterRef", TargetKind: "KubeCluster", Confidence: 0.9},
+		},
+		resolved: []*unstructured.Unstructured{newTestResource("KubeCluster", "referenced")},
+	}
+	engine := newTestTraversalEngine(resolver)
+
+	roots := []*unstructured.Unstructured{
+		newTestResource("KubEnv", "env-a"),
+		newTestResource("KubEnv", "env-b"),
+	}
+
+	config := NewDefaultTraversalConfig()
+	config.MaxDepth = 0
+
+	result, err := engine.ExecuteTransitiveDiscovery(context.Background(), config, roots)
+	require.NoError(t, err)
+
+	assert.Len(t, result.DiscoveredResources, len(roots))
+	for _, root := range roots {
+		assert.Contains(t, result.DiscoveredResources, engine.generateResourceID(root))
+	}
+	assert.Equal(t, 0, resolver.extractCalls)
+	assert.Equal(t, 0, resolver.resolveCalls)
+	assert.Empty(t, result.TraversalPath.Steps)
+	assert.Equal(t, len(roots), result.Statistics.TotalResources)
+	assert.Empty(t, result.ValidationResult.Warnings)
+
+	config.MaxDepth = -1
+	_, err = engine.ExecuteTransitiveDiscovery(context.Background(), config, roots)
+	assert.Error(t, err)
+}
+
+func TestExecuteTransitiveDiscoveryTerminalKinds(t *testing.T) {
+	secret := &unstructured.Unstructured{}
+	secret.SetAPIVersion("v1")
+	secret.SetKind("Secret")
+	secret.SetName("creds")
+	secret.SetNamespace("default")
+
+	resolver := &mockReferenceResolver{
+		references: []dynamictypes.ReferenceField{
+			{FieldPath: "spec.ref", FieldName: "ref", TargetKind: "KubeCluster", Confidence: 0.9},
+		},
+		resolvedBySource: map[string][]*unstructured.Unstructured{
+			"app":     {secret, newTestResource("KubeCluster", "cluster")},
+			"creds":   {newTestResource("KubeNet", "hidden")},
+			"cluster": {newTestResource("KubeNet", "net")},
+		},
+	}
+	engine := newTestTraversalEngine(resolver)
+
+	config := NewDefaultTraversalConfig()
+	config.MaxDepth = 5
+	config.ScopeFilter = &ScopeFilterConfig{CrossNamespaceEnabled: true}
+	config.TerminalKinds = []string{"Secret"}
+
+	result, err := engine.ExecuteTransitiveDiscovery(context.Background(), config, []*unstructured.Unstructured{newTestResource("KubeApp", "app")})
+	require.NoError(t, err)
+
+	assert.Contains(t, result.DiscoveredResources, engine.generateResourceID(secret))
+	assert.Contains(t, result.DiscoveredResources, engine.generateResourceID(newTestResource("KubeNet", "net")))
+	assert.NotContains(t, result.DiscoveredResources, engine.generateResourceID(newTestResource("KubeNet", "hidden")))
+	assert.NotContains(t, resolver.extracted, "creds")
+
+	secretInGraph := false
+	for _, node := range result.ResourceGraph.Nodes {
+		if node.Resource.GetKind() == "Secret" {
+			secretInGraph = true
+		}
+	}
+	assert.True(t, secretInGraph, "terminal resource should still be added to the graph")
+}
+
+func TestExecuteTransitiveDiscoveryAugmentationSelectors(t *testing.T) {
+	newEvent := func(name string, labels map[string]string) *unstructured.Unstructured {
+		event := &unstructured.Unstructured{}
+		event.SetAPIVersion("v1")
+		event.SetKind("Event")
+		event.SetName(name)
+		event.SetNamespace("default")
+		event.SetLabels(labels)
+		return event
+	}
+	watched := newEvent("cluster-ready", map[string]string{"kubecore.io/watch": "true"})
+	ignored := newEvent("unrelated", nil)
+
+	resolver := &mockReferenceResolver{
+		references: []dynamictypes.ReferenceField{
+			{FieldPath: "spec.clusterRef", FieldName: "clusterRef", TargetKind: "KubeCluster", Confidence: 0.9},
+		},
+		resolvedBySource: map[string][]*unstructured.Unstructured{
+			"env":           {newTestResource("KubeCluster", "cluster")},
+			"cluster-ready": {newTestResource("KubeNet", "net")},
+		},
+	}
+	engine := newTestTraversalEngine(resolver)
+	engine.components.DynamicClient = dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), watched, ignored)
+
+	config := NewDefaultTraversalConfig()
+	config.MaxDepth = 2
+	config.ScopeFilter = &ScopeFilterConfig{CrossNamespaceEnabled: true}
+	config.AugmentationSelectors = []AugmentationSelector{{
+		APIVersion:    "v1",
+		Kind:          "Event",
+		Namespace:     "default",
+		LabelSelector: "kubecore.io/watch=true",
+		Depths:        []int{1},
+	}}
+
+	result, err := engine.ExecuteTransitiveDiscovery(context.Background(), config, []*unstructured.Unstructured{newTestResource("KubEnv", "env")})
+	require.NoError(t, err)
+	assert.Empty(t, result.Errors)
+
+	sources := make(map[string]graph.DiscoverySource)
+	depths := make(map[string]int)
+	for _, node := range result.ResourceGraph.Nodes {
+		sources[node.Resource.GetName()] = node.Metadata.DiscoverySource
+		depths[node.Resource.GetName()] = node.DiscoveryDepth
+	}
+
+	assert.Equal(t, map[string]graph.DiscoverySource{
+		"env":           graph.DiscoverySourceRoot,
+		"cluster":       graph.DiscoverySourceReference,
+		"cluster-ready": graph.DiscoverySourceSelector,
+		"net":           graph.DiscoverySourceReference,
+	}, sources)
+	assert.Equal(t, 1, depths["cluster-ready"])
+	assert.Equal(t, 2, result.Statistics.ResourcesByDepth[1], "the seeded event should be counted at depth 1 next to the cluster")
+	assert.Contains(t, resolver.extracted, "cluster-ready", "seeded resources should be expanded")
+}
+
+func TestExecuteTransitiveDiscoveryExpandPredicate(t *testing.T) {
+	withReady := func(resource *unstructured.Unstructured, status string) *unstructured.Unstructured {
+		resource.Object["status"] = map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Ready", "status": status},
+			},
+		}
+		return resource
+	}
+
+	ready := withReady(newTestResource("KubeCluster", "ready"), "True")
+	pending := withReady(newTestResource("KubeCluster", "pending"), "False")
+
+	resolver := &mockReferenceResolver{
+		references: []dynamictypes.ReferenceField{
+			{FieldPath: "spec.ref", FieldName: "ref", TargetKind: "KubeCluster", Confidence: 0.9},
+		},
+		resolvedBySource: map[string][]*unstructured.Unstructured{
+			"app":     {ready, pending},
+			"ready":   {newTestResource("KubeNet", "net")},
+			"pending": {newTestResource("KubeNet", "hidden")},
+		},
+	}
+	engine := newTestTraversalEngine(resolver)
+
+	config := NewDefaultTraversalConfig()
+	config.MaxDepth = 5
+	config.ScopeFilter = &ScopeFilterConfig{CrossNamespaceEnabled: true}
+	config.ExpandPredicate = func(resource *unstructured.Unstructured) bool {
+		conditions, _, _ := unstructured.NestedSlice(resource.Object, "status", "conditions")
+		for _, condition := range conditions {
+			c, ok := condition.(map[string]interface{})
+			if ok && c["type"] == "Ready" {
+				return c["status"] == "True"
+			}
+		}
+		return false
+	}
+
+	root := withReady(newTestResource("KubeApp", "app"), "True")
+	result, err := engine.ExecuteTransitiveDiscovery(context.Background(), config, []*unstructured.Unstructured{root})
+	require.NoError(t, err)
+
+	assert.Contains(t, result.DiscoveredResources, engine.generateResourceID(pending), "rejected resources stay in the graph")
+	assert.Contains(t, result.ResourceGraph.Nodes, graph.NodeID(engine.generateResourceID(pending)))
+	assert.Contains(t, result.DiscoveredResources, engine.generateResourceID(newTestResource("KubeNet", "net")))
+	assert.NotContains(t, result.DiscoveredResources, engine.generateResourceID(newTestResource("KubeNet", "hidden")))
+	assert.NotContains(t, resolver.extracted, "pending")
+}
+
+func TestExecuteTransitiveDiscoveryStepCounters(t *testing.T) {
+	resolver := &mockReferenceResolver{
+		references: []dynamictypes.ReferenceField{
+			{FieldPath: "spec.ref", FieldName: "ref", TargetKind: "KubEnv", Confidence: 0.9},
+		},
+		resolvedBySource: map[string][]*unstructured.Unstructured{
+			"app":     {newTestResource("KubEnv", "env"), newTestResource("KubeNet", "net")},
+			"env":     {newTestResource("KubeNet", "net"), newTestResource("KubeSystem", "system")},
+			"net":     {newTestResource("KubEnv", "env")},
+			"system":  {newTestResource("KubeStorage", "storage")},
+			"storage": nil,
+		},
+	}
+	engine := newTestTraversalEngine(resolver)
+
+	config := NewDefaultTraversalConfig()
+	config.MaxDepth = 5
+	config.ScopeFilter = &ScopeFilterConfig{CrossNamespaceEnabled: true}
+
+	roots := []*unstructured.Unstructured{newTestResource("KubeApp", "app")}
+	result, err := engine.ExecuteTransitiveDiscovery(context.Background(), config, roots)
+	require.NoError(t, err)
+
+	added := 0
+	for _, step := range result.TraversalPath.Steps {
+		added += step.ResourcesAdded
+	}
+	assert.Equal(t, len(result.DiscoveredResources)-len(roots), added)
+	assert.Equal(t, 4, added)
+}
+
+func TestExecuteTransitiveDiscoveryExcludesSpecLessResources(t *testing.T) {
+	env := newTestResource("KubEnv", "env")
+	env.Object["spec"] = map[string]interface{}{"environment": "dev"}
+	status := newTestResource("KubeNet", "net-status")
+
+	resolver := &mockReferenceResolver{
+		references: []dynamictypes.ReferenceField{
+			{FieldPath: "spec.ref", FieldName: "ref", TargetKind: "KubEnv", Confidence: 0.9},
+		},
+		resolvedBySource: map[string][]*unstructured.Unstructured{
+			"app":        {env, status},
+			"env":        nil,
+			"net-status": {newTestResource("KubeSystem", "system")},
+		},
+	}
+	engine := newTestTraversalEngine(resolver)
+	filter := engine.components.ScopeFilter.(*DefaultScopeFilter)
+	filter.ExcludePredicate = AnyOf(HasNoSpec, HasAnnotation("kubecore.io/ephemeral"))
+
+	config := NewDefaultTraversalConfig()
+	config.ScopeFilter = &ScopeFilterConfig{CrossNamespaceEnabled: true}
+
+	root := newTestResource("KubeApp", "app")
+	root.Object["spec"] = map[string]interface{}{"ref": "env"}
+	result, err := engine.ExecuteTransitiveDiscovery(context.Background(), config, []*unstructured.Unstructured{root})
+	require.NoError(t, err)
+
+	assert.Contains(t, result.DiscoveredResources, engine.generateResourceID(env))
+	assert.NotContains(t, result.DiscoveredResources, engine.generateResourceID(status))
+	assert.NotContains(t, result.ResourceGraph.Nodes, graph.NodeID(engine.generateResourceID(status)))
+	assert.Len(t, result.DiscoveredResources, 2, "references of excluded resources should not be followed")
+	assert.Equal(t, 1, filter.GetFilterStatistics().FilterReasons["excluded_by_predicate"])
+}
+
+func TestExecuteTransitiveDiscoverySkipTerminatingResources(t *testing.T) {
+	deletedAt := metav1.NewTime(time.Now())
+	terminating := newTestResource("KubEnv", "env-old")
+	terminating.SetDeletionTimestamp(&deletedAt)
+	live := newTestResource("KubEnv", "env-new")
+
+	cases := map[string]struct {
+		skip      bool
+		wantNames []string
+	}{
+		"IncludeTerminating": {
+			wantNames: []string{"app", "env-new", "env-old"},
+		},
+		"SkipTerminating": {
+			skip:      true,
+			wantNames: []string{"app", "env-new"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			resolver := &mockReferenceResolver{
+				references: []dynamictypes.ReferenceField{
+					{FieldPath: "spec.ref", FieldName: "ref", TargetKind: "KubEnv", Confidence: 0.9},
+				},
+				resolvedBySource: map[string][]*unstructured.Unstructured{
+					"app": {terminating, live},
+				},
+			}
+			engine := newTestTraversalEngine(resolver)
+			filter := engine.components.ScopeFilter.(*DefaultScopeFilter)
+
+			config := NewDefaultTraversalConfig()
+			config.ScopeFilter = &ScopeFilterConfig{CrossNamespaceEnabled: true, SkipTerminatingResources: tc.skip}
+
+			result, err := engine.ExecuteTransitiveDiscovery(context.Background(), config, []*unstructured.Unstructured{newTestResource("KubeApp", "app")})
+			require.NoError(t, err)
+
+			var names []string
+			for _, resource := range result.DiscoveredResources {
+				names = append(names, resource.GetName())
+			}
+			slices.Sort(names)
+			assert.Equal(t, tc.wantNames, names)
+
+			wantFiltered := 0
+			if tc.skip {
+				wantFiltered = 1
+			}
+			assert.Equal(t, wantFiltered, filter.GetFilterStatistics().FilterReasons["terminating"])
+		})
+	}
+}
+
+func TestExecuteTransitiveDiscoveryCycleDetectionThreshold(t *testing.T) {
+	cases := map[string]struct {
+		minNodes    int
+		wantSkipped bool
+	}{
+		"BelowThreshold": {minNodes: 10, wantSkipped: true},
+		"AboveThreshold": {minNodes: 2, wantSkipped: false},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// app and env reference each other, forming a cycle
+			resolver := &mockReferenceResolver{
+				references: []dynamictypes.ReferenceField{
+					{FieldPath: "spec.ref", FieldName: "ref", TargetKind: "KubEnv", Confidence: 0.9},
+				},
+				resolvedBySource: map[string][]*unstructured.Unstructured{
+					"app": {newTestResource("KubEnv", "env")},
+					"env": {newTestResource("KubeApp", "app")},
+				},
+			}
+			engine := newTestTraversalEngine(resolver)
+
+			config := NewDefaultTraversalConfig()
+			config.ScopeFilter = &ScopeFilterConfig{CrossNamespaceEnabled: true}
+			config.CycleHandling.MinNodesForDetection = tc.minNodes
+
+			roots := []*unstructured.Unstructured{newTestResource("KubeApp", "app")}
+			result, err := engine.ExecuteTransitiveDiscovery(context.Background(), config, roots)
+			require.NoError(t, err)
+			require.Len(t, result.ResourceGraph.Nodes, 2)
+
+			assert.Equal(t, tc.wantSkipped, result.Metadata.CycleDetectionSkipped)
+			if tc.wantSkipped {
+				assert.Nil(t, result.CycleResults)
+				return
+			}
+			require.NotNil(t, result.CycleResults)
+			assert.True(t, result.CycleResults.CyclesFound)
+		})
+	}
+}
+
+func TestExecuteTransitiveDiscoveryCollectsErrors(t *testing.T) {
+	resolver := &mockReferenceResolver{
+		references: []dynamictypes.ReferenceField{
+			{FieldPath: "spec.clusterRef", FieldName: "clusterRef", TargetKind: "KubeCluster", Confidence: 0.9},
+		},
+		resolveErrors: []error{fmt.Errorf("kubeclusters.platform.kubecore.io \"missing\" not found")},
+	}
+	engine := newTestTraversalEngine(resolver)
+
+	config := NewDefaultTraversalConfig()
+	config.ScopeFilter = &ScopeFilterConfig{CrossNamespaceEnabled: true}
+
+	root := newTestResource("KubEnv", "env")
+	result, err := engine.ExecuteTransitiveDiscovery(context.Background(), config, []*unstructured.Unstructured{root})
+	require.NoError(t, err)
+
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, TraversalErrorReferenceResolution, result.Errors[0].Type)
+	assert.Equal(t, engine.generateResourceID(root), result.Errors[0].ResourceID)
+	assert.Equal(t, 1, result.Errors[0].Depth)
+	assert.Contains(t, result.Errors[0].Message, "missing")
+}
+
+func TestExecuteTransitiveDiscoveryDeduplicatesErrors(t *testing.T) {
+	transient := fmt.Errorf("etcdserver: request timed out")
+	resolver := &mockReferenceResolver{
+		references: []dynamictypes.ReferenceField{
+			{FieldPath: "spec.clusterRef", FieldName: "clusterRef", TargetKind: "KubeCluster", Confidence: 0.9},
+		},
+		resolveErrors: []error{transient, fmt.Errorf("kubeclusters.platform.kubecore.io \"missing\" not found"), transient, transient},
+	}
+	engine := newTestTraversalEngine(resolver)
+
+	config := NewDefaultTraversalConfig()
+	config.ScopeFilter = &ScopeFilterConfig{CrossNamespaceEnabled: true}
+
+	root := newTestResource("KubEnv", "env")
+	result, err := engine.ExecuteTransitiveDiscovery(context.Background(), config, []*unstructured.Unstructured{root})
+	require.NoError(t, err)
+
+	require.Len(t, result.Errors, 2)
+	assert.Equal(t, transient.Error(), result.Errors[0].Message)
+	assert.Equal(t, 3, result.Errors[0].Count)
+	assert.Equal(t, engine.generateResourceID(root), result.Errors[0].ResourceID)
+	assert.Contains(t, result.Errors[1].Message, "missing")
+	assert.Equal(t, 1, result.Errors[1].Count)
+}
+
+func TestResolveReferenceNameTemplate(t *testing.T) {
+	configMap := &unstructured.Unstructured{}
+	configMap.SetAPIVersion("v1")
+	configMap.SetKind("ConfigMap")
+	configMap.SetName("my-app-config")
+	configMap.SetNamespace("default")
+
+	resolver, _ := newTestReferenceResolver(configMap)
+
+	source := newTestResource("KubeApp", "my-app")
+	source.Object["spec"] = map[string]interface{}{
+		"configEnabled": true,
+		"suffix":        "config",
+	}
+
+	cases := map[string]struct {
+		reference dynamictypes.ReferenceField
+		expected  string
+		expectErr bool
+	}{
+		"TemplateFromSourceName": {
+			reference: dynamictypes.ReferenceField{
+				FieldPath:    "spec.configEnabled",
+				TargetKind:   "ConfigMap",
+				Confidence:   0.9,
+				NameTemplate: "{metadata.name}-config",
+			},
+			expected: "my-app-config",
+		},
+		"TemplateFromMultipleFields": {
+			reference: dynamictypes.ReferenceField{
+				FieldPath:    "spec.suffix",
+				TargetKind:   "ConfigMap",
+				Confidence:   0.9,
+				NameTemplate: "{metadata.name}-{value}",
+			},
+			expected: "my-app-config",
+		},
+		"TemplateMissingField": {
+			reference: dynamictypes.ReferenceField{
+				FieldPath:    "spec.configEnabled",
+				TargetKind:   "ConfigMap",
+				Confidence:   0.9,
+				NameTemplate: "{spec.missing}-config",
+			},
+			expectErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			resolved, err := resolver.ResolveReference(context.Background(), source, tc.reference)
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "ConfigMap", resolved.GetKind())
+			assert.Equal(t, tc.expected, resolved.GetName())
+		})
+	}
+}
+
+func TestResolveReferenceNamespaceRewrite(t *testing.T) {
+	configMap := &unstructured.Unstructured{}
+	configMap.SetAPIVersion("v1")
+	configMap.SetKind("ConfigMap")
+	configMap.SetName("app-config")
+	configMap.SetNamespace("tenant")
+
+	resolver, _ := newTestReferenceResolver(configMap)
+
+	source := newTestResource("KubeApp", "my-app")
+	source.SetNamespace("template")
+	source.Object["spec"] = map[string]interface{}{
+		"configRef": "app-config",
+		"explicitConfigRef": map[string]interface{}{
+			"name":      "app-config",
+			"namespace": "template",
+		},
+	}
+
+	references := []dynamictypes.ReferenceField{
+		{FieldPath: "spec.configRef", TargetKind: "ConfigMap", Confidence: 0.9},
+		{FieldPath: "spec.explicitConfigRef", TargetKind: "ConfigMap", Confidence: 0.9},
+	}
+
+	// Without a rewrite the references resolve in the template namespace
+	for _, reference := range references {
+		_, err := resolver.ResolveReference(context.Background(), source, reference)
+		assert.Error(t, err)
+	}
+
+	resolver.SetNamespaceRewrite(map[string]string{"template": "tenant"})
+	for _, reference := range references {
+		resolved, err := resolver.ResolveReference(context.Background(), source, reference)
+		require.NoError(t, err, reference.FieldPath)
+		assert.Equal(t, "tenant", resolved.GetNamespace())
+		assert.Equal(t, "app-config", resolved.GetName())
+	}
+}
+
+func TestDiscoverReferencedResourcesPerCallResolutionOptions(t *testing.T) {
+	var objects []runtime.Object
+	for _, namespace := range []string{"tenant-a", "tenant-b"} {
+		configMap := &unstructured.Unstructured{}
+		configMap.SetAPIVersion("v1")
+		configMap.SetKind("ConfigMap")
+		configMap.SetName("app-settings")
+		configMap.SetNamespace(namespace)
+		objects = append(objects, configMap)
+	}
+
+	resolver, _ := newTestReferenceResolver(objects...)
+	engine := newTestTraversalEngine(resolver)
+
+	source := newTestResource("KubeApp", "my-app")
+	source.SetNamespace("template")
+	source.Object["spec"] = map[string]interface{}{"configMapRef": "app-settings"}
+
+	configFor := func(namespace string) *TraversalConfig {
+		config := NewDefaultTraversalConfig()
+		config.ScopeFilter = &ScopeFilterConfig{CrossNamespaceEnabled: true}
+		config.ReferenceResolution.NamespaceRewrite = map[string]string{"template": namespace}
+		return config
+	}
+
+	// Discoveries with different rewrites run side by side, each resolving
+	// in its own namespace
+	var wg sync.WaitGroup
+	namespaces := make([][]string, 20)
+	for i := range namespaces {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			result, err := engine.DiscoverReferencedResources(context.Background(), []*unstructured.Unstructured{source}, configFor([]string{"tenant-a", "tenant-b"}[i%2]))
+			if err != nil {
+				return
+			}
+			for _, resource := range result.Resources {
+				namespaces[i] = append(namespaces[i], resource.GetNamespace())
+			}
+		}(i)
 	}
+	wg.Wait()
+
+	for i, found := range namespaces {
+		assert.Equal(t, []string{[]string{"tenant-a", "tenant-b"}[i%2]}, found, "discovery %d", i)
+	}
+
+	// Switching configurations keeps the resolutions cached for both
+	assert.Equal(t, 2, resolver.cache.Size())
+}
+
+func TestResolveReferenceClusterSourceNamespace(t *testing.T) {
+	var objects []runtime.Object
+	for _, namespace := range []string{"platform", "tenant-a"} {
+		configMap := &unstructured.Unstructured{}
+		configMap.SetAPIVersion("v1")
+		configMap.SetKind("ConfigMap")
+		configMap.SetName("app-config")
+		configMap.SetNamespace(namespace)
+		objects = append(objects, configMap)
+	}
+
+	cases := map[string]struct {
+		namespace         string
+		useClaimNamespace bool
+		claimNamespace    string
+		want              string
+	}{
+		"NoDefaultNamespace": {},
+		"ConfiguredNamespace": {
+			namespace: "platform",
+			want:      "platform",
+		},
+		"ClaimNamespace": {
+			namespace:         "platform",
+			useClaimNamespace: true,
+			claimNamespace:    "tenant-a",
+			want:              "tenant-a",
+		},
+		"ClaimNamespaceMissing": {
+			namespace:         "platform",
+			useClaimNamespace: true,
+			want:              "platform",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			resolver, _ := newTestReferenceResolver(objects...)
+			resolver.SetClusterSourceNamespace(tc.namespace, tc.useClaimNamespace)
+
+			// A cluster XR has no namespace of its own
+			source := newTestResource("XKubeApp", "my-app")
+			source.SetNamespace("")
+			if tc.claimNamespace != "" {
+				source.SetLabels(map[string]string{claimNamespaceKey: tc.claimNamespace})
+			}
+			source.Object["spec"] = map[string]interface{}{"configRef": "app-config"}
+
+			reference := dynamictypes.ReferenceField{FieldPath: "spec.configRef", TargetKind: "ConfigMap", Confidence: 0.9}
+			resolved, err := resolver.ResolveReference(context.Background(), source, reference)
+			if tc.want == "" {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, resolved.GetNamespace())
+		})
+	}
+}
+
+func TestResolveReferenceDynamicCRDs(t *testing.T) {
+	// The irregular plural is not something the pluralization heuristics can guess
+	crd := &apiextv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "widgetries.example.io"},
+		Spec: apiextv1.CustomResourceDefinitionSpec{
+			Group: "example.io",
+			Names: apiextv1.CustomResourceDefinitionNames{
+				Kind:     "Widget",
+				Plural:   "widgetries",
+				Singular: "widget",
+			},
+			Scope: apiextv1.NamespaceScoped,
+			Versions: []apiextv1.CustomResourceDefinitionVersion{
+				{
+					Name:    "v1alpha1",
+					Served:  true,
+					Storage: true,
+					Schema: &apiextv1.CustomResourceValidation{
+						OpenAPIV3Schema: &apiextv1.JSONSchemaProps{Type: "object"},
+					},
+				},
+			},
+		},
+	}
+
+	widget := &unstructured.Unstructured{}
+	widget.SetAPIVersion("example.io/v1alpha1")
+	widget.SetKind("Widget")
+	widget.SetName("my-widget")
+	widget.SetNamespace("default")
+	widgetGVR := schema.GroupVersionResource{Group: "example.io", Version: "v1alpha1", Resource: "widgetries"}
+
+	cases := map[string]struct {
+		enabled bool
+		wantErr bool
+	}{
+		"Enabled": {
+			enabled: true,
+		},
+		"Disabled": {
+			enabled: false,
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Created through the client so it is stored under the CRD's plural
+			// rather than the one the fake tracker would guess from the kind
+			dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+				map[schema.GroupVersionResource]string{widgetGVR: "WidgetList"})
+			_, err := dynamicClient.Resource(widgetGVR).Namespace("default").Create(context.Background(), widget, metav1.CreateOptions{})
+			require.NoError(t, err)
+
+			resolver := NewDefaultReferenceResolver(dynamicClient, &mockRegistry{}, logging.NewNopLogger())
+			resolver.SetCRDDiscoverer(dynamictypes.NewCRDDiscoverer(apiextensionsfake.NewSimpleClientset(crd), logging.NewNopLogger()))
+			resolver.SetDynamicCRDs(tc.enabled)
+
+			source := newTestResource("XKubeApp", "my-app")
+			source.Object["spec"] = map[string]interface{}{"widgetRef": "my-widget"}
+
+			reference := dynamictypes.ReferenceField{
+				FieldPath:   "spec.widgetRef",
+				TargetKind:  "Widget",
+				TargetGroup: "example.io",
+				Confidence:  0.9,
+			}
+			resolved, err := resolver.ResolveReference(context.Background(), source, reference)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "my-widget", resolved.GetName())
+			assert.Equal(t, "example.io/v1alpha1", resolved.GetAPIVersion())
+		})
+	}
+
+	t.Run("DiscoveredGroupIsNotAnAlias", func(t *testing.T) {
+		dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{widgetGVR: "WidgetList"})
+		resolver := NewDefaultReferenceResolver(dynamicClient, &mockRegistry{}, logging.NewNopLogger())
+		resolver.SetCRDDiscoverer(dynamictypes.NewCRDDiscoverer(apiextensionsfake.NewSimpleClientset(crd), logging.NewNopLogger()))
+		resolver.SetDynamicCRDs(true)
+
+		source := newTestResource("XKubeApp", "my-app")
+		source.Object["spec"] = map[string]interface{}{"widgetRef": "missing-widget"}
+
+		// The CRD's group differs from the reference's empty one, but no alias
+		// was applied, so the missing widget is not looked up again
+		reference := dynamictypes.ReferenceField{FieldPath: "spec.widgetRef", TargetKind: "Widget", Confidence: 0.9}
+		_, err := resolver.ResolveReference(context.Background(), source, reference)
+		require.Error(t, err)
+		assert.Equal(t, int64(2), resolver.APICalls(), "one CRD discovery and one lookup")
+	})
+}
+
+// blockingCRDDiscoverer counts discoveries per pattern and holds those of
+// blockedPattern until release is closed
+type blockingCRDDiscoverer struct {
+	dynamictypes.CRDDiscoverer
+	blockedPattern string
+	release        chan struct{}
+	crds           []*dynamictypes.CRDInfo
+	calls          sync.Map
+}
+
+func (d *blockingCRDDiscoverer) DiscoverCRDs(_ context.Context, patterns []string) ([]*dynamictypes.CRDInfo, error) {
+	count, _ := d.calls.LoadOrStore(patterns[0], new(atomic.Int32))
+	count.(*atomic.Int32).Add(1)
+	if patterns[0] == d.blockedPattern {
+		<-d.release
+	}
+	return d.crds, nil
+}
+
+func (d *blockingCRDDiscoverer) callCount(pattern string) int32 {
+	count, ok := d.calls.Load(pattern)
+	if !ok {
+		return 0
+	}
+	return count.(*atomic.Int32).Load()
+}
+
+func TestTargetGVRRESTMapper(t *testing.T) {
+	resolver, _ := newTestReferenceResolver()
+	options := resolver.resolutionOptions(context.Background())
+	reference := dynamictypes.ReferenceField{FieldPath: "spec.kubeClusterRef", TargetKind: "KubeCluster", TargetGroup: "platform.kubecore.io"}
+
+	// The heuristics guess KubeClusters are cluster-scoped and served at v1
+	gvr, clusterScoped, err := resolver.targetGVR(context.Background(), reference, options)
+	require.NoError(t, err)
+	assert.Equal(t, schema.GroupVersionResource{Group: "platform.kubecore.io", Version: "v1", Resource: "kubeclusters"}, gvr)
+	assert.True(t, clusterScoped)
+
+	// The cluster serves them namespaced at v1alpha1
+	gvk := schema.GroupVersionKind{Group: "platform.kubecore.io", Version: "v1alpha1", Kind: "KubeCluster"}
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{gvk.GroupVersion()})
+	mapper.Add(gvk, meta.RESTScopeNamespace)
+	newTestTraversalEngine(resolver).SetRESTMapper(mapper)
+
+	gvr, clusterScoped, err = resolver.targetGVR(context.Background(), reference, options)
+	require.NoError(t, err)
+	assert.Equal(t, schema.GroupVersionResource{Group: "platform.kubecore.io", Version: "v1alpha1", Resource: "kubeclusters"}, gvr)
+	assert.False(t, clusterScoped)
+
+	// Kinds the mapper does not know keep the heuristics
+	reference.TargetKind = "KubEnv"
+	gvr, _, err = resolver.targetGVR(context.Background(), reference, options)
+	require.NoError(t, err)
+	assert.Equal(t, "v1", gvr.Version)
+}
+
+func TestDiscoverTargetCRDConcurrency(t *testing.T) {
+	discoverer := &blockingCRDDiscoverer{
+		blockedPattern: "example.io",
+		release:        make(chan struct{}),
+		crds: []*dynamictypes.CRDInfo{
+			{Name: "widgets.example.io", Group: "example.io", Version: "v1", Kind: "Widget", Plural: "widgets", Namespaced: true},
+			{Name: "gadgets.other.io", Group: "other.io", Version: "v1", Kind: "Gadget", Plural: "gadgets", Namespaced: true},
+		},
+	}
+
+	resolver, _ := newTestReferenceResolver()
+	resolver.SetCRDDiscoverer(discoverer)
+
+	// Concurrent lookups of one kind share a single discovery
+	var wg sync.WaitGroup
+	found := make([]*dynamictypes.CRDInfo, 5)
+	for i := range found {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			found[i] = resolver.discoverTargetCRD(context.Background(), "example.io", "Widget")
+		}(i)
+	}
+	require.Eventually(t, func() bool { return discoverer.callCount("example.io") == 1 }, time.Second, time.Millisecond)
+
+	// Other kinds are not held up while it runs
+	gadget := resolver.discoverTargetCRD(context.Background(), "other.io", "Gadget")
+	require.NotNil(t, gadget)
+	assert.Equal(t, "gadgets", gadget.Plural)
+
+	close(discoverer.release)
+	wg.Wait()
+	for _, crd := range found {
+		require.NotNil(t, crd)
+		assert.Equal(t, "widgets", crd.Plural)
+	}
+	assert.Equal(t, int32(1), discoverer.callCount("example.io"))
+
+	// Discovered CRDs are remembered across calls
+	require.NotNil(t, resolver.discoverTargetCRD(context.Background(), "example.io", "Widget"))
+	assert.Equal(t, int32(1), discoverer.callCount("example.io"))
+}
+
+func TestResolveReferenceGroupAliases(t *testing.T) {
+	migrated := &unstructured.Unstructured{}
+	migrated.SetAPIVersion("core.kubecore.io/v1")
+	migrated.SetKind("KubEnv")
+	migrated.SetName("migrated")
+	migrated.SetNamespace("default")
+
+	legacy := newTestResource("KubeNet", "legacy")
+
+	resolver, _ := newTestReferenceResolver(migrated, legacy)
+	resolver.SetGroupAliases(map[string]string{"platform.kubecore.io": "core.kubecore.io"})
+
+	source := newTestResource("KubeApp", "my-app")
+	source.Object["spec"] = map[string]interface{}{
+		"envRef": "migrated",
+		"netRef": "legacy",
+	}
+
+	cases := map[string]struct {
+		reference  dynamictypes.ReferenceField
+		apiVersion string
+	}{
+		"OldGroupResolvesViaAlias": {
+			reference: dynamictypes.ReferenceField{
+				FieldPath:   "spec.envRef",
+				TargetKind:  "KubEnv",
+				TargetGroup: "platform.kubecore.io",
+				Confidence:  0.9,
+			},
+			apiVersion: "core.kubecore.io/v1",
+		},
+		"FallsBackToOriginalGroup": {
+			reference: dynamictypes.ReferenceField{
+				FieldPath:   "spec.netRef",
+				TargetKind:  "KubeNet",
+				TargetGroup: "platform.kubecore.io",
+				Confidence:  0.9,
+			},
+			apiVersion: "platform.kubecore.io/v1",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			resolved, err := resolver.ResolveReference(context.Background(), source, tc.reference)
+			require.NoError(t, err)
+			assert.Equal(t, tc.apiVersion, resolved.GetAPIVersion())
+		})
+	}
+}
+
+func TestExecuteTransitiveDiscoveryListReferences(t *testing.T) {
+	var objects []runtime.Object
+	for _, name := range []string{"a", "b", "c"} {
+		configMap := &unstructured.Unstructured{}
+		configMap.SetAPIVersion("v1")
+		configMap.SetKind("ConfigMap")
+		configMap.SetName(name)
+		configMap.SetNamespace("default")
+		objects = append(objects, configMap)
+	}
+
+	resolver, _ := newTestReferenceResolver(objects...)
+	engine := newTestTraversalEngine(resolver)
+
+	config := NewDefaultTraversalConfig()
+	config.MaxDepth = 1
+	config.ScopeFilter = &ScopeFilterConfig{CrossNamespaceEnabled: true}
+
+	source := newTestResource("KubeApp", "my-app")
+	source.Object["spec"] = map[string]interface{}{
+		"configMapRefs": []interface{}{"a", "b", "c"},
+	}
+
+	result, err := engine.ExecuteTransitiveDiscovery(context.Background(), config, []*unstructured.Unstructured{source})
+	require.NoError(t, err)
+	assert.Empty(t, result.Errors)
+
+	sourceID := graph.NodeID(engine.generateResourceID(source))
+	targets := make([]string, 0, 3)
+	for _, edge := range result.ResourceGraph.Edges {
+		if edge.Source == sourceID {
+			targets = append(targets, result.ResourceGraph.Nodes[edge.Target].Resource.GetName())
+		}
+	}
+	assert.ElementsMatch(t, []string{"a", "b", "c"}, targets)
+	require.Len(t, result.TraversalPath.Steps, 1)
+	assert.Equal(t, 3, result.TraversalPath.Steps[0].APICalls)
+	for _, object := range objects {
+		assert.Contains(t, result.DiscoveredResources, engine.generateResourceID(object.(*unstructured.Unstructured)))
+	}
+}
+
+func TestDiscoverReferencedResourcesSharesTargetLookups(t *testing.T) {
+	configMap := &unstructured.Unstructured{}
+	configMap.SetAPIVersion("v1")
+	configMap.SetKind("ConfigMap")
+	configMap.SetName("shared")
+	configMap.SetNamespace("default")
+
+	resolver, dynamicClient := newTestReferenceResolver(configMap)
+	engine := newTestTraversalEngine(resolver)
+
+	config := NewDefaultTraversalConfig()
+	config.ScopeFilter = &ScopeFilterConfig{CrossNamespaceEnabled: true}
+
+	var sources []*unstructured.Unstructured
+	for _, name := range []string{"app-a", "app-b"} {
+		source := newTestResource("KubeApp", name)
+		source.Object["spec"] = map[string]interface{}{
+			"configMapRef": "shared",
+		}
+		sources = append(sources, source)
+	}
+
+	result, err := engine.DiscoverReferencedResources(context.Background(), sources, config)
+	require.NoError(t, err)
+	assert.Empty(t, result.Errors)
+
+	require.Len(t, result.Resources, 1)
+	assert.Equal(t, "shared", result.Resources[0].GetName())
+	assert.Len(t, result.Edges, 2, "both sources should still reference the shared target")
+	assert.Equal(t, 1, result.Statistics.APICallsToThisDepth)
+
+	gets := 0
+	for _, action := range dynamicClient.Actions() {
+		if action.GetVerb() == "get" && action.GetResource().Resource == "configmaps" {
+			gets++
+		}
+	}
+	assert.Equal(t, 1, gets, "the shared ConfigMap should be fetched once per depth")
+}
+
+// concurrencyBarrier holds every caller until limit callers are inside at
+// once, and records the peak number inside
+type concurrencyBarrier struct {
+	limit        int32
+	inside, peak atomic.Int32
+	full         chan struct{}
+	once         sync.Once
+}
+
+func newConcurrencyBarrier(limit int32) *concurrencyBarrier {
+	return &concurrencyBarrier{limit: limit, full: make(chan struct{})}
+}
+
+func (b *concurrencyBarrier) enter() {
+	n := b.inside.Add(1)
+	for {
+		p := b.peak.Load()
+		if n <= p || b.peak.CompareAndSwap(p, n) {
+			break
+		}
+	}
+	if n >= b.limit {
+		b.once.Do(func() { close(b.full) })
+	}
+
+	// Callers are released once the limit is reached; the timeout only
+	// keeps a broken limit from hanging the test
+	select {
+	case <-b.full:
+	case <-time.After(5 * time.Second):
+	}
+}
+
+func (b *concurrencyBarrier) leave() {
+	b.inside.Add(-1)
+}
+
+// reached reports whether limit callers were ever inside at once
+func (b *concurrencyBarrier) reached() bool {
+	select {
+	case <-b.full:
+		return true
+	default:
+		return false
+	}
+}
+
+// concurrencyTrackingResolver holds extraction and resolution calls at
+// barriers to observe how many run concurrently
+type concurrencyTrackingResolver struct {
+	mockReferenceResolver
+	extracting, resolving *concurrencyBarrier
+}
+
+func (r *concurrencyTrackingResolver) ExtractReferences(ctx context.Context, resource *unstructured.Unstructured) ([]dynamictypes.ReferenceField, error) {
+	r.extracting.enter()
+	defer r.extracting.leave()
+	return r.mockReferenceResolver.ExtractReferences(ctx, resource)
+}
+
+func (r *concurrencyTrackingResolver) ResolveReferenceResults(ctx context.Context, source *unstructured.Unstructured, references []dynamictypes.ReferenceField) []*ReferenceResolutionResult {
+	r.resolving.enter()
+	defer r.resolving.leave()
+	return r.mockReferenceResolver.ResolveReferenceResults(ctx, source, references)
+}
+
+func TestDiscoverReferencedResourcesConcurrencyLimits(t *testing.T) {
+	resolver := &concurrencyTrackingResolver{
+		mockReferenceResolver: mockReferenceResolver{
+			references: []dynamictypes.ReferenceField{
+				{FieldPath: "spec.clusterRef", FieldName: "clusterRef", TargetKind: "KubeCluster", Confidence: 0.9},
+			},
+		},
+		extracting: newConcurrencyBarrier(2),
+		resolving:  newConcurrencyBarrier(4),
+	}
+	engine := newTestTraversalEngine(resolver)
+
+	config := NewDefaultTraversalConfig()
+	config.ScopeFilter = &ScopeFilterConfig{CrossNamespaceEnabled: true}
+	config.Performance.MaxConcurrentExtraction = 2
+	config.Performance.MaxConcurrentRequests = 4
+
+	var sources []*unstructured.Unstructured
+	for i := 0; i < 16; i++ {
+		sources = append(sources, newTestResource("KubeApp", fmt.Sprintf("app-%d", i)))
+	}
+
+	_, err := engine.DiscoverReferencedResources(context.Background(), sources, config)
+	require.NoError(t, err)
+
+	assert.Equal(t, 16, resolver.extractCalls)
+	assert.Equal(t, 16, resolver.resolveCalls)
+
+	// Held resolutions fill their own limit while extraction keeps to its
+	assert.True(t, resolver.extracting.reached(), "extraction should reach its limit")
+	assert.True(t, resolver.resolving.reached(), "resolution should reach its limit despite the lower extraction limit")
+	assert.LessOrEqual(t, resolver.extracting.peak.Load(), int32(2))
+	assert.LessOrEqual(t, resolver.resolving.peak.Load(), int32(4))
+}
+
+func TestExecuteTransitiveDiscoveryMergesDetectionProvenance(t *testing.T) {
+	cluster := newTestResource("KubeCluster", "primary")
+	cluster.SetNamespace("")
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), cluster)
+
+	// The registry declares the field that the built-in patterns also detect
+	reg := &referencesRegistry{references: map[string][]registry.ResourceReference{
+		"KubeApp": {{
+			FieldPath:   "$.spec.kubeClusterRef",
+			TargetKind:  "KubeCluster",
+			TargetGroup: "platform.kubecore.io",
+			RefType:     registry.RefTypeCustom,
+		}},
+	}}
+	resolver := NewDefaultReferenceResolver(dynamicClient, reg, logging.NewNopLogger())
+	engine := newTestTraversalEngine(resolver)
+
+	config := NewDefaultTraversalConfig()
+	config.MaxDepth = 1
+	config.ScopeFilter = &ScopeFilterConfig{CrossNamespaceEnabled: true}
+
+	root := newTestResource("KubeApp", "my-app")
+	root.Object["spec"] = map[string]interface{}{"kubeClusterRef": "primary"}
+
+	result, err := engine.ExecuteTransitiveDiscovery(context.Background(), config, []*unstructured.Unstructured{root})
+	require.NoError(t, err)
+
+	var edges []*graph.ResourceEdge
+	for _, edge := range result.ResourceGraph.Edges {
+		if edge.FieldPath == "spec.kubeClusterRef" {
+			edges = append(edges, edge)
+		}
+	}
+	require.Len(t, edges, 1, "both detections share one edge")
+	edge := edges[0]
+
+	methods := make(map[string]float64)
+	for _, provenance := range edge.Metadata.Provenance {
+		methods[provenance.DetectionMethod] = provenance.Confidence
+	}
+	require.Contains(t, methods, "registry")
+	require.Contains(t, methods, "pattern_match")
+	assert.Equal(t, 1.0, methods["registry"])
+	assert.Equal(t, 1.0, edge.Confidence)
+	assert.Equal(t, 1, result.Statistics.EdgesByRelationType[graph.RelationTypeCustomRef])
+}
+
+func TestExtractReferencesExpandsMapWildcards(t *testing.T) {
+	reg := &referencesRegistry{references: map[string][]registry.ResourceReference{
+		"KubeApp": {{
+			FieldPath:  "$.spec.databases.*.secretRef",
+			TargetKind: "Secret",
+			RefType:    registry.RefTypeSecret,
+		}},
+	}}
+	resolver := NewDefaultReferenceResolver(dynamicfake.NewSimpleDynamicClient(runtime.NewScheme()), reg, logging.NewNopLogger())
+
+	source := newTestResource("KubeApp", "my-app")
+	source.Object["spec"] = map[string]interface{}{
+		"databases": map[string]interface{}{
+			"orders":  map[string]interface{}{"secretRef": "orders-db"},
+			"billing": map[string]interface{}{"secretRef": "billing-db"},
+		},
+	}
+
+	references, err := resolver.ExtractReferences(context.Background(), source)
+	require.NoError(t, err)
+
+	values := make(map[string]interface{})
+	for _, ref := range references {
+		if ref.DetectionMethod != "registry" {
+			continue
+		}
+		assert.Equal(t, "spec.databases.*.secretRef", ref.SchemaPath)
+		value, err := resolver.extractReferenceValue(context.Background(), source, ref.FieldPath, resolver.resolutionOptions(context.Background()))
+		require.NoError(t, err)
+		values[ref.FieldPath] = value
+	}
+	assert.Equal(t, map[string]interface{}{
+		"spec.databases.billing.secretRef": "billing-db",
+		"spec.databases.orders.secretRef":  "orders-db",
+	}, values)
+
+	_, err = resolver.extractReferenceValue(context.Background(), source, "spec.databases.*.secretRef", resolver.resolutionOptions(context.Background()))
+	assert.Error(t, err, "unexpanded schema paths cannot be read")
+}
+
+func TestResolveOwnerReferenceVerifiesUID(t *testing.T) {
+	env := &unstructured.Unstructured{}
+	env.SetAPIVersion("platform.kubecore.io/v1")
+	env.SetKind("KubEnv")
+	env.SetName("dev")
+	env.SetNamespace("default")
+	env.SetUID("uid-current")
+
+	cases := map[string]struct {
+		ownerUID  string
+		expectErr bool
+	}{
+		"MatchingUID": {ownerUID: "uid-current"},
+		"ReusedName":  {ownerUID: "uid-deleted", expectErr: true},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			resolver, _ := newTestReferenceResolver(env)
+
+			source := newTestResource("KubeApp", "my-app")
+			source.SetOwnerReferences([]metav1.OwnerReference{
+				{APIVersion: "platform.kubecore.io/v1", Kind: "KubEnv", Name: "dev", UID: types.UID(tc.ownerUID)},
+			})
+
+			refs, err := resolver.extractOwnerReferences(context.Background(), source, resolver.resolutionOptions(context.Background()))
+			require.NoError(t, err)
+			require.Len(t, refs, 1)
+
+			resolved, err := resolver.ResolveReference(context.Background(), source, refs[0])
+			if tc.expectErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "stale owner reference")
+				assert.Nil(t, resolved)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, env.GetUID(), resolved.GetUID())
+		})
+	}
+}
+
+func TestMultipleControllerOwnerReferences(t *testing.T) {
+	isController := true
+	resolver, _ := newTestReferenceResolver()
+	engine := newTestTraversalEngine(resolver)
+
+	config := NewDefaultTraversalConfig()
+	config.MaxDepth = 1
+	config.ScopeFilter = &ScopeFilterConfig{CrossNamespaceEnabled: true}
+
+	root := newTestResource("KubeApp", "my-app")
+	root.SetOwnerReferences([]metav1.OwnerReference{
+		{APIVersion: "platform.kubecore.io/v1", Kind: "KubEnv", Name: "dev", Controller: &isController},
+		{APIVersion: "platform.kubecore.io/v1alpha1", Kind: "KubEnv", Name: "dev-legacy", Controller: &isController},
+		{APIVersion: "platform.kubecore.io/v1", Kind: "KubeSystem", Name: "system"},
+	})
+
+	result, err := engine.ExecuteTransitiveDiscovery(context.Background(), config, []*unstructured.Unstructured{root})
+	require.NoError(t, err)
+
+	require.Len(t, result.Warnings, 1)
+	warning := result.Warnings[0]
+	assert.Equal(t, TraversalWarningMultipleControllers, warning.Type)
+	assert.Equal(t, "platform.kubecore.io/v1/KubeApp/default/my-app", warning.ResourceID)
+	assert.Equal(t, []string{
+		"platform.kubecore.io/v1/KubEnv/dev",
+		"platform.kubecore.io/v1alpha1/KubEnv/dev-legacy",
+	}, warning.Context["controllers"])
+
+	// Warnings are reported per discovery, not kept on the resolver
+	assert.Empty(t, resolver.TakeWarnings())
+
+	// Concurrent discoveries each report only the warnings of their own resources
+	clean := newTestResource("KubeApp", "other-app")
+	var wg sync.WaitGroup
+	results := make([]*DiscoveryResult, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resource := root
+			if i%2 == 1 {
+				resource = clean
+			}
+			results[i], _ = engine.DiscoverReferencedResources(context.Background(), []*unstructured.Unstructured{resource}, config)
+		}(i)
+	}
+	wg.Wait()
+
+	for i, discovery := range results {
+		require.NotNil(t, discovery)
+		if i%2 == 1 {
+			assert.Empty(t, discovery.Warnings, "discovery %d", i)
+			continue
+		}
+		require.Len(t, discovery.Warnings, 1, "discovery %d", i)
+		assert.Equal(t, TraversalWarningMultipleControllers, discovery.Warnings[0].Type)
+	}
+}
+
+func TestExtractReferencesFromAnnotations(t *testing.T) {
+	env := &unstructured.Unstructured{}
+	env.SetAPIVersion("platform.kubecore.io/v1")
+	env.SetKind("KubEnv")
+	env.SetName("dev")
+	env.SetNamespace("default")
+
+	resolver, _ := newTestReferenceResolver(env)
+
+	source := newTestResource("KubeApp", "my-app")
+	source.SetAnnotations(map[string]string{
+		"kubecore.io/environment": "dev",
+		"kubecore.io/owner-team":  "platform",
+	})
+
+	annotationRefs := func(refs []dynamictypes.ReferenceField) []dynamictypes.ReferenceField {
+		var result []dynamictypes.ReferenceField
+		for _, ref := range refs {
+			if ref.DetectionMethod == "annotation" {
+				result = append(result, ref)
+			}
+		}
+		return result
+	}
+
+	refs, err := resolver.ExtractReferences(context.Background(), source)
+	require.NoError(t, err)
+	assert.Empty(t, annotationRefs(refs), "annotations should not be scanned without patterns")
+
+	resolver.SetAnnotationReferences([]AnnotationReferencePattern{
+		{KeyPrefix: "kubecore.io/environment", TargetKind: "KubEnv", TargetGroup: "platform.kubecore.io", TargetVersion: "v1"},
+	})
+
+	refs, err = resolver.ExtractReferences(context.Background(), source)
+	require.NoError(t, err)
+	found := annotationRefs(refs)
+	require.Len(t, found, 1)
+	assert.Equal(t, "KubEnv", found[0].TargetKind)
+	assert.Equal(t, "kubecore.io/environment", found[0].FieldName)
+
+	resolved, err := resolver.ResolveReference(context.Background(), source, found[0])
+	require.NoError(t, err)
+	assert.Equal(t, "dev", resolved.GetName())
+}
+
+func TestExtractReferencesWithInputPatterns(t *testing.T) {
+	source := newTestResource("KubeApp", "my-app")
+	source.Object["spec"] = map[string]interface{}{
+		"kubeClusterRef": map[string]interface{}{"name": "cluster"},
+		"vaultBinding":   "app-credentials",
+	}
+
+	inputPattern := ReferencePattern{
+		Pattern:    "vaultBinding",
+		TargetKind: "Secret",
+		RefType:    RefTypeSecret,
+		Confidence: 0.9,
+	}
+
+	cases := map[string]struct {
+		patterns        []ReferencePattern
+		replaceDefaults bool
+		expected        []string
+	}{
+		"Defaults": {
+			expected: []string{"kubeClusterRef"},
+		},
+		"Append": {
+			patterns: []ReferencePattern{inputPattern},
+			expected: []string{"kubeClusterRef", "vaultBinding"},
+		},
+		"Replace": {
+			patterns:        []ReferencePattern{inputPattern},
+			replaceDefaults: true,
+			expected:        []string{"vaultBinding"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			resolver, _ := newTestReferenceResolver()
+			resolver.SetReferencePatterns(tc.patterns, tc.replaceDefaults)
+
+			refs, err := resolver.extractReferencesFromPatterns(source, resolver.resolutionOptions(context.Background()).referenceDetector)
+			require.NoError(t, err)
+
+			matched := make(map[string]bool)
+			for _, ref := range refs {
+				if ref.DetectionMethod != "pattern_match" {
+					continue
+				}
+				matched[ref.FieldName] = true
+				if ref.FieldName == "vaultBinding" {
+					assert.Equal(t, "Secret", ref.TargetKind)
+					assert.Equal(t, dynamictypes.RefTypeSecret, ref.RefType)
+					assert.Equal(t, "spec.vaultBinding", ref.FieldPath)
+				}
+			}
+
+			fields := make([]string, 0, len(matched))
+			for field := range matched {
+				fields = append(fields, field)
+			}
+			assert.ElementsMatch(t, tc.expected, fields)
+		})
+	}
+}
+
+func TestReferenceResolverStructuredLogFields(t *testing.T) {
+	var mu sync.Mutex
+	var entries []map[string]interface{}
+	sink := funcr.NewJSON(func(obj string) {
+		entry := make(map[string]interface{})
+		require.NoError(t, json.Unmarshal([]byte(obj), &entry))
+		mu.Lock()
+		entries = append(entries, entry)
+		mu.Unlock()
+	}, funcr.Options{Verbosity: 1})
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+	resolver := NewDefaultReferenceResolver(dynamicClient, &mockRegistry{}, logging.NewLogrLogger(sink))
+
+	source := newTestResource("KubeApp", "my-app")
+	source.Object["spec"] = map[string]interface{}{
+		"kubeClusterRef": map[string]interface{}{"name": "cluster"},
+	}
+	_, err := resolver.ExtractReferences(context.Background(), source)
+	require.NoError(t, err)
+
+	find := func(msg string) map[string]interface{} {
+		for _, entry := range entries {
+			if entry["msg"] == msg {
+				return entry
+			}
+		}
+		return nil
+	}
+
+	extracted := find("Extracted references from resource")
+	require.NotNil(t, extracted)
+	assert.Equal(t, logfields.ComponentReferenceResolver, extracted[logfields.Component])
+	assert.Equal(t, "platform.kubecore.io/v1, Kind=KubeApp", extracted[logfields.ResourceGVK])
+	assert.Equal(t, "my-app", extracted[logfields.ResourceName])
+	assert.Equal(t, "default", extracted[logfields.ResourceNamespace])
+
+	detected := find("Reference found")
+	require.NotNil(t, detected)
+	assert.Equal(t, "spec.kubeClusterRef", detected[logfields.FieldPath])
+
+	matched := find("Pattern match found!")
+	require.NotNil(t, matched)
+	assert.Equal(t, logfields.ComponentReferenceDetector, matched[logfields.Component])
+}
+
+func TestExecuteTransitiveDiscoverySkipsOutOfScopeOwners(t *testing.T) {
+	replicaSet := &unstructured.Unstructured{}
+	replicaSet.SetAPIVersion("apps/v1")
+	replicaSet.SetKind("ReplicaSet")
+	replicaSet.SetName("app-rs")
+	replicaSet.SetNamespace("default")
+
+	env := newTestResource("KubEnv", "dev")
+
+	resolver, _ := newTestReferenceResolver(replicaSet, env)
+	engine := newTestTraversalEngine(resolver)
+
+	config := NewDefaultTraversalConfig()
+	config.MaxDepth = 1
+	config.ScopeFilter = &ScopeFilterConfig{PlatformOnly: true, CrossNamespaceEnabled: true}
+
+	source := newTestResource("KubeApp", "my-app")
+	source.SetOwnerReferences([]metav1.OwnerReference{
+		{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "app-rs"},
+		{APIVersion: "platform.kubecore.io/v1", Kind: "KubEnv", Name: "dev"},
+	})
+
+	result, err := engine.ExecuteTransitiveDiscovery(context.Background(), config, []*unstructured.Unstructured{source})
+	require.NoError(t, err)
+
+	assert.Contains(t, result.DiscoveredResources, engine.generateResourceID(env))
+	assert.NotContains(t, result.DiscoveredResources, engine.generateResourceID(replicaSet))
+	assert.Equal(t, int64(1), resolver.APICalls(), "the ReplicaSet owner should never be looked up")
+
+	ctx, err := engine.withResolutionOptions(context.Background(), config)
+	require.NoError(t, err)
+	refs, err := resolver.ExtractReferences(ctx, source)
+	require.NoError(t, err)
+	for _, ref := range refs {
+		assert.NotEqual(t, "ReplicaSet", ref.TargetKind)
+	}
+}
+
+func TestTraversalEngineClose(t *testing.T) {
+	before := goruntime.NumGoroutine()
+
+	resolver, _ := newTestReferenceResolver()
+	engine := newTestTraversalEngine(resolver)
+	engine.components.Cache = NewLRUCache(DefaultCacheMaxSize, DefaultCacheTTL)
+	engine.components.Cache.Set("key", "value", 0)
+	engine.metricsCollector.RecordResourceProcessed()
+
+	// Both caches run a cleanup goroutine
+	assert.GreaterOrEqual(t, goruntime.NumGoroutine(), before+2)
+
+	require.NoError(t, engine.Close())
+	require.NoError(t, engine.Close())
+
+	assert.Equal(t, 0, engine.components.Cache.Size())
+	assert.Equal(t, int64(0), engine.metricsCollector.GetTotalResourcesProcessed())
+	// assert.Eventually evaluates the condition on a goroutine of its own
+	assert.Eventually(t, func() bool {
+		return goruntime.NumGoroutine() <= before+1
+	}, time.Second, 10*time.Millisecond)
+}
+
+// Integration test for traversal engine (would require actual Kubernetes cluster)
+func TestTraversalEngineIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test")
+	}
+
+	// This test is designed to show what would happen with a real integration
+	// but in unit test environment, we cannot create real clients
+	// So we'll just skip it for now
+	t.Skip("Integration test requires real Kubernetes cluster")
+}
+
+// Benchmark tests
+func BenchmarkLRUCache(b *testing.B) {
+	cache := NewLRUCache(1000, 1*time.Minute)
+	defer cache.Close()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		key := fmt.Sprintf("key-%d", i%500)
+		cache.Set(key, fmt.Sprintf("value-%d", i), 1*time.Minute)
+		cache.Get(key)
+	}
+}
+
+func BenchmarkResourceTracker(b *testing.B) {
+	tracker := NewResourceTracker()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		resourceID := fmt.Sprintf("resource-%d", i)
+		tracker.MarkProcessed(resourceID, i%5)
+		tracker.IsProcessed(resourceID)
+	}
+}
+
+func TestResolveAllReferencesMatchesGraphEdges(t *testing.T) {
+	resolver := &mockReferenceResolver{
+		references: []dynamictypes.ReferenceField{
+			{FieldPath: "spec.clusterRef", FieldName: "clusterRef", TargetKind: "KubeCluster", RefType: dynamictypes.RefTypeCustom, Confidence: 0.9},
+		},
+		resolvedBySource: map[string][]*unstructured.Unstructured{
+			"app-a": {newTestResource("KubeCluster", "cluster-a")},
+			"app-b": {newTestResource("KubeCluster", "cluster-a"), newTestResource("KubeCluster", "cluster-b")},
+		},
+	}
+	engine := newTestTraversalEngine(resolver)
+
+	config := NewDefaultTraversalConfig()
+	config.MaxDepth = 1
+	config.ScopeFilter = &ScopeFilterConfig{CrossNamespaceEnabled: true}
+
+	roots := []*unstructured.Unstructured{
+		newTestResource("KubeApp", "app-a"),
+		newTestResource("KubeApp", "app-b"),
+	}
+
+	resolved, err := engine.ResolveAllReferences(context.Background(), roots, config)
+	require.NoError(t, err)
+
+	result, err := engine.ExecuteTransitiveDiscovery(context.Background(), config, roots)
+	require.NoError(t, err)
+
+	type flatEdge struct {
+		source, target string
+		relation       graph.RelationType
+		fieldPath      string
+		confidence     float64
+	}
+
+	want := make(map[flatEdge]bool)
+	for _, edge := range result.ResourceGraph.Edges {
+		want[flatEdge{string(edge.Source), string(edge.Target), edge.RelationType, edge.FieldPath, edge.Confidence}] = true
+	}
+
+	got := make(map[flatEdge]bool)
+	for _, ref := range resolved {
+		source := &unstructured.Unstructured{}
+		source.SetGroupVersionKind(ref.SourceGVK)
+		source.SetNamespace(ref.SourceNamespace)
+		source.SetName(ref.SourceName)
+		got[flatEdge{engine.generateResourceID(source), engine.generateResourceID(ref.Target), ref.RelationType, ref.FieldPath, ref.Confidence}] = true
+	}
+
+	assert.Len(t, resolved, 3)
+	assert.Equal(t, want, got)
+}
+
+func TestExecuteTransitiveDiscoveryCrossNamespace(t *testing.T) {
+	newConfigMap := func(name, namespace string) *unstructured.Unstructured {
+		configMap := &unstructured.Unstructured{}
+		configMap.SetAPIVersion("v1")
+		configMap.SetKind("ConfigMap")
+		configMap.SetName(name)
+		configMap.SetNamespace(namespace)
+		return configMap
+	}
+
+	cases := map[string]struct {
+		crossNamespaceEnabled bool
+		expectShared          bool
+		expectWarnings        int
+	}{
+		"Disabled": {crossNamespaceEnabled: false, expectShared: false, expectWarnings: 1},
+		"Enabled":  {crossNamespaceEnabled: true, expectShared: true, expectWarnings: 0},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			local := newConfigMap("local", "default")
+			shared := newConfigMap("shared", "platform")
+
+			resolver, _ := newTestReferenceResolver(local, shared)
+			engine := newTestTraversalEngine(resolver)
+
+			config := NewDefaultTraversalConfig()
+			config.MaxDepth = 1
+			config.ScopeFilter = &ScopeFilterConfig{CrossNamespaceEnabled: tc.crossNamespaceEnabled}
+
+			source := newTestResource("KubeApp", "my-app")
+			source.Object["spec"] = map[string]interface{}{
+				"configMapRef": "local",
+				"sharedConfigMapRef": map[string]interface{}{
+					"name":      "shared",
+					"namespace": "platform",
+				},
+			}
+
+			result, err := engine.ExecuteTransitiveDiscovery(context.Background(), config, []*unstructured.Unstructured{source})
+			require.NoError(t, err)
+
+			assert.Contains(t, result.DiscoveredResources, engine.generateResourceID(local))
+			if tc.expectShared {
+				assert.Contains(t, result.DiscoveredResources, engine.generateResourceID(shared))
+			} else {
+				assert.NotContains(t, result.DiscoveredResources, engine.generateResourceID(shared))
+			}
+
+			var warnings []ValidationWarning
+			for _, warning := range result.ValidationResult.Warnings {
+				if warning.Type == ValidationWarningScopeFiltered {
+					warnings = append(warnings, warning)
+				}
+			}
+			require.Len(t, warnings, tc.expectWarnings)
+			for _, warning := range warnings {
+				assert.Contains(t, warning.Message, "platform")
+			}
+		})
+	}
+}
+
+func TestExtractReferencesCEL(t *testing.T) {
+	cluster := &unstructured.Unstructured{}
+	cluster.SetAPIVersion("platform.kubecore.io/v1")
+	cluster.SetKind("KubeCluster")
+	cluster.SetName("primary")
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), cluster)
+
+	// Neither field name looks like a reference, so patterns cannot find it
+	source := newTestResource("KubeApp", "shop")
+	source.Object["spec"] = map[string]interface{}{"foo": "primary", "bar": "KubeCluster"}
+
+	cases := map[string]struct {
+		extractors []CELReferenceExtractor
+		want       bool
+	}{
+		"NoExtractor": {},
+		"OtherKind": {
+			extractors: []CELReferenceExtractor{{
+				Kind:       "KubEnv",
+				Expression: `{"name": object.spec.foo, "kind": object.spec.bar}`,
+			}},
+		},
+		"MissingField": {
+			extractors: []CELReferenceExtractor{{
+				Kind:       "KubeApp",
+				Expression: `{"name": object.spec.missing, "kind": object.spec.bar}`,
+			}},
+		},
+		"Extracted": {
+			extractors: []CELReferenceExtractor{{
+				Kind:       "KubeApp",
+				Expression: `{"name": object.spec.foo, "kind": object.spec.bar, "apiVersion": "platform.kubecore.io/v1"}`,
+			}},
+			want: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			resolver := NewDefaultReferenceResolver(client, &mockRegistry{}, logging.NewNopLogger())
+			require.NoError(t, resolver.SetCELReferences(tc.extractors))
+
+			references, err := resolver.ExtractReferences(context.Background(), source)
+			require.NoError(t, err)
+
+			var celRefs []dynamictypes.ReferenceField
+			for _, ref := range references {
+				if ref.DetectionMethod == "cel" {
+					celRefs = append(celRefs, ref)
+				}
+			}
+			if !tc.want {
+				assert.Empty(t, celRefs)
+				return
+			}
+
+			require.Len(t, celRefs, 1)
+			assert.Equal(t, "KubeCluster", celRefs[0].TargetKind)
+			assert.Equal(t, "platform.kubecore.io", celRefs[0].TargetGroup)
+			assert.Equal(t, "v1", celRefs[0].TargetVersion)
+
+			resolved, err := resolver.ResolveReference(context.Background(), source, celRefs[0])
+			require.NoError(t, err)
+			assert.Equal(t, "primary", resolved.GetName())
+		})
+	}
+
+	t.Run("InvalidExpression", func(t *testing.T) {
+		resolver := NewDefaultReferenceResolver(client, &mockRegistry{}, logging.NewNopLogger())
+		err := resolver.SetCELReferences([]CELReferenceExtractor{{Kind: "KubeApp", Expression: `{"name": `}})
+		assert.Error(t, err)
+	})
+
+	t.Run("CostLimitExceeded", func(t *testing.T) {
+		// Searching a string for itself costs the square of its length
+		large := source.DeepCopy()
+		large.Object["spec"].(map[string]interface{})["text"] = strings.Repeat("a", 20000)
+
+		resolver := NewDefaultReferenceResolver(client, &mockRegistry{}, logging.NewNopLogger())
+		require.NoError(t, resolver.SetCELReferences([]CELReferenceExtractor{{
+			Kind:       "KubeApp",
+			Expression: `object.spec.text.contains(object.spec.text) ? [{"name": object.spec.foo, "kind": object.spec.bar}] : []`,
+		}}))
+
+		references, err := resolver.ExtractReferences(context.Background(), large)
+		require.NoError(t, err)
+		for _, ref := range references {
+			assert.NotEqual(t, "cel", ref.DetectionMethod)
+		}
+	})
+}
+
+func TestExecuteTransitiveDiscoveryTargetKinds(t *testing.T) {
+	newSecret := func(name string) *unstructured.Unstructured {
+		secret := &unstructured.Unstructured{}
+		secret.SetAPIVersion("v1")
+		secret.SetKind("Secret")
+		secret.SetName(name)
+		secret.SetNamespace("default")
+		return secret
+	}
+
+	// app -> env -> cluster -> cluster-creds, and app -> app-creds
+	resolver := &mockReferenceResolver{
+		references: []dynamictypes.ReferenceField{
+			{FieldPath: "spec.ref", FieldName: "ref", TargetKind: "KubeCluster", Confidence: 0.9},
+		},
+		resolvedBySource: map[string][]*unstructured.Unstructured{
+			"app":     {newTestResource("KubEnv", "env"), newSecret("app-creds")},
+			"env":     {newTestResource("KubeCluster", "cluster")},
+			"cluster": {newSecret("cluster-creds")},
+		},
+	}
+	engine := newTestTraversalEngine(resolver)
+
+	config := NewDefaultTraversalConfig()
+	config.MaxDepth = 5
+	config.ScopeFilter = &ScopeFilterConfig{CrossNamespaceEnabled: true}
+	config.TargetKinds = []string{"Secret"}
+
+	result, err := engine.ExecuteTransitiveDiscovery(context.Background(), config, []*unstructured.Unstructured{newTestResource("XKubeApp", "app")})
+	require.NoError(t, err)
+
+	var names []string
+	for _, resource := range result.DiscoveredResources {
+		assert.Equal(t, "Secret", resource.GetKind())
+		names = append(names, resource.GetName())
+	}
+	slices.Sort(names)
+	assert.Equal(t, []string{"app-creds", "cluster-creds"}, names)
+
+	// Intermediate resources were traversed and stay in the graph for pathing
+	assert.Subset(t, resolver.extracted, []string{"app", "env", "cluster"})
+	assert.Len(t, result.ResourceGraph.Nodes, 5)
+}
+
+func TestExecuteTransitiveDiscoveryCompositionMetadata(t *testing.T) {
+	newXRD := func(name, group, kind string) *unstructured.Unstructured {
+		xrd := &unstructured.Unstructured{}
+		xrd.SetAPIVersion("apiextensions.crossplane.io/v1")
+		xrd.SetKind("CompositeResourceDefinition")
+		xrd.SetName(name)
+		xrd.Object["spec"] = map[string]interface{}{
+			"group": group,
+			"names": map[string]interface{}{"kind": kind},
+		}
+		return xrd
+	}
+	composition := &unstructured.Unstructured{}
+	composition.SetAPIVersion("apiextensions.crossplane.io/v1")
+	composition.SetKind("Composition")
+	composition.SetName("xkubeapps-default")
+
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			compositeResourceDefinitionGVR: "CompositeResourceDefinitionList",
+			compositionGVR:                 "CompositionList",
+		},
+		newXRD("xkubeenvs.platform.kubecore.io", "platform.kubecore.io", "XKubEnv"),
+		newXRD("xkubeapps.platform.kubecore.io", "platform.kubecore.io", "XKubeApp"),
+		composition,
+	)
+
+	// The XR is discovered from the root rather than being the root itself
+	xr := newTestResource("XKubeApp", "app")
+	xr.Object["spec"] = map[string]interface{}{
+		"compositionRef": map[string]interface{}{"name": "xkubeapps-default"},
+	}
+	resolver := &mockReferenceResolver{
+		references: []dynamictypes.ReferenceField{
+			{FieldPath: "spec.appRef", FieldName: "appRef", TargetKind: "XKubeApp", Confidence: 0.9},
+		},
+		resolvedBySource: map[string][]*unstructured.Unstructured{"env": {xr}},
+	}
+
+	cases := map[string]struct {
+		include   bool
+		wantEdges map[graph.RelationType]string
+	}{
+		"Disabled": {},
+		"Enabled": {
+			include: true,
+			wantEdges: map[graph.RelationType]string{
+				graph.RelationTypeDefinedBy:  "xkubeapps.platform.kubecore.io",
+				graph.RelationTypeComposedBy: "xkubeapps-default",
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			engine := newTestTraversalEngine(resolver)
+			engine.components.DynamicClient = client
+
+			config := NewDefaultTraversalConfig()
+			config.MaxDepth = 2
+			config.ScopeFilter = &ScopeFilterConfig{CrossNamespaceEnabled: true}
+			config.IncludeCompositionMetadata = tc.include
+
+			result, err := engine.ExecuteTransitiveDiscovery(context.Background(), config, []*unstructured.Unstructured{newTestResource("KubEnv", "env")})
+			require.NoError(t, err)
+			assert.Empty(t, result.Errors)
+
+			xrID := graph.NodeID(engine.generateResourceID(xr))
+			got := make(map[graph.RelationType]string)
+			for _, edge := range result.ResourceGraph.Edges {
+				if edge.Source != xrID {
+					continue
+				}
+				target := result.ResourceGraph.Nodes[edge.Target]
+				require.NotNil(t, target)
+				got[edge.RelationType] = target.Resource.GetName()
+			}
+
+			if tc.wantEdges == nil {
+				assert.Empty(t, got)
+				return
+			}
+			assert.Equal(t, tc.wantEdges, got)
+		})
+	}
+}
+
+func TestExecuteTransitiveDiscoveryMaxEdges(t *testing.T) {
+	// Every environment references every cluster, so the graph grows
+	// quadratically unless the edge cap stops it
+	envs := []*unstructured.Unstructured{
+		newTestResource("KubEnv", "env-a"),
+		newTestResource("KubEnv", "env-b"),
+		newTestResource("KubEnv", "env-c"),
+		newTestResource("KubEnv", "env-d"),
+	}
+	clusters := []*unstructured.Unstructured{
+		newTestResource("KubeSystem", "system-a"),
+		newTestResource("KubeSystem", "system-b"),
+		newTestResource("KubeSystem", "system-c"),
+	}
+	resolvedBySource := map[string][]*unstructured.Unstructured{"app": envs}
+	for _, env := range envs {
+		resolvedBySource[env.GetName()] = clusters
+	}
+
+	resolver := &mockReferenceResolver{
+		references: []dynamictypes.ReferenceField{
+			{FieldPath: "spec.ref", FieldName: "ref", TargetKind: "KubEnv", Confidence: 0.9},
+		},
+		resolvedBySource: resolvedBySource,
+	}
+	engine := newTestTraversalEngine(resolver)
+
+	config := NewDefaultTraversalConfig()
+	config.MaxDepth = 5
+	config.MaxEdges = 3
+	config.ScopeFilter = &ScopeFilterConfig{CrossNamespaceEnabled: true}
+
+	result, err := engine.ExecuteTransitiveDiscovery(context.Background(), config, []*unstructured.Unstructured{newTestResource("XKubeApp", "app")})
+	require.NoError(t, err)
+
+	assert.Len(t, result.ResourceGraph.Edges, 3)
+	assert.True(t, result.ResourceGraph.Metadata.EdgesTruncated)
+	assert.Equal(t, TerminationReasonMaxEdges, result.Metadata.TerminationReason)
+
+	// Expansion stopped once the cap was hit, so no environment was expanded
+	assert.Equal(t, []string{"app"}, resolver.extracted)
+	assert.Equal(t, 1, result.TraversalPath.MaxDepthReached)
+}
+
+func TestExecuteTransitiveDiscoveryEdgesByRelationType(t *testing.T) {
+	// app owns env, env references two clusters through a custom field
+	resolver := &mockReferenceResolver{
+		referencesBySource: map[string][]dynamictypes.ReferenceField{
+			"app": {{FieldPath: "metadata.ownerReferences[0]", FieldName: "ownerReferences", TargetKind: "KubEnv", RefType: dynamictypes.RefTypeOwnerRef, Confidence: 1.0}},
+			"env": {{FieldPath: "spec.clusterRef", FieldName: "clusterRef", TargetKind: "KubeSystem", RefType: dynamictypes.RefTypeCustom, Confidence: 0.9}},
+		},
+		resolvedBySource: map[string][]*unstructured.Unstructured{
+			"app": {newTestResource("KubEnv", "env")},
+			"env": {newTestResource("KubeSystem", "system-a"), newTestResource("KubeSystem", "system-b")},
+		},
+	}
+	engine := newTestTraversalEngine(resolver)
+
+	config := NewDefaultTraversalConfig()
+	config.MaxDepth = 3
+	config.ScopeFilter = &ScopeFilterConfig{CrossNamespaceEnabled: true}
+
+	result, err := engine.ExecuteTransitiveDiscovery(context.Background(), config, []*unstructured.Unstructured{newTestResource("XKubeApp", "app")})
+	require.NoError(t, err)
+
+	counts := make(map[graph.RelationType]int)
+	for _, edge := range result.ResourceGraph.Edges {
+		counts[edge.RelationType]++
+	}
+	assert.Equal(t, map[graph.RelationType]int{
+		graph.RelationTypeOwnerRef:  1,
+		graph.RelationTypeCustomRef: 2,
+	}, counts)
+	assert.Equal(t, counts, result.Statistics.EdgesByRelationType)
+}
+
+func TestResolveReferenceForbidden(t *testing.T) {
+	resolver, dynamicClient := newTestReferenceResolver()
+	dynamicClient.PrependReactor("get", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "configmaps"}, "app-config", fmt.Errorf("RBAC: access denied"))
+	})
+
+	source := newTestResource("KubeApp", "my-app")
+	source.Object["spec"] = map[string]interface{}{"configRef": "app-config"}
+	reference := dynamictypes.ReferenceField{FieldPath: "spec.configRef", TargetKind: "ConfigMap", Confidence: 0.9}
+
+	_, err := resolver.ResolveReference(context.Background(), source, reference)
+	require.Error(t, err)
+	assert.True(t, apierrors.IsForbidden(err))
+
+	warnings := resolver.TakeWarnings()
+	require.Len(t, warnings, 1)
+	assert.Equal(t, TraversalWarningAccessDenied, warnings[0].Type)
+	assert.Equal(t, "platform.kubecore.io/v1/KubeApp/default/my-app", warnings[0].ResourceID)
+	assert.Equal(t, "/v1, Kind=ConfigMap", warnings[0].Context["gvk"])
+	assert.Equal(t, "default", warnings[0].Context["namespace"])
+	assert.Equal(t, "app-config", warnings[0].Context["name"])
+}
+
+func TestExecuteTransitiveDiscoveryForbidden(t *testing.T) {
+	forbidden := apierrors.NewForbidden(schema.GroupResource{Resource: "configmaps"}, "app-config", fmt.Errorf("RBAC: access denied"))
+
+	cases := map[string]struct {
+		failOnForbidden bool
+		wantErr         bool
+	}{
+		"RecordedAsWarning": {},
+		"FailOnForbidden": {
+			failOnForbidden: true,
+			wantErr:         true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			resolver := &mockReferenceResolver{
+				references: []dynamictypes.ReferenceField{
+					{FieldPath: "spec.configRef", FieldName: "configRef", TargetKind: "ConfigMap", Confidence: 0.9},
+				},
+				resolveErrors: []error{forbidden},
+			}
+			engine := newTestTraversalEngine(resolver)
+
+			config := NewDefaultTraversalConfig()
+			config.ScopeFilter = &ScopeFilterConfig{CrossNamespaceEnabled: true}
+			config.ReferenceResolution.FailOnForbidden = tc.failOnForbidden
+
+			result, err := engine.ExecuteTransitiveDiscovery(context.Background(), config, []*unstructured.Unstructured{newTestResource("XKubeApp", "app")})
+			if tc.wantErr {
+				require.Error(t, err)
+				assert.True(t, apierrors.IsForbidden(err))
+				assert.Equal(t, TerminationReasonError, result.Metadata.TerminationReason)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Empty(t, result.Errors, "access denied should not be reported as a resolution error")
+		})
+	}
+}
+
+func TestMergeTraversalResults(t *testing.T) {
+	// Both apps reference the same environment
+	resolver := &mockReferenceResolver{
+		references: []dynamictypes.ReferenceField{
+			{FieldPath: "spec.ref", FieldName: "ref", TargetKind: "KubEnv", Confidence: 0.9},
+		},
+		resolvedBySource: map[string][]*unstructured.Unstructured{
+			"app-a": {newTestResource("KubEnv", "shared-env")},
+			"app-b": {newTestResource("KubEnv", "shared-env")},
+		},
+	}
+	engine := newTestTraversalEngine(resolver)
+
+	config := NewDefaultTraversalConfig()
+	config.ScopeFilter = &ScopeFilterConfig{CrossNamespaceEnabled: true}
+
+	first, err := engine.ExecuteTransitiveDiscovery(context.Background(), config, []*unstructured.Unstructured{newTestResource("KubeApp", "app-a")})
+	require.NoError(t, err)
+	second, err := engine.ExecuteTransitiveDiscovery(context.Background(), config, []*unstructured.Unstructured{newTestResource("KubeApp", "app-b")})
+	require.NoError(t, err)
+	first.Statistics.APICallCount = 2
+	second.Statistics.APICallCount = 3
+
+	merged := MergeTraversalResults(first, second)
+
+	sharedID := engine.generateResourceID(newTestResource("KubEnv", "shared-env"))
+	assert.Len(t, merged.DiscoveredResources, 3)
+	assert.Contains(t, merged.DiscoveredResources, sharedID)
+	assert.Len(t, merged.ResourceGraph.Nodes, 3)
+	assert.Len(t, merged.ResourceGraph.Edges, 2)
+	assert.Len(t, merged.ResourceGraph.ReverseAdjacencyList[graph.NodeID(sharedID)], 2)
+
+	assert.Equal(t, 3, merged.Statistics.TotalResources)
+	assert.Equal(t, map[string]int{"KubeApp": 2, "KubEnv": 1}, merged.Statistics.ResourcesByKind)
+	assert.Equal(t, map[int]int{0: 2, 1: 1}, merged.Statistics.ResourcesByDepth)
+	assert.Equal(t, 2, merged.Statistics.EdgesByRelationType[graph.RelationTypeCustomRef])
+	assert.Equal(t, 5, merged.Statistics.APICallCount)
+
+	steps := len(first.TraversalPath.Steps) + len(second.TraversalPath.Steps)
+	require.Len(t, merged.TraversalPath.Steps, steps)
+	assert.Equal(t, steps, merged.TraversalPath.TotalSteps)
+	for i, step := range merged.TraversalPath.Steps {
+		assert.Equal(t, i, step.StepID)
+	}
+	assert.ElementsMatch(t, append(first.Metadata.StartResources, second.Metadata.StartResources...), merged.Metadata.StartResources)
+}
+
+func TestExtractReferencesArrayFieldPaths(t *testing.T) {
+	configMap := &unstructured.Unstructured{}
+	configMap.SetAPIVersion("v1")
+	configMap.SetKind("ConfigMap")
+	configMap.SetName("app-settings")
+	configMap.SetNamespace("default")
+
+	resolver, _ := newTestReferenceResolver(configMap)
+
+	source := newTestResource("KubeApp", "my-app")
+	source.Object["spec"] = map[string]interface{}{
+		"volumes": []interface{}{
+			map[string]interface{}{"configMapRef": "app-settings"},
+			map[string]interface{}{"configMapRef": "app-flags"},
+		},
+	}
+
+	refs, err := resolver.extractReferencesFromPatterns(source, resolver.resolutionOptions(context.Background()).referenceDetector)
+	require.NoError(t, err)
+
+	var arrayRefs []dynamictypes.ReferenceField
+	for _, ref := range refs {
+		if ref.FieldName == "configMapRef" {
+			arrayRefs = append(arrayRefs, ref)
+		}
+	}
+	require.Len(t, arrayRefs, 2)
+
+	paths := make([]string, 0, len(arrayRefs))
+	for _, ref := range arrayRefs {
+		assert.Equal(t, "spec.volumes[*].configMapRef", ref.SchemaPath)
+		paths = append(paths, ref.FieldPath)
+	}
+	assert.ElementsMatch(t, []string{"spec.volumes[0].configMapRef", "spec.volumes[1].configMapRef"}, paths)
+
+	for _, ref := range arrayRefs {
+		if ref.FieldPath != "spec.volumes[0].configMapRef" {
+			continue
+		}
+		resolved, err := resolver.ResolveReference(context.Background(), source, ref)
+		require.NoError(t, err)
+		assert.Equal(t, "app-settings", resolved.GetName())
+	}
+}
+
+func TestStreamResultJSONL(t *testing.T) {
+	// Two apps share an environment: three nodes and two edges
+	resolver := &mockReferenceResolver{
+		references: []dynamictypes.ReferenceField{
+			{FieldPath: "spec.ref", FieldName: "ref", TargetKind: "KubEnv", Confidence: 0.9},
+		},
+		resolvedBySource: map[string][]*unstructured.Unstructured{
+			"app-a": {newTestResource("KubEnv", "shared-env")},
+			"app-b": {newTestResource("KubEnv", "shared-env")},
+		},
+	}
+	engine := newTestTraversalEngine(resolver)
+
+	config := NewDefaultTraversalConfig()
+	config.ScopeFilter = &ScopeFilterConfig{CrossNamespaceEnabled: true}
+
+	result, err := engine.ExecuteTransitiveDiscovery(context.Background(), config, []*unstructured.Unstructured{
+		newTestResource("KubeApp", "app-a"),
+		newTestResource("KubeApp", "app-b"),
+	})
+	require.NoError(t, err)
+	require.Len(t, result.ResourceGraph.Nodes, 3)
+	require.Len(t, result.ResourceGraph.Edges, 2)
+
+	var out strings.Builder
+	require.NoError(t, StreamResultJSONL(&out, result))
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	require.Len(t, lines, len(result.ResourceGraph.Nodes)+len(result.ResourceGraph.Edges))
+
+	counts := make(map[string]int)
+	for _, line := range lines {
+		var object map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &object), line)
+		counts[object["type"].(string)]++
+
+		switch object["type"] {
+		case "node":
+			assert.NotEmpty(t, object["id"])
+			assert.NotEmpty(t, object["kind"])
+		case "edge":
+			assert.NotEmpty(t, object["source"])
+			assert.NotEmpty(t, object["target"])
+			assert.Equal(t, string(graph.RelationTypeCustomRef), object["relationType"])
+		}
+	}
+	assert.Equal(t, map[string]int{"node": 3, "edge": 2}, counts)
+}
+
+func TestExecuteTransitiveDiscoveryLowConfidenceWarning(t *testing.T) {
+	cases := map[string]struct {
+		confidence float64
+		threshold  float64
+		wantWarned bool
+	}{
+		"HeuristicReferencesOnly": {
+			confidence: 0.6,
+			threshold:  0.7,
+			wantWarned: true,
+		},
+		"ConfidentReferences": {
+			confidence: 0.9,
+			threshold:  0.7,
+		},
+		"Disabled": {
+			confidence: 0.6,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			resolver := &mockReferenceResolver{
+				references: []dynamictypes.ReferenceField{
+					{FieldPath: "spec.envRef", FieldName: "envRef", TargetKind: "KubEnv", Confidence: tc.confidence},
+					{FieldPath: "spec.netRef", FieldName: "netRef", TargetKind: "KubeNet", Confidence: tc.confidence},
+				},
+				resolvedBySource: map[string][]*unstructured.Unstructured{
+					"my-app": {newTestResource("KubEnv", "env"), newTestResource("KubeNet", "net")},
+				},
+			}
+			engine := newTestTraversalEngine(resolver)
+
+			config := NewDefaultTraversalConfig()
+			config.ScopeFilter = &ScopeFilterConfig{CrossNamespaceEnabled: true}
+			config.ReferenceResolution.LowConfidenceWarningThreshold = tc.threshold
+
+			app := newTestResource("KubeApp", "my-app")
+			result, err := engine.ExecuteTransitiveDiscovery(context.Background(), config, []*unstructured.Unstructured{app})
+			require.NoError(t, err)
+			require.NotEmpty(t, result.ResourceGraph.AdjacencyList[graph.NodeID(engine.generateResourceID(app))])
+
+			var warnings []ValidationWarning
+			for _, warning := range result.ValidationResult.Warnings {
+				if warning.Type == ValidationWarningLowConfidence {
+					warnings = append(warnings, warning)
+				}
+			}
+
+			if !tc.wantWarned {
+				assert.Empty(t, warnings)
+				return
+			}
+			require.Len(t, warnings, 1)
+			assert.Equal(t, engine.generateResourceID(app), warnings[0].ResourceID)
+			assert.Contains(t, warnings[0].Message, "KubeApp")
+			assert.Contains(t, warnings[0].Message, "0.60")
+		})
+	}
+}
+
+func TestExecuteTransitiveDiscoveryResourceTransform(t *testing.T) {
+	withManagedFields := func(resource *unstructured.Unstructured) *unstructured.Unstructured {
+		resource.SetManagedFields([]metav1.ManagedFieldsEntry{{Manager: "kubectl", Operation: metav1.ManagedFieldsOperationApply}})
+		return resource
+	}
+
+	app := withManagedFields(newTestResource("KubeApp", "my-app"))
+	env := withManagedFields(newTestResource("KubEnv", "env"))
+	resolver := &mockReferenceResolver{
+		references: []dynamictypes.ReferenceField{
+			{FieldPath: "spec.envRef", FieldName: "envRef", TargetKind: "KubEnv", Confidence: 1.0},
+		},
+		resolvedBySource: map[string][]*unstructured.Unstructured{
+			"my-app": {env},
+		},
+	}
+	engine := newTestTraversalEngine(resolver)
+	engine.SetResourceTransform(StripManagedFields)
+
+	config := NewDefaultTraversalConfig()
+	config.ScopeFilter = &ScopeFilterConfig{CrossNamespaceEnabled: true}
+
+	result, err := engine.ExecuteTransitiveDiscovery(context.Background(), config, []*unstructured.Unstructured{app})
+	require.NoError(t, err)
+
+	for _, source := range []*unstructured.Unstructured{app, env} {
+		resourceID := engine.generateResourceID(source)
+		node := result.ResourceGraph.Nodes[graph.NodeID(resourceID)]
+		require.NotNil(t, node, resourceID)
+		assert.Empty(t, node.Resource.GetManagedFields(), resourceID)
+		assert.Empty(t, result.DiscoveredResources[resourceID].GetManagedFields(), resourceID)
+		assert.Len(t, source.GetManagedFields(), 1, "source %s must not be mutated", resourceID)
+	}
+}
+
+func TestExecuteTransitiveDiscoveryStorageChain(t *testing.T) {
+	newObject := func(apiVersion, kind, namespace, name string, spec map[string]interface{}) *unstructured.Unstructured {
+		object := &unstructured.Unstructured{}
+		object.SetAPIVersion(apiVersion)
+		object.SetKind(kind)
+		object.SetNamespace(namespace)
+		object.SetName(name)
+		if spec != nil {
+			object.Object["spec"] = spec
+		}
+		return object
+	}
+
+	pod := newObject("v1", "Pod", "default", "db-0", map[string]interface{}{
+		"volumes": []interface{}{
+			map[string]interface{}{
+				"name":                  "data",
+				"persistentVolumeClaim": map[string]interface{}{"claimName": "data-db-0"},
+			},
+		},
+	})
+	claim := newObject("v1", "PersistentVolumeClaim", "default", "data-db-0", map[string]interface{}{
+		"volumeName":       "pvc-1234",
+		"storageClassName": "fast-ssd",
+	})
+	volume := newObject("v1", "PersistentVolume", "", "pvc-1234", map[string]interface{}{
+		"storageClassName": "fast-ssd",
+	})
+	storageClass := newObject("storage.k8s.io/v1", "StorageClass", "", "fast-ssd", nil)
+	storageClass.Object["provisioner"] = "ebs.csi.aws.com"
+
+	resolver, _ := newTestReferenceResolver(claim, volume, storageClass)
+	engine := newTestTraversalEngine(resolver)
+
+	config := NewDefaultTraversalConfig()
+	config.ScopeFilter = &ScopeFilterConfig{CrossNamespaceEnabled: true}
+
+	result, err := engine.ExecuteTransitiveDiscovery(context.Background(), config, []*unstructured.Unstructured{pod})
+	require.NoError(t, err)
+	assert.Empty(t, result.Errors)
+
+	fieldPaths := make(map[[2]string]string)
+	for _, edge := range result.ResourceGraph.Edges {
+		source := result.ResourceGraph.Nodes[edge.Source].Resource.GetKind()
+		target := result.ResourceGraph.Nodes[edge.Target].Resource.GetKind()
+		fieldPaths[[2]string{source, target}] = edge.FieldPath
+	}
+	assert.Equal(t, map[[2]string]string{
+		{"Pod", "PersistentVolumeClaim"}:              "spec.volumes[0].persistentVolumeClaim.claimName",
+		{"PersistentVolumeClaim", "PersistentVolume"}: "spec.volumeName",
+		{"PersistentVolumeClaim", "StorageClass"}:     "spec.storageClassName",
+		{"PersistentVolume", "StorageClass"}:          "spec.storageClassName",
+	}, fieldPaths)
+
+	for _, object := range []*unstructured.Unstructured{claim, volume, storageClass} {
+		assert.Contains(t, result.DiscoveredResources, engine.generateResourceID(object))
+	}
+}
+
+func TestResolveReferenceKindMismatch(t *testing.T) {
+	// A Secret comes back where the reference expects a ConfigMap
+	secret := &unstructured.Unstructured{}
+	secret.SetAPIVersion("v1")
+	secret.SetKind("Secret")
+	secret.SetName("app-config")
+	secret.SetNamespace("default")
+
+	resolver, dynamicClient := newTestReferenceResolver()
+	dynamicClient.PrependReactor("get", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, secret.DeepCopy(), nil
+	})
+
+	source := newTestResource("KubeApp", "my-app")
+	source.Object["spec"] = map[string]interface{}{"configMapRef": "app-config"}
+
+	t.Run("ResolveReference", func(t *testing.T) {
+		reference := dynamictypes.ReferenceField{FieldPath: "spec.configMapRef", TargetKind: "ConfigMap", Confidence: 0.9}
+
+		resolved, err := resolver.ResolveReference(context.Background(), source, reference)
+		require.ErrorIs(t, err, errKindMismatch)
+		assert.Nil(t, resolved)
+
+		warnings := resolver.TakeWarnings()
+		require.Len(t, warnings, 1)
+		assert.Equal(t, TraversalWarningKindMismatch, warnings[0].Type)
+		assert.Equal(t, "platform.kubecore.io/v1/KubeApp/default/my-app", warnings[0].ResourceID)
+		assert.Equal(t, "ConfigMap", warnings[0].Context["expectedKind"])
+		assert.Equal(t, "Secret", warnings[0].Context["resolvedKind"])
+	})
+
+	t.Run("NotLinked", func(t *testing.T) {
+		engine := newTestTraversalEngine(resolver)
+		config := NewDefaultTraversalConfig()
+		config.ScopeFilter = &ScopeFilterConfig{CrossNamespaceEnabled: true}
+
+		result, err := engine.ExecuteTransitiveDiscovery(context.Background(), config, []*unstructured.Unstructured{source})
+		require.NoError(t, err)
+		assert.Empty(t, result.ResourceGraph.Edges)
+		assert.NotContains(t, result.DiscoveredResources, engine.generateResourceID(secret))
+		assert.Empty(t, result.Errors)
+
+		var mismatches []TraversalWarning
+		for _, warning := range result.Warnings {
+			if warning.Type == TraversalWarningKindMismatch {
+				mismatches = append(mismatches, warning)
+			}
+		}
+		require.Len(t, mismatches, 1)
+		assert.Equal(t, "spec.configMapRef", mismatches[0].Context["fieldPath"])
+	})
 }
@@ -0,0 +1,118 @@
+package traversal
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+
+	functionerrors "github.com/crossplane/function-kubecore-schema-registry/pkg/errors"
+	"github.com/crossplane/function-kubecore-schema-registry/pkg/graph"
+)
+
+const (
+	// jsonlTypeNode tags JSONL lines that describe a graph node
+	jsonlTypeNode = "node"
+	// jsonlTypeEdge tags JSONL lines that describe a graph edge
+	jsonlTypeEdge = "edge"
+)
+
+// jsonlNode is the JSONL line written for a graph node
+type jsonlNode struct {
+	Type            string                 `json:"type"`
+	ID              graph.NodeID           `json:"id"`
+	APIVersion      string                 `json:"apiVersion,omitempty"`
+	Kind            string                 `json:"kind,omitempty"`
+	Namespace       string                 `json:"namespace,omitempty"`
+	Name            string                 `json:"name,omitempty"`
+	UID             string                 `json:"uid,omitempty"`
+	Depth           int                    `json:"depth"`
+	Platform        bool                   `json:"platform"`
+	DiscoverySource graph.DiscoverySource  `json:"discoverySource,omitempty"`
+	Category        string                 `json:"category,omitempty"`
+	Resource        map[string]interface{} `json:"resource,omitempty"`
+}
+
+// jsonlEdge is the JSONL line written for a graph edge
+type jsonlEdge struct {
+	Type            string             `json:"type"`
+	ID              graph.EdgeID       `json:"id"`
+	Source          graph.NodeID       `json:"source"`
+	Target          graph.NodeID       `json:"target"`
+	RelationType    graph.RelationType `json:"relationType"`
+	FieldPath       string             `json:"fieldPath,omitempty"`
+	FieldName       string             `json:"fieldName,omitempty"`
+	Confidence      float64            `json:"confidence"`
+	DetectionMethod string             `json:"detectionMethod,omitempty"`
+}
+
+// StreamResultJSONL writes the result's graph to w as JSON Lines: one object
+// per node, sorted by node ID, followed by one object per edge in SortedEdges
+// order. Every line carries a "type" of "node" or "edge" and parses on its
+// own, so the output can be streamed into a data store line by line.
+func StreamResultJSONL(w io.Writer, result *TraversalResult) error {
+	if result == nil || result.ResourceGraph == nil {
+		return nil
+	}
+	resourceGraph := result.ResourceGraph
+
+	// Encode writes a trailing newline after each value
+	encoder := json.NewEncoder(w)
+
+	for _, nodeID := range sortedNodeIDs(resourceGraph) {
+		if err := encoder.Encode(newJSONLNode(resourceGraph.Nodes[nodeID])); err != nil {
+			return functionerrors.Wrap(err, "failed to write node "+string(nodeID))
+		}
+	}
+
+	for _, edge := range graph.SortedEdges(resourceGraph) {
+		line := jsonlEdge{
+			Type:            jsonlTypeEdge,
+			ID:              edge.ID,
+			Source:          edge.Source,
+			Target:          edge.Target,
+			RelationType:    edge.RelationType,
+			FieldPath:       edge.FieldPath,
+			FieldName:       edge.FieldName,
+			Confidence:      edge.Confidence,
+			DetectionMethod: edge.DetectionMethod,
+		}
+		if err := encoder.Encode(line); err != nil {
+			return functionerrors.Wrap(err, "failed to write edge "+string(edge.ID))
+		}
+	}
+
+	return nil
+}
+
+// sortedNodeIDs returns the IDs of the graph's nodes in ascending order
+func sortedNodeIDs(resourceGraph *graph.ResourceGraph) []graph.NodeID {
+	nodeIDs := make([]graph.NodeID, 0, len(resourceGraph.Nodes))
+	for nodeID := range resourceGraph.Nodes {
+		nodeIDs = append(nodeIDs, nodeID)
+	}
+	sort.Slice(nodeIDs, func(i, j int) bool { return nodeIDs[i] < nodeIDs[j] })
+	return nodeIDs
+}
+
+// newJSONLNode builds the JSONL line for a node
+func newJSONLNode(node *graph.ResourceNode) jsonlNode {
+	line := jsonlNode{
+		Type:     jsonlTypeNode,
+		ID:       node.ID,
+		UID:      string(node.UID),
+		Depth:    node.DiscoveryDepth,
+		Platform: node.Platform,
+	}
+	if node.Metadata != nil {
+		line.DiscoverySource = node.Metadata.DiscoverySource
+		line.Category = node.Metadata.Category
+	}
+	if node.Resource != nil {
+		line.APIVersion = node.Resource.GetAPIVersion()
+		line.Kind = node.Resource.GetKind()
+		line.Namespace = node.Resource.GetNamespace()
+		line.Name = node.Resource.GetName()
+		line.Resource = node.Resource.Object
+	}
+	return line
+}
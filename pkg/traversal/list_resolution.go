@@ -0,0 +1,200 @@
+package traversal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	dynamictypes "github.com/crossplane/function-kubecore-schema-registry/pkg/dynamic"
+	"github.com/crossplane/function-kubecore-schema-registry/pkg/logfields"
+)
+
+// listScope identifies the targets a single List call returns
+type listScope struct {
+	gvr             schema.GroupVersionResource
+	namespace       string
+	isClusterScoped bool
+}
+
+// listedReference is a reference whose targets are resolved against a listed scope
+type listedReference struct {
+	result *ReferenceResolutionResult
+	names  []string
+
+	// aliased is set when the scope's group is an alias of the reference's
+	aliased bool
+}
+
+// ResolveReferencesWithListCache resolves references by listing each target
+// kind and namespace once and matching names against the listed objects,
+// instead of issuing a Get per target. References that cannot be grouped,
+// such as templated names, owner references and namespace-less lookups, and
+// scopes whose List fails, fall back to ResolveReferenceTargets.
+func (rr *DefaultReferenceResolver) ResolveReferencesWithListCache(ctx context.Context, source *unstructured.Unstructured, references []dynamictypes.ReferenceField) []*ReferenceResolutionResult {
+	results := make([]*ReferenceResolutionResult, 0, len(references))
+	scopes := make(map[listScope][]listedReference)
+	var order []listScope
+	options := rr.resolutionOptions(ctx)
+
+	for _, ref := range references {
+		result := &ReferenceResolutionResult{Reference: ref}
+		results = append(results, result)
+
+		scope, names, ok := rr.listableTargets(ctx, source, ref)
+		if !ok {
+			rr.resolveIndividually(ctx, source, result)
+			continue
+		}
+		if _, exists := scopes[scope]; !exists {
+			order = append(order, scope)
+		}
+		scopes[scope] = append(scopes[scope], listedReference{
+			result:  result,
+			names:   names,
+			aliased: options.groupAliased(ref.TargetGroup),
+		})
+	}
+
+	for _, scope := range order {
+		listed := scopes[scope]
+		startTime := time.Now()
+
+		index, err := rr.listIndexedTargets(ctx, scope)
+		if err != nil {
+			rr.logger.Debug("Listing reference targets failed, resolving individually",
+				"gvr", scope.gvr.String(),
+				"namespace", scope.namespace,
+				"error", err)
+			for _, item := range listed {
+				rr.resolveIndividually(ctx, source, item.result)
+			}
+			continue
+		}
+
+		for _, item := range listed {
+			ref := item.result.Reference
+			var missing []string
+			for _, name := range item.names {
+				if target, found := index[name]; found {
+					item.result.ResolvedResources = append(item.result.ResolvedResources, target)
+				} else {
+					missing = append(missing, name)
+				}
+			}
+
+			// Targets missing under an aliased group may still exist under the original one
+			if len(missing) > 0 && item.aliased {
+				item.result.ResolvedResources = nil
+				rr.resolveIndividually(ctx, source, item.result)
+				continue
+			}
+
+			if len(missing) > 0 {
+				item.result.Error = fmt.Errorf("failed to resolve %d of %d references in %s to %s: %s not found",
+					len(missing), len(item.names), ref.FieldPath, ref.TargetKind, strings.Join(missing, ", "))
+			}
+			item.result.ResolutionTime = time.Since(startTime)
+		}
+	}
+
+	return results
+}
+
+// listableTargets returns the list scope and target names of a reference, or
+// false if the reference must be resolved individually
+func (rr *DefaultReferenceResolver) listableTargets(ctx context.Context, source *unstructured.Unstructured, reference dynamictypes.ReferenceField) (listScope, []string, bool) {
+	if reference.NameTemplate != "" || reference.RefType == dynamictypes.RefTypeOwnerRef {
+		return listScope{}, nil, false
+	}
+	// Lists are only shared within the cluster being traversed
+	if rr.targetCluster(source, reference) != "" {
+		return listScope{}, nil, false
+	}
+	if err := rr.ValidateReference(reference); err != nil {
+		return listScope{}, nil, false
+	}
+
+	options := rr.resolutionOptions(ctx)
+	refValue, err := rr.extractReferenceValue(ctx, source, reference.FieldPath, options)
+	if err != nil {
+		return listScope{}, nil, false
+	}
+	items, isList := refValue.([]interface{})
+	if !isList {
+		items = []interface{}{refValue}
+	}
+
+	gvr, isClusterScoped, err := rr.targetGVR(ctx, reference, options)
+	if err != nil {
+		return listScope{}, nil, false
+	}
+	scope := listScope{
+		gvr:             gvr,
+		isClusterScoped: isClusterScoped,
+	}
+
+	names := make([]string, 0, len(items))
+	for i, item := range items {
+		name, namespace, err := rr.parseReferenceValue(item, reference, source, options)
+		if err != nil {
+			return listScope{}, nil, false
+		}
+		if scope.isClusterScoped {
+			namespace = ""
+		} else if namespace == "" {
+			// Namespace-less lookups try several scopes, so a single List cannot answer them
+			return listScope{}, nil, false
+		}
+		if i > 0 && namespace != scope.namespace {
+			return listScope{}, nil, false
+		}
+		scope.namespace = namespace
+		names = append(names, name)
+	}
+
+	if len(names) == 0 {
+		return listScope{}, nil, false
+	}
+
+	return scope, names, true
+}
+
+// listIndexedTargets lists the objects in a scope and indexes them by name
+func (rr *DefaultReferenceResolver) listIndexedTargets(ctx context.Context, scope listScope) (map[string]*unstructured.Unstructured, error) {
+	rr.apiCalls.Add(1)
+
+	var list *unstructured.UnstructuredList
+	var err error
+	if scope.isClusterScoped {
+		list, err = rr.dynamicClient.Resource(scope.gvr).List(ctx, metav1.ListOptions{})
+	} else {
+		list, err = rr.dynamicClient.Resource(scope.gvr).Namespace(scope.namespace).List(ctx, metav1.ListOptions{})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[string]*unstructured.Unstructured, len(list.Items))
+	for i := range list.Items {
+		index[list.Items[i].GetName()] = &list.Items[i]
+	}
+	return index, nil
+}
+
+// resolveIndividually resolves a reference with per-target lookups
+func (rr *DefaultReferenceResolver) resolveIndividually(ctx context.Context, source *unstructured.Unstructured, result *ReferenceResolutionResult) {
+	startTime := time.Now()
+	result.ResolvedResources, result.Error = rr.ResolveReferenceTargets(ctx, source, result.Reference)
+	result.ResolutionTime = time.Since(startTime)
+
+	if result.Error != nil {
+		rr.logger.Debug("Individual reference resolution failed",
+			logfields.FieldPath, result.Reference.FieldPath,
+			"error", result.Error)
+	}
+}
@@ -0,0 +1,84 @@
+package traversal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	dynamictypes "github.com/crossplane/function-kubecore-schema-registry/pkg/dynamic"
+)
+
+func TestResolveReferencesWithListCache(t *testing.T) {
+	names := []string{"a", "b", "c", "d", "e"}
+
+	var objects []runtime.Object
+	for _, name := range names {
+		configMap := &unstructured.Unstructured{}
+		configMap.SetAPIVersion("v1")
+		configMap.SetKind("ConfigMap")
+		configMap.SetName(name)
+		configMap.SetNamespace("default")
+		objects = append(objects, configMap)
+	}
+
+	resolver, dynamicClient := newTestReferenceResolver(objects...)
+
+	source := newTestResource("KubeApp", "my-app")
+	spec := make(map[string]interface{})
+	references := make([]dynamictypes.ReferenceField, 0, len(names))
+	for _, name := range names {
+		field := name + "ConfigRef"
+		spec[field] = name
+		references = append(references, dynamictypes.ReferenceField{
+			FieldPath:     "spec." + field,
+			FieldName:     field,
+			TargetKind:    "ConfigMap",
+			TargetVersion: "v1",
+			RefType:       dynamictypes.RefTypeConfigMap,
+			Confidence:    0.9,
+		})
+	}
+	spec["missingConfigRef"] = "missing"
+	references = append(references, dynamictypes.ReferenceField{
+		FieldPath:     "spec.missingConfigRef",
+		FieldName:     "missingConfigRef",
+		TargetKind:    "ConfigMap",
+		TargetVersion: "v1",
+		RefType:       dynamictypes.RefTypeConfigMap,
+		Confidence:    0.9,
+	})
+	source.Object["spec"] = spec
+
+	results := resolver.ResolveReferencesWithListCache(context.Background(), source, references)
+	require.Len(t, results, len(references))
+
+	resolved := make([]string, 0, len(names))
+	for _, result := range results {
+		if result.Reference.FieldPath == "spec.missingConfigRef" {
+			assert.Error(t, result.Error)
+			assert.Empty(t, result.ResolvedResources)
+			continue
+		}
+		require.NoError(t, result.Error)
+		require.Len(t, result.ResolvedResources, 1)
+		resolved = append(resolved, result.ResolvedResources[0].GetName())
+	}
+	assert.Equal(t, names, resolved)
+
+	lists, gets := 0, 0
+	for _, action := range dynamicClient.Actions() {
+		switch action.GetVerb() {
+		case "list":
+			lists++
+		case "get":
+			gets++
+		}
+	}
+	assert.Equal(t, 1, lists)
+	assert.Equal(t, 0, gets)
+	assert.Equal(t, int64(1), resolver.APICalls())
+}
@@ -0,0 +1,167 @@
+package traversal
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/crossplane/function-kubecore-schema-registry/pkg/graph"
+)
+
+// MergeTraversalResults combines results of traversals run separately, e.g.
+// one per root group, into a single result. Graphs are merged with
+// MergeGraphs and discovered resources are unioned by resource key, so a
+// resource reached from several roots appears once. Traversal steps and
+// errors are concatenated in result order, with identical errors counted
+// once per occurrence.
+//
+// Statistics that count work, such as API calls, cache hits and references
+// followed, are summed. Statistics that count resources and edges are
+// recomputed from the merged graph so shared resources are not counted twice.
+func MergeTraversalResults(results ...*TraversalResult) *TraversalResult {
+	merged := &TraversalResult{
+		DiscoveredResources: make(map[string]*unstructured.Unstructured),
+		TraversalPath: &TraversalPath{
+			Steps: make([]TraversalStep, 0),
+		},
+		Errors: make([]TraversalError, 0),
+		Statistics: &TraversalStatistics{
+			ResourcesByDepth:    make(map[int]int),
+			ResourcesByKind:     make(map[string]int),
+			ResourcesByAPIGroup: make(map[string]int),
+			EdgesByRelationType: make(map[graph.RelationType]int),
+			MemoryUsage:         &MemoryUsageStats{},
+			PerformanceMetrics:  &PerformanceMetrics{},
+		},
+		Metadata: &TraversalMetadata{
+			TerminationReason: TerminationReasonCompleted,
+		},
+	}
+
+	builder := graph.NewDefaultGraphBuilder(NewDefaultPlatformChecker(defaultPlatformAPIGroups))
+	graphs := make([]*graph.ResourceGraph, 0, len(results))
+	startResources := make(map[string]bool)
+	edgesTruncated := false
+
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+
+		if result.ResourceGraph != nil {
+			graphs = append(graphs, result.ResourceGraph)
+			edgesTruncated = edgesTruncated || result.ResourceGraph.Metadata.EdgesTruncated
+		}
+
+		for key, resource := range result.DiscoveredResources {
+			if _, exists := merged.DiscoveredResources[key]; !exists {
+				merged.DiscoveredResources[key] = resource
+			}
+		}
+
+		if result.TraversalPath != nil {
+			mergeTraversalPath(merged.TraversalPath, result.TraversalPath)
+		}
+
+		for _, err := range result.Errors {
+			merged.Errors = appendTraversalError(merged.Errors, err)
+		}
+		merged.Warnings = append(merged.Warnings, result.Warnings...)
+
+		if result.Statistics != nil {
+			sumTraversalStatistics(merged.Statistics, result.Statistics)
+		}
+
+		if result.Metadata != nil {
+			mergeTraversalMetadata(merged.Metadata, result.Metadata, startResources)
+		}
+	}
+
+	// MergeGraphs does not fail for graphs built by the engine
+	mergedGraph, err := builder.MergeGraphs(graphs)
+	if err != nil || mergedGraph == nil {
+		mergedGraph = builder.NewGraph()
+	}
+	mergedGraph.Metadata.EdgesTruncated = edgesTruncated
+	merged.ResourceGraph = mergedGraph
+
+	merged.TraversalPath.TotalSteps = len(merged.TraversalPath.Steps)
+	if !merged.TraversalPath.StartTime.IsZero() {
+		merged.TraversalPath.Duration = merged.TraversalPath.EndTime.Sub(merged.TraversalPath.StartTime)
+	}
+
+	countMergedGraph(merged.Statistics, mergedGraph, len(merged.DiscoveredResources))
+
+	return merged
+}
+
+// mergeTraversalPath appends the steps of path to merged, renumbering them,
+// and widens merged's time window and depth to cover path
+func mergeTraversalPath(merged, path *TraversalPath) {
+	for _, step := range path.Steps {
+		step.StepID = len(merged.Steps)
+		merged.Steps = append(merged.Steps, step)
+	}
+
+	if path.MaxDepthReached > merged.MaxDepthReached {
+		merged.MaxDepthReached = path.MaxDepthReached
+	}
+	if !path.StartTime.IsZero() && (merged.StartTime.IsZero() || path.StartTime.Before(merged.StartTime)) {
+		merged.StartTime = path.StartTime
+	}
+	if path.EndTime.After(merged.EndTime) {
+		merged.EndTime = path.EndTime
+	}
+}
+
+// sumTraversalStatistics adds the work counters of stats to merged
+func sumTraversalStatistics(merged, stats *TraversalStatistics) {
+	merged.TotalReferences += stats.TotalReferences
+	merged.ReferencesFollowed += stats.ReferencesFollowed
+	merged.ReferencesSkipped += stats.ReferencesSkipped
+	merged.APICallCount += stats.APICallCount
+	merged.CacheHits += stats.CacheHits
+	merged.CacheMisses += stats.CacheMisses
+}
+
+// mergeTraversalMetadata folds metadata into merged. The merged traversal
+// completed only if every traversal did; otherwise the first other reason wins.
+func mergeTraversalMetadata(merged, metadata *TraversalMetadata, startResources map[string]bool) {
+	if merged.Config == nil {
+		merged.Config = metadata.Config
+		merged.Version = metadata.Version
+		merged.Environment = metadata.Environment
+	}
+
+	for _, resourceID := range metadata.StartResources {
+		if !startResources[resourceID] {
+			startResources[resourceID] = true
+			merged.StartResources = append(merged.StartResources, resourceID)
+		}
+	}
+
+	if metadata.CompletedAt.After(merged.CompletedAt) {
+		merged.CompletedAt = metadata.CompletedAt
+	}
+	if merged.TerminationReason == TerminationReasonCompleted && metadata.TerminationReason != "" {
+		merged.TerminationReason = metadata.TerminationReason
+	}
+	merged.CycleDetectionSkipped = merged.CycleDetectionSkipped || metadata.CycleDetectionSkipped
+}
+
+// countMergedGraph recomputes the resource and edge statistics from the
+// merged graph, where each resource has a single node
+func countMergedGraph(stats *TraversalStatistics, mergedGraph *graph.ResourceGraph, totalResources int) {
+	stats.TotalResources = totalResources
+
+	for _, node := range mergedGraph.Nodes {
+		if node.Resource == nil {
+			continue
+		}
+		stats.ResourcesByDepth[node.DiscoveryDepth]++
+		stats.ResourcesByKind[node.Resource.GetKind()]++
+		stats.ResourcesByAPIGroup[apiGroupOf(node.Resource.GetAPIVersion())]++
+	}
+
+	for _, edge := range mergedGraph.Edges {
+		stats.EdgesByRelationType[edge.RelationType]++
+	}
+}
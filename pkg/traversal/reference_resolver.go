@@ -2,12 +2,19 @@ package traversal
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"golang.org/x/sync/singleflight"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 
@@ -15,6 +22,7 @@ import (
 
 	dynamictypes "github.com/crossplane/function-kubecore-schema-registry/pkg/dynamic"
 	functionerrors "github.com/crossplane/function-kubecore-schema-registry/pkg/errors"
+	"github.com/crossplane/function-kubecore-schema-registry/pkg/logfields"
 	"github.com/crossplane/function-kubecore-schema-registry/pkg/registry"
 )
 
@@ -26,6 +34,9 @@ type ReferenceResolver interface {
 	// ResolveReferences resolves reference fields to actual resources
 	ResolveReferences(ctx context.Context, source *unstructured.Unstructured, references []dynamictypes.ReferenceField) ([]*unstructured.Unstructured, []error)
 
+	// ResolveReferenceResults resolves reference fields and reports the targets of each field
+	ResolveReferenceResults(ctx context.Context, source *unstructured.Unstructured, references []dynamictypes.ReferenceField) []*ReferenceResolutionResult
+
 	// ResolveReference resolves a single reference field
 	ResolveReference(ctx context.Context, source *unstructured.Unstructured, reference dynamictypes.ReferenceField) (*unstructured.Unstructured, error)
 
@@ -38,26 +49,78 @@ type DefaultReferenceResolver struct {
 	// dynamicClient provides access to Kubernetes dynamic API
 	dynamicClient dynamic.Interface
 
+	// clientFactory provides clients for references carrying a cluster hint;
+	// clusterClients remembers the client created for each cluster, and
+	// factoryGeneration counts factory changes so clients built by a replaced
+	// factory are not remembered
+	clientsMu         sync.Mutex
+	clientFactory     ClientFactory
+	clusterClients    map[string]dynamic.Interface
+	factoryGeneration uint64
+
 	// registry provides resource type information
 	registry registry.Registry
 
-	// referenceDetector detects reference fields in resources
+	// referenceDetector detects reference fields with the built-in patterns,
+	// for discoveries that configure no patterns of their own
 	referenceDetector dynamictypes.ReferenceDetector
 
 	// logger provides structured logging
 	logger logging.Logger
 
+	// baseLogger is the logger before it was tagged with the resolver's
+	// component, for the reference detectors the resolver builds
+	baseLogger logging.Logger
+
 	// cache stores resolved references
 	cache Cache
+
+	// options are used by calls whose context carries no resolution options
+	optionsMu sync.RWMutex
+	options   resolutionOptions
+
+	// apiCalls counts lookups made against the Kubernetes API
+	apiCalls atomic.Int64
+
+	// warnings collects conditions noticed during calls whose context carries
+	// no warning collector, until taken
+	warningsMu sync.Mutex
+	warnings   []TraversalWarning
+
+	// crdDiscoverer learns target kinds missing from the registry when
+	// dynamic CRDs are enabled; discoveredCRDs remembers its answers per kind
+	// and crdLookups coalesces concurrent discoveries of the same kind.
+	// crdGeneration changes whenever remembered answers are forgotten.
+	crdMu          sync.Mutex
+	crdDiscoverer  dynamictypes.CRDDiscoverer
+	discoveredCRDs map[schema.GroupKind]discoveredCRD
+	crdGeneration  uint64
+	crdLookups     singleflight.Group
+
+	// restMapper, when set, maps target kinds to their resource and scope
+	restMapper meta.RESTMapper
 }
 
+// claimNamespaceKey is the label, or annotation, Crossplane sets on a composite
+// resource to record the namespace of the claim it was created for
+const claimNamespaceKey = "crossplane.io/claim-namespace"
+
+// errCrossNamespaceReference reports a reference into another namespace while
+// cross-namespace references are disabled
+var errCrossNamespaceReference = errors.New("cross-namespace reference not allowed")
+
+// errKindMismatch reports a reference target that resolved to an object of
+// another kind than the reference expects. It is recorded as a kind_mismatch
+// warning rather than a resolution error.
+var errKindMismatch = errors.New("referenced object has an unexpected kind")
+
 // ReferenceResolutionResult contains the result of reference resolution
 type ReferenceResolutionResult struct {
 	// Reference is the reference field that was resolved
 	Reference dynamictypes.ReferenceField
 
-	// ResolvedResource is the resolved resource (nil if not found)
-	ResolvedResource *unstructured.Unstructured
+	// ResolvedResources are the resolved resources; list-valued fields resolve to several
+	ResolvedResources []*unstructured.Unstructured
 
 	// Error contains any error that occurred during resolution
 	Error error
@@ -71,13 +134,191 @@ type ReferenceResolutionResult struct {
 
 // NewDefaultReferenceResolver creates a new default reference resolver
 func NewDefaultReferenceResolver(dynamicClient dynamic.Interface, registry registry.Registry, logger logging.Logger) *DefaultReferenceResolver {
-	return &DefaultReferenceResolver{
+	resolver := &DefaultReferenceResolver{
 		dynamicClient:     dynamicClient,
+		clientFactory:     SingleClusterClientFactory(dynamicClient),
 		registry:          registry,
 		referenceDetector: dynamictypes.NewReferenceDetector(logger),
-		logger:            logger,
+		logger:            logfields.ForComponent(logger, logfields.ComponentReferenceResolver),
+		baseLogger:        logger,
 		cache:             NewLRUCache(1000, 5*time.Minute),
 	}
+	resolver.options.referenceDetector = resolver.referenceDetector
+	resolver.options.updateCacheScope()
+	return resolver
+}
+
+// The Set methods below configure calls whose context carries no resolution
+// options. The traversal engine passes the options of each discovery through
+// its context instead, so they do not affect it. Resolutions are cached per
+// set of options, so changing them never serves a resolution made under
+// other options.
+
+// SetNamespaceRewrite sets the namespace rewrites applied to reference targets
+func (rr *DefaultReferenceResolver) SetNamespaceRewrite(rewrite map[string]string) {
+	rr.updateOptions(func(options *resolutionOptions) {
+		options.namespaceRewrite = rewrite
+	})
+}
+
+// SetGroupAliases sets the API group aliases consulted when building target GVRs
+func (rr *DefaultReferenceResolver) SetGroupAliases(aliases map[string]string) {
+	rr.updateOptions(func(options *resolutionOptions) {
+		options.groupAliases = aliases
+	})
+}
+
+// SetAnnotationReferences sets the patterns used to detect references encoded
+// in annotations. An empty list disables annotation scanning.
+func (rr *DefaultReferenceResolver) SetAnnotationReferences(patterns []AnnotationReferencePattern) {
+	rr.updateOptions(func(options *resolutionOptions) {
+		options.annotationPatterns = patterns
+	})
+}
+
+// SetReferencePatterns sets the patterns used to detect reference fields. They
+// are added to the built-in patterns unless replaceDefaults is set. Patterns
+// from a previous call are discarded.
+func (rr *DefaultReferenceResolver) SetReferencePatterns(patterns []ReferencePattern, replaceDefaults bool) {
+	detector := rr.newReferenceDetector(patterns, replaceDefaults)
+	rr.updateOptions(func(options *resolutionOptions) {
+		options.referenceDetector = detector
+	})
+}
+
+// newReferenceDetector returns a detector for the given patterns, added to
+// the built-in ones unless replaceDefaults is set. Without patterns of its own
+// the resolver's detector for the built-in patterns is shared.
+func (rr *DefaultReferenceResolver) newReferenceDetector(patterns []ReferencePattern, replaceDefaults bool) dynamictypes.ReferenceDetector {
+	if len(patterns) == 0 && !replaceDefaults {
+		return rr.referenceDetector
+	}
+
+	converted := make([]dynamictypes.ReferencePattern, 0, len(patterns))
+	for _, pattern := range patterns {
+		converted = append(converted, dynamictypes.ReferencePattern{
+			Pattern:      pattern.Pattern,
+			TargetKind:   pattern.TargetKind,
+			TargetGroup:  pattern.TargetGroup,
+			RefType:      dynamictypes.RefType(pattern.RefType),
+			Confidence:   pattern.Confidence,
+			NameTemplate: pattern.NameTemplate,
+		})
+	}
+
+	detector := dynamictypes.NewReferenceDetector(rr.baseLogger)
+	if replaceDefaults {
+		detector.LoadCustomPatterns(converted)
+	} else {
+		for _, pattern := range converted {
+			detector.AddPattern(pattern)
+		}
+	}
+	return detector
+}
+
+// SetOwnerReferenceScope makes owner reference extraction skip owners that the
+// scope filter would not follow, e.g. core controllers under PlatformOnly, so
+// they are never resolved. A nil filter or config extracts every owner.
+func (rr *DefaultReferenceResolver) SetOwnerReferenceScope(filter ScopeFilter, config *ScopeFilterConfig) {
+	rr.updateOptions(func(options *resolutionOptions) {
+		options.ownerScopeFilter = filter
+		options.ownerScope = config
+	})
+}
+
+// SetSameNamespaceOnly makes resolution reject references whose target
+// namespace differs from the source resource's namespace
+func (rr *DefaultReferenceResolver) SetSameNamespaceOnly(enabled bool) {
+	rr.updateOptions(func(options *resolutionOptions) {
+		options.sameNamespaceOnly = enabled
+	})
+}
+
+// SetClusterSourceNamespace sets the namespace that references from sources
+// without a namespace, such as cluster XRs, default to when they name none.
+// With useClaimNamespace the claim's namespace recorded on the source is
+// preferred when present.
+func (rr *DefaultReferenceResolver) SetClusterSourceNamespace(namespace string, useClaimNamespace bool) {
+	rr.updateOptions(func(options *resolutionOptions) {
+		options.clusterSourceNamespace = namespace
+		options.useClaimNamespace = useClaimNamespace
+	})
+}
+
+// APICalls returns the number of Kubernetes API calls made so far
+func (rr *DefaultReferenceResolver) APICalls() int64 {
+	return rr.apiCalls.Load()
+}
+
+// TakeWarnings returns the warnings recorded since the last call and forgets
+// them. Warnings noticed during a discovery are reported on its result instead.
+func (rr *DefaultReferenceResolver) TakeWarnings() []TraversalWarning {
+	rr.warningsMu.Lock()
+	defer rr.warningsMu.Unlock()
+
+	warnings := rr.warnings
+	rr.warnings = nil
+	return warnings
+}
+
+// recordWarning adds a warning to the collector carried by ctx, or keeps it
+// until it is taken by TakeWarnings
+func (rr *DefaultReferenceResolver) recordWarning(ctx context.Context, warning TraversalWarning) {
+	if collector := warningCollectorFrom(ctx); collector != nil {
+		collector.add(warning)
+		return
+	}
+
+	rr.warningsMu.Lock()
+	defer rr.warningsMu.Unlock()
+
+	rr.warnings = append(rr.warnings, warning)
+}
+
+// warningCollector gathers the warnings noticed during one discovery, so
+// concurrent discoveries sharing a resolver each report only their own
+type warningCollector struct {
+	mu       sync.Mutex
+	warnings []TraversalWarning
+}
+
+type warningCollectorKey struct{}
+
+// withWarningCollector returns a context carrying a fresh warning collector
+func withWarningCollector(ctx context.Context) (context.Context, *warningCollector) {
+	collector := &warningCollector{}
+	return context.WithValue(ctx, warningCollectorKey{}, collector), collector
+}
+
+// warningCollectorFrom returns the warning collector carried by ctx, if any
+func warningCollectorFrom(ctx context.Context) *warningCollector {
+	collector, _ := ctx.Value(warningCollectorKey{}).(*warningCollector)
+	return collector
+}
+
+// add records a warning
+func (c *warningCollector) add(warning TraversalWarning) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.warnings = append(c.warnings, warning)
+}
+
+// take returns the warnings recorded so far and forgets them
+func (c *warningCollector) take() []TraversalWarning {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	warnings := c.warnings
+	c.warnings = nil
+	return warnings
+}
+
+// Close drops cached resolutions and stops the cache's cleanup goroutine
+func (rr *DefaultReferenceResolver) Close() {
+	rr.cache.Clear()
+	closeCache(rr.cache)
 }
 
 // ExtractReferences extracts reference fields from a resource
@@ -85,11 +326,11 @@ func (rr *DefaultReferenceResolver) ExtractReferences(ctx context.Context, resou
 	// Get resource type information
 	resourceType, err := rr.registry.GetResourceType(resource.GetAPIVersion(), resource.GetKind())
 	if err != nil {
-		rr.logger.Debug("Resource type not found in registry, using heuristic detection",
-			"apiVersion", resource.GetAPIVersion(),
-			"kind", resource.GetKind())
+		rr.logger.Debug("Resource type not found in registry, using heuristic detection", logfields.Resource(resource)...)
 	}
 
+	options := rr.resolutionOptions(ctx)
+
 	// Extract references using multiple methods
 	var allReferences []dynamictypes.ReferenceField
 
@@ -102,27 +343,35 @@ func (rr *DefaultReferenceResolver) ExtractReferences(ctx context.Context, resou
 	}
 
 	// Method 2: Pattern-based detection
-	patternRefs, err := rr.extractReferencesFromPatterns(resource)
+	patternRefs, err := rr.extractReferencesFromPatterns(resource, options.referenceDetector)
 	if err == nil {
 		allReferences = append(allReferences, patternRefs...)
 	}
 
 	// Method 3: Owner reference extraction
-	ownerRefs, err := rr.extractOwnerReferences(resource)
+	ownerRefs, err := rr.extractOwnerReferences(ctx, resource, options)
 	if err == nil {
 		allReferences = append(allReferences, ownerRefs...)
 	}
 
+	// Method 4: Annotation-encoded references (only when patterns are configured)
+	annotationRefs := rr.extractAnnotationReferences(resource, options.annotationPatterns)
+	allReferences = append(allReferences, annotationRefs...)
+
+	// Method 5: CEL expressions configured for the resource's kind
+	celRefs := rr.extractCELReferences(ctx, resource, options.celExtractors)
+	allReferences = append(allReferences, celRefs...)
+
 	// Deduplicate references
 	deduplicatedRefs := rr.deduplicateReferences(allReferences)
 
-	rr.logger.Debug("Extracted references from resource",
-		"resource", fmt.Sprintf("%s/%s", resource.GetNamespace(), resource.GetName()),
-		"kind", resource.GetKind(),
+	rr.logger.Debug("Extracted references from resource", logfields.Resource(resource,
 		"totalReferences", len(deduplicatedRefs),
-		"registryRefs", len(allReferences)-len(patternRefs)-len(ownerRefs),
+		"registryRefs", len(allReferences)-len(patternRefs)-len(ownerRefs)-len(annotationRefs)-len(celRefs),
 		"patternRefs", len(patternRefs),
-		"ownerRefs", len(ownerRefs))
+		"ownerRefs", len(ownerRefs),
+		"annotationRefs", len(annotationRefs),
+		"celRefs", len(celRefs))...)
 
 	return deduplicatedRefs, nil
 }
@@ -132,6 +381,18 @@ func (rr *DefaultReferenceResolver) ResolveReferences(ctx context.Context, sourc
 	var resolvedResources []*unstructured.Unstructured
 	var errors []error
 
+	for _, result := range rr.ResolveReferenceResults(ctx, source, references) {
+		if result.Error != nil {
+			errors = append(errors, result.Error)
+		}
+		resolvedResources = append(resolvedResources, result.ResolvedResources...)
+	}
+
+	return resolvedResources, errors
+}
+
+// ResolveReferenceResults resolves reference fields and reports the targets of each field
+func (rr *DefaultReferenceResolver) ResolveReferenceResults(ctx context.Context, source *unstructured.Unstructured, references []dynamictypes.ReferenceField) []*ReferenceResolutionResult {
 	// Process references concurrently for better performance
 	results := make(chan *ReferenceResolutionResult, len(references))
 
@@ -140,40 +401,116 @@ func (rr *DefaultReferenceResolver) ResolveReferences(ctx context.Context, sourc
 		go func(ref dynamictypes.ReferenceField) {
 			startTime := time.Now()
 
-			resolved, err := rr.ResolveReference(ctx, source, ref)
+			resolved, err := rr.ResolveReferenceTargets(ctx, source, ref)
 
 			results <- &ReferenceResolutionResult{
-				Reference:        ref,
-				ResolvedResource: resolved,
-				Error:            err,
-				ResolutionTime:   time.Since(startTime),
+				Reference:         ref,
+				ResolvedResources: resolved,
+				Error:             err,
+				ResolutionTime:    time.Since(startTime),
 			}
 		}(ref)
 	}
 
 	// Collect results
+	collected := make([]*ReferenceResolutionResult, 0, len(references))
 	for i := 0; i < len(references); i++ {
-		result := <-results
+		collected = append(collected, <-results)
+	}
 
-		if result.Error != nil {
-			errors = append(errors, result.Error)
-		} else if result.ResolvedResource != nil {
-			resolvedResources = append(resolvedResources, result.ResolvedResource)
+	return collected
+}
+
+// ResolveReferenceTargets resolves a reference field to every resource it names.
+// List-valued fields such as spec.configMapRefs: ["a", "b"] resolve each element,
+// which may be a name string or a name/namespace object. Elements that fail are
+// reported in the returned error alongside the targets that did resolve.
+func (rr *DefaultReferenceResolver) ResolveReferenceTargets(ctx context.Context, source *unstructured.Unstructured, reference dynamictypes.ReferenceField) ([]*unstructured.Unstructured, error) {
+	options := rr.resolutionOptions(ctx)
+	refValue, err := rr.extractReferenceValue(ctx, source, reference.FieldPath, options)
+	items, isList := refValue.([]interface{})
+	if err != nil || !isList || reference.NameTemplate != "" {
+		resolved, err := rr.ResolveReference(ctx, source, reference)
+		if err != nil {
+			return nil, err
 		}
+		return []*unstructured.Unstructured{resolved}, nil
 	}
 
-	return resolvedResources, errors
+	if err := rr.ValidateReference(reference); err != nil {
+		return nil, functionerrors.Wrap(err, "reference validation failed")
+	}
+
+	resolved := make([]*unstructured.Unstructured, 0, len(items))
+	var failures []string
+	var forbidden error
+	for i, item := range items {
+		target, err := rr.resolveReferenceItem(ctx, source, reference, item, options)
+		if err != nil && apierrors.IsForbidden(err) {
+			// Already recorded as an access_denied warning
+			forbidden = err
+			continue
+		}
+		if errors.Is(err, errKindMismatch) {
+			// Already recorded as a kind_mismatch warning
+			continue
+		}
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s[%d]: %v", reference.FieldPath, i, err))
+			continue
+		}
+		resolved = append(resolved, target)
+	}
+
+	if len(failures) == 0 && forbidden != nil {
+		return resolved, forbidden
+	}
+
+	if len(failures) > 0 {
+		return resolved, fmt.Errorf("failed to resolve %d of %d list references: %s",
+			len(failures), len(items), strings.Join(failures, "; "))
+	}
+
+	return resolved, nil
+}
+
+// resolveReferenceItem resolves one element of a list-valued reference field
+func (rr *DefaultReferenceResolver) resolveReferenceItem(ctx context.Context, source *unstructured.Unstructured, reference dynamictypes.ReferenceField, item interface{}, options *resolutionOptions) (*unstructured.Unstructured, error) {
+	targetName, targetNamespace, err := rr.parseReferenceValue(item, reference, source, options)
+	if err != nil {
+		return nil, functionerrors.Wrap(err, "failed to parse reference value")
+	}
+
+	// Elements of the same field are cached by the target they name
+	cacheKey := fmt.Sprintf("%s[%s/%s]", rr.generateCacheKey(source, reference, options), targetNamespace, targetName)
+	if cached, found := rr.cache.Get(cacheKey); found {
+		if cachedResource, ok := cached.(*unstructured.Unstructured); ok {
+			rr.logger.Debug("Reference resolved from cache", logfields.FieldPath, reference.FieldPath, "targetName", targetName)
+			return cachedResource, nil
+		}
+	}
+
+	resolvedResource, err := rr.lookupTarget(ctx, source, reference, targetName, targetNamespace, options)
+	if err != nil {
+		return nil, err
+	}
+
+	rr.cache.Set(cacheKey, resolvedResource, 5*time.Minute)
+
+	return resolvedResource, nil
 }
 
 // ResolveReference resolves a single reference field
 func (rr *DefaultReferenceResolver) ResolveReference(ctx context.Context, source *unstructured.Unstructured, reference dynamictypes.ReferenceField) (*unstructured.Unstructured, error) {
+	options := rr.resolutionOptions(ctx)
+
 	// Generate cache key
-	cacheKey := rr.generateCacheKey(source, reference)
+	cacheKey := rr.generateCacheKey(source, reference, options)
 
 	// Check cache first
 	if cached, found := rr.cache.Get(cacheKey); found {
 		if cachedResource, ok := cached.(*unstructured.Unstructured); ok {
-			rr.logger.Debug("Reference resolved from cache", "reference", reference.FieldPath)
+			rr.logger.Debug("Reference resolved from cache", logfields.FieldPath, reference.FieldPath)
 			return cachedResource, nil
 		}
 	}
@@ -184,25 +521,65 @@ func (rr *DefaultReferenceResolver) ResolveReference(ctx context.Context, source
 	}
 
 	// Extract reference value from source resource
-	refValue, err := rr.extractReferenceValue(source, reference.FieldPath)
-	if err != nil {
+	refValue, err := rr.extractReferenceValue(ctx, source, reference.FieldPath, options)
+	if err != nil && reference.NameTemplate == "" {
 		return nil, functionerrors.Wrap(err, "failed to extract reference value")
 	}
 
-	// Parse reference value to get target resource details
-	targetName, targetNamespace, err := rr.parseReferenceValue(refValue, reference, source.GetNamespace())
+	// Parse reference value to get target resource details, deriving the name
+	// from the template when the field value is not the target name
+	var targetName, targetNamespace string
+	if reference.NameTemplate != "" {
+		targetName, targetNamespace, err = rr.resolveTemplatedName(source, reference.NameTemplate, refValue, options)
+		if err != nil {
+			return nil, functionerrors.Wrap(err, "failed to evaluate name template")
+		}
+	} else {
+		targetName, targetNamespace, err = rr.parseReferenceValue(refValue, reference, source, options)
+		if err != nil {
+			return nil, functionerrors.Wrap(err, "failed to parse reference value")
+		}
+	}
+
+	resolvedResource, err := rr.lookupTarget(ctx, source, reference, targetName, targetNamespace, options)
 	if err != nil {
-		return nil, functionerrors.Wrap(err, "failed to parse reference value")
+		return nil, err
 	}
 
-	// Build GroupVersionResource for the target
-	gvr, err := rr.buildGVR(reference.TargetGroup, reference.TargetVersion, reference.TargetKind)
+	// Owner references name a specific object, so a same-named replacement is not the owner
+	if reference.RefType == dynamictypes.RefTypeOwnerRef {
+		if err := rr.verifyOwnerUID(source, resolvedResource, reference); err != nil {
+			return nil, err
+		}
+	}
+
+	// Cache the result
+	rr.cache.Set(cacheKey, resolvedResource, 5*time.Minute)
+
+	rr.logger.Debug("Reference resolved successfully",
+		logfields.FieldPath, reference.FieldPath,
+		"targetKind", reference.TargetKind,
+		"targetName", targetName,
+		"targetNamespace", targetNamespace)
+
+	return resolvedResource, nil
+}
+
+// lookupTarget fetches the named target of a reference, falling back to the
+// original API group when an aliased group has no such resource
+func (rr *DefaultReferenceResolver) lookupTarget(ctx context.Context, source *unstructured.Unstructured, reference dynamictypes.ReferenceField, targetName, targetNamespace string, options *resolutionOptions) (*unstructured.Unstructured, error) {
+	// Build GroupVersionResource and scope for the target
+	gvr, isClusterScoped, err := rr.targetGVR(ctx, reference, options)
 	if err != nil {
 		return nil, functionerrors.Wrap(err, "failed to build GroupVersionResource")
 	}
 
-	// Special handling for cluster-scoped resources
-	isClusterScoped := rr.isClusterScopedResource(reference.TargetKind, reference.TargetGroup)
+	// References hinted at another cluster are looked up with that cluster's client
+	cluster := rr.targetCluster(source, reference)
+	client, err := rr.clientFor(cluster)
+	if err != nil {
+		return nil, functionerrors.Wrap(err, fmt.Sprintf("failed to get client for cluster %s", cluster))
+	}
 
 	// Resolve the reference
 	var resolvedResource *unstructured.Unstructured
@@ -213,29 +590,41 @@ func (rr *DefaultReferenceResolver) ResolveReference(ctx context.Context, source
 		"targetName", targetName,
 		"targetNamespace", targetNamespace,
 		"isClusterScoped", isClusterScoped,
+		"cluster", cluster,
 		"gvr", gvr.String())
 
-	if isClusterScoped {
-		// Force cluster-scoped lookup for resources like GithubProvider
-		rr.logger.Debug("Performing cluster-scoped resource lookup", "targetKind", reference.TargetKind)
-		resolvedResource, err = rr.dynamicClient.Resource(gvr).Get(ctx, targetName, metav1.GetOptions{})
-	} else if targetNamespace != "" {
-		// Namespaced resource
-		rr.logger.Debug("Performing namespaced resource lookup", "targetKind", reference.TargetKind, "namespace", targetNamespace)
-		resolvedResource, err = rr.dynamicClient.Resource(gvr).Namespace(targetNamespace).Get(ctx, targetName, metav1.GetOptions{})
-	} else {
-		// Try both - first cluster-scoped, then default namespace
-		rr.logger.Debug("Trying both cluster-scoped and namespaced lookup", "targetKind", reference.TargetKind)
-		resolvedResource, err = rr.dynamicClient.Resource(gvr).Get(ctx, targetName, metav1.GetOptions{})
-		if err != nil {
-			rr.logger.Debug("Cluster-scoped lookup failed, trying default namespace", "error", err)
-			// Try with default namespace
-			defaultNamespace := source.GetNamespace()
-			if defaultNamespace == "" {
-				defaultNamespace = "default"
-			}
-			resolvedResource, err = rr.dynamicClient.Resource(gvr).Namespace(defaultNamespace).Get(ctx, targetName, metav1.GetOptions{})
+	aliased := options.groupAliased(reference.TargetGroup)
+	lookup := func() (*unstructured.Unstructured, error) {
+		resolved, err := rr.getTarget(ctx, client, gvr, source, reference, targetName, targetNamespace, isClusterScoped)
+
+		// Fall back to the original group when the aliased one has no such resource
+		if err != nil && aliased {
+			rr.logger.Debug("Aliased group lookup failed, falling back to original group",
+				"aliasedGroup", gvr.Group,
+				"originalGroup", reference.TargetGroup,
+				"error", err)
+			originalGVR := gvr
+			originalGVR.Group = reference.TargetGroup
+			resolved, err = rr.getTarget(ctx, client, originalGVR, source, reference, targetName, targetNamespace, isClusterScoped)
+		}
+
+		// Targets from another cluster are told apart from same-named local ones
+		if err == nil && cluster != "" {
+			resolved = withResolvedCluster(resolved, cluster)
 		}
+
+		return resolved, err
+	}
+
+	// Share the lookup with other resources resolving the same target at this depth
+	if memo := resolutionMemoFrom(ctx); memo != nil {
+		key := rr.targetKey(gvr, source, targetName, targetNamespace, isClusterScoped)
+		if cluster != "" {
+			key = cluster + "|" + key
+		}
+		resolvedResource, err = memo.do(key, lookup)
+	} else {
+		resolvedResource, err = lookup()
 	}
 
 	if err != nil {
@@ -245,21 +634,90 @@ func (rr *DefaultReferenceResolver) ResolveReference(ctx context.Context, source
 			"targetNamespace", targetNamespace,
 			"isClusterScoped", isClusterScoped,
 			"error", err)
+		if apierrors.IsForbidden(err) {
+			rr.recordAccessDenied(ctx, source, gvr, reference.TargetKind, targetName, targetNamespace)
+		}
 		return nil, functionerrors.Wrap(err, fmt.Sprintf("failed to resolve reference to %s/%s", reference.TargetKind, targetName))
 	}
 
-	// Cache the result
-	rr.cache.Set(cacheKey, resolvedResource, 5*time.Minute)
-
-	rr.logger.Debug("Reference resolved successfully",
-		"reference", reference.FieldPath,
-		"targetKind", reference.TargetKind,
-		"targetName", targetName,
-		"targetNamespace", targetNamespace)
+	// The object a name resolves to is not the target when it is another kind,
+	// e.g. when the target GVR maps to a different resource than intended
+	if err := rr.verifyTargetKind(ctx, source, resolvedResource, reference); err != nil {
+		return nil, err
+	}
 
 	return resolvedResource, nil
 }
 
+// verifyTargetKind checks that a resolved object is of the kind the reference
+// expects, recording a kind_mismatch warning when it is not. Kinds are compared
+// ignoring case, since kinds inferred from field names may differ in case.
+func (rr *DefaultReferenceResolver) verifyTargetKind(ctx context.Context, source, resolved *unstructured.Unstructured, reference dynamictypes.ReferenceField) error {
+	kind := resolved.GetKind()
+	if reference.TargetKind == "" || kind == "" || strings.EqualFold(kind, reference.TargetKind) {
+		return nil
+	}
+
+	resourceID := resourceIDOf(source)
+	target := strings.TrimPrefix(resolved.GetNamespace()+"/"+resolved.GetName(), "/")
+
+	rr.logger.Info("Referenced object has an unexpected kind, not linking it",
+		logfields.ResourceID, resourceID,
+		logfields.FieldPath, reference.FieldPath,
+		"expectedKind", reference.TargetKind,
+		"resolvedKind", kind,
+		"name", target)
+
+	rr.recordWarning(ctx, TraversalWarning{
+		Type:       TraversalWarningKindMismatch,
+		Message:    fmt.Sprintf("%s resolved to %s %s, expected a %s", reference.FieldPath, kind, target, reference.TargetKind),
+		ResourceID: resourceID,
+		Context: map[string]interface{}{
+			"fieldPath":    reference.FieldPath,
+			"expectedKind": reference.TargetKind,
+			"resolvedKind": kind,
+			"name":         target,
+		},
+	})
+
+	return fmt.Errorf("%w: %s is a %s, expected a %s", errKindMismatch, target, kind, reference.TargetKind)
+}
+
+// getTarget fetches the referenced resource with the given cluster client
+// using the scope-appropriate lookup
+func (rr *DefaultReferenceResolver) getTarget(ctx context.Context, client dynamic.Interface, gvr schema.GroupVersionResource, source *unstructured.Unstructured, reference dynamictypes.ReferenceField, targetName, targetNamespace string, isClusterScoped bool) (*unstructured.Unstructured, error) {
+	if isClusterScoped {
+		// Force cluster-scoped lookup for resources like GithubProvider
+		rr.logger.Debug("Performing cluster-scoped resource lookup", "targetKind", reference.TargetKind)
+		rr.apiCalls.Add(1)
+		return client.Resource(gvr).Get(ctx, targetName, metav1.GetOptions{})
+	}
+
+	if targetNamespace != "" {
+		// Namespaced resource
+		rr.logger.Debug("Performing namespaced resource lookup", "targetKind", reference.TargetKind, "namespace", targetNamespace)
+		rr.apiCalls.Add(1)
+		return client.Resource(gvr).Namespace(targetNamespace).Get(ctx, targetName, metav1.GetOptions{})
+	}
+
+	// Try both - first cluster-scoped, then default namespace
+	rr.logger.Debug("Trying both cluster-scoped and namespaced lookup", "targetKind", reference.TargetKind)
+	rr.apiCalls.Add(1)
+	resolvedResource, err := client.Resource(gvr).Get(ctx, targetName, metav1.GetOptions{})
+	if err != nil {
+		rr.logger.Debug("Cluster-scoped lookup failed, trying default namespace", "error", err)
+		// Try with default namespace
+		defaultNamespace := source.GetNamespace()
+		if defaultNamespace == "" {
+			defaultNamespace = "default"
+		}
+		rr.apiCalls.Add(1)
+		resolvedResource, err = client.Resource(gvr).Namespace(defaultNamespace).Get(ctx, targetName, metav1.GetOptions{})
+	}
+
+	return resolvedResource, err
+}
+
 // ValidateReference validates if a reference can be resolved
 func (rr *DefaultReferenceResolver) ValidateReference(reference dynamictypes.ReferenceField) error {
 	// Validate required fields
@@ -283,17 +741,44 @@ func (rr *DefaultReferenceResolver) ValidateReference(reference dynamictypes.Ref
 
 // extractReferencesFromRegistry extracts references using registry information
 func (rr *DefaultReferenceResolver) extractReferencesFromRegistry(resource *unstructured.Unstructured, resourceType *registry.ResourceType) ([]dynamictypes.ReferenceField, error) {
-	// This would use the registry's schema information to identify reference fields
-	// For now, return empty slice as the registry interface would need extension
-	return []dynamictypes.ReferenceField{}, nil
+	registryRefs, err := rr.registry.GetReferences(resourceType.APIVersion, resourceType.Kind)
+	if err != nil {
+		return nil, err
+	}
+
+	var references []dynamictypes.ReferenceField
+	for _, registryRef := range registryRefs {
+		schemaPath := strings.TrimPrefix(registryRef.FieldPath, "$.")
+
+		// Only fields set on this resource are references
+		instancePaths, err := dynamictypes.ExpandFieldPath(resource.Object, schemaPath)
+		if err != nil {
+			rr.logger.Debug("Skipping registry reference with invalid field path", logfields.FieldPath, registryRef.FieldPath, "error", err)
+			continue
+		}
+
+		fieldName := strings.TrimSuffix(schemaPath[strings.LastIndex(schemaPath, ".")+1:], dynamictypes.ArrayWildcard)
+		for _, instancePath := range instancePaths {
+			references = append(references, dynamictypes.ReferenceField{
+				FieldPath:       instancePath,
+				SchemaPath:      schemaPath,
+				FieldName:       fieldName,
+				TargetKind:      registryRef.TargetKind,
+				TargetGroup:     registryRef.TargetGroup,
+				RefType:         dynamictypes.RefType(registryRef.RefType),
+				Confidence:      1.0, // The registry declares its references explicitly
+				DetectionMethod: "registry",
+			})
+		}
+	}
+
+	return references, nil
 }
 
 // extractReferencesFromPatterns extracts references using pattern matching
-func (rr *DefaultReferenceResolver) extractReferencesFromPatterns(resource *unstructured.Unstructured) ([]dynamictypes.ReferenceField, error) {
+func (rr *DefaultReferenceResolver) extractReferencesFromPatterns(resource *unstructured.Unstructured, detector dynamictypes.ReferenceDetector) ([]dynamictypes.ReferenceField, error) {
 	// Debug logging for schema conversion
-	rr.logger.Debug("Converting resource to schema",
-		"kind", resource.GetKind(),
-		"name", resource.GetName())
+	rr.logger.Debug("Converting resource to schema", logfields.Resource(resource)...)
 
 	// Use the reference detector to find references based on patterns
 	resourceSchema := rr.convertToResourceSchema(resource)
@@ -308,14 +793,15 @@ func (rr *DefaultReferenceResolver) extractReferencesFromPatterns(resource *unst
 		"fieldCount", len(resourceSchema.Fields),
 		"fieldNames", fieldNames)
 
-	references, err := rr.referenceDetector.DetectReferences(resourceSchema)
+	references, err := detector.DetectReferences(resourceSchema)
 	if err == nil {
+		references = rr.expandArrayReferences(resource, references)
 		rr.logger.Debug("Pattern-based references detected",
 			"referenceCount", len(references))
 		for _, ref := range references {
 			rr.logger.Debug("Reference found",
 				"fieldName", ref.FieldName,
-				"fieldPath", ref.FieldPath,
+				logfields.FieldPath, ref.FieldPath,
 				"targetKind", ref.TargetKind,
 				"targetGroup", ref.TargetGroup,
 				"refType", ref.RefType,
@@ -329,11 +815,42 @@ func (rr *DefaultReferenceResolver) extractReferencesFromPatterns(resource *unst
 	return references, err
 }
 
+// expandArrayReferences replaces each reference detected under a list or map,
+// whose field path holds [*] or .*, with one reference per list element or
+// map value it is set in. The expanded references keep the wildcard path as
+// their schema path.
+func (rr *DefaultReferenceResolver) expandArrayReferences(resource *unstructured.Unstructured, references []dynamictypes.ReferenceField) []dynamictypes.ReferenceField {
+	expanded := make([]dynamictypes.ReferenceField, 0, len(references))
+	for _, ref := range references {
+		if !dynamictypes.HasWildcard(ref.FieldPath) {
+			expanded = append(expanded, ref)
+			continue
+		}
+
+		instancePaths, err := dynamictypes.ExpandFieldPath(resource.Object, ref.FieldPath)
+		if err != nil {
+			rr.logger.Debug("Skipping reference with invalid field path", logfields.FieldPath, ref.FieldPath, "error", err)
+			continue
+		}
+		for _, instancePath := range instancePaths {
+			instanceRef := ref
+			instanceRef.FieldPath = instancePath
+			if instanceRef.SchemaPath == "" {
+				instanceRef.SchemaPath = ref.FieldPath
+			}
+			expanded = append(expanded, instanceRef)
+		}
+	}
+	return expanded
+}
+
 // extractOwnerReferences extracts owner references
-func (rr *DefaultReferenceResolver) extractOwnerReferences(resource *unstructured.Unstructured) ([]dynamictypes.ReferenceField, error) {
+func (rr *DefaultReferenceResolver) extractOwnerReferences(ctx context.Context, resource *unstructured.Unstructured, options *resolutionOptions) ([]dynamictypes.ReferenceField, error) {
 	var references []dynamictypes.ReferenceField
 
 	ownerRefs := resource.GetOwnerReferences()
+	rr.checkControllerOwners(ctx, resource, ownerRefs)
+
 	for i, ownerRef := range ownerRefs {
 		ref := dynamictypes.ReferenceField{
 			FieldPath:       fmt.Sprintf("metadata.ownerReferences[%d]", i),
@@ -355,12 +872,123 @@ func (rr *DefaultReferenceResolver) extractOwnerReferences(resource *unstructure
 			ref.TargetVersion = ownerRef.APIVersion
 		}
 
+		// Skip owners outside the discovery scope before they are ever resolved
+		if options.ownerScopeFilter != nil && options.ownerScope != nil && !options.ownerScopeFilter.ShouldFollowReference(ref, options.ownerScope) {
+			rr.logger.Debug("Skipping out-of-scope owner reference",
+				"ownerKind", ownerRef.Kind,
+				"ownerAPIVersion", ownerRef.APIVersion,
+				"ownerName", ownerRef.Name)
+			continue
+		}
+
 		references = append(references, ref)
 	}
 
 	return references, nil
 }
 
+// checkControllerOwners records a warning when more than one owner reference
+// is marked as the controller. Kubernetes only enforces a single controller
+// within one API version, so stale or buggy controllers can leave several
+// behind. All of them are still followed rather than silently picking one.
+func (rr *DefaultReferenceResolver) checkControllerOwners(ctx context.Context, resource *unstructured.Unstructured, ownerRefs []metav1.OwnerReference) {
+	var controllers []string
+	for _, ownerRef := range ownerRefs {
+		if ownerRef.Controller != nil && *ownerRef.Controller {
+			controllers = append(controllers, fmt.Sprintf("%s/%s/%s", ownerRef.APIVersion, ownerRef.Kind, ownerRef.Name))
+		}
+	}
+	if len(controllers) < 2 {
+		return
+	}
+
+	resourceID := resourceIDOf(resource)
+
+	rr.logger.Info("Resource has multiple controller owner references",
+		logfields.ResourceID, resourceID,
+		"controllers", controllers)
+
+	rr.recordWarning(ctx, TraversalWarning{
+		Type:       TraversalWarningMultipleControllers,
+		Message:    fmt.Sprintf("%d owner references are marked as controller: %s", len(controllers), strings.Join(controllers, ", ")),
+		ResourceID: resourceID,
+		Context: map[string]interface{}{
+			"controllers": controllers,
+		},
+	})
+}
+
+// recordAccessDenied records a warning for a reference target the function
+// is not allowed to read, so RBAC gaps are not mistaken for missing resources
+func (rr *DefaultReferenceResolver) recordAccessDenied(ctx context.Context, source *unstructured.Unstructured, gvr schema.GroupVersionResource, kind, name, namespace string) {
+	resourceID := resourceIDOf(source)
+	gvk := gvr.GroupVersion().WithKind(kind)
+
+	rr.logger.Info("Access denied reading referenced resource",
+		logfields.ResourceID, resourceID,
+		logfields.ResourceGVK, gvk.String(),
+		logfields.ResourceNamespace, namespace,
+		logfields.ResourceName, name)
+
+	rr.recordWarning(ctx, TraversalWarning{
+		Type:       TraversalWarningAccessDenied,
+		Message:    fmt.Sprintf("access denied reading %s %s", gvk.String(), strings.TrimPrefix(namespace+"/"+name, "/")),
+		ResourceID: resourceID,
+		Context: map[string]interface{}{
+			"gvk":       gvk.String(),
+			"namespace": namespace,
+			"name":      name,
+		},
+	})
+}
+
+// extractAnnotationReferences extracts references from annotations whose key
+// matches a configured prefix
+func (rr *DefaultReferenceResolver) extractAnnotationReferences(resource *unstructured.Unstructured, patterns []AnnotationReferencePattern) []dynamictypes.ReferenceField {
+	if len(patterns) == 0 {
+		return nil
+	}
+
+	var references []dynamictypes.ReferenceField
+	for key, value := range resource.GetAnnotations() {
+		if value == "" {
+			continue
+		}
+
+		for _, pattern := range patterns {
+			if pattern.KeyPrefix == "" || !strings.HasPrefix(key, pattern.KeyPrefix) {
+				continue
+			}
+
+			confidence := pattern.Confidence
+			if confidence == 0 {
+				confidence = 0.9
+			}
+
+			references = append(references, dynamictypes.ReferenceField{
+				FieldPath:       annotationFieldPath(key),
+				FieldName:       key,
+				TargetKind:      pattern.TargetKind,
+				TargetGroup:     pattern.TargetGroup,
+				TargetVersion:   pattern.TargetVersion,
+				RefType:         dynamictypes.RefTypeCustom,
+				Confidence:      confidence,
+				DetectionMethod: "annotation",
+				MatchedPattern:  pattern.KeyPrefix,
+			})
+			break
+		}
+	}
+
+	return references
+}
+
+// annotationFieldPath returns the field path of an annotation. Keys usually
+// contain dots, so they are bracketed rather than joined with the path.
+func annotationFieldPath(key string) string {
+	return fmt.Sprintf("metadata.annotations[%s]", key)
+}
+
 // convertToResourceSchema converts an unstructured resource to a ResourceSchema
 func (rr *DefaultReferenceResolver) convertToResourceSchema(resource *unstructured.Unstructured) *dynamictypes.ResourceSchema {
 	rootFields := make(map[string]*dynamictypes.FieldDefinition)
@@ -374,7 +1002,7 @@ func (rr *DefaultReferenceResolver) convertToResourceSchema(resource *unstructur
 		// Use empty basePath so field names are preserved for pattern matching
 		rr.analyzeFields(spec, "", specField.Properties)
 		rootFields["spec"] = specField
-		
+
 		// Also add spec fields directly to root for pattern matching
 		// This allows patterns to match both "githubProviderRef" and "spec.githubProviderRef"
 		for fieldName, fieldDef := range specField.Properties {
@@ -391,7 +1019,7 @@ func (rr *DefaultReferenceResolver) convertToResourceSchema(resource *unstructur
 		// Use empty basePath so field names are preserved for pattern matching
 		rr.analyzeFields(status, "", statusField.Properties)
 		rootFields["status"] = statusField
-		
+
 		// Don't add status fields directly to root to avoid noise in pattern matching
 		// Status fields are less likely to contain references and can cause false positives
 	}
@@ -406,7 +1034,7 @@ func (rr *DefaultReferenceResolver) convertToResourceSchema(resource *unstructur
 func (rr *DefaultReferenceResolver) analyzeFields(obj interface{}, basePath string, fields map[string]*dynamictypes.FieldDefinition) {
 	// Handle different map types that can result from YAML parsing
 	var mapObj map[string]interface{}
-	
+
 	switch v := obj.(type) {
 	case map[string]interface{}:
 		mapObj = v
@@ -424,7 +1052,7 @@ func (rr *DefaultReferenceResolver) analyzeFields(obj interface{}, basePath stri
 		rr.logger.Debug("Unexpected object type in analyzeFields", "type", fmt.Sprintf("%T", obj))
 		return
 	}
-	
+
 	for key, value := range mapObj {
 		// Fix leading dot issue when basePath is empty
 		var fieldPath string
@@ -444,28 +1072,58 @@ func (rr *DefaultReferenceResolver) analyzeFields(obj interface{}, basePath stri
 			// Recursive call with proper nested object handling
 			rr.analyzeFields(value, fieldPath, properties)
 			fieldDef.Properties = properties
-			
-			rr.logger.Debug("Nested object analyzed", 
+
+			rr.logger.Debug("Nested object analyzed",
 				"fieldName", key,
-				"fieldPath", fieldPath, 
+				logfields.FieldPath, fieldPath,
 				"propertiesCount", len(properties))
 		}
 
+		// Describe list elements so list-valued reference fields can be detected
+		if items, ok := value.([]interface{}); ok && len(items) > 0 {
+			fieldDef.Items = rr.analyzeListItems(items, fieldPath)
+		}
+
 		// CRITICAL FIX: Use field name as key for pattern matching, not full path
 		// This allows patterns like "githubProviderRef*" to match field "githubProviderRef"
 		// instead of failing to match "spec.githubProviderRef"
 		fields[key] = fieldDef
-		
+
 		// Add comprehensive debug logging to trace field analysis
-		rr.logger.Debug("Field analyzed", 
-			"fieldName", key, 
-			"fieldPath", fieldPath, 
+		rr.logger.Debug("Field analyzed",
+			"fieldName", key,
+			logfields.FieldPath, fieldPath,
 			"fieldType", fieldDef.Type,
 			"hasProperties", fieldDef.Properties != nil,
 			"propertiesCount", len(fieldDef.Properties))
 	}
 }
 
+// analyzeListItems builds the item definition of a list. String lists are
+// described from their first element. Object elements are merged so a field
+// set in any element, e.g. the claimName of one of a Pod's volumes, is
+// described; elements differ in which of their optional fields they set.
+func (rr *DefaultReferenceResolver) analyzeListItems(items []interface{}, fieldPath string) *dynamictypes.FieldDefinition {
+	switch items[0].(type) {
+	case string:
+		return &dynamictypes.FieldDefinition{Type: "string"}
+	case map[string]interface{}:
+		properties := make(map[string]*dynamictypes.FieldDefinition)
+		for _, item := range items {
+			element := make(map[string]*dynamictypes.FieldDefinition)
+			rr.analyzeFields(item, fieldPath+dynamictypes.ArrayWildcard, element)
+			for key, definition := range element {
+				if _, seen := properties[key]; !seen {
+					properties[key] = definition
+				}
+			}
+		}
+		return &dynamictypes.FieldDefinition{Type: "object", Properties: properties}
+	default:
+		rr.logger.Debug("Skipping list items that cannot hold references", logfields.FieldPath, fieldPath)
+		return nil
+	}
+}
 
 // determineFieldType determines the type of a field value
 func (rr *DefaultReferenceResolver) determineFieldType(value interface{}) string {
@@ -497,34 +1155,92 @@ func (rr *DefaultReferenceResolver) isMapType(value interface{}) bool {
 	}
 }
 
-// deduplicateReferences removes duplicate references
+// deduplicateReferences removes duplicate references. The first detection of
+// a field is kept, and later ones are recorded on it in AlsoDetectedBy so
+// their provenance is not lost; it takes the highest confidence of them all.
 func (rr *DefaultReferenceResolver) deduplicateReferences(references []dynamictypes.ReferenceField) []dynamictypes.ReferenceField {
-	seen := make(map[string]bool)
+	seen := make(map[string]int)
 	var result []dynamictypes.ReferenceField
 
 	for _, ref := range references {
 		key := fmt.Sprintf("%s:%s:%s", ref.FieldPath, ref.TargetKind, ref.TargetGroup)
-		if !seen[key] {
-			seen[key] = true
+		i, exists := seen[key]
+		if !exists {
+			seen[key] = len(result)
 			result = append(result, ref)
+			continue
+		}
+
+		kept := &result[i]
+		addDetection(kept, dynamictypes.ReferenceDetection{
+			DetectionMethod: ref.DetectionMethod,
+			Confidence:      ref.Confidence,
+			MatchedPattern:  ref.MatchedPattern,
+		})
+		for _, detection := range ref.AlsoDetectedBy {
+			addDetection(kept, detection)
+		}
+		if ref.Confidence > kept.Confidence {
+			kept.Confidence = ref.Confidence
 		}
 	}
 
 	return result
 }
 
+// addDetection records another detection of a reference, skipping one that
+// repeats a detection already recorded
+func addDetection(ref *dynamictypes.ReferenceField, detection dynamictypes.ReferenceDetection) {
+	if detection.DetectionMethod == ref.DetectionMethod && detection.MatchedPattern == ref.MatchedPattern {
+		return
+	}
+	for _, existing := range ref.AlsoDetectedBy {
+		if existing.DetectionMethod == detection.DetectionMethod && existing.MatchedPattern == detection.MatchedPattern {
+			return
+		}
+	}
+	ref.AlsoDetectedBy = append(ref.AlsoDetectedBy, detection)
+}
+
 // extractReferenceValue extracts the value of a reference field from a resource
-func (rr *DefaultReferenceResolver) extractReferenceValue(resource *unstructured.Unstructured, fieldPath string) (interface{}, error) {
+func (rr *DefaultReferenceResolver) extractReferenceValue(ctx context.Context, resource *unstructured.Unstructured, fieldPath string, options *resolutionOptions) (interface{}, error) {
+	// Annotation keys are bracketed since they usually contain dots
+	if strings.HasPrefix(fieldPath, "metadata.annotations[") && strings.HasSuffix(fieldPath, "]") {
+		key := strings.TrimSuffix(strings.TrimPrefix(fieldPath, "metadata.annotations["), "]")
+		value, found := resource.GetAnnotations()[key]
+		if !found {
+			return nil, fmt.Errorf("annotation not found: %s", key)
+		}
+		return value, nil
+	}
+
+	// CEL references are recomputed from the resource
+	if strings.HasPrefix(fieldPath, celFieldPathPrefix) {
+		return rr.celReferenceValue(ctx, resource, fieldPath, options.celExtractors)
+	}
+
 	pathParts := strings.Split(fieldPath, ".")
 
 	// Handle owner references specially
 	if len(pathParts) >= 2 && pathParts[0] == "metadata" && strings.HasPrefix(pathParts[1], "ownerReferences") {
-		ownerRefs := resource.GetOwnerReferences()
-		if len(ownerRefs) > 0 {
-			// Return the name of the first owner reference
-			return ownerRefs[0].Name, nil
+		ownerRef, err := rr.ownerReferenceAt(resource, fieldPath)
+		if err != nil {
+			return nil, err
 		}
-		return nil, fmt.Errorf("no owner references found")
+		return ownerRef.Name, nil
+	}
+
+	// List elements are addressed by index, which NestedFieldCopy cannot
+	// follow, and unexpanded wildcards are rejected rather than looked up
+	if strings.Contains(fieldPath, "[") || dynamictypes.HasWildcard(fieldPath) {
+		value, found, err := dynamictypes.NestedFieldValue(resource.Object, fieldPath)
+		if err != nil {
+			return nil, functionerrors.Wrap(err, "failed to extract field value")
+		}
+		if !found {
+			return nil, fmt.Errorf("field not found: %s", fieldPath)
+		}
+		return runtime.DeepCopyJSONValue(value), nil
 	}
 
 	// Use unstructured.NestedFieldCopy to extract the field value
@@ -540,13 +1256,43 @@ func (rr *DefaultReferenceResolver) extractReferenceValue(resource *unstructured
 	return value, nil
 }
 
+// ownerReferenceAt returns the owner reference a metadata.ownerReferences[i]
+// field path points at, or the first owner reference when it has no index
+func (rr *DefaultReferenceResolver) ownerReferenceAt(resource *unstructured.Unstructured, fieldPath string) (*metav1.OwnerReference, error) {
+	ownerRefs := resource.GetOwnerReferences()
+	if len(ownerRefs) == 0 {
+		return nil, fmt.Errorf("no owner references found")
+	}
+
+	index := 0
+	if _, err := fmt.Sscanf(strings.TrimPrefix(fieldPath, "metadata."), "ownerReferences[%d]", &index); err == nil && (index < 0 || index >= len(ownerRefs)) {
+		return nil, fmt.Errorf("owner reference index out of range: %s", fieldPath)
+	}
+	return &ownerRefs[index], nil
+}
+
+// verifyOwnerUID checks that a resolved owner is the object the owner
+// reference was recorded for, rejecting owners whose name has been reused
+func (rr *DefaultReferenceResolver) verifyOwnerUID(source, resolved *unstructured.Unstructured, reference dynamictypes.ReferenceField) error {
+	ownerRef, err := rr.ownerReferenceAt(source, reference.FieldPath)
+	if err != nil {
+		return err
+	}
+
+	if ownerRef.UID != "" && resolved.GetUID() != ownerRef.UID {
+		return fmt.Errorf("stale owner reference to %s/%s: expected UID %s, found %s",
+			ownerRef.Kind, ownerRef.Name, ownerRef.UID, resolved.GetUID())
+	}
+	return nil
+}
+
 // parseReferenceValue parses a reference value to extract target name and namespace
-func (rr *DefaultReferenceResolver) parseReferenceValue(refValue interface{}, reference dynamictypes.ReferenceField, sourceNamespace string) (name, namespace string, err error) {
+func (rr *DefaultReferenceResolver) parseReferenceValue(refValue interface{}, reference dynamictypes.ReferenceField, source *unstructured.Unstructured, options *resolutionOptions) (name, namespace string, err error) {
 	switch v := refValue.(type) {
 	case string:
 		// Simple string reference (just the name)
 		name = v
-		namespace = sourceNamespace // Default to source namespace
+		namespace = options.defaultTargetNamespace(source)
 
 	case map[string]interface{}:
 		// Object reference with name and optionally namespace
@@ -566,7 +1312,7 @@ func (rr *DefaultReferenceResolver) parseReferenceValue(refValue interface{}, re
 				namespace = nsStr
 			}
 		} else {
-			namespace = sourceNamespace // Default to source namespace
+			namespace = options.defaultTargetNamespace(source)
 		}
 
 	default:
@@ -578,11 +1324,123 @@ func (rr *DefaultReferenceResolver) parseReferenceValue(refValue interface{}, re
 		return "", "", fmt.Errorf("empty reference name")
 	}
 
-	return name, namespace, nil
+	if err := options.checkSameNamespace(source.GetNamespace(), namespace); err != nil {
+		return "", "", err
+	}
+
+	return name, options.rewriteNamespace(namespace), nil
+}
+
+// defaultTargetNamespace returns the namespace a reference naming none resolves
+// in: the source's own namespace, or for cluster-scoped sources the claim
+// namespace and then the configured cluster source namespace, if any
+func (o *resolutionOptions) defaultTargetNamespace(source *unstructured.Unstructured) string {
+	if namespace := source.GetNamespace(); namespace != "" {
+		return namespace
+	}
+
+	if o.useClaimNamespace {
+		if namespace := source.GetLabels()[claimNamespaceKey]; namespace != "" {
+			return namespace
+		}
+		if namespace := source.GetAnnotations()[claimNamespaceKey]; namespace != "" {
+			return namespace
+		}
+	}
+
+	return o.clusterSourceNamespace
+}
+
+// checkSameNamespace rejects a target namespace other than the source's when
+// cross-namespace references are disabled. Cluster-scoped sources have no
+// namespace to stay within, so their references are always allowed.
+func (o *resolutionOptions) checkSameNamespace(sourceNamespace, namespace string) error {
+	if o.sameNamespaceOnly && sourceNamespace != "" && namespace != sourceNamespace {
+		return fmt.Errorf("%w: target namespace %q differs from source namespace %q",
+			errCrossNamespaceReference, namespace, sourceNamespace)
+	}
+	return nil
+}
+
+// rewriteNamespace maps a target namespace through the configured rewrites
+func (o *resolutionOptions) rewriteNamespace(namespace string) string {
+	if rewritten, ok := o.namespaceRewrite[namespace]; ok {
+		return rewritten
+	}
+	return namespace
+}
+
+// resolveTemplatedName evaluates a name template against the source resource.
+// Placeholders like {metadata.name} are replaced with source field values and
+// {value} with the reference field's own string value. The namespace is taken
+// from the field value when it is an object carrying one, otherwise from the source.
+func (rr *DefaultReferenceResolver) resolveTemplatedName(source *unstructured.Unstructured, template string, refValue interface{}, options *resolutionOptions) (name, namespace string, err error) {
+	namespace = options.defaultTargetNamespace(source)
+	if obj, ok := refValue.(map[string]interface{}); ok {
+		if ns, ok := obj["namespace"].(string); ok && ns != "" {
+			namespace = ns
+		}
+	}
+
+	var builder strings.Builder
+	remaining := template
+	for {
+		start := strings.Index(remaining, "{")
+		if start < 0 {
+			builder.WriteString(remaining)
+			break
+		}
+		end := strings.Index(remaining[start:], "}")
+		if end < 0 {
+			return "", "", fmt.Errorf("unterminated placeholder in name template %q", template)
+		}
+		end += start
+
+		builder.WriteString(remaining[:start])
+		placeholder := remaining[start+1 : end]
+
+		var value string
+		if placeholder == "value" {
+			str, ok := refValue.(string)
+			if !ok {
+				return "", "", fmt.Errorf("{value} requires a string reference field, got %T", refValue)
+			}
+			value = str
+		} else {
+			field, found, fieldErr := unstructured.NestedFieldNoCopy(source.Object, strings.Split(placeholder, ".")...)
+			if fieldErr != nil || !found {
+				return "", "", fmt.Errorf("name template field not found: %s", placeholder)
+			}
+			value = fmt.Sprintf("%v", field)
+		}
+
+		builder.WriteString(value)
+		remaining = remaining[end+1:]
+	}
+
+	name = builder.String()
+	if name == "" {
+		return "", "", fmt.Errorf("name template %q evaluated to an empty name", template)
+	}
+
+	if err := options.checkSameNamespace(source.GetNamespace(), namespace); err != nil {
+		return "", "", err
+	}
+
+	return name, options.rewriteNamespace(namespace), nil
 }
 
 // buildGVR builds a GroupVersionResource from the reference information
-func (rr *DefaultReferenceResolver) buildGVR(group, version, kind string) (schema.GroupVersionResource, error) {
+func (rr *DefaultReferenceResolver) buildGVR(group, version, kind string, groupAliases map[string]string) (schema.GroupVersionResource, error) {
+	// Map renamed API groups to their current name
+	if alias, ok := groupAliases[group]; ok {
+		rr.logger.Debug("Using API group alias",
+			"group", group,
+			"alias", alias,
+			"kind", kind)
+		group = alias
+	}
+
 	// Special handling for GitHub resources - they use v1alpha1
 	if strings.Contains(group, "github") || kind == "GithubProvider" {
 		if version == "" {
@@ -666,14 +1524,41 @@ func (rr *DefaultReferenceResolver) kindToResource(kind string) string {
 }
 
 // generateCacheKey generates a cache key for a reference resolution
-func (rr *DefaultReferenceResolver) generateCacheKey(source *unstructured.Unstructured, reference dynamictypes.ReferenceField) string {
-	return fmt.Sprintf("%s/%s/%s:%s:%s:%s",
+func (rr *DefaultReferenceResolver) generateCacheKey(source *unstructured.Unstructured, reference dynamictypes.ReferenceField, options *resolutionOptions) string {
+	key := fmt.Sprintf("%s|%s/%s/%s:%s:%s:%s",
+		options.cacheScope,
 		source.GetAPIVersion(),
 		source.GetKind(),
 		source.GetName(),
 		reference.FieldPath,
 		reference.TargetKind,
 		reference.TargetGroup)
+
+	// Templated references resolve to different targets for the same field
+	if reference.NameTemplate != "" {
+		key += ":" + reference.NameTemplate
+	}
+
+	if cluster := rr.targetCluster(source, reference); cluster != "" {
+		key += "@" + cluster
+	}
+
+	return key
+}
+
+// targetKey identifies the resource a lookup will fetch by group, version,
+// resource, namespace and name. Lookups without a namespace depend on the source
+// namespace they fall back to, so it is part of their key.
+func (rr *DefaultReferenceResolver) targetKey(gvr schema.GroupVersionResource, source *unstructured.Unstructured, targetName, targetNamespace string, isClusterScoped bool) string {
+	namespace := targetNamespace
+	switch {
+	case isClusterScoped:
+		namespace = ""
+	case targetNamespace == "":
+		namespace = "?" + source.GetNamespace()
+	}
+
+	return fmt.Sprintf("%s/%s/%s/%s/%s", gvr.Group, gvr.Version, gvr.Resource, namespace, targetName)
 }
 
 // getFieldNames returns a slice of field names for debugging
@@ -691,12 +1576,15 @@ func (rr *DefaultReferenceResolver) isClusterScopedResource(kind, group string)
 	clusterScopedResources := map[string]map[string]bool{
 		// Core Kubernetes cluster-scoped resources
 		"": {
-			"Node":                      true,
-			"PersistentVolume":          true,
-			"StorageClass":              true,
-			"ClusterRole":               true,
-			"ClusterRoleBinding":        true,
-			"CustomResourceDefinition":  true,
+			"Node":                     true,
+			"PersistentVolume":         true,
+			"StorageClass":             true,
+			"ClusterRole":              true,
+			"ClusterRoleBinding":       true,
+			"CustomResourceDefinition": true,
+		},
+		"storage.k8s.io": {
+			"StorageClass": true,
 		},
 		// GitHub platform resources are typically cluster-scoped
 		"github.platform.kubecore.io": {
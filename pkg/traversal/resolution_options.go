@@ -0,0 +1,134 @@
+package traversal
+
+import (
+	"context"
+	"fmt"
+
+	dynamictypes "github.com/crossplane/function-kubecore-schema-registry/pkg/dynamic"
+)
+
+// resolutionOptions configure how the resolver extracts and resolves
+// references. The engine builds them from the configuration of each discovery
+// and passes them through the context, so discoveries running concurrently
+// with different configurations never see each other's settings. Calls
+// without them use the options set on the resolver.
+type resolutionOptions struct {
+	// namespaceRewrite maps target namespaces to the namespace used for resolution
+	namespaceRewrite map[string]string
+
+	// groupAliases maps renamed API groups to their current name
+	groupAliases map[string]string
+
+	// annotationPatterns select annotations that encode references
+	annotationPatterns []AnnotationReferencePattern
+
+	// celExtractors derive references from CEL expressions per kind
+	celExtractors []compiledCELExtractor
+
+	// referenceDetector detects reference fields in resources
+	referenceDetector dynamictypes.ReferenceDetector
+
+	// ownerScopeFilter and ownerScope drop out-of-scope owner references at extraction
+	ownerScopeFilter ScopeFilter
+	ownerScope       *ScopeFilterConfig
+
+	// sameNamespaceOnly rejects references that name another namespace
+	sameNamespaceOnly bool
+
+	// clusterSourceNamespace and useClaimNamespace choose the namespace that
+	// references from cluster-scoped sources default to
+	clusterSourceNamespace string
+	useClaimNamespace      bool
+
+	// dynamicCRDs looks up target kinds the registry does not know through
+	// CRD discovery
+	dynamicCRDs bool
+
+	// cacheScope is part of every resolution cache key, so resolutions made
+	// under options that change their outcome are cached apart
+	cacheScope string
+}
+
+// updateCacheScope recomputes the cache scope from the options that change
+// what a reference resolves to. fmt prints maps with sorted keys, so equal
+// options always give equal scopes.
+func (o *resolutionOptions) updateCacheScope() {
+	o.cacheScope = fmt.Sprintf("%v|%v|%t|%s|%t|%t",
+		o.namespaceRewrite,
+		o.groupAliases,
+		o.sameNamespaceOnly,
+		o.clusterSourceNamespace,
+		o.useClaimNamespace,
+		o.dynamicCRDs)
+}
+
+// groupAliased reports whether group is mapped to another group, so targets
+// looked up under the alias may still exist under group itself
+func (o *resolutionOptions) groupAliased(group string) bool {
+	alias, ok := o.groupAliases[group]
+	return ok && alias != group
+}
+
+type resolutionOptionsKey struct{}
+
+// withResolutionOptions returns a context carrying options
+func withResolutionOptions(ctx context.Context, options *resolutionOptions) context.Context {
+	return context.WithValue(ctx, resolutionOptionsKey{}, options)
+}
+
+// resolutionOptionsFrom returns the resolution options carried by ctx, if any
+func resolutionOptionsFrom(ctx context.Context) *resolutionOptions {
+	options, _ := ctx.Value(resolutionOptionsKey{}).(*resolutionOptions)
+	return options
+}
+
+// resolutionOptions returns the options carried by ctx, or else a copy of the
+// options set on the resolver
+func (rr *DefaultReferenceResolver) resolutionOptions(ctx context.Context) *resolutionOptions {
+	if options := resolutionOptionsFrom(ctx); options != nil {
+		return options
+	}
+
+	rr.optionsMu.RLock()
+	defer rr.optionsMu.RUnlock()
+
+	options := rr.options
+	return &options
+}
+
+// updateOptions applies update to the options set on the resolver
+func (rr *DefaultReferenceResolver) updateOptions(update func(*resolutionOptions)) {
+	rr.optionsMu.Lock()
+	defer rr.optionsMu.Unlock()
+
+	update(&rr.options)
+	rr.options.updateCacheScope()
+}
+
+// newResolutionOptions builds the resolution options of one discovery from
+// its configuration, compiling its CEL extractors and reference patterns
+func (rr *DefaultReferenceResolver) newResolutionOptions(config *TraversalConfig, scopeFilter ScopeFilter) (*resolutionOptions, error) {
+	resolution := config.ReferenceResolution
+
+	celExtractors, err := compileCELExtractors(resolution.CELReferences)
+	if err != nil {
+		return nil, err
+	}
+
+	options := &resolutionOptions{
+		namespaceRewrite:       resolution.NamespaceRewrite,
+		groupAliases:           resolution.GroupAliases,
+		annotationPatterns:     resolution.AnnotationReferences,
+		celExtractors:          celExtractors,
+		referenceDetector:      rr.newReferenceDetector(resolution.ReferencePatterns, resolution.ReplaceDefaultPatterns),
+		ownerScopeFilter:       scopeFilter,
+		ownerScope:             config.ScopeFilter,
+		sameNamespaceOnly:      config.ScopeFilter != nil && !config.ScopeFilter.CrossNamespaceEnabled,
+		clusterSourceNamespace: resolution.ClusterSourceNamespace,
+		useClaimNamespace:      resolution.UseClaimNamespace,
+		dynamicCRDs:            resolution.EnableDynamicCRDs,
+	}
+	options.updateCacheScope()
+
+	return options, nil
+}
@@ -1,6 +1,7 @@
 package traversal
 
 import (
+	"slices"
 	"sync"
 	"time"
 
@@ -149,6 +150,52 @@ func (rt *ResourceTracker) MarkProcessed(resourceID string, depth int) {
 	rt.depthIndex[depth] = append(rt.depthIndex[depth], resourceID)
 }
 
+// ShouldProcess reports whether a resource reached at depth needs processing
+// and records it as processed if so. A resource is processed the first time
+// it is seen and again whenever it is reached at a shorter depth than the one
+// recorded, so a resource found deep in a forward pass is re-expanded when a
+// reverse pass reaches it sooner. The recorded depth is updated on re-processing.
+func (rt *ResourceTracker) ShouldProcess(resourceID string, depth int) bool {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	now := time.Now()
+
+	info, exists := rt.processedResources[resourceID]
+	if !exists {
+		rt.processedResources[resourceID] = &ProcessedResourceInfo{
+			ResourceID:      resourceID,
+			ProcessedAt:     now,
+			LastProcessedAt: now,
+			Depth:           depth,
+			ProcessingCount: 1,
+			DiscoveryPath:   make([]string, 0),
+			Metadata:        make(map[string]interface{}),
+		}
+		rt.processingOrder = append(rt.processingOrder, resourceID)
+		rt.depthIndex[depth] = append(rt.depthIndex[depth], resourceID)
+		return true
+	}
+
+	if depth >= info.Depth {
+		return false
+	}
+
+	// Move the resource to its shorter depth
+	rt.depthIndex[info.Depth] = slices.DeleteFunc(rt.depthIndex[info.Depth], func(id string) bool {
+		return id == resourceID
+	})
+	if len(rt.depthIndex[info.Depth]) == 0 {
+		delete(rt.depthIndex, info.Depth)
+	}
+	rt.depthIndex[depth] = append(rt.depthIndex[depth], resourceID)
+
+	info.Depth = depth
+	info.ProcessingCount++
+	info.LastProcessedAt = now
+	return true
+}
+
 // MarkProcessedWithUID marks a resource as processed with its UID
 func (rt *ResourceTracker) MarkProcessedWithUID(resourceID string, uid types.UID, depth int) {
 	rt.mu.Lock()
@@ -0,0 +1,84 @@
+package traversal
+
+import (
+	"sort"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/crossplane/function-kubecore-schema-registry/pkg/graph"
+)
+
+// UnresolvedTargetKinds counts, per target GVK, the references that could not
+// be resolved. Kinds with high counts usually point at a missing CRD or a
+// reference pattern that infers the wrong target. References skipped for low
+// confidence did resolve and are not counted.
+func (r *TraversalResult) UnresolvedTargetKinds() map[schema.GroupVersionKind]int {
+	counts := make(map[schema.GroupVersionKind]int)
+
+	for _, err := range r.Errors {
+		if err.Type != TraversalErrorReferenceResolution {
+			continue
+		}
+		if gvk, ok := err.Context["targetGVK"].(schema.GroupVersionKind); ok {
+			counts[gvk]++
+		}
+	}
+
+	// References skipped while building the graph do not know their version
+	if r.ResourceGraph != nil {
+		for _, node := range r.ResourceGraph.Nodes {
+			if node.Metadata == nil {
+				continue
+			}
+			for _, skipped := range node.Metadata.SkippedReferences {
+				if skipped.Reason != graph.SkipReasonTargetNotDiscovered {
+					continue
+				}
+				counts[schema.GroupVersionKind{Group: skipped.TargetGroup, Kind: skipped.TargetKind}]++
+			}
+		}
+	}
+
+	return counts
+}
+
+// AssertDiscovered compares the discovered resources against the expected
+// resource IDs, written as apiVersion/kind/namespace/name with an empty
+// namespace for cluster-scoped resources. It returns, sorted, the expected IDs
+// that were not discovered and the discovered IDs that were not expected.
+func (r *TraversalResult) AssertDiscovered(expected []string) (missing, extra []string) {
+	expectedIDs := make(map[string]struct{}, len(expected))
+	for _, id := range expected {
+		expectedIDs[id] = struct{}{}
+		if _, found := r.DiscoveredResources[id]; !found {
+			missing = append(missing, id)
+		}
+	}
+
+	for id := range r.DiscoveredResources {
+		if _, ok := expectedIDs[id]; !ok {
+			extra = append(extra, id)
+		}
+	}
+
+	sort.Strings(missing)
+	sort.Strings(extra)
+	return missing, extra
+}
+
+// PathCountByTarget returns how many distinct paths from the root resources
+// reach each discovered resource in the graph. Resources reached by many
+// paths are over-connected and often worth a look.
+func (r *TraversalResult) PathCountByTarget() map[graph.NodeID]int {
+	if r.ResourceGraph == nil {
+		return map[graph.NodeID]int{}
+	}
+
+	var roots []graph.NodeID
+	for nodeID, node := range r.ResourceGraph.Nodes {
+		if node.DiscoveryDepth == 0 {
+			roots = append(roots, nodeID)
+		}
+	}
+	return graph.PathCounts(r.ResourceGraph, roots)
+}
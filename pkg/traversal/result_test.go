@@ -0,0 +1,116 @@
+package traversal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/crossplane/function-kubecore-schema-registry/pkg/graph"
+)
+
+func TestUnresolvedTargetKinds(t *testing.T) {
+	resolver, _ := newTestReferenceResolver()
+	engine := newTestTraversalEngine(resolver)
+
+	config := NewDefaultTraversalConfig()
+	config.MaxDepth = 1
+	config.ScopeFilter = &ScopeFilterConfig{CrossNamespaceEnabled: true}
+
+	// Both apps reference KubeClusters that do not exist in the cluster
+	var roots []*unstructured.Unstructured
+	for _, name := range []string{"app-a", "app-b"} {
+		root := newTestResource("KubeApp", name)
+		root.Object["spec"] = map[string]interface{}{
+			"kubeClusterRef": "missing-" + name,
+		}
+		roots = append(roots, root)
+	}
+
+	result, err := engine.ExecuteTransitiveDiscovery(context.Background(), config, roots)
+	require.NoError(t, err)
+
+	// A reference dropped for low confidence was resolved, so it is not counted
+	for _, node := range result.ResourceGraph.Nodes {
+		node.Metadata.SkippedReferences = append(node.Metadata.SkippedReferences, graph.SkippedReference{
+			FieldPath:   "spec.kubEnvRef",
+			Reason:      graph.SkipReasonBelowConfidenceFloor,
+			TargetKind:  "KubEnv",
+			TargetGroup: "platform.kubecore.io",
+		})
+	}
+
+	counts := make(map[string]int)
+	for gvk, count := range result.UnresolvedTargetKinds() {
+		counts[gvk.GroupKind().String()] += count
+	}
+	assert.Equal(t, map[string]int{"KubeCluster.platform.kubecore.io": 2}, counts)
+}
+
+func TestPathCountByTarget(t *testing.T) {
+	builder := graph.NewDefaultGraphBuilder(NewDefaultPlatformChecker([]string{"*.kubecore.io"}))
+	resourceGraph := builder.NewGraph()
+
+	// app reaches db directly, through net, and through cluster then net
+	app := builder.AddNode(resourceGraph, newTestResource("KubeApp", "app"), 0, nil)
+	cluster := builder.AddNode(resourceGraph, newTestResource("KubeCluster", "cluster"), 1, nil)
+	network := builder.AddNode(resourceGraph, newTestResource("KubeNet", "net"), 1, nil)
+	db := builder.AddNode(resourceGraph, newTestResource("KubeDB", "db"), 1, nil)
+	builder.AddEdge(resourceGraph, app.ID, cluster.ID, graph.RelationTypeCustomRef, "spec.clusterRef", "clusterRef", 0.9)
+	builder.AddEdge(resourceGraph, app.ID, network.ID, graph.RelationTypeCustomRef, "spec.netRef", "netRef", 0.9)
+	builder.AddEdge(resourceGraph, app.ID, db.ID, graph.RelationTypeCustomRef, "spec.dbRef", "dbRef", 0.9)
+	builder.AddEdge(resourceGraph, cluster.ID, network.ID, graph.RelationTypeCustomRef, "spec.netRef", "netRef", 0.9)
+	builder.AddEdge(resourceGraph, network.ID, db.ID, graph.RelationTypeCustomRef, "spec.dbRef", "dbRef", 0.9)
+	// A cycle back to the root does not add paths
+	builder.AddEdge(resourceGraph, db.ID, app.ID, graph.RelationTypeCustomRef, "spec.appRef", "appRef", 0.9)
+
+	result := &TraversalResult{ResourceGraph: resourceGraph}
+	assert.Equal(t, map[graph.NodeID]int{
+		cluster.ID: 1,
+		network.ID: 2,
+		db.ID:      3,
+	}, result.PathCountByTarget())
+}
+
+func TestAssertDiscovered(t *testing.T) {
+	result := &TraversalResult{
+		DiscoveredResources: map[string]*unstructured.Unstructured{
+			"platform.kubecore.io/v1/KubeApp/default/app":       newTestResource("KubeApp", "app"),
+			"platform.kubecore.io/v1/KubEnv/default/dev":        newTestResource("KubEnv", "dev"),
+			"platform.kubecore.io/v1/KubeCluster/default/extra": newTestResource("KubeCluster", "extra"),
+		},
+	}
+
+	cases := map[string]struct {
+		expected        []string
+		expectedMissing []string
+		expectedExtra   []string
+	}{
+		"ExactMatch": {
+			expected: []string{
+				"platform.kubecore.io/v1/KubeApp/default/app",
+				"platform.kubecore.io/v1/KubEnv/default/dev",
+				"platform.kubecore.io/v1/KubeCluster/default/extra",
+			},
+		},
+		"MissingAndExtra": {
+			expected: []string{
+				"platform.kubecore.io/v1/KubeApp/default/app",
+				"platform.kubecore.io/v1/KubEnv/default/dev",
+				"v1/Secret/default/app-credentials",
+			},
+			expectedMissing: []string{"v1/Secret/default/app-credentials"},
+			expectedExtra:   []string{"platform.kubecore.io/v1/KubeCluster/default/extra"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			missing, extra := result.AssertDiscovered(tc.expected)
+			assert.Equal(t, tc.expectedMissing, missing)
+			assert.Equal(t, tc.expectedExtra, extra)
+		})
+	}
+}
@@ -2,8 +2,10 @@ package traversal
 
 import (
 	"strings"
+	"sync"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"github.com/crossplane/function-sdk-go/logging"
 
@@ -36,8 +38,45 @@ type DefaultScopeFilter struct {
 	// logger provides structured logging
 	logger logging.Logger
 
-	// statistics tracks filtering operations
+	// statistics tracks filtering operations; filters run concurrently from
+	// the resolution workers, so it is guarded by mu
 	statistics *FilterStatistics
+	mu         sync.Mutex
+
+	// ExcludePredicate drops discovered resources it returns true for, such as
+	// status projections, before they are added to the graph
+	ExcludePredicate ResourcePredicate
+}
+
+// ResourcePredicate reports whether a resource matches some criteria
+type ResourcePredicate func(*unstructured.Unstructured) bool
+
+// HasNoSpec matches resources without a spec, which are usually status-only
+// projections rather than resources the platform manages
+func HasNoSpec(resource *unstructured.Unstructured) bool {
+	_, found := resource.Object["spec"]
+	return !found
+}
+
+// HasAnnotation returns a predicate matching resources that carry the given
+// annotation key, e.g. one marking them ephemeral
+func HasAnnotation(key string) ResourcePredicate {
+	return func(resource *unstructured.Unstructured) bool {
+		_, found := resource.GetAnnotations()[key]
+		return found
+	}
+}
+
+// AnyOf returns a predicate matching resources that match any of the predicates
+func AnyOf(predicates ...ResourcePredicate) ResourcePredicate {
+	return func(resource *unstructured.Unstructured) bool {
+		for _, predicate := range predicates {
+			if predicate(resource) {
+				return true
+			}
+		}
+		return false
+	}
 }
 
 // FilterStatistics contains statistics about filtering operations
@@ -143,14 +182,11 @@ func (sf *DefaultScopeFilter) FilterResources(resources []*unstructured.Unstruct
 	var filtered []*unstructured.Unstructured
 
 	for _, resource := range resources {
-		sf.statistics.ResourcesEvaluated++
-
-		if sf.ShouldIncludeResource(resource, config) {
+		included := sf.ShouldIncludeResource(resource, config)
+		if included {
 			filtered = append(filtered, resource)
-			sf.statistics.ResourcesIncluded++
-		} else {
-			sf.statistics.ResourcesExcluded++
 		}
+		sf.recordResource(included)
 	}
 
 	sf.logger.Debug("Filtered resources",
@@ -166,14 +202,11 @@ func (sf *DefaultScopeFilter) FilterReferences(references []dynamictypes.Referen
 	var filtered []dynamictypes.ReferenceField
 
 	for _, reference := range references {
-		sf.statistics.ReferencesEvaluated++
-
-		if sf.ShouldFollowReference(reference, config) {
+		included := sf.ShouldFollowReference(reference, config)
+		if included {
 			filtered = append(filtered, reference)
-			sf.statistics.ReferencesIncluded++
-		} else {
-			sf.statistics.ReferencesExcluded++
 		}
+		sf.recordReference(included)
 	}
 
 	sf.logger.Debug("Filtered references",
@@ -184,8 +217,45 @@ func (sf *DefaultScopeFilter) FilterReferences(references []dynamictypes.Referen
 	return filtered
 }
 
-// ShouldIncludeResource determines if a resource should be included in traversal
+// ExcludesResource reports whether ExcludePredicate drops the resource, or
+// the configuration drops it for being terminating
+func (sf *DefaultScopeFilter) ExcludesResource(resource *unstructured.Unstructured, config *ScopeFilterConfig) bool {
+	reason := ""
+	switch {
+	case sf.ExcludePredicate != nil && sf.ExcludePredicate(resource):
+		reason = "excluded_by_predicate"
+	case isTerminatingExcluded(resource, config):
+		reason = "terminating"
+	default:
+		return false
+	}
+
+	sf.recordResourceExclusion(reason)
+	return true
+}
+
+// isTerminatingExcluded reports whether the resource is being deleted and the
+// configuration skips terminating resources
+func isTerminatingExcluded(resource *unstructured.Unstructured, config *ScopeFilterConfig) bool {
+	return config != nil && config.SkipTerminatingResources && resource.GetDeletionTimestamp() != nil
+}
+
+// ShouldIncludeResource determines if a resource should be included in
+// traversal, counting the reason it is excluded
 func (sf *DefaultScopeFilter) ShouldIncludeResource(resource *unstructured.Unstructured, config *ScopeFilterConfig) bool {
+	if sf.ExcludesResource(resource, config) {
+		return false
+	}
+	if reason := sf.scopeExclusionReason(resource, config); reason != "" {
+		sf.recordResourceExclusion(reason)
+		return false
+	}
+	return true
+}
+
+// scopeExclusionReason returns why the scope configuration excludes a
+// resource, or "" when it does not
+func (sf *DefaultScopeFilter) scopeExclusionReason(resource *unstructured.Unstructured, config *ScopeFilterConfig) string {
 	// Extract resource information
 	apiVersion := resource.GetAPIVersion()
 	kind := resource.GetKind()
@@ -195,59 +265,56 @@ func (sf *DefaultScopeFilter) ShouldIncludeResource(resource *unstructured.Unstr
 	// Apply platform-only filter
 	if config.PlatformOnly {
 		if !sf.platformChecker.IsPlatformResource(resource) {
-			sf.statistics.FilterReasons["not_platform"]++
-			return false
+			return "not_platform"
 		}
 	}
 
 	// Apply API group filters
 	if len(config.IncludeAPIGroups) > 0 {
 		if !sf.matchesAPIGroupPatterns(apiGroup, config.IncludeAPIGroups) {
-			sf.statistics.FilterReasons["api_group_not_included"]++
-			return false
+			return "api_group_not_included"
 		}
 	}
 
 	if len(config.ExcludeAPIGroups) > 0 {
 		if sf.matchesAPIGroupPatterns(apiGroup, config.ExcludeAPIGroups) {
-			sf.statistics.FilterReasons["api_group_excluded"]++
-			return false
+			return "api_group_excluded"
 		}
 	}
 
 	// Apply kind filters
 	if len(config.IncludeKinds) > 0 {
 		if !sf.stringInSlice(kind, config.IncludeKinds) {
-			sf.statistics.FilterReasons["kind_not_included"]++
-			return false
+			return "kind_not_included"
 		}
 	}
 
 	if len(config.ExcludeKinds) > 0 {
 		if sf.stringInSlice(kind, config.ExcludeKinds) {
-			sf.statistics.FilterReasons["kind_excluded"]++
-			return false
+			return "kind_excluded"
 		}
 	}
 
+	if !sf.kindAllowed(kind, apiGroup, config.AllowedKinds) {
+		return "kind_not_allowed"
+	}
+
 	// Apply namespace filters
 	if namespace != "" { // Only apply to namespaced resources
 		if len(config.IncludeNamespaces) > 0 {
 			if !sf.stringInSlice(namespace, config.IncludeNamespaces) {
-				sf.statistics.FilterReasons["namespace_not_included"]++
-				return false
+				return "namespace_not_included"
 			}
 		}
 
 		if len(config.ExcludeNamespaces) > 0 {
 			if sf.stringInSlice(namespace, config.ExcludeNamespaces) {
-				sf.statistics.FilterReasons["namespace_excluded"]++
-				return false
+				return "namespace_excluded"
 			}
 		}
 	}
 
-	return true
+	return ""
 }
 
 // ShouldFollowReference determines if a reference should be followed
@@ -255,7 +322,7 @@ func (sf *DefaultScopeFilter) ShouldFollowReference(reference dynamictypes.Refer
 	// Apply platform-only filter
 	if config.PlatformOnly {
 		if !sf.platformChecker.IsPlatformKind(reference.TargetKind, reference.TargetGroup) {
-			sf.statistics.FilterReasons["ref_target_not_platform"]++
+			sf.countFilterReason("ref_target_not_platform")
 			return false
 		}
 	}
@@ -263,14 +330,14 @@ func (sf *DefaultScopeFilter) ShouldFollowReference(reference dynamictypes.Refer
 	// Apply API group filters for references
 	if len(config.IncludeAPIGroups) > 0 {
 		if !sf.matchesAPIGroupPatterns(reference.TargetGroup, config.IncludeAPIGroups) {
-			sf.statistics.FilterReasons["ref_api_group_not_included"]++
+			sf.countFilterReason("ref_api_group_not_included")
 			return false
 		}
 	}
 
 	if len(config.ExcludeAPIGroups) > 0 {
 		if sf.matchesAPIGroupPatterns(reference.TargetGroup, config.ExcludeAPIGroups) {
-			sf.statistics.FilterReasons["ref_api_group_excluded"]++
+			sf.countFilterReason("ref_api_group_excluded")
 			return false
 		}
 	}
@@ -278,36 +345,83 @@ func (sf *DefaultScopeFilter) ShouldFollowReference(reference dynamictypes.Refer
 	// Apply kind filters for references
 	if len(config.IncludeKinds) > 0 {
 		if !sf.stringInSlice(reference.TargetKind, config.IncludeKinds) {
-			sf.statistics.FilterReasons["ref_kind_not_included"]++
+			sf.countFilterReason("ref_kind_not_included")
 			return false
 		}
 	}
 
 	if len(config.ExcludeKinds) > 0 {
 		if sf.stringInSlice(reference.TargetKind, config.ExcludeKinds) {
-			sf.statistics.FilterReasons["ref_kind_excluded"]++
+			sf.countFilterReason("ref_kind_excluded")
 			return false
 		}
 	}
 
-	// Check cross-namespace references
-	if !config.CrossNamespaceEnabled {
-		// This is a simplified check - in practice we'd need to compare
-		// the source resource's namespace with the target's namespace
-		if reference.RefType != dynamictypes.RefTypeOwnerRef {
-			// For now, allow owner references across namespaces
-			// but restrict other reference types
-			sf.statistics.FilterReasons["cross_namespace_disabled"]++
-			return false
-		}
+	if !sf.kindAllowed(reference.TargetKind, reference.TargetGroup, config.AllowedKinds) {
+		sf.countFilterReason("ref_kind_not_allowed")
+		return false
 	}
 
+	// Cross-namespace references are rejected by the resolver, since only the
+	// reference value tells which namespace it targets
+
 	return true
 }
 
-// GetFilterStatistics returns statistics about filtering operations
+// GetFilterStatistics returns a snapshot of the statistics about filtering
+// operations
 func (sf *DefaultScopeFilter) GetFilterStatistics() *FilterStatistics {
-	return sf.statistics
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+
+	snapshot := *sf.statistics
+	snapshot.FilterReasons = make(map[string]int, len(sf.statistics.FilterReasons))
+	for reason, count := range sf.statistics.FilterReasons {
+		snapshot.FilterReasons[reason] = count
+	}
+	return &snapshot
+}
+
+// recordResource counts an evaluated resource; exclusions are counted with
+// their reason by recordResourceExclusion
+func (sf *DefaultScopeFilter) recordResource(included bool) {
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+
+	sf.statistics.ResourcesEvaluated++
+	if included {
+		sf.statistics.ResourcesIncluded++
+	}
+}
+
+// recordReference counts an evaluated reference
+func (sf *DefaultScopeFilter) recordReference(included bool) {
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+
+	sf.statistics.ReferencesEvaluated++
+	if included {
+		sf.statistics.ReferencesIncluded++
+	} else {
+		sf.statistics.ReferencesExcluded++
+	}
+}
+
+// recordResourceExclusion counts a resource excluded for the given reason
+func (sf *DefaultScopeFilter) recordResourceExclusion(reason string) {
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+
+	sf.statistics.ResourcesExcluded++
+	sf.statistics.FilterReasons[reason]++
+}
+
+// countFilterReason counts a reason a resource or reference was filtered out
+func (sf *DefaultScopeFilter) countFilterReason(reason string) {
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+
+	sf.statistics.FilterReasons[reason]++
 }
 
 // PlatformChecker implementation methods
@@ -431,6 +545,16 @@ func (pc *DefaultPlatformChecker) matchesPattern(value, pattern string) bool {
 	return value == pattern
 }
 
+// kindAllowed checks if a kind is on the allowlist, either bare or qualified
+// with its group; an empty allowlist allows every kind
+func (sf *DefaultScopeFilter) kindAllowed(kind, apiGroup string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	groupKind := schema.GroupKind{Group: apiGroup, Kind: kind}.String()
+	return sf.stringInSlice(kind, allowed) || sf.stringInSlice(groupKind, allowed)
+}
+
 // stringInSlice checks if a string is in a slice
 func (sf *DefaultScopeFilter) stringInSlice(str string, slice []string) bool {
 	for _, s := range slice {
@@ -443,6 +567,9 @@ func (sf *DefaultScopeFilter) stringInSlice(str string, slice []string) bool {
 
 // ResetStatistics resets the filtering statistics
 func (sf *DefaultScopeFilter) ResetStatistics() {
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+
 	sf.statistics = &FilterStatistics{
 		FilterReasons: make(map[string]int),
 	}
@@ -450,12 +577,13 @@ func (sf *DefaultScopeFilter) ResetStatistics() {
 
 // LogFilteringSummary logs a summary of filtering operations
 func (sf *DefaultScopeFilter) LogFilteringSummary() {
+	statistics := sf.GetFilterStatistics()
 	sf.logger.Info("Filtering summary",
-		"resourcesEvaluated", sf.statistics.ResourcesEvaluated,
-		"resourcesIncluded", sf.statistics.ResourcesIncluded,
-		"resourcesExcluded", sf.statistics.ResourcesExcluded,
-		"referencesEvaluated", sf.statistics.ReferencesEvaluated,
-		"referencesIncluded", sf.statistics.ReferencesIncluded,
-		"referencesExcluded", sf.statistics.ReferencesExcluded,
-		"filterReasons", sf.statistics.FilterReasons)
+		"resourcesEvaluated", statistics.ResourcesEvaluated,
+		"resourcesIncluded", statistics.ResourcesIncluded,
+		"resourcesExcluded", statistics.ResourcesExcluded,
+		"referencesEvaluated", statistics.ReferencesEvaluated,
+		"referencesIncluded", statistics.ReferencesIncluded,
+		"referencesExcluded", statistics.ReferencesExcluded,
+		"filterReasons", statistics.FilterReasons)
 }
@@ -0,0 +1,133 @@
+package traversal
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// StreamReferencedResources discovers the resources referenced by the given
+// resources like DiscoverReferencedResources, but emits each resolved reference
+// on the returned channel instead of collecting them. The channel holds at most
+// bufferSize results; once it is full, workers block until the caller drains
+// it, so at most MaxConcurrentRequests resources are processed ahead of the
+// consumer. A target referenced by several sources is emitted once per edge.
+// The channel is closed when discovery finishes or ctx is done.
+func (te *DefaultTraversalEngine) StreamReferencedResources(ctx context.Context, resources []*unstructured.Unstructured, config *TraversalConfig, bufferSize int) <-chan StreamedReference {
+	if bufferSize < 0 {
+		bufferSize = 0
+	}
+	out := make(chan StreamedReference, bufferSize)
+
+	workers := config.Performance.MaxConcurrentRequests
+	if workers <= 0 {
+		workers = 1
+	}
+	maxExtraction := config.Performance.MaxConcurrentExtraction
+	if maxExtraction <= 0 {
+		maxExtraction = workers
+	}
+	extractSem := make(chan struct{}, maxExtraction)
+	resolveSem := make(chan struct{}, workers)
+
+	// Invalid resolution options fail the whole stream with a single error
+	ctx, err := te.withResolutionOptions(ctx, config)
+	if err != nil {
+		go func() {
+			defer close(out)
+			select {
+			case out <- StreamedReference{Error: &TraversalError{
+				Type:      TraversalErrorReferenceResolution,
+				Message:   err.Error(),
+				Timestamp: time.Now(),
+			}}:
+			case <-ctx.Done():
+			}
+		}()
+		return out
+	}
+
+	// Resources streamed together share target lookups like a discovery depth
+	ctx = withResolutionMemo(ctx)
+
+	// send blocks until the consumer takes the result, reporting false once
+	// ctx is done so workers stop producing
+	send := func(item StreamedReference) bool {
+		select {
+		case out <- item:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	jobs := make(chan *unstructured.Unstructured)
+	go func() {
+		defer close(jobs)
+		for _, resource := range resources {
+			select {
+			case jobs <- resource:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// A fixed pool keeps a worker busy until its results are sent, which is
+	// what applies the consumer's backpressure to resolution
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for resource := range jobs {
+				if !te.streamResourceReferences(ctx, resource, config, extractSem, resolveSem, send) {
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out
+}
+
+// streamResourceReferences resolves the references of one resource and sends
+// each result, reporting false if sending was cut short by cancellation
+func (te *DefaultTraversalEngine) streamResourceReferences(ctx context.Context, resource *unstructured.Unstructured, config *TraversalConfig, extractSem, resolveSem chan struct{}, send func(StreamedReference) bool) bool {
+	resourceID := te.generateResourceID(resource)
+
+	_, resolutionResults, err := te.resolveResourceReferences(ctx, resource, config, extractSem, resolveSem)
+	if err != nil {
+		traversalError := extractionError(resourceID, err)
+		return send(StreamedReference{Error: &traversalError})
+	}
+
+	for _, resolution := range resolutionResults {
+		for _, referencedResource := range resolution.ResolvedResources {
+			edge := ReferenceEdge{
+				SourceID:  resourceID,
+				TargetID:  te.generateResourceID(referencedResource),
+				Reference: resolution.Reference,
+			}
+			if !send(StreamedReference{Edge: edge, Resource: referencedResource}) {
+				return false
+			}
+		}
+
+		if resolution.Error != nil {
+			traversalError := te.resolutionError(resourceID, resolution, config)
+			if !send(StreamedReference{Error: &traversalError}) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
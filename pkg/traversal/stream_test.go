@@ -0,0 +1,99 @@
+package traversal
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	dynamictypes "github.com/crossplane/function-kubecore-schema-registry/pkg/dynamic"
+)
+
+// backlogGaugeResolver resolves one target per source and records the peak
+// number of resolved results the consumer has not taken yet
+type backlogGaugeResolver struct {
+	mockReferenceResolver
+	produced, consumed atomic.Int32
+	peakBacklog        atomic.Int32
+}
+
+func (r *backlogGaugeResolver) ResolveReferenceResults(ctx context.Context, source *unstructured.Unstructured, references []dynamictypes.ReferenceField) []*ReferenceResolutionResult {
+	backlog := r.produced.Add(1) - r.consumed.Load()
+	for {
+		peak := r.peakBacklog.Load()
+		if backlog <= peak || r.peakBacklog.CompareAndSwap(peak, backlog) {
+			break
+		}
+	}
+	return []*ReferenceResolutionResult{{
+		Reference:         references[0],
+		ResolvedResources: []*unstructured.Unstructured{newTestResource("KubeCluster", source.GetName()+"-cluster")},
+	}}
+}
+
+func TestStreamReferencedResourcesBackpressure(t *testing.T) {
+	const (
+		bufferSize = 2
+		workers    = 3
+		sources    = 30
+	)
+
+	newResolver := func() *backlogGaugeResolver {
+		return &backlogGaugeResolver{mockReferenceResolver: mockReferenceResolver{
+			references: []dynamictypes.ReferenceField{
+				{FieldPath: "spec.clusterRef", FieldName: "clusterRef", TargetKind: "KubeCluster", Confidence: 0.9},
+			},
+		}}
+	}
+
+	config := NewDefaultTraversalConfig()
+	config.ScopeFilter = &ScopeFilterConfig{CrossNamespaceEnabled: true}
+	config.Performance.MaxConcurrentRequests = workers
+
+	var roots []*unstructured.Unstructured
+	for i := 0; i < sources; i++ {
+		roots = append(roots, newTestResource("KubeApp", fmt.Sprintf("app-%d", i)))
+	}
+
+	t.Run("SlowConsumer", func(t *testing.T) {
+		resolver := newResolver()
+		engine := newTestTraversalEngine(resolver)
+
+		received := 0
+		for item := range engine.StreamReferencedResources(context.Background(), roots, config, bufferSize) {
+			resolver.consumed.Add(1)
+			require.Nil(t, item.Error)
+			assert.Equal(t, item.Edge.TargetID, engine.generateResourceID(item.Resource))
+			received++
+			time.Sleep(2 * time.Millisecond)
+		}
+
+		assert.Equal(t, sources, received)
+		// Each worker holds at most one unsent result beyond the buffer, plus
+		// the result the consumer has received but not counted yet
+		assert.LessOrEqual(t, int(resolver.peakBacklog.Load()), bufferSize+workers+1)
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		resolver := newResolver()
+		engine := newTestTraversalEngine(resolver)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		stream := engine.StreamReferencedResources(ctx, roots, config, bufferSize)
+		<-stream
+		cancel()
+
+		// The channel closes once the workers notice the cancellation
+		drained := 0
+		for range stream {
+			drained++
+		}
+		assert.Less(t, 1+drained, sources)
+		assert.Less(t, int(resolver.produced.Load()), sources)
+	})
+}
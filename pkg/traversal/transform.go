@@ -0,0 +1,46 @@
+package traversal
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ResourceTransform rewrites a resource before it is added to the graph, e.g.
+// to drop fields that are large and irrelevant to discovery. It must not
+// modify its argument; returning nil keeps the resource unchanged.
+type ResourceTransform func(*unstructured.Unstructured) *unstructured.Unstructured
+
+// StripManagedFields returns a copy of the resource without
+// metadata.managedFields, which is often the largest part of a resource
+func StripManagedFields(resource *unstructured.Unstructured) *unstructured.Unstructured {
+	if len(resource.GetManagedFields()) == 0 {
+		return resource
+	}
+	stripped := resource.DeepCopy()
+	stripped.SetManagedFields(nil)
+	return stripped
+}
+
+// SetResourceTransform sets the transform applied to resources before they
+// are added to the graph and to the discovered resources. References are
+// still extracted from the untransformed resources. A nil transform keeps
+// resources as they were fetched.
+func (te *DefaultTraversalEngine) SetResourceTransform(transform ResourceTransform) {
+	te.mu.Lock()
+	defer te.mu.Unlock()
+	te.resourceTransform = transform
+}
+
+// transformResource applies the engine's resource transform, if any
+func (te *DefaultTraversalEngine) transformResource(resource *unstructured.Unstructured) *unstructured.Unstructured {
+	te.mu.RLock()
+	transform := te.resourceTransform
+	te.mu.RUnlock()
+
+	if transform == nil {
+		return resource
+	}
+	if transformed := transform(resource); transformed != nil {
+		return transformed
+	}
+	return resource
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 
@@ -21,30 +22,74 @@ type TraversalEngine interface {
 	// DiscoverReferencedResources discovers resources referenced by the given resources
 	DiscoverReferencedResources(ctx context.Context, resources []*unstructured.Unstructured, config *TraversalConfig) (*DiscoveryResult, error)
 
+	// StreamReferencedResources discovers resources referenced by the given
+	// resources and emits them on a bounded channel as they are resolved
+	StreamReferencedResources(ctx context.Context, resources []*unstructured.Unstructured, config *TraversalConfig, bufferSize int) <-chan StreamedReference
+
+	// ResolveAllReferences resolves the references of the given resources to flat edges
+	// without building a resource graph
+	ResolveAllReferences(ctx context.Context, resources []*unstructured.Unstructured, config *TraversalConfig) ([]ResolvedReference, error)
+
 	// BuildResourceGraph builds a resource dependency graph from discovered resources
 	BuildResourceGraph(ctx context.Context, resources []*unstructured.Unstructured, config *TraversalConfig) (*graph.ResourceGraph, error)
 
 	// ValidateTraversalResult validates the results of transitive discovery
 	ValidateTraversalResult(result *TraversalResult) *TraversalValidationResult
+
+	// Close releases caches and background goroutines held by the engine
+	Close() error
 }
 
 // TraversalConfig contains configuration for transitive discovery
 type TraversalConfig struct {
-	// MaxDepth limits the depth of transitive discovery
+	// MaxDepth limits the depth of transitive discovery. Zero returns only
+	// the root resources without following any references.
 	MaxDepth int
 
 	// MaxResources limits the total number of resources to discover
 	MaxResources int
 
+	// MaxEdges limits the total number of edges in the resource graph. Once
+	// reached, further edges are dropped and expansion stops; zero means
+	// unlimited
+	MaxEdges int
+
 	// Timeout limits the total time for traversal
 	Timeout time.Duration
 
 	// Direction specifies the direction of traversal
 	Direction graph.TraversalDirection
 
+	// SchedulingMode chooses the order in which forward traversal expands
+	// discovered resources; empty means SchedulingModeBreadthFirst
+	SchedulingMode SchedulingMode
+
 	// ScopeFilter determines which resources to include in traversal
 	ScopeFilter *ScopeFilterConfig
 
+	// TerminalKinds lists resource kinds that are added to the graph but
+	// whose references are not followed
+	TerminalKinds []string
+
+	// TargetKinds, when set, makes discovery goal-directed: references are
+	// still followed through resources of any kind, but only resources of
+	// these kinds are kept in DiscoveredResources. The resource graph keeps
+	// the intermediate resources so paths to the targets remain available.
+	TargetKinds []string
+
+	// ExpandPredicate decides whether a discovered resource's references are
+	// followed; resources it rejects are still added to the graph
+	ExpandPredicate ResourcePredicate
+
+	// AugmentationSelectors list additional resources that are folded into
+	// the frontier at each depth alongside the referenced ones
+	AugmentationSelectors []AugmentationSelector
+
+	// IncludeCompositionMetadata links every discovered composite resource to
+	// the CompositeResourceDefinition that defines its kind and to the
+	// Composition it selected, adding both to the graph
+	IncludeCompositionMetadata bool
+
 	// BatchConfig controls batch processing optimization
 	BatchConfig *BatchConfig
 
@@ -61,6 +106,18 @@ type TraversalConfig struct {
 	Performance *PerformanceConfig
 }
 
+// SchedulingMode defines the order in which discovered resources are expanded
+type SchedulingMode string
+
+const (
+	// SchedulingModeBreadthFirst expands every resource at one depth before
+	// moving to the next
+	SchedulingModeBreadthFirst SchedulingMode = "BreadthFirst"
+	// SchedulingModeBestFirst expands one resource at a time, picking the one
+	// whose discovery path has the highest product of reference confidences
+	SchedulingModeBestFirst SchedulingMode = "BestFirst"
+)
+
 // ScopeFilterConfig controls which resources are included in traversal
 type ScopeFilterConfig struct {
 	// IncludeAPIGroups specifies which API groups to include (allowlist)
@@ -75,10 +132,16 @@ type ScopeFilterConfig struct {
 	// ExcludeKinds specifies which resource kinds to exclude
 	ExcludeKinds []string
 
+	// AllowedKinds restricts traversal to these kinds, each a bare Kind allowed
+	// in any group or Kind.group, e.g. "Deployment.apps". Empty allows every kind.
+	AllowedKinds []string
+
 	// PlatformOnly limits traversal to platform resources only
 	PlatformOnly bool
 
-	// CrossNamespaceEnabled allows traversal across namespace boundaries
+	// CrossNamespaceEnabled allows traversal across namespace boundaries. When
+	// false, references naming a namespace other than their source's are skipped
+	// during resolution and reported as scope filter errors.
 	CrossNamespaceEnabled bool
 
 	// IncludeNamespaces specifies which namespaces to include
@@ -86,6 +149,10 @@ type ScopeFilterConfig struct {
 
 	// ExcludeNamespaces specifies which namespaces to exclude
 	ExcludeNamespaces []string
+
+	// SkipTerminatingResources drops resources whose deletionTimestamp is
+	// set, since they are on their way out rather than live dependencies
+	SkipTerminatingResources bool
 }
 
 // BatchConfig controls batch processing optimization
@@ -147,11 +214,95 @@ type ReferenceResolutionConfig struct {
 	// SkipMissingReferences continues traversal when referenced resources are missing
 	SkipMissingReferences bool
 
+	// FailOnForbidden aborts traversal when a referenced resource cannot be
+	// read because access is denied, instead of recording an access_denied
+	// warning and continuing
+	FailOnForbidden bool
+
 	// ReferencePatterns additional patterns for detecting reference fields
 	ReferencePatterns []ReferencePattern
 
+	// ReplaceDefaultPatterns detects references with ReferencePatterns only,
+	// instead of adding them to the built-in patterns
+	ReplaceDefaultPatterns bool
+
 	// MinConfidenceThreshold is the minimum confidence required for following references
 	MinConfidenceThreshold float64
+
+	// LowConfidenceWarningThreshold warns about resources whose references
+	// average a confidence below it, which usually means only heuristics
+	// matched and the kind should be added to the registry. Zero disables
+	// the warning.
+	LowConfidenceWarningThreshold float64
+
+	// NamespaceRewrite maps a reference's target namespace to the namespace it
+	// should be resolved in. Rewrites apply after the default same-namespace
+	// rule, so both explicit namespaces and namespaces inherited from the
+	// source resource are rewritten.
+	NamespaceRewrite map[string]string
+
+	// GroupAliases maps old API group names to their replacement. References
+	// to an aliased group are looked up in the new group first and fall back
+	// to the original group if that lookup fails.
+	GroupAliases map[string]string
+
+	// AnnotationReferences maps annotation key prefixes to reference targets.
+	// Annotations are only scanned for references when this is non-empty.
+	AnnotationReferences []AnnotationReferencePattern
+
+	// CELReferences derive references from resources of a kind by evaluating
+	// a CEL expression against the whole resource
+	CELReferences []CELReferenceExtractor
+
+	// ClusterSourceNamespace is the namespace that references from
+	// cluster-scoped sources, such as cluster XRs, resolve in when they name
+	// no namespace. Empty keeps the cluster-scoped then "default" lookup.
+	ClusterSourceNamespace string
+
+	// UseClaimNamespace resolves such references in the claim namespace
+	// recorded on the source by Crossplane, when present, before falling
+	// back to ClusterSourceNamespace
+	UseClaimNamespace bool
+}
+
+// AnnotationReferencePattern detects references encoded in annotations. Any
+// annotation whose key starts with KeyPrefix is a reference whose value is the
+// target name, resolved in the source resource's namespace.
+type AnnotationReferencePattern struct {
+	KeyPrefix     string
+	TargetKind    string
+	TargetGroup   string
+	TargetVersion string
+	Confidence    float64
+}
+
+// CELReferenceExtractor evaluates Expression against resources of Kind (and
+// APIVersion, when set) to produce references. The expression sees the
+// resource as "object" and returns a map, or a list of maps, with the target's
+// "name" and "kind" and optionally its "apiVersion" and "namespace".
+type CELReferenceExtractor struct {
+	Kind       string
+	APIVersion string
+	Expression string
+	Confidence float64
+}
+
+// AugmentationSelector selects resources to add to the traversal at a depth
+// even though nothing discovered so far references them, e.g. Events about
+// platform resources. Matched resources are expanded like referenced ones.
+type AugmentationSelector struct {
+	// APIVersion and Kind of the resources to list
+	APIVersion string
+	Kind       string
+
+	// Namespace limits the listing to one namespace; empty lists all namespaces
+	Namespace string
+
+	// LabelSelector filters the listed resources, in Kubernetes selector syntax
+	LabelSelector string
+
+	// Depths are the depths this selector applies at; empty means every depth
+	Depths []int
 }
 
 // CycleHandlingConfig controls how cycles are handled
@@ -167,6 +318,9 @@ type CycleHandlingConfig struct {
 
 	// ReportCycles includes cycle information in results
 	ReportCycles bool
+
+	// MinNodesForDetection skips cycle detection on graphs with fewer nodes (0 means always detect)
+	MinNodesForDetection int
 }
 
 // CycleAction defines actions to take when cycles are detected
@@ -186,6 +340,10 @@ type PerformanceConfig struct {
 	// MaxConcurrentRequests limits concurrent Kubernetes API requests
 	MaxConcurrentRequests int
 
+	// MaxConcurrentExtraction limits concurrent reference extraction, which
+	// is CPU-bound, separately from resolution. Zero uses MaxConcurrentRequests.
+	MaxConcurrentExtraction int
+
 	// RequestTimeout is the timeout for individual API requests
 	RequestTimeout time.Duration
 
@@ -231,6 +389,13 @@ type TraversalResult struct {
 	// CycleResults contains information about detected cycles
 	CycleResults *graph.CycleDetectionResult
 
+	// Errors contains recoverable errors encountered at every depth
+	Errors []TraversalError
+
+	// Warnings contains suspicious but non-fatal conditions found in the
+	// discovered resources
+	Warnings []TraversalWarning
+
 	// Metadata contains additional traversal metadata
 	Metadata *TraversalMetadata
 }
@@ -243,6 +408,9 @@ type DiscoveryResult struct {
 	// References contains the reference fields found in the resources
 	References map[string][]dynamictypes.ReferenceField
 
+	// Edges links each source resource to the resources its references resolved to
+	Edges []ReferenceEdge
+
 	// Depth is the depth at which these resources were discovered
 	Depth int
 
@@ -251,6 +419,61 @@ type DiscoveryResult struct {
 
 	// Errors contains any errors encountered during discovery
 	Errors []TraversalError
+
+	// Warnings contains suspicious but non-fatal conditions found while
+	// extracting and resolving references, unless the discovery is part of a
+	// transitive discovery, which reports them on its own result
+	Warnings []TraversalWarning
+}
+
+// ReferenceEdge is a resolved reference from one resource to another
+type ReferenceEdge struct {
+	// SourceID is the ID of the referencing resource
+	SourceID string
+
+	// TargetID is the ID of the resolved resource
+	TargetID string
+
+	// Reference is the reference field that resolved to the target
+	Reference dynamictypes.ReferenceField
+}
+
+// StreamedReference is one result emitted by StreamReferencedResources: either
+// a resolved reference with its target, or an error
+type StreamedReference struct {
+	// Edge links the source resource to the resolved target
+	Edge ReferenceEdge
+
+	// Resource is the resolved target resource
+	Resource *unstructured.Unstructured
+
+	// Error is set instead of Edge and Resource when extraction or resolution failed
+	Error *TraversalError
+}
+
+// ResolvedReference is a flat source to target reference edge, for consumers
+// that build their own structures instead of a ResourceGraph
+type ResolvedReference struct {
+	// SourceGVK is the group, version and kind of the referencing resource
+	SourceGVK schema.GroupVersionKind
+
+	// SourceNamespace is the namespace of the referencing resource
+	SourceNamespace string
+
+	// SourceName is the name of the referencing resource
+	SourceName string
+
+	// Target is the resource the reference resolved to
+	Target *unstructured.Unstructured
+
+	// RelationType is the graph relation type the reference maps to
+	RelationType graph.RelationType
+
+	// Confidence is the detection confidence of the reference
+	Confidence float64
+
+	// FieldPath is the path of the reference field in the source resource
+	FieldPath string
 }
 
 // TraversalPath represents the path taken during traversal
@@ -294,6 +517,12 @@ type TraversalStep struct {
 	// ReferencesFollowed is the number of references followed
 	ReferencesFollowed int
 
+	// ResourcesAdded is the number of resources first discovered in this step
+	ResourcesAdded int
+
+	// APICalls is the number of Kubernetes API calls made in this step
+	APICalls int
+
 	// Timestamp is when this step occurred
 	Timestamp time.Time
 
@@ -334,6 +563,10 @@ type TraversalStatistics struct {
 	// ResourcesByAPIGroup groups resources by their API group
 	ResourcesByAPIGroup map[string]int
 
+	// EdgesByRelationType groups the edges added to the graph by their
+	// relation type
+	EdgesByRelationType map[graph.RelationType]int
+
 	// TotalReferences is the total number of references found
 	TotalReferences int
 
@@ -523,6 +756,8 @@ const (
 	ValidationWarningManyResources ValidationWarningType = "many_resources"
 	// ValidationWarningSlowPerformance indicates slow traversal performance
 	ValidationWarningSlowPerformance ValidationWarningType = "slow_performance"
+	// ValidationWarningScopeFiltered indicates references were skipped by scope rules
+	ValidationWarningScopeFiltered ValidationWarningType = "scope_filtered"
 )
 
 // ValidationStatistics contains validation statistics
@@ -565,6 +800,10 @@ type TraversalError struct {
 
 	// Context provides additional context about the error
 	Context map[string]interface{}
+
+	// Count is how many times an error with the same type, resource and
+	// message occurred; repeats are folded into the first occurrence
+	Count int
 }
 
 // TraversalErrorType defines types of traversal errors
@@ -583,6 +822,39 @@ const (
 	TraversalErrorMemoryLimit TraversalErrorType = "memory_limit"
 )
 
+// TraversalWarning describes a suspicious condition found while traversing
+// that did not stop any reference from being followed
+type TraversalWarning struct {
+	// Type is the type of warning
+	Type TraversalWarningType
+
+	// Message is the warning message
+	Message string
+
+	// ResourceID identifies the resource the warning is about
+	ResourceID string
+
+	// Context provides additional context about the warning
+	Context map[string]interface{}
+}
+
+// TraversalWarningType defines types of traversal warnings
+type TraversalWarningType string
+
+const (
+	// TraversalWarningMultipleControllers indicates a resource has more than
+	// one owner reference marked as its controller
+	TraversalWarningMultipleControllers TraversalWarningType = "multiple_controllers"
+
+	// TraversalWarningAccessDenied indicates a referenced resource could not
+	// be read because the function lacks RBAC permission to read it
+	TraversalWarningAccessDenied TraversalWarningType = "access_denied"
+
+	// TraversalWarningKindMismatch indicates a reference resolved to an object
+	// of another kind than it expects, which was not linked
+	TraversalWarningKindMismatch TraversalWarningType = "kind_mismatch"
+)
+
 // TraversalMetadata contains additional metadata about the traversal
 type TraversalMetadata struct {
 	// Config is the configuration used for traversal
@@ -597,6 +869,10 @@ type TraversalMetadata struct {
 	// TerminationReason indicates why traversal stopped
 	TerminationReason TerminationReason
 
+	// CycleDetectionSkipped indicates cycle detection was enabled but skipped
+	// because the graph was smaller than CycleHandlingConfig.MinNodesForDetection
+	CycleDetectionSkipped bool
+
 	// Version is the version of the traversal engine used
 	Version string
 
@@ -614,6 +890,8 @@ const (
 	TerminationReasonMaxDepth TerminationReason = "max_depth"
 	// TerminationReasonMaxResources indicates maximum resource count was reached
 	TerminationReasonMaxResources TerminationReason = "max_resources"
+	// TerminationReasonMaxEdges indicates maximum edge count was reached
+	TerminationReasonMaxEdges TerminationReason = "max_edges"
 	// TerminationReasonTimeout indicates timeout was reached
 	TerminationReasonTimeout TerminationReason = "timeout"
 	// TerminationReasonError indicates an error caused termination
@@ -722,11 +1000,12 @@ func NewDefaultTraversalConfig() *TraversalConfig {
 
 // ReferencePattern defines patterns for detecting reference fields
 type ReferencePattern struct {
-	Pattern     string
-	TargetKind  string
-	TargetGroup string
-	RefType     RefType
-	Confidence  float64
+	Pattern      string
+	TargetKind   string
+	TargetGroup  string
+	RefType      RefType
+	Confidence   float64
+	NameTemplate string
 }
 
 // RefType represents the type of reference relationship
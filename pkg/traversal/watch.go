@@ -0,0 +1,162 @@
+package traversal
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/watch"
+
+	functionerrors "github.com/crossplane/function-kubecore-schema-registry/pkg/errors"
+	"github.com/crossplane/function-kubecore-schema-registry/pkg/graph"
+	"github.com/crossplane/function-kubecore-schema-registry/pkg/logfields"
+)
+
+// ApplyEvent updates a graph from a watch event instead of rebuilding it.
+//
+// Added and Modified events add the resource, as a new root if it is not in
+// the graph yet, or replace the resource of its node. Its reference edges are
+// then derived again from freshly extracted and resolved references; targets
+// new to the graph are added without following their own references, which
+// their own events bring up to date. Deleted events remove the node and its
+// edges. Either way, nodes the resource used to lead to that can no longer be
+// reached from a root are removed as well. Bookmark events are ignored.
+//
+// ApplyEvent is an engine method taking ctx and a TraversalConfig, not a
+// function of the graph alone: deriving the edges again resolves references
+// through the engine's resolver, which makes API calls bounded by ctx and
+// follows the scope and reference settings of the config. Events are taken to
+// come from the cluster being traversed; see ApplyClusterEvent for events
+// watched in another cluster.
+func (te *DefaultTraversalEngine) ApplyEvent(ctx context.Context, resourceGraph *graph.ResourceGraph, eventType watch.EventType, resource *unstructured.Unstructured, config *TraversalConfig) error {
+	return te.ApplyClusterEvent(ctx, resourceGraph, "", eventType, resource, config)
+}
+
+// ApplyClusterEvent applies a watch event like ApplyEvent, for a resource
+// watched in the given cluster. The resource is annotated with the cluster the
+// same way discovery annotates resources resolved from it, so the event
+// matches the node discovery added; "" is the cluster being traversed.
+func (te *DefaultTraversalEngine) ApplyClusterEvent(ctx context.Context, resourceGraph *graph.ResourceGraph, cluster string, eventType watch.EventType, resource *unstructured.Unstructured, config *TraversalConfig) error {
+	if resourceGraph == nil || resource == nil {
+		return nil
+	}
+	if cluster != "" {
+		resource = withResolvedCluster(resource, cluster)
+	}
+	nodeID := graph.NodeID(te.generateResourceID(resource))
+
+	switch eventType {
+	case watch.Added, watch.Modified:
+		return te.upsertEventResource(ctx, resourceGraph, nodeID, resource, config)
+	case watch.Deleted:
+		descendants := descendantsOf(resourceGraph, nodeID)
+		if graph.RemoveNode(resourceGraph, nodeID) {
+			pruneUnreachable(resourceGraph, descendants)
+		}
+		return nil
+	case watch.Bookmark:
+		return nil
+	default:
+		return fmt.Errorf("unsupported watch event type %q", eventType)
+	}
+}
+
+// upsertEventResource adds or replaces the node of an added or modified
+// resource and derives its reference edges again
+func (te *DefaultTraversalEngine) upsertEventResource(ctx context.Context, resourceGraph *graph.ResourceGraph, nodeID graph.NodeID, resource *unstructured.Unstructured, config *TraversalConfig) error {
+	discovery, err := te.DiscoverReferencedResources(ctx, []*unstructured.Unstructured{resource}, config)
+	if err != nil {
+		return functionerrors.Wrap(err, "failed to resolve references of "+string(nodeID))
+	}
+	for _, discoveryError := range discovery.Errors {
+		te.logger.Debug("Reference of watched resource not resolved",
+			logfields.ResourceID, nodeID,
+			"error", discoveryError.Message)
+	}
+
+	descendants := descendantsOf(resourceGraph, nodeID)
+	stored := te.transformResource(resource)
+	node, exists := resourceGraph.Nodes[nodeID]
+	if exists {
+		node.Resource = stored
+		node.UID = resource.GetUID()
+		for _, edgeID := range referenceEdgesOf(resourceGraph, nodeID) {
+			graph.RemoveEdge(resourceGraph, edgeID)
+		}
+	} else {
+		node = te.components.GraphBuilder.AddNode(resourceGraph, stored, 0, []graph.NodeID{})
+	}
+
+	for _, target := range discovery.Resources {
+		if _, known := resourceGraph.Nodes[graph.NodeID(te.generateResourceID(target))]; known {
+			continue
+		}
+		discoveryPath := te.buildDiscoveryPath(target, resourceGraph)
+		te.components.GraphBuilder.AddNode(resourceGraph, te.transformResource(target), node.DiscoveryDepth+1, discoveryPath)
+	}
+	for _, edge := range discovery.Edges {
+		reference := edge.Reference
+		for _, provenance := range graph.ReferenceProvenances(reference) {
+			te.components.GraphBuilder.AddEdgeWithProvenance(resourceGraph, graph.NodeID(edge.SourceID), graph.NodeID(edge.TargetID),
+				relationTypeForReference(reference.RefType), reference.FieldPath, reference.FieldName, provenance)
+		}
+	}
+
+	pruneUnreachable(resourceGraph, descendants)
+	return nil
+}
+
+// referenceEdgesOf returns the edges derived from the node's own references:
+// its outbound edges, and the inbound edges of relation types the builder
+// reverses. Composition edges are kept, as they are not derived from references.
+func referenceEdgesOf(resourceGraph *graph.ResourceGraph, nodeID graph.NodeID) []graph.EdgeID {
+	var edgeIDs []graph.EdgeID
+	collect := func(edgeIDsOfNode []graph.EdgeID, reversed bool) {
+		for _, edgeID := range edgeIDsOfNode {
+			edge, exists := resourceGraph.Edges[edgeID]
+			if !exists || edge.RelationType == graph.RelationTypeDefinedBy || edge.RelationType == graph.RelationTypeComposedBy {
+				continue
+			}
+			if (edge.Metadata != nil && edge.Metadata.Reversed) == reversed {
+				edgeIDs = append(edgeIDs, edgeID)
+			}
+		}
+	}
+	collect(resourceGraph.AdjacencyList[nodeID], false)
+	collect(resourceGraph.ReverseAdjacencyList[nodeID], true)
+	return edgeIDs
+}
+
+// descendantsOf returns the nodes reachable from a node, excluding the node
+func descendantsOf(resourceGraph *graph.ResourceGraph, nodeID graph.NodeID) []graph.NodeID {
+	reachable := graph.ReachableSet(resourceGraph, []graph.NodeID{nodeID}, -1)
+	descendants := make([]graph.NodeID, 0, len(reachable))
+	for descendant := range reachable {
+		if descendant != nodeID {
+			descendants = append(descendants, descendant)
+		}
+	}
+	return descendants
+}
+
+// pruneUnreachable removes the candidate nodes no root reaches any more. Roots
+// are the graph's root nodes and the nodes traversal started from, at depth 0.
+func pruneUnreachable(resourceGraph *graph.ResourceGraph, candidates []graph.NodeID) {
+	if len(candidates) == 0 {
+		return
+	}
+
+	roots := append([]graph.NodeID(nil), resourceGraph.Metadata.RootNodes...)
+	for nodeID, node := range resourceGraph.Nodes {
+		if node.DiscoveryDepth == 0 {
+			roots = append(roots, nodeID)
+		}
+	}
+
+	reachable := graph.ReachableSet(resourceGraph, roots, -1)
+	for _, nodeID := range candidates {
+		if _, ok := reachable[nodeID]; !ok {
+			graph.RemoveNode(resourceGraph, nodeID)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package traversal
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/watch"
+
+	dynamictypes "github.com/crossplane/function-kubecore-schema-registry/pkg/dynamic"
+	"github.com/crossplane/function-kubecore-schema-registry/pkg/graph"
+)
+
+func TestApplyEvent(t *testing.T) {
+	app := newTestResource("KubeApp", "my-app")
+	otherApp := newTestResource("KubeApp", "other-app")
+	env := newTestResource("KubEnv", "env")
+	cluster := newTestResource("KubeCluster", "cluster")
+
+	resolver := &mockReferenceResolver{
+		references: []dynamictypes.ReferenceField{
+			{FieldPath: "spec.ref", FieldName: "ref", TargetKind: "KubEnv", Confidence: 0.9},
+		},
+		resolvedBySource: map[string][]*unstructured.Unstructured{
+			"my-app":    {env},
+			"other-app": {env},
+			"env":       {cluster},
+		},
+	}
+	engine := newTestTraversalEngine(resolver)
+
+	config := NewDefaultTraversalConfig()
+	config.ScopeFilter = &ScopeFilterConfig{CrossNamespaceEnabled: true}
+
+	result, err := engine.ExecuteTransitiveDiscovery(context.Background(), config, []*unstructured.Unstructured{app})
+	require.NoError(t, err)
+	resourceGraph := result.ResourceGraph
+
+	id := func(resource *unstructured.Unstructured) graph.NodeID {
+		return graph.NodeID(engine.generateResourceID(resource))
+	}
+	requireGraph := func(wantNodes []graph.NodeID, wantEdges [][2]graph.NodeID) {
+		t.Helper()
+		nodes := make([]graph.NodeID, 0, len(resourceGraph.Nodes))
+		for nodeID := range resourceGraph.Nodes {
+			nodes = append(nodes, nodeID)
+		}
+		edges := make([][2]graph.NodeID, 0, len(resourceGraph.Edges))
+		for _, edge := range resourceGraph.Edges {
+			edges = append(edges, [2]graph.NodeID{edge.Source, edge.Target})
+		}
+		require.ElementsMatch(t, wantNodes, nodes)
+		require.ElementsMatch(t, wantEdges, edges)
+		require.Equal(t, len(resourceGraph.Nodes), resourceGraph.Metadata.TotalNodes)
+		require.Equal(t, len(resourceGraph.Edges), resourceGraph.Metadata.TotalEdges)
+		require.True(t, engine.components.GraphBuilder.ValidateGraph(resourceGraph).Valid)
+	}
+	requireGraph(
+		[]graph.NodeID{id(app), id(env), id(cluster)},
+		[][2]graph.NodeID{{id(app), id(env)}, {id(env), id(cluster)}},
+	)
+
+	// A second app sharing the environment joins as a root
+	require.NoError(t, engine.ApplyEvent(context.Background(), resourceGraph, watch.Added, otherApp, config))
+	requireGraph(
+		[]graph.NodeID{id(app), id(otherApp), id(env), id(cluster)},
+		[][2]graph.NodeID{{id(app), id(env)}, {id(otherApp), id(env)}, {id(env), id(cluster)}},
+	)
+	assert.Equal(t, 2, resourceGraph.Nodes[id(env)].Metadata.InboundReferenceCount)
+
+	// The environment is still used by the second app
+	require.NoError(t, engine.ApplyEvent(context.Background(), resourceGraph, watch.Deleted, app, config))
+	requireGraph(
+		[]graph.NodeID{id(otherApp), id(env), id(cluster)},
+		[][2]graph.NodeID{{id(otherApp), id(env)}, {id(env), id(cluster)}},
+	)
+	assert.Equal(t, 1, resourceGraph.Nodes[id(env)].Metadata.InboundReferenceCount)
+
+	// Deleting the last app cascades to everything only it led to
+	require.NoError(t, engine.ApplyEvent(context.Background(), resourceGraph, watch.Deleted, otherApp, config))
+	requireGraph([]graph.NodeID{}, [][2]graph.NodeID{})
+}
+
+func TestApplyClusterEventDeletesRemoteNode(t *testing.T) {
+	app := newTestResource("KubeApp", "my-app")
+	env := newTestResource("KubEnv", "env")
+	cluster := newTestResource("KubeCluster", "cluster")
+
+	// The environment and everything behind it live in a spoke cluster
+	resolver := &mockReferenceResolver{
+		references: []dynamictypes.ReferenceField{
+			{FieldPath: "spec.ref", FieldName: "ref", TargetKind: "KubEnv", Confidence: 0.9},
+		},
+		resolvedBySource: map[string][]*unstructured.Unstructured{
+			"my-app": {withResolvedCluster(env, "spoke-1")},
+			"env":    {withResolvedCluster(cluster, "spoke-1")},
+		},
+	}
+	engine := newTestTraversalEngine(resolver)
+
+	config := NewDefaultTraversalConfig()
+	config.ScopeFilter = &ScopeFilterConfig{CrossNamespaceEnabled: true}
+
+	result, err := engine.ExecuteTransitiveDiscovery(context.Background(), config, []*unstructured.Unstructured{app})
+	require.NoError(t, err)
+	resourceGraph := result.ResourceGraph
+
+	appID := graph.NodeID(engine.generateResourceID(app))
+	envID := graph.NodeID("platform.kubecore.io/v1/KubEnv/default/env@spoke-1")
+	clusterID := graph.NodeID("platform.kubecore.io/v1/KubeCluster/default/cluster@spoke-1")
+	require.Contains(t, resourceGraph.Nodes, envID)
+	require.Contains(t, resourceGraph.Nodes, clusterID)
+
+	// The event object carries no annotation; as a local event it matches nothing
+	require.NoError(t, engine.ApplyEvent(context.Background(), resourceGraph, watch.Deleted, env, config))
+	assert.Len(t, resourceGraph.Nodes, 3)
+
+	// From the spoke it matches the remote node and the deletion cascades
+	require.NoError(t, engine.ApplyClusterEvent(context.Background(), resourceGraph, "spoke-1", watch.Deleted, env, config))
+	assert.Equal(t, []graph.NodeID{appID}, slices.Collect(maps.Keys(resourceGraph.Nodes)))
+	assert.Empty(t, resourceGraph.Edges)
+}
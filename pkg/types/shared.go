@@ -42,13 +42,27 @@ type RegistryConfig struct {
 	CacheEnabled     bool
 	CacheTTL         time.Duration
 	LogLevel         string
+
+	// AllowedKinds restricts the kinds fetch requests may name, as Kind or
+	// Kind.group entries. Empty allows every kind.
+	AllowedKinds []string
+
+	// FetchMaxRetries and FetchRetryBackoff control retries of transient
+	// failures on individual resource Gets and Lists. Zero retries disables them.
+	FetchMaxRetries   int
+	FetchRetryBackoff time.Duration
+
+	// FetchRetryJitter is the largest fraction of each backoff added at random
+	FetchRetryJitter float64
 }
 
 // Default configuration values
 const (
-	DefaultDiscoveryTimeout = 5 * time.Second
-	DefaultCacheTTL         = 10 * time.Minute
-	DefaultMaxConcurrency   = 5
+	DefaultDiscoveryTimeout  = 5 * time.Second
+	DefaultCacheTTL          = 10 * time.Minute
+	DefaultMaxConcurrency    = 5
+	DefaultFetchRetryBackoff = 200 * time.Millisecond
+	DefaultFetchRetryJitter  = 0.2
 )
 
 // Default API group patterns for KubeCore